@@ -7,23 +7,24 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 
-	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
-	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
 	"github.com/PatrickWalther/twitch-miner-go/internal/logger"
-	"github.com/PatrickWalther/twitch-miner-go/internal/miner"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+	"github.com/PatrickWalther/twitch-miner-go/internal/pyimport"
+	"github.com/PatrickWalther/twitch-miner-go/internal/selftest"
 	"github.com/PatrickWalther/twitch-miner-go/internal/version"
-	"github.com/PatrickWalther/twitch-miner-go/internal/web"
+	"github.com/PatrickWalther/twitch-miner-go/pkg/miner"
 )
 
 var (
 	configFile = flag.String("config", "config.json", "Path to configuration file")
 	debug      = flag.Bool("debug", false, "Enable debug logging")
 	genConfig  = flag.Bool("generate-config", false, "Generate a sample configuration file")
+	importPy   = flag.String("import-python", "", "Import run.py settings and analytics from a Twitch-Channel-Points-Miner-v2 directory")
+	selfTest   = flag.Bool("selftest", false, "Check the login, Twitch services, Discord and the database, then exit")
 )
 
 func main() {
@@ -35,6 +36,12 @@ func main() {
 		return
 	}
 
+	if *importPy != "" {
+		setupBasicLogger(*debug)
+		importPythonMiner(*importPy, *configFile)
+		return
+	}
+
 	cfg, err := loadOrCreateConfig(*configFile)
 	if err != nil {
 		setupBasicLogger(*debug)
@@ -48,9 +55,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	if len(cfg.Streamers) == 0 {
+	if *selfTest {
 		setupBasicLogger(*debug)
-		slog.Error("At least one streamer is required in configuration")
+		runSelfTest(cfg)
+		return
+	}
+
+	if len(cfg.Streamers) == 0 && !cfg.Follows.Enabled {
+		setupBasicLogger(*debug)
+		slog.Error("At least one streamer is required in configuration, or follows.enabled")
 		os.Exit(1)
 	}
 
@@ -70,47 +83,58 @@ func main() {
 
 	slog.Info("Twitch Channel Points Miner", "version", version.Version)
 
-	var analyticsSvc *analytics.Service
-	var webServer *web.Server
-	var db *database.DB
-	if cfg.EnableAnalytics {
-		dbBasePath := filepath.Join("database", cfg.Username)
-		if err := os.MkdirAll(dbBasePath, 0755); err != nil {
-			slog.Error("Failed to create database directory", "error", err)
-			os.Exit(1)
-		}
-		db, err = database.Open(dbBasePath)
-		if err != nil {
-			slog.Error("Failed to open database", "error", err)
-			os.Exit(1)
-		}
-		defer func() { _ = db.Close() }()
-
-		analyticsSvc, err = analytics.NewService(db, dbBasePath)
-		if err != nil {
-			slog.Error("Failed to create analytics service", "error", err)
-			os.Exit(1)
-		}
-
-		webServer = web.NewServerEarly(cfg.Analytics, cfg.Username, dbBasePath, analyticsSvc)
-		if webServer != nil {
-			webServer.Start()
-			defer webServer.Stop()
-		}
+	m, err := miner.New(cfg, miner.Options{ConfigPath: *configFile, Dashboard: true})
+	if err != nil {
+		slog.Error("Failed to create miner", "error", err)
+		os.Exit(1)
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	m := miner.New(cfg, *configFile)
-	if analyticsSvc != nil {
-		m.SetAnalyticsService(analyticsSvc)
+	if err := run(ctx, m, cfg); err != nil {
+		slog.Error("Miner error", "error", err)
+		os.Exit(1)
+	}
+}
+
+func importPythonMiner(source, configPath string) {
+	result, err := pyimport.Run(source, configPath)
+	if result != nil {
+		for _, warning := range result.Warnings {
+			slog.Warn(warning)
+		}
 	}
-	if webServer != nil {
-		m.SetWebServer(webServer)
+	if err != nil {
+		slog.Error("Failed to import Python miner", "error", err)
+		os.Exit(1)
 	}
-	if err := m.Run(ctx); err != nil {
-		slog.Error("Miner error", "error", err)
+
+	fmt.Printf("\nImported %d streamers for %s into %s\n", len(result.Config.Streamers), result.Config.Username, result.ConfigPath)
+	if len(result.Analytics) > 0 {
+		points, annotations := 0, 0
+		for _, a := range result.Analytics {
+			points += a.Points
+			annotations += a.Annotations
+		}
+		fmt.Printf("Imported analytics of %d streamers: %d points entries, %d annotations\n", len(result.Analytics), points, annotations)
+	}
+	fmt.Println("Start the miner and log in with the device code to finish the migration")
+}
+
+// runSelfTest prints a PASS/FAIL table of the self-test checks and exits
+// with status 1 if any failed.
+func runSelfTest(cfg *config.Config) {
+	config.ValidateConfig(cfg)
+	if err := httpx.SetTLS(cfg.TLS.CAFile, cfg.TLS.InsecureSkipVerify); err != nil {
+		slog.Error("Failed to configure TLS", "error", err)
+		os.Exit(1)
+	}
+
+	results := selftest.Run(cfg)
+	fmt.Println()
+	selftest.Print(os.Stdout, results)
+	if selftest.Failed(results) {
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,16 @@
+//go:build !tray
+
+package main
+
+import (
+	"context"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/pkg/miner"
+)
+
+// run mines until ctx is cancelled. Builds with the tray tag can show a
+// system tray icon instead; see tray.go.
+func run(ctx context.Context, m *miner.Miner, cfg *config.Config) error {
+	return m.Run(ctx)
+}
@@ -0,0 +1,179 @@
+//go:build tray
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log/slog"
+	"net"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"fyne.io/systray"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/util"
+	"github.com/PatrickWalther/twitch-miner-go/pkg/miner"
+)
+
+var trayMode = flag.Bool("tray", false, "Run in the system tray instead of the terminal")
+
+// run mines until ctx is cancelled. With -tray, it shows a system tray icon
+// with the miner status, the total points and actions to pause, open the
+// dashboard and quit. The tray runs on the main goroutine, as macOS requires.
+func run(ctx context.Context, m *miner.Miner, cfg *config.Config) error {
+	if !*trayMode {
+		return m.Run(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Run(ctx)
+		systray.Quit()
+	}()
+
+	systray.Run(func() { setupTray(ctx, m, dashboardURL(cfg)) }, cancel)
+	cancel()
+	return <-done
+}
+
+func setupTray(ctx context.Context, m *miner.Miner, dashboard string) {
+	systray.SetIcon(trayIcon())
+	systray.SetTitle("Twitch Miner")
+	systray.SetTooltip("Twitch Channel Points Miner")
+
+	status := systray.AddMenuItem("Starting up...", "Miner status")
+	status.Disable()
+	points := systray.AddMenuItem("Points: -", "Channel points of all streamers")
+	points.Disable()
+	systray.AddSeparator()
+	pause := systray.AddMenuItem("Pause mining", "Stop or continue watching streams")
+	open := systray.AddMenuItem("Open dashboard", dashboard)
+	if dashboard == "" {
+		open.Disable()
+	}
+	systray.AddSeparator()
+	quit := systray.AddMenuItem("Quit", "Stop the miner")
+
+	statuses, unsubscribeStatus := m.SubscribeStatus()
+	events, unsubscribeEvents := m.Subscribe()
+
+	go func() {
+		defer unsubscribeStatus()
+		defer unsubscribeEvents()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case s, ok := <-statuses:
+				if !ok {
+					return
+				}
+				status.SetTitle(trayStatus(s, m.Paused()))
+				points.SetTitle("Points: " + util.FormatNumber(m.TotalPoints()))
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				switch e.Type {
+				case miner.EventPointsEarned, miner.EventPointsSpent, miner.EventPredictionResult:
+					points.SetTitle("Points: " + util.FormatNumber(m.TotalPoints()))
+				}
+			case <-pause.ClickedCh:
+				if m.Paused() {
+					m.Resume()
+					pause.SetTitle("Pause mining")
+				} else {
+					m.Pause()
+					pause.SetTitle("Resume mining")
+				}
+				status.SetTitle(trayStatus(m.Status(), m.Paused()))
+			case <-open.ClickedCh:
+				if err := openBrowser(dashboard); err != nil {
+					slog.Warn("Failed to open dashboard", "url", dashboard, "error", err)
+				}
+			case <-quit.ClickedCh:
+				systray.Quit()
+				return
+			}
+		}
+	}()
+}
+
+func trayStatus(s miner.Status, paused bool) string {
+	switch {
+	case s.Login != nil && s.Login.UserCode != "":
+		return fmt.Sprintf("Log in at %s with code %s", s.Login.VerificationURI, s.Login.UserCode)
+	case paused:
+		return "Mining paused"
+	case s.Message != "":
+		return s.Message
+	}
+	return string(s.State)
+}
+
+// dashboardURL returns the address of the web dashboard on this machine, or
+// an empty string if it is served on a Unix socket.
+func dashboardURL(cfg *config.Config) string {
+	if !cfg.EnableAnalytics || cfg.Analytics.Socket != "" {
+		return ""
+	}
+	host := cfg.Analytics.Host
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.Analytics.Port)) + "/"
+}
+
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	case "darwin":
+		return exec.Command("open", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+// trayIcon draws the Twitch purple icon. Windows needs it wrapped in an ICO
+// container, which may hold PNG data.
+func trayIcon() []byte {
+	const size = 32
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	purple := color.NRGBA{R: 0x91, G: 0x46, B: 0xff, A: 0xff}
+	for y := 2; y < size-2; y++ {
+		for x := 2; x < size-2; x++ {
+			img.Set(x, y, purple)
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	if runtime.GOOS != "windows" {
+		return buf.Bytes()
+	}
+
+	var ico bytes.Buffer
+	// ICONDIR: reserved, type 1 (icon), one image.
+	_ = binary.Write(&ico, binary.LittleEndian, [3]uint16{0, 1, 1})
+	// ICONDIRENTRY: width, height, colors, reserved, planes, bit count,
+	// data size and the offset after the 6 + 16 header bytes.
+	ico.Write([]byte{size, size, 0, 0})
+	_ = binary.Write(&ico, binary.LittleEndian, [2]uint16{1, 32})
+	_ = binary.Write(&ico, binary.LittleEndian, [2]uint32{uint32(buf.Len()), 22})
+	ico.Write(buf.Bytes())
+	return ico.Bytes()
+}
@@ -13,3 +13,18 @@ func NewChatLoggerAdapter(service *Service) *ChatLoggerAdapter {
 func (a *ChatLoggerAdapter) RecordChatMessage(streamer string, msg chat.ChatMessageData) error {
 	return a.service.RecordChatMessage(streamer, msg.Username, msg.DisplayName, msg.Message, msg.Emotes, msg.Badges, msg.Color)
 }
+
+// ParseEmotes parses the stored IRC emotes tag of a message into the emote
+// occurrences served as ChatMessage.EmoteList.
+func ParseEmotes(tag, message string) []Emote {
+	parsed := chat.ParseEmotes(tag, message)
+	if len(parsed) == 0 {
+		return nil
+	}
+
+	emotes := make([]Emote, len(parsed))
+	for i, e := range parsed {
+		emotes[i] = Emote(e)
+	}
+	return emotes
+}
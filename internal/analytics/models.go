@@ -1,5 +1,11 @@
 package analytics
 
+import (
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
 type SeriesPoint struct {
 	X int64  `json:"x"`
 	Y int    `json:"y"`
@@ -10,6 +16,9 @@ type Annotation struct {
 	X           int64           `json:"x"`
 	BorderColor string          `json:"borderColor"`
 	Label       AnnotationLabel `json:"label"`
+	// Category is the annotation type, e.g. WIN or WATCH_STREAK. It is empty
+	// for annotations of unknown type.
+	Category string `json:"category,omitempty"`
 }
 
 type AnnotationLabel struct {
@@ -42,6 +51,20 @@ type ChatMessage struct {
 	Emotes      string `json:"emotes,omitempty"`
 	Badges      string `json:"badges,omitempty"`
 	Color       string `json:"color,omitempty"`
+
+	// EmoteList is Emotes parsed into positions and CDN URLs. It is filled in
+	// when messages are served and not stored.
+	EmoteList []Emote `json:"emote_list,omitempty"`
+}
+
+// Emote is an emote occurrence in a ChatMessage. Start and End are inclusive
+// rune positions in the message.
+type Emote struct {
+	ID    string `json:"id"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Code  string `json:"code"`
+	URL   string `json:"url"`
 }
 
 type ChatLogData struct {
@@ -49,3 +72,189 @@ type ChatLogData struct {
 	TotalCount int           `json:"total_count"`
 	HasMore    bool          `json:"has_more"`
 }
+
+// ChatStats aggregates a streamer's chat log over a time window.
+type ChatStats struct {
+	Streamer        string         `json:"streamer"`
+	Since           int64          `json:"since"`
+	TotalMessages   int            `json:"total_messages"`
+	UniqueChatters  int            `json:"unique_chatters"`
+	NoBadgeChatters int            `json:"no_badge_chatters"`
+	TopMentioners   []ChatterCount `json:"top_mentioners"`
+	Badges          []BadgeStat    `json:"badges"`
+	Streams         []ChatSession  `json:"streams"`
+}
+
+// ChatterCount is how many matching messages a chatter sent.
+type ChatterCount struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Messages    int    `json:"messages"`
+}
+
+// BadgeStat counts chatters whose latest message carried a badge (broadcaster,
+// moderator, vip, subscriber, ...) and the messages those chatters sent.
+type BadgeStat struct {
+	Badge    string `json:"badge"`
+	Chatters int    `json:"chatters"`
+	Messages int    `json:"messages"`
+}
+
+// ChatSession is a run of chat activity without a gap longer than
+// ChatSessionGap, used as a stand-in for one stream.
+type ChatSession struct {
+	Start           int64   `json:"start"`
+	End             int64   `json:"end"`
+	Messages        int     `json:"messages"`
+	UniqueChatters  int     `json:"unique_chatters"`
+	MessagesPerHour float64 `json:"messages_per_hour"`
+}
+
+// AlignedSeries is a streamer's points over a date range with time and points
+// made relative to the start of the range, so ranges can be overlaid.
+type AlignedSeries struct {
+	Start  int64          `json:"start"`
+	End    int64          `json:"end"`
+	Points []AlignedPoint `json:"points"`
+}
+
+// AlignedPoint is a point of an AlignedSeries. Offset is milliseconds since
+// the range start and Gained is the change in points since then.
+type AlignedPoint struct {
+	Offset int64  `json:"offset"`
+	Gained int    `json:"gained"`
+	Points int    `json:"points"`
+	Event  string `json:"event,omitempty"`
+}
+
+// WatchSlotRange is a continuous period during which a streamer occupied a watch slot.
+type WatchSlotRange struct {
+	Streamer string `json:"streamer"`
+	Start    int64  `json:"start"`
+	End      int64  `json:"end"`
+	Samples  int    `json:"samples"`
+}
+
+// WatchSlot is a streamer occupying a watch slot and its viewer count at the time.
+type WatchSlot struct {
+	Streamer string
+	Viewers  int
+}
+
+// Efficiency summarizes how well a streamer paid off over a period: points
+// gained per hour spent in a watch slot.
+type Efficiency struct {
+	WatchedMinutes int     `json:"watched_minutes"`
+	Gained         int     `json:"gained"`
+	AvgViewers     int     `json:"avg_viewers"`
+	PointsPerHour  float64 `json:"points_per_hour"`
+}
+
+// HeartbeatDay is how many minute-watched reports for a streamer succeeded and
+// failed on one day, and how many bonus chests were claimed. ClaimsPerHour is
+// relative to the successful reports, one per minute watched.
+type HeartbeatDay struct {
+	Day           string  `json:"day"`
+	OK            int     `json:"ok"`
+	Failed        int     `json:"failed"`
+	Claims        int     `json:"claims"`
+	ClaimsPerHour float64 `json:"claims_per_hour"`
+}
+
+// PredictionRecord is the outcome of a single prediction the miner bet on.
+// BetSettings are the streamer's bet settings the bet was calculated with; it
+// is nil for predictions recorded before they were stored.
+type PredictionRecord struct {
+	Timestamp   int64               `json:"timestamp"`
+	EventID     string              `json:"event_id"`
+	Title       string              `json:"title"`
+	ResultType  string              `json:"result_type"`
+	Placed      int                 `json:"placed"`
+	Won         int                 `json:"won"`
+	Gained      int                 `json:"gained"`
+	BetSettings *models.BetSettings `json:"bet_settings"`
+}
+
+// PredictionSummary aggregates a streamer's prediction results over a period.
+// BiggestLoss is zero or negative.
+type PredictionSummary struct {
+	Streamer    string `json:"streamer"`
+	Predictions int    `json:"predictions"`
+	Wins        int    `json:"wins"`
+	Losses      int    `json:"losses"`
+	Refunds     int    `json:"refunds"`
+	NetPoints   int    `json:"net_points"`
+	BiggestWin  int    `json:"biggest_win"`
+	BiggestLoss int    `json:"biggest_loss"`
+}
+
+// StreamSession summarizes what the miner did during one of a streamer's
+// streams, from going live to going offline. Gained is the change in balance
+// over the stream, including points spent.
+type StreamSession struct {
+	Streamer            string    `json:"streamer"`
+	StartedAt           time.Time `json:"started_at"`
+	EndedAt             time.Time `json:"ended_at"`
+	PointsStart         int       `json:"points_start"`
+	PointsEnd           int       `json:"points_end"`
+	Gained              int       `json:"gained"`
+	WatchStreak         int       `json:"watch_streak"`
+	MinutesWatched      int       `json:"minutes_watched"`
+	PredictionsWon      int       `json:"predictions_won"`
+	PredictionsLost     int       `json:"predictions_lost"`
+	PredictionsRefunded int       `json:"predictions_refunded"`
+	PredictionNet       int       `json:"prediction_net"`
+}
+
+// BetTimingRecord is when a bet was placed relative to its prediction's lock.
+type BetTimingRecord struct {
+	EventID      string
+	Window       float64
+	Margin       float64
+	Late         bool
+	DecisionOdds float64
+	LockOdds     float64
+}
+
+// BetTimingSummary aggregates a streamer's bet timings. Times are in seconds:
+// AvgMargin and MinMargin are how long before the lock on-time bets were
+// placed, MaxLate how long after it the latest rejected bet came. OddsShift is
+// the average change of the chosen outcome's odds between the decision and the
+// lock, in percent.
+type BetTimingSummary struct {
+	Streamer  string  `json:"streamer"`
+	Bets      int     `json:"bets"`
+	Late      int     `json:"late"`
+	AvgMargin float64 `json:"avg_margin"`
+	MinMargin float64 `json:"min_margin"`
+	MaxLate   float64 `json:"max_late"`
+	AvgWindow float64 `json:"avg_window"`
+	OddsShift float64 `json:"odds_shift"`
+}
+
+// WinRate returns the percentage of decided (non-refunded) predictions that were won.
+func (s PredictionSummary) WinRate() float64 {
+	decided := s.Wins + s.Losses
+	if decided == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(decided) * 100
+}
+
+// Redemption is a channel points reward redeemed by the account.
+type Redemption struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	RewardID  string `json:"reward_id"`
+	Title     string `json:"title"`
+	Cost      int    `json:"cost"`
+	UserInput string `json:"user_input,omitempty"`
+}
+
+// StreamerNote is the user's note and labels for a channel, such as why it is
+// tracked.
+type StreamerNote struct {
+	Note      string   `json:"note"`
+	Labels    []string `json:"labels"`
+	UpdatedAt int64    `json:"updated_at"`
+}
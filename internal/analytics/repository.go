@@ -2,10 +2,16 @@ package analytics
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 	"github.com/PatrickWalther/twitch-miner-go/internal/util"
 )
 
@@ -13,17 +19,42 @@ type Repository interface {
 	RecordPoints(streamer string, points int, eventType string) error
 	RecordAnnotation(streamer string, eventType, text, color string) error
 	GetStreamerData(streamer string) (*StreamerData, error)
-	GetStreamerDataFiltered(streamer string, startTime, endTime time.Time) (*StreamerData, error)
+	GetStreamerDataFiltered(streamer string, startTime, endTime time.Time, types []string) (*StreamerData, error)
+	GetAlignedSeries(streamer string, startTime, endTime time.Time) (*AlignedSeries, error)
 	ListStreamers() ([]StreamerInfo, error)
+	GetPointsTotals(since time.Time) (total, gained int, err error)
+	GetPointsGained(since time.Time) (map[string]int, error)
 	RecordChatMessage(streamer string, msg ChatMessage) error
 	GetChatMessages(streamer string, limit, offset int) (*ChatLogData, error)
 	SearchChatMessages(streamer string, query string, limit, offset int) (*ChatLogData, error)
+	GetChatStats(streamer, mention string, since time.Time) (*ChatStats, error)
+	RecordWatchSlots(slots []WatchSlot) error
+	GetWatchSlotHistory(startTime, endTime time.Time) ([]WatchSlotRange, error)
+	GetEfficiency(since time.Time) (map[string]Efficiency, error)
+	RecordHeartbeat(streamer string, ok bool) error
+	GetHeartbeatTotals(since time.Time) (map[string][]HeartbeatDay, error)
+	RecordBonusClaim(streamer string) error
+	RecordPrediction(streamer string, prediction PredictionRecord) error
+	GetPredictionSummaries(startTime, endTime time.Time) ([]PredictionSummary, error)
+	GetPredictionHistory(streamer string, since time.Time) ([]PredictionRecord, error)
+	RecordBetTiming(streamer string, timing BetTimingRecord) error
+	RecordStreamSession(session StreamSession) error
+	GetBetTimingSummary(streamer string, since time.Time) (*BetTimingSummary, error)
+	RecordRedemption(streamer string, redemption Redemption) error
+	GetRedemptions(streamer string, limit int) ([]Redemption, error)
+	GetStreamerNotes() (map[string]StreamerNote, error)
+	SetStreamerNote(streamer string, note StreamerNote) error
+	ImportStreamerData(streamer string, data *StreamerData) (points, annotations int, err error)
+	EventStyle(eventType string) (EventStyle, bool)
+	GetEventStyles() []EventStyle
+	SetEventStyles(styles []EventStyle) error
 	Close() error
 }
 
 type SQLiteRepository struct {
 	db       *database.DB
 	basePath string
+	styles   *styleRegistry
 }
 
 type AnalyticsModule struct{}
@@ -86,6 +117,186 @@ func (m *AnalyticsModule) Migrations() []database.Migration {
 				CREATE INDEX IF NOT EXISTS idx_chat_streamer_time ON chat_messages(streamer_id, timestamp);
 			`,
 		},
+		{
+			Version:     3,
+			Description: "Create watch_slots table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS watch_slots (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					streamer_id INTEGER NOT NULL,
+					timestamp INTEGER NOT NULL,
+					FOREIGN KEY (streamer_id) REFERENCES streamers(id)
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_watch_slots_time ON watch_slots(timestamp);
+			`,
+		},
+		{
+			Version:     4,
+			Description: "Create predictions table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS predictions (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					streamer_id INTEGER NOT NULL,
+					event_id TEXT NOT NULL,
+					title TEXT NOT NULL,
+					timestamp INTEGER NOT NULL,
+					result_type TEXT NOT NULL,
+					placed INTEGER NOT NULL,
+					won INTEGER NOT NULL,
+					gained INTEGER NOT NULL,
+					FOREIGN KEY (streamer_id) REFERENCES streamers(id)
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_predictions_streamer_time ON predictions(streamer_id, timestamp);
+			`,
+		},
+		{
+			Version:     5,
+			Description: "Create redemptions table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS redemptions (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					streamer_id INTEGER NOT NULL,
+					redemption_id TEXT NOT NULL,
+					timestamp INTEGER NOT NULL,
+					reward_id TEXT NOT NULL,
+					title TEXT NOT NULL,
+					cost INTEGER NOT NULL,
+					user_input TEXT NOT NULL DEFAULT '',
+					FOREIGN KEY (streamer_id) REFERENCES streamers(id)
+				);
+
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_redemptions_redemption_id ON redemptions(redemption_id);
+				CREATE INDEX IF NOT EXISTS idx_redemptions_streamer_time ON redemptions(streamer_id, timestamp);
+			`,
+		},
+		{
+			Version:     6,
+			Description: "Index chat_messages by chatter",
+			SQL: `
+				CREATE INDEX IF NOT EXISTS idx_chat_streamer_user_time ON chat_messages(streamer_id, username, timestamp);
+			`,
+		},
+		{
+			Version:     7,
+			Description: "Create streamer_notes table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS streamer_notes (
+					streamer_id INTEGER PRIMARY KEY,
+					note TEXT NOT NULL DEFAULT '',
+					labels TEXT NOT NULL DEFAULT '[]',
+					updated_at INTEGER NOT NULL,
+					FOREIGN KEY (streamer_id) REFERENCES streamers(id)
+				);
+			`,
+		},
+		{
+			Version:     8,
+			Description: "Record viewer counts with watch slots",
+			SQL: `
+				ALTER TABLE watch_slots ADD COLUMN viewers INTEGER NOT NULL DEFAULT 0;
+				CREATE INDEX IF NOT EXISTS idx_watch_slots_streamer_time ON watch_slots(streamer_id, timestamp);
+			`,
+		},
+		{
+			Version:     9,
+			Description: "Create heartbeats table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS heartbeats (
+					streamer_id INTEGER NOT NULL,
+					day TEXT NOT NULL,
+					ok INTEGER NOT NULL DEFAULT 0,
+					failed INTEGER NOT NULL DEFAULT 0,
+					PRIMARY KEY (streamer_id, day),
+					FOREIGN KEY (streamer_id) REFERENCES streamers(id)
+				);
+			`,
+		},
+		{
+			Version:     10,
+			Description: "Add category to annotations",
+			SQL: `
+				ALTER TABLE annotations ADD COLUMN category TEXT NOT NULL DEFAULT '';
+				UPDATE annotations SET category = CASE color
+					WHEN '#45c1ff' THEN 'WATCH_STREAK'
+					WHEN '#ffe045' THEN 'PREDICTION_MADE'
+					WHEN '#a3a3a3' THEN 'PREDICTION_SKIP'
+					WHEN '#36b535' THEN 'WIN'
+					WHEN '#ff4545' THEN 'LOSE'
+					WHEN '#c084fc' THEN 'NOTE'
+					ELSE '' END;
+				CREATE INDEX IF NOT EXISTS idx_annotations_streamer_category ON annotations(streamer_id, category, timestamp);
+			`,
+		},
+		{
+			Version:     11,
+			Description: "Create bet_timings table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS bet_timings (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					streamer_id INTEGER NOT NULL,
+					event_id TEXT NOT NULL,
+					timestamp INTEGER NOT NULL,
+					window_seconds REAL NOT NULL,
+					margin_seconds REAL NOT NULL,
+					late INTEGER NOT NULL DEFAULT 0,
+					decision_odds REAL NOT NULL DEFAULT 0,
+					lock_odds REAL NOT NULL DEFAULT 0,
+					FOREIGN KEY (streamer_id) REFERENCES streamers(id)
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_bet_timings_streamer_time ON bet_timings(streamer_id, timestamp);
+			`,
+		},
+		{
+			Version:     12,
+			Description: "Count bonus claims with heartbeats",
+			SQL: `
+				ALTER TABLE heartbeats ADD COLUMN claims INTEGER NOT NULL DEFAULT 0;
+			`,
+		},
+		{
+			Version:     13,
+			Description: "Create stream_sessions table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS stream_sessions (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					streamer_id INTEGER NOT NULL,
+					started_at INTEGER NOT NULL,
+					ended_at INTEGER NOT NULL,
+					points_start INTEGER NOT NULL,
+					points_end INTEGER NOT NULL,
+					watch_streak INTEGER NOT NULL DEFAULT 0,
+					minutes_watched INTEGER NOT NULL DEFAULT 0,
+					predictions_won INTEGER NOT NULL DEFAULT 0,
+					predictions_lost INTEGER NOT NULL DEFAULT 0,
+					predictions_refunded INTEGER NOT NULL DEFAULT 0,
+					prediction_net INTEGER NOT NULL DEFAULT 0,
+					FOREIGN KEY (streamer_id) REFERENCES streamers(id)
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_stream_sessions_streamer_time ON stream_sessions(streamer_id, started_at);
+			`,
+		},
+		{
+			Version:     14,
+			Description: "Add bet_settings to predictions",
+			SQL: `
+				ALTER TABLE predictions ADD COLUMN bet_settings TEXT;
+			`,
+		},
+		{
+			Version:     15,
+			Description: "Create event_styles table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS event_styles (
+					type TEXT PRIMARY KEY,
+					color TEXT NOT NULL,
+					icon TEXT NOT NULL DEFAULT ''
+				);
+			`,
+		},
 	}
 }
 
@@ -95,14 +306,38 @@ func NewSQLiteRepository(db *database.DB, basePath string) (*SQLiteRepository, e
 		return nil, fmt.Errorf("failed to register analytics module: %w", err)
 	}
 
+	overrides, err := loadEventStyles(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event styles: %w", err)
+	}
+
 	repo := &SQLiteRepository{
 		db:       db,
 		basePath: basePath,
+		styles:   newStyleRegistry(overrides),
 	}
 
 	return repo, nil
 }
 
+func loadEventStyles(db *database.DB) ([]EventStyle, error) {
+	rows, err := db.Query("SELECT type, color, icon FROM event_styles")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var styles []EventStyle
+	for rows.Next() {
+		var style EventStyle
+		if err := rows.Scan(&style.Type, &style.Color, &style.Icon); err != nil {
+			return nil, err
+		}
+		styles = append(styles, style)
+	}
+	return styles, rows.Err()
+}
+
 func (r *SQLiteRepository) getOrCreateStreamer(name string) (int64, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -156,17 +391,20 @@ func (r *SQLiteRepository) RecordAnnotation(streamer string, eventType, text, co
 	}
 
 	_, err = r.db.Exec(
-		"INSERT INTO annotations (streamer_id, timestamp, text, color) VALUES (?, ?, ?, ?)",
-		streamerID, time.Now().UnixMilli(), text, color,
+		"INSERT INTO annotations (streamer_id, timestamp, text, color, category) VALUES (?, ?, ?, ?, ?)",
+		streamerID, time.Now().UnixMilli(), text, color, eventType,
 	)
 	return err
 }
 
 func (r *SQLiteRepository) GetStreamerData(streamer string) (*StreamerData, error) {
-	return r.GetStreamerDataFiltered(streamer, time.Time{}, time.Time{})
+	return r.GetStreamerDataFiltered(streamer, time.Time{}, time.Time{}, nil)
 }
 
-func (r *SQLiteRepository) GetStreamerDataFiltered(streamer string, startTime, endTime time.Time) (*StreamerData, error) {
+// GetStreamerDataFiltered returns the streamer's points and annotations
+// between startTime and endTime; zero times leave that side open. If types is
+// not empty only annotations of those categories are returned.
+func (r *SQLiteRepository) GetStreamerDataFiltered(streamer string, startTime, endTime time.Time, types []string) (*StreamerData, error) {
 	var streamerID int64
 	err := r.db.QueryRow("SELECT id FROM streamers WHERE name = ?", streamer).Scan(&streamerID)
 	if err == sql.ErrNoRows {
@@ -206,9 +444,16 @@ func (r *SQLiteRepository) GetStreamerDataFiltered(streamer string, startTime, e
 		data.Series = append(data.Series, p)
 	}
 
-	annotationsQuery := "SELECT timestamp, text, color FROM annotations WHERE streamer_id = ?"
+	annotationsQuery := "SELECT timestamp, text, color, category FROM annotations WHERE streamer_id = ?"
 	args = []interface{}{streamerID}
 
+	if len(types) > 0 {
+		annotationsQuery += " AND category IN (?" + strings.Repeat(", ?", len(types)-1) + ")"
+		for _, t := range types {
+			args = append(args, t)
+		}
+	}
+
 	if !startTime.IsZero() {
 		annotationsQuery += " AND timestamp >= ?"
 		args = append(args, startTime.UnixMilli())
@@ -228,20 +473,80 @@ func (r *SQLiteRepository) GetStreamerDataFiltered(streamer string, startTime, e
 	for rows.Next() {
 		var a Annotation
 		var text, color string
-		if err := rows.Scan(&a.X, &text, &color); err != nil {
+		if err := rows.Scan(&a.X, &text, &color, &a.Category); err != nil {
 			return nil, err
 		}
-		a.BorderColor = color
-		a.Label = AnnotationLabel{
-			Style: map[string]string{"color": "#000", "background": color},
-			Text:  text,
-		}
+		r.styles.apply(&a, text, color)
 		data.Annotations = append(data.Annotations, a)
 	}
 
 	return data, nil
 }
 
+// GetAlignedSeries returns the streamer's points between startTime and
+// endTime relative to startTime. Points gained are counted from the last
+// balance recorded before the range, which is included at offset 0, or from
+// the first point in the range if there is none.
+func (r *SQLiteRepository) GetAlignedSeries(streamer string, startTime, endTime time.Time) (*AlignedSeries, error) {
+	series := &AlignedSeries{
+		Start:  startTime.UnixMilli(),
+		End:    endTime.UnixMilli(),
+		Points: []AlignedPoint{},
+	}
+
+	var streamerID int64
+	err := r.db.QueryRow("SELECT id FROM streamers WHERE name = ?", streamer).Scan(&streamerID)
+	if err == sql.ErrNoRows {
+		return series, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline int
+	hasBaseline := true
+	err = r.db.QueryRow(
+		"SELECT points FROM points WHERE streamer_id = ? AND timestamp < ? ORDER BY timestamp DESC LIMIT 1",
+		streamerID, series.Start,
+	).Scan(&baseline)
+	if err == sql.ErrNoRows {
+		hasBaseline = false
+	} else if err != nil {
+		return nil, err
+	}
+	if hasBaseline {
+		series.Points = append(series.Points, AlignedPoint{Offset: 0, Gained: 0, Points: baseline})
+	}
+
+	rows, err := r.db.Query(
+		`SELECT timestamp, points, COALESCE(event_type, '') FROM points
+		WHERE streamer_id = ? AND timestamp >= ? AND timestamp <= ?
+		ORDER BY timestamp ASC`,
+		streamerID, series.Start, series.End,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ts int64
+		var p AlignedPoint
+		if err := rows.Scan(&ts, &p.Points, &p.Event); err != nil {
+			return nil, err
+		}
+		if !hasBaseline {
+			baseline = p.Points
+			hasBaseline = true
+		}
+		p.Offset = ts - series.Start
+		p.Gained = p.Points - baseline
+		series.Points = append(series.Points, p)
+	}
+
+	return series, rows.Err()
+}
+
 func (r *SQLiteRepository) ListStreamers() ([]StreamerInfo, error) {
 	query := `
 		SELECT s.name, 
@@ -271,6 +576,53 @@ func (r *SQLiteRepository) ListStreamers() ([]StreamerInfo, error) {
 	return streamers, nil
 }
 
+// GetPointsTotals returns the latest balance summed over all streamers and how
+// much of it was gained since the given time, measured from each streamer's
+// first sample after it.
+func (r *SQLiteRepository) GetPointsTotals(since time.Time) (total, gained int, err error) {
+	err = r.db.QueryRow(`
+		SELECT COALESCE(SUM(latest), 0), COALESCE(SUM(latest - first_since), 0)
+		FROM (
+			SELECT
+				(SELECT points FROM points WHERE streamer_id = s.id ORDER BY timestamp DESC LIMIT 1) AS latest,
+				(SELECT points FROM points WHERE streamer_id = s.id AND timestamp >= ? ORDER BY timestamp ASC LIMIT 1) AS first_since
+			FROM streamers s
+		)
+	`, since.UnixMilli()).Scan(&total, &gained)
+	return total, gained, err
+}
+
+// GetPointsGained returns how much each streamer's balance changed since the
+// given time, measured like GetPointsTotals. Streamers without samples since
+// then are left out.
+func (r *SQLiteRepository) GetPointsGained(since time.Time) (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT name, latest - first_since
+		FROM (
+			SELECT s.name,
+				(SELECT points FROM points WHERE streamer_id = s.id ORDER BY timestamp DESC LIMIT 1) AS latest,
+				(SELECT points FROM points WHERE streamer_id = s.id AND timestamp >= ? ORDER BY timestamp ASC LIMIT 1) AS first_since
+			FROM streamers s
+		)
+		WHERE first_since IS NOT NULL
+	`, since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gained := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var points int
+		if err := rows.Scan(&name, &points); err != nil {
+			return nil, err
+		}
+		gained[name] = points
+	}
+	return gained, rows.Err()
+}
+
 func (r *SQLiteRepository) RecordChatMessage(streamer string, msg ChatMessage) error {
 	streamerID, err := r.getOrCreateStreamer(streamer)
 	if err != nil {
@@ -396,6 +748,793 @@ func (r *SQLiteRepository) SearchChatMessages(streamer string, query string, lim
 	}, nil
 }
 
+// watchSlotMergeGap is the maximum distance between two consecutive slot samples
+// of the same streamer for them to be merged into one continuous range.
+const watchSlotMergeGap = 5 * time.Minute
+
+const (
+	// ChatSessionGap is the longest silence within one chat session. Streams
+	// are not recorded, so a session of chat activity stands in for a stream.
+	ChatSessionGap = 30 * time.Minute
+
+	maxChatStatsSessions   = 20
+	maxChatStatsMentioners = 10
+)
+
+// GetChatStats aggregates the chat log of a streamer since the given time:
+// chatters who mention the given name most, badge breakdown, and per-session
+// message rates and unique chatters.
+func (r *SQLiteRepository) GetChatStats(streamer, mention string, since time.Time) (*ChatStats, error) {
+	stats := &ChatStats{
+		Streamer:      streamer,
+		Since:         since.UnixMilli(),
+		TopMentioners: []ChatterCount{},
+		Badges:        []BadgeStat{},
+		Streams:       []ChatSession{},
+	}
+
+	var streamerID int64
+	err := r.db.QueryRow("SELECT id FROM streamers WHERE name = ?", streamer).Scan(&streamerID)
+	if err == sql.ErrNoRows {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.chatBadgeStats(stats, streamerID); err != nil {
+		return nil, err
+	}
+	if mention != "" {
+		if err := r.chatTopMentioners(stats, streamerID, mention); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.chatSessions(stats, streamerID); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// chatBadgeStats counts messages and chatters, attributing each chatter to the
+// badges of their latest message.
+func (r *SQLiteRepository) chatBadgeStats(stats *ChatStats, streamerID int64) error {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(badges, ''), MAX(timestamp), COUNT(*)
+		FROM chat_messages
+		WHERE streamer_id = ? AND timestamp >= ?
+		GROUP BY username
+	`, streamerID, stats.Since)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byBadge := make(map[string]*BadgeStat)
+	for rows.Next() {
+		var badges string
+		var latest int64
+		var messages int
+		if err := rows.Scan(&badges, &latest, &messages); err != nil {
+			return err
+		}
+
+		stats.UniqueChatters++
+		stats.TotalMessages += messages
+
+		names := parseBadgeNames(badges)
+		if len(names) == 0 {
+			stats.NoBadgeChatters++
+			continue
+		}
+		for _, name := range names {
+			stat, ok := byBadge[name]
+			if !ok {
+				stat = &BadgeStat{Badge: name}
+				byBadge[name] = stat
+			}
+			stat.Chatters++
+			stat.Messages += messages
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, stat := range byBadge {
+		stats.Badges = append(stats.Badges, *stat)
+	}
+	sort.Slice(stats.Badges, func(i, j int) bool {
+		if stats.Badges[i].Chatters != stats.Badges[j].Chatters {
+			return stats.Badges[i].Chatters > stats.Badges[j].Chatters
+		}
+		return stats.Badges[i].Badge < stats.Badges[j].Badge
+	})
+	return nil
+}
+
+// parseBadgeNames returns the badge names of an IRC badges tag
+// ("subscriber/12,vip/1"), without versions.
+func parseBadgeNames(badges string) []string {
+	var names []string
+	for _, badge := range strings.Split(badges, ",") {
+		name, _, _ := strings.Cut(badge, "/")
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (r *SQLiteRepository) chatTopMentioners(stats *ChatStats, streamerID int64, mention string) error {
+	mention = strings.ToLower(mention)
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(mention)
+
+	rows, err := r.db.Query(`
+		SELECT username, MAX(display_name), COUNT(*) AS messages
+		FROM chat_messages
+		WHERE streamer_id = ? AND timestamp >= ? AND username != ? AND message LIKE ? ESCAPE '\'
+		GROUP BY username
+		ORDER BY messages DESC, username
+		LIMIT ?
+	`, streamerID, stats.Since, mention, "%"+escaped+"%", maxChatStatsMentioners)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c ChatterCount
+		if err := rows.Scan(&c.Username, &c.DisplayName, &c.Messages); err != nil {
+			return err
+		}
+		stats.TopMentioners = append(stats.TopMentioners, c)
+	}
+	return rows.Err()
+}
+
+// chatSessions splits the chat log into sessions separated by ChatSessionGap
+// and returns the most recent ones, newest first.
+func (r *SQLiteRepository) chatSessions(stats *ChatStats, streamerID int64) error {
+	rows, err := r.db.Query(`
+		WITH gaps AS (
+			SELECT timestamp, username,
+				CASE WHEN timestamp - LAG(timestamp) OVER (ORDER BY timestamp) <= ? THEN 0 ELSE 1 END AS starts
+			FROM chat_messages
+			WHERE streamer_id = ? AND timestamp >= ?
+		),
+		sessions AS (
+			SELECT timestamp, username, SUM(starts) OVER (ORDER BY timestamp) AS session
+			FROM gaps
+		)
+		SELECT MIN(timestamp), MAX(timestamp), COUNT(*), COUNT(DISTINCT username)
+		FROM sessions
+		GROUP BY session
+		ORDER BY session DESC
+		LIMIT ?
+	`, ChatSessionGap.Milliseconds(), streamerID, stats.Since, maxChatStatsSessions)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var session ChatSession
+		if err := rows.Scan(&session.Start, &session.End, &session.Messages, &session.UniqueChatters); err != nil {
+			return err
+		}
+		hours := max(time.Duration(session.End-session.Start)*time.Millisecond, time.Minute).Hours()
+		session.MessagesPerHour = math.Round(float64(session.Messages)/hours*10) / 10
+		stats.Streams = append(stats.Streams, session)
+	}
+	return rows.Err()
+}
+
+func (r *SQLiteRepository) RecordWatchSlots(slots []WatchSlot) error {
+	if len(slots) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UnixMilli()
+	for _, slot := range slots {
+		streamerID, err := r.getOrCreateStreamerTx(tx, slot.Streamer)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO watch_slots (streamer_id, timestamp, viewers) VALUES (?, ?, ?)", streamerID, now, slot.Viewers); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteRepository) GetWatchSlotHistory(startTime, endTime time.Time) ([]WatchSlotRange, error) {
+	query := `SELECT s.name, w.timestamp FROM watch_slots w
+		JOIN streamers s ON s.id = w.streamer_id
+		WHERE 1 = 1`
+	var args []interface{}
+
+	if !startTime.IsZero() {
+		query += " AND w.timestamp >= ?"
+		args = append(args, startTime.UnixMilli())
+	}
+	if !endTime.IsZero() {
+		query += " AND w.timestamp <= ?"
+		args = append(args, endTime.UnixMilli())
+	}
+	query += " ORDER BY s.name ASC, w.timestamp ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gap := watchSlotMergeGap.Milliseconds()
+	span := time.Minute.Milliseconds()
+
+	ranges := []WatchSlotRange{}
+	for rows.Next() {
+		var name string
+		var ts int64
+		if err := rows.Scan(&name, &ts); err != nil {
+			return nil, err
+		}
+
+		if n := len(ranges); n > 0 {
+			last := &ranges[n-1]
+			if last.Streamer == name && ts-last.End <= gap {
+				last.End = ts + span
+				last.Samples++
+				continue
+			}
+		}
+
+		ranges = append(ranges, WatchSlotRange{
+			Streamer: name,
+			Start:    ts,
+			End:      ts + span,
+			Samples:  1,
+		})
+	}
+
+	return ranges, rows.Err()
+}
+
+// heartbeatDayFormat is the local-time day heartbeat totals are kept under.
+const heartbeatDayFormat = "2006-01-02"
+
+// RecordHeartbeat adds a minute-watched report to the streamer's total for today.
+func (r *SQLiteRepository) RecordHeartbeat(streamer string, ok bool) error {
+	streamerID, err := r.getOrCreateStreamer(streamer)
+	if err != nil {
+		return err
+	}
+
+	okCount, failed := 0, 0
+	if ok {
+		okCount = 1
+	} else {
+		failed = 1
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO heartbeats (streamer_id, day, ok, failed) VALUES (?, ?, ?, ?)
+		ON CONFLICT(streamer_id, day) DO UPDATE SET
+			ok = ok + excluded.ok, failed = failed + excluded.failed
+	`, streamerID, time.Now().Format(heartbeatDayFormat), okCount, failed)
+	return err
+}
+
+// RecordBonusClaim adds a claimed bonus chest to the streamer's total for today.
+func (r *SQLiteRepository) RecordBonusClaim(streamer string) error {
+	streamerID, err := r.getOrCreateStreamer(streamer)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO heartbeats (streamer_id, day, claims) VALUES (?, ?, 1)
+		ON CONFLICT(streamer_id, day) DO UPDATE SET claims = claims + 1
+	`, streamerID, time.Now().Format(heartbeatDayFormat))
+	return err
+}
+
+// GetHeartbeatTotals returns the daily minute-watched and bonus claim totals of
+// every streamer from the day of since onwards, oldest first.
+func (r *SQLiteRepository) GetHeartbeatTotals(since time.Time) (map[string][]HeartbeatDay, error) {
+	rows, err := r.db.Query(`
+		SELECT s.name, h.day, h.ok, h.failed, h.claims
+		FROM heartbeats h
+		JOIN streamers s ON s.id = h.streamer_id
+		WHERE h.day >= ?
+		ORDER BY s.name ASC, h.day ASC
+	`, since.Format(heartbeatDayFormat))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string][]HeartbeatDay)
+	for rows.Next() {
+		var name string
+		var day HeartbeatDay
+		if err := rows.Scan(&name, &day.Day, &day.OK, &day.Failed, &day.Claims); err != nil {
+			return nil, err
+		}
+		if day.OK > 0 {
+			day.ClaimsPerHour = float64(day.Claims) * 60 / float64(day.OK)
+		}
+		totals[name] = append(totals[name], day)
+	}
+
+	return totals, rows.Err()
+}
+
+// GetEfficiency returns, per streamer, the minutes spent in a watch slot since
+// the given time, the average viewer count while watched and the points gained
+// in that period. Every balance increase counts as gained, spending is ignored.
+func (r *SQLiteRepository) GetEfficiency(since time.Time) (map[string]Efficiency, error) {
+	sinceMs := since.UnixMilli()
+	result := make(map[string]Efficiency)
+
+	rows, err := r.db.Query(`
+		SELECT s.name, COUNT(*), COALESCE(AVG(NULLIF(w.viewers, 0)), 0)
+		FROM watch_slots w
+		JOIN streamers s ON s.id = w.streamer_id
+		WHERE w.timestamp >= ?
+		GROUP BY s.name
+	`, sinceMs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var eff Efficiency
+		var avgViewers float64
+		if err := rows.Scan(&name, &eff.WatchedMinutes, &avgViewers); err != nil {
+			return nil, err
+		}
+		eff.AvgViewers = int(avgViewers + 0.5)
+		result[name] = eff
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	gainRows, err := r.db.Query(`
+		SELECT s.name, SUM(d.delta)
+		FROM (
+			SELECT streamer_id, timestamp,
+				points - LAG(points) OVER (PARTITION BY streamer_id ORDER BY timestamp) AS delta
+			FROM points
+		) d
+		JOIN streamers s ON s.id = d.streamer_id
+		WHERE d.timestamp >= ? AND d.delta > 0
+		GROUP BY s.name
+	`, sinceMs)
+	if err != nil {
+		return nil, err
+	}
+	defer gainRows.Close()
+
+	for gainRows.Next() {
+		var name string
+		var gained int
+		if err := gainRows.Scan(&name, &gained); err != nil {
+			return nil, err
+		}
+		eff := result[name]
+		eff.Gained = gained
+		result[name] = eff
+	}
+	if err := gainRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for name, eff := range result {
+		if eff.WatchedMinutes > 0 {
+			eff.PointsPerHour = float64(eff.Gained) / (float64(eff.WatchedMinutes) / 60)
+		}
+		result[name] = eff
+	}
+
+	return result, nil
+}
+
+func (r *SQLiteRepository) RecordPrediction(streamer string, prediction PredictionRecord) error {
+	streamerID, err := r.getOrCreateStreamer(streamer)
+	if err != nil {
+		return err
+	}
+
+	var betSettings sql.NullString
+	if prediction.BetSettings != nil {
+		data, err := json.Marshal(prediction.BetSettings)
+		if err != nil {
+			return err
+		}
+		betSettings = sql.NullString{String: string(data), Valid: true}
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO predictions (streamer_id, event_id, title, timestamp, result_type, placed, won, gained, bet_settings)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		streamerID, prediction.EventID, prediction.Title, time.Now().UnixMilli(),
+		prediction.ResultType, prediction.Placed, prediction.Won, prediction.Gained, betSettings,
+	)
+	return err
+}
+
+// GetPredictionHistory returns the streamer's predictions recorded since the
+// given time, oldest first, with the bet settings each bet was calculated with.
+func (r *SQLiteRepository) GetPredictionHistory(streamer string, since time.Time) ([]PredictionRecord, error) {
+	rows, err := r.db.Query(`
+		SELECT p.timestamp, p.event_id, p.title, p.result_type, p.placed, p.won, p.gained, p.bet_settings
+		FROM predictions p
+		JOIN streamers s ON s.id = p.streamer_id
+		WHERE s.name = ? AND p.timestamp >= ?
+		ORDER BY p.timestamp ASC
+	`, streamer, since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []PredictionRecord{}
+	for rows.Next() {
+		var record PredictionRecord
+		var betSettings sql.NullString
+		if err := rows.Scan(
+			&record.Timestamp, &record.EventID, &record.Title, &record.ResultType,
+			&record.Placed, &record.Won, &record.Gained, &betSettings,
+		); err != nil {
+			return nil, err
+		}
+		if betSettings.Valid {
+			record.BetSettings = &models.BetSettings{}
+			if err := json.Unmarshal([]byte(betSettings.String), record.BetSettings); err != nil {
+				return nil, err
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+func (r *SQLiteRepository) GetPredictionSummaries(startTime, endTime time.Time) ([]PredictionSummary, error) {
+	rows, err := r.db.Query(`
+		SELECT s.name,
+			COUNT(*),
+			SUM(CASE WHEN p.result_type = 'WIN' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN p.result_type = 'LOSE' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN p.result_type = 'REFUND' THEN 1 ELSE 0 END),
+			SUM(p.gained),
+			MAX(p.gained),
+			MIN(p.gained)
+		FROM predictions p
+		JOIN streamers s ON s.id = p.streamer_id
+		WHERE p.timestamp >= ? AND p.timestamp < ?
+		GROUP BY s.name
+		ORDER BY s.name ASC
+	`, startTime.UnixMilli(), endTime.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []PredictionSummary{}
+	for rows.Next() {
+		var summary PredictionSummary
+		if err := rows.Scan(
+			&summary.Streamer, &summary.Predictions, &summary.Wins, &summary.Losses, &summary.Refunds,
+			&summary.NetPoints, &summary.BiggestWin, &summary.BiggestLoss,
+		); err != nil {
+			return nil, err
+		}
+		if summary.BiggestWin < 0 {
+			summary.BiggestWin = 0
+		}
+		if summary.BiggestLoss > 0 {
+			summary.BiggestLoss = 0
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+func (r *SQLiteRepository) RecordBetTiming(streamer string, timing BetTimingRecord) error {
+	streamerID, err := r.getOrCreateStreamer(streamer)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO bet_timings (streamer_id, event_id, timestamp, window_seconds, margin_seconds, late, decision_odds, lock_odds)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		streamerID, timing.EventID, time.Now().UnixMilli(), timing.Window, timing.Margin,
+		timing.Late, timing.DecisionOdds, timing.LockOdds,
+	)
+	return err
+}
+
+// RecordStreamSession stores the summary of a stream that ended.
+func (r *SQLiteRepository) RecordStreamSession(session StreamSession) error {
+	streamerID, err := r.getOrCreateStreamer(session.Streamer)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO stream_sessions (streamer_id, started_at, ended_at, points_start, points_end, watch_streak,
+			minutes_watched, predictions_won, predictions_lost, predictions_refunded, prediction_net)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		streamerID, session.StartedAt.UnixMilli(), session.EndedAt.UnixMilli(), session.PointsStart, session.PointsEnd,
+		session.WatchStreak, session.MinutesWatched, session.PredictionsWon, session.PredictionsLost,
+		session.PredictionsRefunded, session.PredictionNet,
+	)
+	return err
+}
+
+// GetBetTimingSummary aggregates the streamer's bet timings recorded since the
+// given time. Margins and the odds shift only count bets placed before the lock.
+func (r *SQLiteRepository) GetBetTimingSummary(streamer string, since time.Time) (*BetTimingSummary, error) {
+	summary := &BetTimingSummary{Streamer: streamer}
+	var avgMargin, minMargin, maxLate, avgWindow, oddsShift sql.NullFloat64
+
+	err := r.db.QueryRow(`
+		SELECT COUNT(*),
+			COALESCE(SUM(t.late), 0),
+			AVG(CASE WHEN t.late = 0 THEN t.margin_seconds END),
+			MIN(CASE WHEN t.late = 0 THEN t.margin_seconds END),
+			MAX(CASE WHEN t.late = 1 THEN -t.margin_seconds END),
+			AVG(t.window_seconds),
+			AVG(CASE WHEN t.late = 0 AND t.decision_odds > 0 AND t.lock_odds > 0
+				THEN ABS(t.lock_odds - t.decision_odds) * 100 / t.decision_odds END)
+		FROM bet_timings t
+		JOIN streamers s ON s.id = t.streamer_id
+		WHERE s.name = ? AND t.timestamp >= ?
+	`, streamer, since.UnixMilli()).Scan(
+		&summary.Bets, &summary.Late, &avgMargin, &minMargin, &maxLate, &avgWindow, &oddsShift,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.AvgMargin = avgMargin.Float64
+	summary.MinMargin = minMargin.Float64
+	summary.MaxLate = max(maxLate.Float64, 0)
+	summary.AvgWindow = avgWindow.Float64
+	summary.OddsShift = oddsShift.Float64
+	return summary, nil
+}
+
+// ImportStreamerData stores a streamer's points and annotations with their
+// original timestamps in one transaction. Rows matching an existing row's
+// timestamp are skipped, so importing the same data twice adds nothing.
+func (r *SQLiteRepository) ImportStreamerData(streamer string, data *StreamerData) (points, annotations int, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	streamerID, err := r.getOrCreateStreamerTx(tx, streamer)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, p := range data.Series {
+		result, err := tx.Exec(`
+			INSERT INTO points (streamer_id, timestamp, points, event_type)
+			SELECT ?, ?, ?, ?
+			WHERE NOT EXISTS (SELECT 1 FROM points WHERE streamer_id = ? AND timestamp = ?)`,
+			streamerID, p.X, p.Y, p.Z, streamerID, p.X,
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			points++
+		}
+	}
+
+	for _, a := range data.Annotations {
+		category := a.Category
+		if category == "" {
+			category = annotationCategory(a.BorderColor)
+		}
+		result, err := tx.Exec(`
+			INSERT INTO annotations (streamer_id, timestamp, text, color, category)
+			SELECT ?, ?, ?, ?, ?
+			WHERE NOT EXISTS (SELECT 1 FROM annotations WHERE streamer_id = ? AND timestamp = ?)`,
+			streamerID, a.X, a.Label.Text, a.BorderColor, category, streamerID, a.X,
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			annotations++
+		}
+	}
+
+	return points, annotations, tx.Commit()
+}
+
+// EventStyle returns the current style of an annotation type, or false if
+// the type is unknown.
+func (r *SQLiteRepository) EventStyle(eventType string) (EventStyle, bool) {
+	return r.styles.get(eventType)
+}
+
+// GetEventStyles returns the current style of every annotation type.
+func (r *SQLiteRepository) GetEventStyles() []EventStyle {
+	return r.styles.all()
+}
+
+// SetEventStyles stores the given styles, leaving other types unchanged. A
+// style equal to the default removes the stored override. Nothing is stored
+// if any style is invalid.
+func (r *SQLiteRepository) SetEventStyles(styles []EventStyle) error {
+	normalized := make([]EventStyle, 0, len(styles))
+	for _, style := range styles {
+		style, err := normalizeEventStyle(style)
+		if err != nil {
+			return err
+		}
+		normalized = append(normalized, style)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, style := range normalized {
+		if def, _ := defaultEventStyle(style.Type); style == def {
+			_, err = tx.Exec("DELETE FROM event_styles WHERE type = ?", style.Type)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO event_styles (type, color, icon) VALUES (?, ?, ?)
+				ON CONFLICT(type) DO UPDATE SET color = excluded.color, icon = excluded.icon
+			`, style.Type, style.Color, style.Icon)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.styles.set(normalized)
+	return nil
+}
+
 func (r *SQLiteRepository) Close() error {
 	return nil
 }
+
+// RecordRedemption stores a channel points redemption. Duplicate deliveries of
+// the same redemption are ignored.
+func (r *SQLiteRepository) RecordRedemption(streamer string, redemption Redemption) error {
+	streamerID, err := r.getOrCreateStreamer(streamer)
+	if err != nil {
+		return err
+	}
+
+	timestamp := redemption.Timestamp
+	if timestamp == 0 {
+		timestamp = time.Now().UnixMilli()
+	}
+
+	_, err = r.db.Exec(
+		`INSERT OR IGNORE INTO redemptions (streamer_id, redemption_id, timestamp, reward_id, title, cost, user_input)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		streamerID, redemption.ID, timestamp, redemption.RewardID, redemption.Title, redemption.Cost, redemption.UserInput,
+	)
+	return err
+}
+
+// GetRedemptions returns the most recent redemptions on a streamer's channel, newest first.
+func (r *SQLiteRepository) GetRedemptions(streamer string, limit int) ([]Redemption, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := r.db.Query(`
+		SELECT rd.redemption_id, rd.timestamp, rd.reward_id, rd.title, rd.cost, rd.user_input
+		FROM redemptions rd
+		JOIN streamers s ON s.id = rd.streamer_id
+		WHERE s.name = ?
+		ORDER BY rd.timestamp DESC
+		LIMIT ?
+	`, streamer, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redemptions := []Redemption{}
+	for rows.Next() {
+		var redemption Redemption
+		if err := rows.Scan(
+			&redemption.ID, &redemption.Timestamp, &redemption.RewardID,
+			&redemption.Title, &redemption.Cost, &redemption.UserInput,
+		); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, redemption)
+	}
+
+	return redemptions, rows.Err()
+}
+
+// GetStreamerNotes returns the notes of every streamer that has one, keyed by
+// streamer name.
+func (r *SQLiteRepository) GetStreamerNotes() (map[string]StreamerNote, error) {
+	rows, err := r.db.Query(`
+		SELECT s.name, n.note, n.labels, n.updated_at
+		FROM streamer_notes n
+		JOIN streamers s ON s.id = n.streamer_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make(map[string]StreamerNote)
+	for rows.Next() {
+		var name, labels string
+		var note StreamerNote
+		if err := rows.Scan(&name, &note.Note, &labels, &note.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(labels), &note.Labels); err != nil {
+			note.Labels = nil
+		}
+		notes[name] = note
+	}
+
+	return notes, rows.Err()
+}
+
+// SetStreamerNote replaces a streamer's note and labels. An empty note
+// without labels removes it.
+func (r *SQLiteRepository) SetStreamerNote(streamer string, note StreamerNote) error {
+	streamerID, err := r.getOrCreateStreamer(streamer)
+	if err != nil {
+		return err
+	}
+
+	if note.Note == "" && len(note.Labels) == 0 {
+		_, err = r.db.Exec("DELETE FROM streamer_notes WHERE streamer_id = ?", streamerID)
+		return err
+	}
+
+	labels, err := json.Marshal(note.Labels)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO streamer_notes (streamer_id, note, labels, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(streamer_id) DO UPDATE SET
+			note = excluded.note, labels = excluded.labels, updated_at = excluded.updated_at
+	`, streamerID, note.Note, string(labels), time.Now().UnixMilli())
+	return err
+}
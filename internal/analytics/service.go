@@ -1,16 +1,25 @@
+// Package analytics records channel points, annotations, chat messages and
+// prediction results in SQLite and reads them back for the dashboard. It has
+// no HTTP surface; all handlers and templates live in internal/web, which
+// reaches the data through Service.Repository.
 package analytics
 
 import (
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/database"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 )
 
+// ErrorHandler is called when recording analytics data fails.
+type ErrorHandler func(err error)
+
 type Service struct {
 	repo     Repository
 	basePath string
+	onError  ErrorHandler
 }
 
 func NewService(db *database.DB, basePath string) (*Service, error) {
@@ -24,6 +33,17 @@ func NewService(db *database.DB, basePath string) (*Service, error) {
 	}, nil
 }
 
+// SetErrorHandler sets the handler called when a write to the database fails.
+func (s *Service) SetErrorHandler(handler ErrorHandler) {
+	s.onError = handler
+}
+
+func (s *Service) reportError(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
 func (s *Service) Repository() Repository {
 	return s.repo
 }
@@ -34,29 +54,32 @@ func (s *Service) BasePath() string {
 
 func (s *Service) RecordPoints(streamer *models.Streamer, eventType string) {
 	eventType = strings.ReplaceAll(eventType, "_", " ")
-	if err := s.repo.RecordPoints(streamer.Username, streamer.GetChannelPoints(), eventType); err != nil {
-		slog.Error("Failed to record points", "streamer", streamer.Username, "error", err)
+	if err := s.repo.RecordPoints(streamer.GetUsername(), streamer.GetChannelPoints(), eventType); err != nil {
+		slog.Error("Failed to record points", "streamer", streamer.GetUsername(), "error", err)
+		s.reportError(err)
 	}
 }
 
+// RecordAnnotation adds an annotation of a known event type, in its current
+// color, to the streamer's chart. Unknown types are not recorded.
 func (s *Service) RecordAnnotation(streamer *models.Streamer, eventType, text string) {
-	colors := map[string]string{
-		"WATCH_STREAK":    "#45c1ff",
-		"PREDICTION_MADE": "#ffe045",
-		"WIN":             "#36b535",
-		"LOSE":            "#ff4545",
-	}
-
-	color, ok := colors[eventType]
+	style, ok := s.repo.EventStyle(eventType)
 	if !ok {
 		return
 	}
 
-	if err := s.repo.RecordAnnotation(streamer.Username, eventType, text, color); err != nil {
-		slog.Error("Failed to record annotation", "streamer", streamer.Username, "error", err)
+	if err := s.repo.RecordAnnotation(streamer.GetUsername(), eventType, text, style.Color); err != nil {
+		slog.Error("Failed to record annotation", "streamer", streamer.GetUsername(), "error", err)
+		s.reportError(err)
 	}
 }
 
+// RecordNote adds an annotation written by the user to the streamer's chart.
+func (s *Service) RecordNote(streamer, text string) error {
+	style, _ := s.repo.EventStyle("NOTE")
+	return s.repo.RecordAnnotation(streamer, "NOTE", text, style.Color)
+}
+
 func (s *Service) RecordChatMessage(streamer string, username, displayName, message, emotes, badges, color string) error {
 	msg := ChatMessage{
 		Username:    username,
@@ -69,6 +92,91 @@ func (s *Service) RecordChatMessage(streamer string, username, displayName, mess
 	return s.repo.RecordChatMessage(streamer, msg)
 }
 
+// RecordWatchSlots stores which streamers occupied the watch slots in the current interval.
+func (s *Service) RecordWatchSlots(streamers []*models.Streamer) {
+	slots := make([]WatchSlot, len(streamers))
+	names := make([]string, len(streamers))
+	for i, streamer := range streamers {
+		names[i] = streamer.GetUsername()
+		slots[i] = WatchSlot{Streamer: names[i], Viewers: streamer.GetStream().GetViewersCount()}
+	}
+	if err := s.repo.RecordWatchSlots(slots); err != nil {
+		slog.Error("Failed to record watch slots", "streamers", names, "error", err)
+		s.reportError(err)
+	}
+}
+
+// RecordHeartbeat adds a minute-watched report to the streamer's daily totals.
+func (s *Service) RecordHeartbeat(streamer string, ok bool) {
+	if err := s.repo.RecordHeartbeat(streamer, ok); err != nil {
+		slog.Error("Failed to record heartbeat", "streamer", streamer, "error", err)
+		s.reportError(err)
+	}
+}
+
+// RecordBonusClaim adds a claimed bonus chest to the streamer's daily totals.
+func (s *Service) RecordBonusClaim(streamer string) {
+	if err := s.repo.RecordBonusClaim(streamer); err != nil {
+		slog.Error("Failed to record bonus claim", "streamer", streamer, "error", err)
+		s.reportError(err)
+	}
+}
+
+func (s *Service) RecordPredictionResult(event *models.EventPrediction, placed, won, gained int) {
+	record := PredictionRecord{
+		EventID:    event.EventID,
+		Title:      event.Title,
+		ResultType: string(event.Result.Type),
+		Placed:     placed,
+		Won:        won,
+		Gained:     gained,
+	}
+	if event.Bet != nil {
+		settings := event.Bet.Settings
+		record.BetSettings = &settings
+	}
+	if err := s.repo.RecordPrediction(event.Streamer.GetUsername(), record); err != nil {
+		slog.Error("Failed to record prediction", "streamer", event.Streamer.GetUsername(), "error", err)
+		s.reportError(err)
+	}
+}
+
+// RecordBetTiming stores how close to the lock a bet on the streamer's
+// prediction was placed.
+func (s *Service) RecordBetTiming(streamer *models.Streamer, timing models.BetTiming) {
+	record := BetTimingRecord{
+		EventID:      timing.EventID,
+		Window:       timing.Window,
+		Margin:       timing.Margin,
+		Late:         timing.Late,
+		DecisionOdds: timing.DecisionOdds,
+		LockOdds:     timing.LockOdds,
+	}
+	if err := s.repo.RecordBetTiming(streamer.GetUsername(), record); err != nil {
+		slog.Error("Failed to record bet timing", "streamer", streamer.GetUsername(), "error", err)
+		s.reportError(err)
+	}
+}
+
+// RecordStreamSession stores the summary of a stream that ended.
+func (s *Service) RecordStreamSession(session StreamSession) {
+	if err := s.repo.RecordStreamSession(session); err != nil {
+		slog.Error("Failed to record stream session", "streamer", session.Streamer, "error", err)
+		s.reportError(err)
+	}
+}
+
+func (s *Service) GetPredictionSummaries(startTime, endTime time.Time) ([]PredictionSummary, error) {
+	return s.repo.GetPredictionSummaries(startTime, endTime)
+}
+
+func (s *Service) RecordRedemption(streamer *models.Streamer, redemption Redemption) {
+	if err := s.repo.RecordRedemption(streamer.GetUsername(), redemption); err != nil {
+		slog.Error("Failed to record redemption", "streamer", streamer.GetUsername(), "error", err)
+		s.reportError(err)
+	}
+}
+
 func (s *Service) Close() error {
 	if s.repo != nil {
 		return s.repo.Close()
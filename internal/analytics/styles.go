@@ -0,0 +1,151 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxIconLength is the longest icon, in runes, an event style may have. Emoji
+// with skin tones or joiners take several runes.
+const maxIconLength = 8
+
+// ErrInvalidEventStyle is returned for a style with an unknown type, a color
+// that is not #rrggbb or an icon longer than maxIconLength.
+var ErrInvalidEventStyle = errors.New("invalid event style")
+
+var styleColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// EventStyle is how annotations of one event type are drawn on the chart.
+// Icon, if set, is shown in front of the annotation text.
+type EventStyle struct {
+	Type  string `json:"type"`
+	Label string `json:"label"`
+	Color string `json:"color"`
+	Icon  string `json:"icon"`
+}
+
+// defaultEventStyles are the annotation types the recorder knows, in the
+// order the settings page lists them. Types missing here are not recorded.
+var defaultEventStyles = []EventStyle{
+	{Type: "WATCH_STREAK", Label: "Watch streak", Color: "#45c1ff"},
+	{Type: "PREDICTION_MADE", Label: "Prediction placed", Color: "#ffe045"},
+	{Type: "PREDICTION_SKIP", Label: "Prediction skipped", Color: "#a3a3a3"},
+	{Type: "PREDICTION_LATE", Label: "Late bet", Color: "#ff9f45"},
+	{Type: "WIN", Label: "Prediction won", Color: "#36b535"},
+	{Type: "LOSE", Label: "Prediction lost", Color: "#ff4545"},
+	{Type: "NOTE", Label: "Note", Color: "#c084fc"},
+}
+
+// DefaultEventStyles returns the built-in style of every annotation type.
+func DefaultEventStyles() []EventStyle {
+	return append([]EventStyle(nil), defaultEventStyles...)
+}
+
+func defaultEventStyle(eventType string) (EventStyle, bool) {
+	for _, style := range defaultEventStyles {
+		if style.Type == eventType {
+			return style, true
+		}
+	}
+	return EventStyle{}, false
+}
+
+// annotationCategory returns the type an annotation of the given color was
+// recorded as, for data exported before annotations stored their category.
+// Exports only ever used the default colors.
+func annotationCategory(color string) string {
+	for _, style := range defaultEventStyles {
+		if strings.EqualFold(style.Color, color) {
+			return style.Type
+		}
+	}
+	return ""
+}
+
+// normalizeEventStyle validates a style and fills in its label. An empty
+// color falls back to the default.
+func normalizeEventStyle(style EventStyle) (EventStyle, error) {
+	def, ok := defaultEventStyle(strings.ToUpper(style.Type))
+	if !ok {
+		return EventStyle{}, fmt.Errorf("%w: unknown type %q", ErrInvalidEventStyle, style.Type)
+	}
+
+	style.Type = def.Type
+	style.Label = def.Label
+	style.Color = strings.ToLower(strings.TrimSpace(style.Color))
+	style.Icon = strings.TrimSpace(style.Icon)
+	if style.Color == "" {
+		style.Color = def.Color
+	}
+	if !styleColorPattern.MatchString(style.Color) {
+		return EventStyle{}, fmt.Errorf("%w: color %q of %s is not #rrggbb", ErrInvalidEventStyle, style.Color, style.Type)
+	}
+	if len([]rune(style.Icon)) > maxIconLength {
+		return EventStyle{}, fmt.Errorf("%w: icon of %s is longer than %d characters", ErrInvalidEventStyle, style.Type, maxIconLength)
+	}
+	return style, nil
+}
+
+// styleRegistry holds the style of every annotation type: the defaults with
+// the overrides stored in event_styles applied.
+type styleRegistry struct {
+	styles map[string]EventStyle
+	mu     sync.RWMutex
+}
+
+func newStyleRegistry(overrides []EventStyle) *styleRegistry {
+	r := &styleRegistry{styles: make(map[string]EventStyle, len(defaultEventStyles))}
+	for _, style := range defaultEventStyles {
+		r.styles[style.Type] = style
+	}
+	for _, override := range overrides {
+		if style, err := normalizeEventStyle(override); err == nil {
+			r.styles[style.Type] = style
+		}
+	}
+	return r
+}
+
+func (r *styleRegistry) get(eventType string) (EventStyle, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	style, ok := r.styles[eventType]
+	return style, ok
+}
+
+func (r *styleRegistry) all() []EventStyle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	styles := make([]EventStyle, 0, len(defaultEventStyles))
+	for _, def := range defaultEventStyles {
+		styles = append(styles, r.styles[def.Type])
+	}
+	return styles
+}
+
+func (r *styleRegistry) set(styles []EventStyle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, style := range styles {
+		r.styles[style.Type] = style
+	}
+}
+
+// apply draws an annotation in the current style of its category.
+// Annotations of unknown type keep the color they were recorded with.
+func (r *styleRegistry) apply(a *Annotation, text, color string) {
+	a.BorderColor = color
+	if style, ok := r.get(a.Category); ok {
+		a.BorderColor = style.Color
+		if style.Icon != "" {
+			text = style.Icon + " " + text
+		}
+	}
+	a.Label = AnnotationLabel{
+		Style: map[string]string{"color": "#000", "background": a.BorderColor},
+		Text:  text,
+	}
+}
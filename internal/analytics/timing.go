@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+const (
+	// minTimingSamples is how many timed bets are needed before advising.
+	minTimingSamples = 3
+	// betSafetyMargin is how many seconds before the lock a suggested delay
+	// aims to place bets.
+	betSafetyMargin = 3.0
+	// lateRateThreshold is the share of late bets from which bets count as
+	// too late.
+	lateRateThreshold = 0.1
+	// oddsShiftThreshold is the average odds change, in percent, from which
+	// bets with room to spare count as too early.
+	oddsShiftThreshold = 10.0
+)
+
+// Bet timing verdicts.
+const (
+	TimingLate  = "late"
+	TimingEarly = "early"
+	TimingOK    = "ok"
+)
+
+// BetTimingAdvice is a tuning suggestion for a streamer's bet delay. Verdict
+// is empty while there are too few bets to judge.
+type BetTimingAdvice struct {
+	Verdict    string  `json:"verdict"`
+	Suggestion string  `json:"suggestion"`
+	Delay      float64 `json:"delay"`
+}
+
+// Advise judges the timings against the streamer's current delay settings and
+// suggests a delay that places bets about betSafetyMargin seconds before the
+// lock: earlier if too many bets were late, later if the odds kept moving
+// after bets placed with time to spare.
+func (s BetTimingSummary) Advise(bet models.BetSettings) BetTimingAdvice {
+	advice := BetTimingAdvice{Delay: bet.Delay}
+
+	if s.Bets < minTimingSamples {
+		advice.Suggestion = fmt.Sprintf("Not enough bets to judge yet (%d of %d).", s.Bets, minTimingSamples)
+		return advice
+	}
+
+	if float64(s.Late)/float64(s.Bets) >= lateRateThreshold {
+		shift := math.Ceil(s.MaxLate + betSafetyMargin)
+		advice.Verdict = TimingLate
+		advice.Delay = bet.ShiftedDelay(-shift, s.AvgWindow)
+		advice.Suggestion = fmt.Sprintf(
+			"%d of %d bets came after the lock. Bet about %.0fs earlier: set the delay to %s.",
+			s.Late, s.Bets, shift, formatDelay(bet.DelayMode, advice.Delay),
+		)
+		return advice
+	}
+
+	if s.Late < s.Bets && s.MinMargin > 2*betSafetyMargin && s.OddsShift >= oddsShiftThreshold {
+		shift := math.Floor(s.MinMargin - betSafetyMargin)
+		advice.Verdict = TimingEarly
+		advice.Delay = bet.ShiftedDelay(shift, s.AvgWindow)
+		advice.Suggestion = fmt.Sprintf(
+			"Odds moved %.0f%% after the bets on average and no bet was closer than %.0fs to the lock. Bet about %.0fs later: set the delay to %s.",
+			s.OddsShift, s.MinMargin, shift, formatDelay(bet.DelayMode, advice.Delay),
+		)
+		return advice
+	}
+
+	advice.Verdict = TimingOK
+	advice.Suggestion = fmt.Sprintf(
+		"Bets land %.0fs before the lock on average and the odds moved %.0f%% afterwards.",
+		s.AvgMargin, s.OddsShift,
+	)
+	return advice
+}
+
+func formatDelay(mode models.DelayMode, delay float64) string {
+	if mode == models.DelayModePercentage {
+		return fmt.Sprintf("%.2f", delay)
+	}
+	return fmt.Sprintf("%.0fs", delay)
+}
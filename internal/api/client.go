@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,6 +16,8 @@ import (
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/auth"
 	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/gql"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 	"github.com/PatrickWalther/twitch-miner-go/internal/util"
 )
@@ -22,8 +25,59 @@ import (
 var (
 	ErrStreamerDoesNotExist = errors.New("streamer does not exist")
 	ErrStreamerIsOffline    = errors.New("streamer is offline")
+	ErrPredictionRestricted = errors.New("prediction is restricted")
+	ErrPredictionLocked     = errors.New("prediction is locked")
+	ErrContributionRejected = errors.New("contribution rejected")
+	ErrUnauthorized         = errors.New("auth token rejected")
+	ErrPointsFloor          = errors.New("balance at points floor")
+	ErrGQL                  = errors.New("GQL request failed")
+	ErrIntegrity            = errors.New("GQL request failed the integrity check")
 )
 
+// channelIDBatchSize is how many logins GetChannelIDs resolves per GQL request.
+const channelIDBatchSize = 25
+
+// maxFollowPages caps how many pages of 100 follows GetFollows requests.
+const maxFollowPages = 50
+
+// FailureHandler is called after every failed GQL request with the number of
+// consecutive failures, including this one.
+type FailureHandler func(err error, consecutive int)
+
+// TokenRefreshHandler is called with the new access token after a rejected
+// token was refreshed.
+type TokenRefreshHandler func(token string)
+
+// predictionRestrictionMarkers are substrings of makePrediction error codes
+// that mean the account may never bet on the event (e.g. subscriber-only
+// predictions), as opposed to transient failures. Twitch does not document
+// the codes, so they are matched loosely rather than listed one by one.
+var predictionRestrictionMarkers = []string{"RESTRICT", "SUBSCRIBER", "SUB_ONLY", "ELIGIBLE", "FORBIDDEN", "NOT_ALLOWED", "BANNED"}
+
+// predictionLockedMarkers are substrings of makePrediction error codes that
+// mean the bet arrived after the event stopped taking bets.
+var predictionLockedMarkers = []string{"NOT_ACTIVE", "LOCKED"}
+
+func isPredictionLocked(code string) bool {
+	upper := strings.ToUpper(code)
+	for _, marker := range predictionLockedMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPredictionRestricted(code string) bool {
+	upper := strings.ToUpper(code)
+	for _, marker := range predictionRestrictionMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 type TwitchClient struct {
 	auth          *auth.TwitchAuth
 	deviceID      string
@@ -36,6 +90,19 @@ type TwitchClient struct {
 	spadeURLPattern        *regexp.Regexp
 	settingsURLPattern     *regexp.Regexp
 
+	onFailure        FailureHandler
+	onTokenRefreshed TokenRefreshHandler
+	failures         int
+
+	// pointsFloor is the balance bets and contributions never take a
+	// streamer below; 0 disables it.
+	pointsFloor int
+
+	// onlineChecks holds a channel per streamer with an online check in
+	// flight, closed when it finishes.
+	onlineChecks map[string]chan struct{}
+	checkMu      sync.Mutex
+
 	mu sync.RWMutex
 }
 
@@ -46,28 +113,195 @@ func NewTwitchClient(twitchAuth *auth.TwitchAuth, deviceID string) *TwitchClient
 		clientSession:          util.RandomHex(16),
 		clientVersion:          constants.DefaultClientVersion,
 		userAgent:              constants.TVUserAgent,
-		client:                 &http.Client{Timeout: 30 * time.Second},
+		client:                 httpx.NewClient(30 * time.Second),
 		twilightBuildIDPattern: regexp.MustCompile(`window\.__twilightBuildID\s*=\s*"([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})"`),
 		spadeURLPattern:        regexp.MustCompile(`"spade_url":"(.*?)"`),
 		settingsURLPattern:     regexp.MustCompile(`(https://static.twitchcdn.net/config/settings.*?js|https://assets.twitch.tv/config/settings.*?.js)`),
+		onlineChecks:           make(map[string]chan struct{}),
+	}
+}
+
+// SetPointsFloor sets the channel points balance automated spending never
+// takes a streamer below. 0 disables the floor.
+func (c *TwitchClient) SetPointsFloor(floor int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pointsFloor = max(0, floor)
+}
+
+// capSpend returns how much of amount streamer may spend without dropping
+// below the points floor, or ErrPointsFloor if it may spend nothing. Every
+// mutation spending points goes through it.
+func (c *TwitchClient) capSpend(streamer *models.Streamer, amount int) (int, error) {
+	c.mu.RLock()
+	floor := c.pointsFloor
+	c.mu.RUnlock()
+
+	if floor == 0 {
+		return amount, nil
+	}
+	headroom := streamer.GetChannelPoints() - floor
+	if headroom <= 0 {
+		return 0, fmt.Errorf("%w: %s has %d points, floor is %d",
+			ErrPointsFloor, streamer.GetUsername(), streamer.GetChannelPoints(), floor)
+	}
+	return min(amount, headroom), nil
+}
+
+// SetFailureHandler sets the handler called when GQL requests fail.
+func (c *TwitchClient) SetFailureHandler(handler FailureHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onFailure = handler
+}
+
+// SetTokenRefreshHandler sets the handler called when the auth token was
+// refreshed after Twitch rejected it.
+func (c *TwitchClient) SetTokenRefreshHandler(handler TokenRefreshHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onTokenRefreshed = handler
+}
+
+// query sends operation and decodes the data of its response into data,
+// which may be nil when only the outcome matters. Responses Twitch rejected
+// fail with ErrIntegrity or ErrGQL; errors reported next to data are logged
+// and the data is still decoded.
+func (c *TwitchClient) query(operation constants.GQLOperation, data interface{}) error {
+	resp, err := c.postGQLRequest(operation)
+	if err != nil {
+		return err
 	}
+	return decodeResponse(operation.OperationName, resp, data)
 }
 
-func (c *TwitchClient) PostGQL(operation constants.GQLOperation) (map[string]interface{}, error) {
-	return c.postGQLRequest(operation)
+// decodeResponse checks the envelope of a response to the named operation
+// and decodes its data into data. A failed integrity check is counted
+// towards the error budget.
+func decodeResponse(name string, resp *gql.Response, data interface{}) error {
+	if resp.Integrity() {
+		httpx.RecordIntegrityError()
+		return fmt.Errorf("%w: %s", ErrIntegrity, name)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%w: %s: %s (%d): %s", ErrGQL, name, resp.Error, resp.Status, resp.Message)
+	}
+
+	messages := make([]string, 0, len(resp.Errors))
+	for _, e := range resp.Errors {
+		messages = append(messages, e.Message)
+	}
+	if !resp.HasData() {
+		if len(messages) == 0 {
+			return fmt.Errorf("%w: %s: no data in response", ErrGQL, name)
+		}
+		return fmt.Errorf("%w: %s: %s", ErrGQL, name, strings.Join(messages, "; "))
+	}
+	if len(messages) > 0 {
+		slog.Debug("GQL response has errors", "operation", name, "errors", strings.Join(messages, "; "))
+	}
+
+	if data == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, data); err != nil {
+		return fmt.Errorf("failed to decode %s data: %w", name, err)
+	}
+	return nil
 }
 
-func (c *TwitchClient) PostGQLBatch(operations []constants.GQLOperation) ([]map[string]interface{}, error) {
-	return c.postGQLBatchRequest(operations)
+func (c *TwitchClient) postGQLRequest(operation constants.GQLOperation) (*gql.Response, error) {
+	token := c.auth.GetAuthToken()
+	body, err := c.doGQLRequest(operation)
+	if errors.Is(err, ErrUnauthorized) && c.refreshToken(token) {
+		body, err = c.doGQLRequest(operation)
+	}
+
+	var resp gql.Response
+	if err == nil {
+		if err = json.Unmarshal(body, &resp); err != nil {
+			err = fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	c.recordOutcome(err)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
-func (c *TwitchClient) postGQLRequest(operation constants.GQLOperation) (map[string]interface{}, error) {
+// postGQLBatchRequest sends operations in one request and returns their
+// responses in the same order, each to be checked with decodeResponse.
+func (c *TwitchClient) postGQLBatchRequest(operations []constants.GQLOperation) ([]gql.Response, error) {
+	token := c.auth.GetAuthToken()
+	body, err := c.doGQLBatchRequest(operations)
+	if errors.Is(err, ErrUnauthorized) && c.refreshToken(token) {
+		body, err = c.doGQLBatchRequest(operations)
+	}
+
+	var responses []gql.Response
+	if err == nil {
+		if err = json.Unmarshal(body, &responses); err != nil {
+			err = fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	c.recordOutcome(err)
+	return responses, err
+}
+
+// refreshToken renews the auth token after Twitch rejected rejected, and
+// reports whether the request should be retried with the new token.
+func (c *TwitchClient) refreshToken(rejected string) bool {
+	refreshed, err := c.auth.Refresh(rejected)
+	if err != nil {
+		slog.Warn("Failed to refresh rejected auth token", "error", err)
+	}
+	token := c.auth.GetAuthToken()
+	if token == rejected {
+		return false
+	}
+	if !refreshed {
+		// Another request already refreshed it.
+		return true
+	}
+	slog.Info("Refreshed rejected auth token")
+
+	c.mu.RLock()
+	handler := c.onTokenRefreshed
+	c.mu.RUnlock()
+	if handler != nil {
+		handler(token)
+	}
+	return true
+}
+
+// recordOutcome tracks consecutive GQL failures and reports them to the failure handler.
+func (c *TwitchClient) recordOutcome(err error) {
+	c.mu.Lock()
+	if err == nil {
+		c.failures = 0
+		c.mu.Unlock()
+		return
+	}
+	c.failures++
+	consecutive := c.failures
+	handler := c.onFailure
+	c.mu.Unlock()
+
+	if handler != nil {
+		handler(err, consecutive)
+	}
+}
+
+// doGQLRequest sends operation and returns the raw response body.
+func (c *TwitchClient) doGQLRequest(operation constants.GQLOperation) ([]byte, error) {
 	body, err := json.Marshal(operation)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal operation: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", constants.GQLURL, bytes.NewReader(body))
+	ctx := httpx.WithOperation(context.Background(), "gql:"+operation.OperationName)
+	req, err := http.NewRequestWithContext(ctx, "POST", constants.GQLURL, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -87,21 +321,21 @@ func (c *TwitchClient) postGQLRequest(operation constants.GQLOperation) (map[str
 
 	slog.Debug("GQL response", "operation", operation.OperationName, "status", resp.StatusCode)
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrUnauthorized
 	}
 
-	return result, nil
+	return respBody, nil
 }
 
-func (c *TwitchClient) postGQLBatchRequest(operations []constants.GQLOperation) ([]map[string]interface{}, error) {
+func (c *TwitchClient) doGQLBatchRequest(operations []constants.GQLOperation) ([]byte, error) {
 	body, err := json.Marshal(operations)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal operations: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", constants.GQLURL, bytes.NewReader(body))
+	ctx := httpx.WithOperation(context.Background(), batchOperationName(operations))
+	req, err := http.NewRequestWithContext(ctx, "POST", constants.GQLURL, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -119,12 +353,24 @@ func (c *TwitchClient) postGQLBatchRequest(operations []constants.GQLOperation)
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	var result []map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrUnauthorized
 	}
 
-	return result, nil
+	return respBody, nil
+}
+
+// batchOperationName labels a batched GQL request by its distinct operation names.
+func batchOperationName(operations []constants.GQLOperation) string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, op := range operations {
+		if !seen[op.OperationName] {
+			seen[op.OperationName] = true
+			names = append(names, op.OperationName)
+		}
+	}
+	return "gql-batch:" + strings.Join(names, "+")
 }
 
 func (c *TwitchClient) setGQLHeaders(req *http.Request) {
@@ -177,119 +423,199 @@ func (c *TwitchClient) GetChannelID(username string) (string, error) {
 		"login": strings.ToLower(username),
 	})
 
-	resp, err := c.postGQLRequest(op)
-	if err != nil {
+	var data gql.GetIDFromLogin
+	if err := c.query(op, &data); err != nil {
 		return "", err
 	}
-
-	data, ok := resp["data"].(map[string]interface{})
-	if !ok {
+	if data.User == nil || data.User.ID == "" {
 		return "", ErrStreamerDoesNotExist
 	}
+	return data.User.ID, nil
+}
 
-	user, ok := data["user"].(map[string]interface{})
-	if !ok || user == nil {
+// GetProfileImageURL returns the URL of a channel's current profile image.
+func (c *TwitchClient) GetProfileImageURL(login string) (string, error) {
+	op := constants.ChannelShell.WithVariables(map[string]interface{}{
+		"login": strings.ToLower(login),
+	})
+
+	var data gql.ChannelShell
+	if err := c.query(op, &data); err != nil {
+		return "", err
+	}
+	if data.UserOrError == nil || data.UserOrError.ProfileImageURL == "" {
 		return "", ErrStreamerDoesNotExist
 	}
+	return data.UserOrError.ProfileImageURL, nil
+}
 
-	id, ok := user["id"].(string)
-	if !ok {
-		return "", ErrStreamerDoesNotExist
+// GetChannelMetadata returns a channel's partner and affiliate status,
+// follower count and creation date.
+func (c *TwitchClient) GetChannelMetadata(login string) (models.ChannelMetadata, error) {
+	op := constants.ChannelMetadata.WithVariables(map[string]interface{}{
+		"login": strings.ToLower(login),
+	})
+
+	var data gql.ChannelMetadata
+	if err := c.query(op, &data); err != nil {
+		return models.ChannelMetadata{}, err
+	}
+	if data.User == nil {
+		return models.ChannelMetadata{}, ErrStreamerDoesNotExist
 	}
 
-	return id, nil
+	return models.ChannelMetadata{
+		Partner:   data.User.Roles.IsPartner,
+		Affiliate: data.User.Roles.IsAffiliate,
+		Followers: data.User.Followers.TotalCount,
+		CreatedAt: data.User.CreatedAt.Time,
+		FetchedAt: time.Now(),
+	}, nil
 }
 
-func (c *TwitchClient) GetStreamInfo(streamer *models.Streamer) (map[string]interface{}, error) {
-	op := constants.VideoPlayerStreamInfoOverlayChannel.WithVariables(map[string]interface{}{
-		"channel": streamer.Username,
-	})
+// GetChannelIDs resolves many logins with batched GQL requests. Logins of
+// channels that do not exist are missing from the returned map; logins whose
+// lookup returned an error are listed in failed instead.
+func (c *TwitchClient) GetChannelIDs(usernames []string) (ids map[string]string, failed []string, err error) {
+	ids = make(map[string]string, len(usernames))
 
-	resp, err := c.postGQLRequest(op)
-	if err != nil {
-		return nil, err
+	for start := 0; start < len(usernames); start += channelIDBatchSize {
+		batch := usernames[start:min(start+channelIDBatchSize, len(usernames))]
+
+		ops := make([]constants.GQLOperation, len(batch))
+		for i, username := range batch {
+			ops[i] = constants.GetIDFromLogin.WithVariables(map[string]interface{}{
+				"login": strings.ToLower(username),
+			})
+		}
+
+		responses, err := c.postGQLBatchRequest(ops)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i, login := range batch {
+			if i >= len(responses) {
+				failed = append(failed, login)
+				continue
+			}
+			var data gql.GetIDFromLogin
+			if err := decodeResponse(constants.GetIDFromLogin.OperationName, &responses[i], &data); err != nil {
+				slog.Debug("Failed to resolve channel ID", "login", login, "error", err)
+				failed = append(failed, login)
+				continue
+			}
+			if data.User != nil && data.User.ID != "" {
+				ids[login] = data.User.ID
+			}
+		}
 	}
 
-	data, ok := resp["data"].(map[string]interface{})
-	if !ok {
-		return nil, ErrStreamerIsOffline
+	return ids, failed, nil
+}
+
+// GetFollows returns the channels the account follows, keyed by login, with
+// the time each was followed. The time is zero if Twitch does not report it.
+func (c *TwitchClient) GetFollows() (map[string]time.Time, error) {
+	follows := make(map[string]time.Time)
+	cursor := ""
+
+	for page := 0; page < maxFollowPages; page++ {
+		op := constants.ChannelFollows.WithVariables(map[string]interface{}{
+			"limit":  100,
+			"order":  "ASC",
+			"cursor": cursor,
+		})
+
+		var data gql.ChannelFollows
+		if err := c.query(op, &data); err != nil {
+			return nil, err
+		}
+		if data.User == nil || data.User.Follows == nil {
+			return nil, fmt.Errorf("unexpected follows response")
+		}
+
+		for _, edge := range data.User.Follows.Edges {
+			if edge.Node.Login == "" {
+				continue
+			}
+			follows[strings.ToLower(edge.Node.Login)] = edge.FollowedAt.Time
+			cursor = edge.Cursor
+		}
+
+		if !data.User.Follows.PageInfo.HasNextPage || cursor == "" {
+			break
+		}
 	}
 
-	user, ok := data["user"].(map[string]interface{})
-	if !ok || user == nil {
-		return nil, ErrStreamerIsOffline
+	return follows, nil
+}
+
+// GetStreamGame returns the game/category a channel is currently streaming.
+// It returns nil without error if the channel is live without a category.
+func (c *TwitchClient) GetStreamGame(login string) (*models.Game, error) {
+	user, err := c.getStreamInfo(login)
+	if err != nil {
+		return nil, err
 	}
+	if user.BroadcastSettings == nil {
+		return nil, nil
+	}
+	return models.GameFromGQL(user.BroadcastSettings.Game), nil
+}
 
-	stream, ok := user["stream"].(map[string]interface{})
-	if !ok || stream == nil {
+// getStreamInfo returns the channel with its live stream, or
+// ErrStreamerIsOffline.
+func (c *TwitchClient) getStreamInfo(login string) (*gql.StreamUser, error) {
+	op := constants.VideoPlayerStreamInfoOverlayChannel.WithVariables(map[string]interface{}{
+		"channel": login,
+	})
+
+	var data gql.StreamInfo
+	if err := c.query(op, &data); err != nil {
+		return nil, err
+	}
+	if data.User == nil || data.User.Stream == nil {
 		return nil, ErrStreamerIsOffline
 	}
-
-	return user, nil
+	return data.User, nil
 }
 
 func (c *TwitchClient) UpdateStream(streamer *models.Streamer) error {
-	if !streamer.Stream.UpdateRequired() {
+	if !streamer.GetStream().UpdateRequired() {
 		return nil
 	}
 
-	streamInfo, err := c.GetStreamInfo(streamer)
+	user, err := c.getStreamInfo(streamer.GetUsername())
 	if err != nil {
 		return err
 	}
 
-	stream, ok := streamInfo["stream"].(map[string]interface{})
-	if !ok {
-		return ErrStreamerIsOffline
-	}
-
-	broadcastSettings, _ := streamInfo["broadcastSettings"].(map[string]interface{})
-
-	broadcastID, _ := stream["id"].(string)
+	broadcastID := user.Stream.ID
 	title := ""
-	if broadcastSettings != nil {
-		title, _ = broadcastSettings["title"].(string)
-	}
-
 	var game *models.Game
-	if broadcastSettings != nil {
-		if gameData, ok := broadcastSettings["game"].(map[string]interface{}); ok && gameData != nil {
-			game = &models.Game{}
-			game.ID, _ = gameData["id"].(string)
-			game.Name, _ = gameData["name"].(string)
-			game.DisplayName, _ = gameData["displayName"].(string)
-		}
+	if user.BroadcastSettings != nil {
+		title = user.BroadcastSettings.Title
+		game = models.GameFromGQL(user.BroadcastSettings.Game)
 	}
 
 	var tags []models.Tag
-	if tagsData, ok := stream["tags"].([]interface{}); ok {
-		for _, t := range tagsData {
-			if tagMap, ok := t.(map[string]interface{}); ok {
-				tag := models.Tag{}
-				tag.ID, _ = tagMap["id"].(string)
-				tag.LocalizedName, _ = tagMap["localizedName"].(string)
-				tags = append(tags, tag)
-			}
-		}
+	for _, t := range user.Stream.Tags {
+		tags = append(tags, models.Tag{ID: t.ID, LocalizedName: t.LocalizedName})
 	}
 
-	viewersCount := 0
-	if vc, ok := stream["viewersCount"].(float64); ok {
-		viewersCount = int(vc)
-	}
+	streamer.GetStream().Update(broadcastID, strings.TrimSpace(title), game, tags, user.Stream.ViewersCount)
 
-	streamer.Stream.Update(broadcastID, strings.TrimSpace(title), game, tags, viewersCount)
-
-	if game != nil && game.Name != "" && game.ID != "" && streamer.Settings.ClaimDrops {
+	if game != nil && game.Name != "" && game.ID != "" && streamer.GetSettings().ClaimDrops {
 		campaignIDs, _ := c.GetCampaignIDsFromStreamer(streamer)
-		streamer.Stream.CampaignIDs = campaignIDs
+		streamer.GetStream().SetCampaignIDs(campaignIDs)
 	}
 
-	streamer.Stream.SetPayload(
-		streamer.ChannelID,
+	streamer.GetStream().SetPayload(
+		streamer.GetChannelID(),
 		broadcastID,
 		c.auth.GetUserID(),
-		streamer.Username,
+		streamer.GetUsername(),
 		game,
 	)
 
@@ -297,9 +623,21 @@ func (c *TwitchClient) UpdateStream(streamer *models.Streamer) error {
 }
 
 func (c *TwitchClient) GetSpadeURL(streamer *models.Streamer) error {
-	streamerURL := fmt.Sprintf("%s/%s", constants.TwitchURL, streamer.Username)
+	return c.fetchSpadeURL(streamer, "spade:url")
+}
+
+// RefreshSpadeURL fetches the spade URL of a live streamer again after
+// minute-watched reports to it kept failing. Its requests are tracked
+// separately from the lookups made when a streamer comes online.
+func (c *TwitchClient) RefreshSpadeURL(streamer *models.Streamer) error {
+	return c.fetchSpadeURL(streamer, "spade:url-refresh")
+}
+
+func (c *TwitchClient) fetchSpadeURL(streamer *models.Streamer, operation string) error {
+	streamerURL := fmt.Sprintf("%s/%s", constants.TwitchURL, streamer.GetUsername())
 
-	req, err := http.NewRequest("GET", streamerURL, nil)
+	ctx := httpx.WithOperation(context.Background(), operation)
+	req, err := http.NewRequestWithContext(ctx, "GET", streamerURL, nil)
 	if err != nil {
 		return err
 	}
@@ -321,7 +659,11 @@ func (c *TwitchClient) GetSpadeURL(streamer *models.Streamer) error {
 		return fmt.Errorf("failed to find settings URL")
 	}
 
-	settingsResp, err := c.client.Get(string(settingsMatches[1]))
+	settingsReq, err := http.NewRequestWithContext(ctx, "GET", string(settingsMatches[1]), nil)
+	if err != nil {
+		return err
+	}
+	settingsResp, err := c.client.Do(settingsReq)
 	if err != nil {
 		return err
 	}
@@ -337,11 +679,47 @@ func (c *TwitchClient) GetSpadeURL(streamer *models.Streamer) error {
 		return fmt.Errorf("failed to find spade URL")
 	}
 
-	streamer.Stream.SpadeURL = string(spadeMatches[1])
+	streamer.GetStream().SetSpadeURL(string(spadeMatches[1]))
 	return nil
 }
 
+// minOnlineRecheck is the shortest time between two online checks of the same
+// streamer. Checks requested sooner keep the previous result.
+const minOnlineRecheck = 15 * time.Second
+
+// CheckStreamerOnline updates whether the streamer is live. The watcher, the
+// stream check loop, PubSub and the dashboard may all ask at once: a call made
+// while a check of the same streamer is in flight waits for that check instead
+// of starting another, and a call within minOnlineRecheck of the last check
+// returns immediately.
 func (c *TwitchClient) CheckStreamerOnline(streamer *models.Streamer) {
+	key := streamer.GetUsername()
+
+	c.checkMu.Lock()
+	if done, ok := c.onlineChecks[key]; ok {
+		c.checkMu.Unlock()
+		<-done
+		return
+	}
+	if last := streamer.GetLastChecked(); !last.IsZero() && time.Since(last) < minOnlineRecheck {
+		c.checkMu.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	c.onlineChecks[key] = done
+	c.checkMu.Unlock()
+
+	defer func() {
+		c.checkMu.Lock()
+		delete(c.onlineChecks, key)
+		c.checkMu.Unlock()
+		close(done)
+	}()
+
+	c.checkStreamerOnline(streamer)
+}
+
+func (c *TwitchClient) checkStreamerOnline(streamer *models.Streamer) {
 	if time.Since(streamer.GetOfflineAt()) < time.Minute {
 		return
 	}
@@ -350,95 +728,83 @@ func (c *TwitchClient) CheckStreamerOnline(streamer *models.Streamer) {
 
 	if !streamer.GetIsOnline() {
 		if err := c.GetSpadeURL(streamer); err != nil {
-			slog.Debug("Failed to get spade URL", "streamer", streamer.Username, "error", err)
+			slog.Debug("Failed to get spade URL", "streamer", streamer.GetUsername(), "error", err)
 			streamer.SetOffline()
 			return
 		}
 
 		if err := c.UpdateStream(streamer); err != nil {
-			slog.Debug("Failed to update stream", "streamer", streamer.Username, "error", err)
+			slog.Debug("Failed to update stream", "streamer", streamer.GetUsername(), "error", err)
 			streamer.SetOffline()
 			return
 		}
 
 		streamer.SetOnline()
-		slog.Info("Streamer is online", "streamer", streamer.Username)
+		slog.Info("Streamer is online", "streamer", streamer.GetUsername())
 	} else {
 		if err := c.UpdateStream(streamer); err != nil {
-			slog.Info("Streamer went offline", "streamer", streamer.Username)
+			slog.Info("Streamer went offline", "streamer", streamer.GetUsername())
 			streamer.SetOffline()
 		}
 	}
 }
 
+// LoadChannelPointsContext loads the streamer's balance, multipliers and
+// community goals and claims an available bonus. Drops-only streamers are
+// skipped.
 func (c *TwitchClient) LoadChannelPointsContext(streamer *models.Streamer) error {
+	if streamer.GetSettings().DropsOnly {
+		return nil
+	}
+
 	op := constants.ChannelPointsContext.WithVariables(map[string]interface{}{
-		"channelLogin": streamer.Username,
+		"channelLogin": streamer.GetUsername(),
 	})
 
-	resp, err := c.postGQLRequest(op)
-	if err != nil {
+	var data gql.ChannelPointsContext
+	if err := c.query(op, &data); err != nil {
 		return err
 	}
-
-	data, ok := resp["data"].(map[string]interface{})
-	if !ok {
-		return ErrStreamerDoesNotExist
-	}
-
-	community, ok := data["community"].(map[string]interface{})
-	if !ok || community == nil {
-		return ErrStreamerDoesNotExist
-	}
-
-	channel, ok := community["channel"].(map[string]interface{})
-	if !ok || channel == nil {
+	if data.Community == nil || data.Community.Channel == nil {
 		return ErrStreamerDoesNotExist
 	}
+	channel := data.Community.Channel
 
-	self, ok := channel["self"].(map[string]interface{})
-	if !ok {
-		return nil
+	settings := channel.CommunityPointsSettings
+	if settings != nil && settings.IsEnabled != nil && streamer.SetPointsDisabled(!*settings.IsEnabled) {
+		if *settings.IsEnabled {
+			slog.Info("Channel points were re-enabled", "streamer", streamer.GetUsername())
+		} else {
+			slog.Warn("Channel points are disabled; only drops will be mined", "streamer", streamer.GetUsername())
+		}
 	}
 
-	communityPoints, ok := self["communityPoints"].(map[string]interface{})
-	if !ok {
+	if channel.Self == nil || channel.Self.CommunityPoints == nil {
 		return nil
 	}
+	communityPoints := channel.Self.CommunityPoints
 
-	if balance, ok := communityPoints["balance"].(float64); ok {
-		streamer.SetChannelPoints(int(balance))
+	if communityPoints.Balance != nil {
+		streamer.SetChannelPoints(*communityPoints.Balance)
 	}
 
-	if multipliers, ok := communityPoints["activeMultipliers"].([]interface{}); ok {
-		streamer.ActiveMultipliers = nil
-		for _, m := range multipliers {
-			if mMap, ok := m.(map[string]interface{}); ok {
-				if factor, ok := mMap["factor"].(float64); ok {
-					streamer.ActiveMultipliers = append(streamer.ActiveMultipliers, models.Multiplier{Factor: factor})
-				}
-			}
+	if communityPoints.ActiveMultipliers != nil {
+		var active []models.Multiplier
+		for _, m := range communityPoints.ActiveMultipliers {
+			active = append(active, models.Multiplier{Factor: m.Factor})
 		}
+		streamer.SetActiveMultipliers(active)
 	}
 
-	if streamer.Settings.CommunityGoals {
-		if settings, ok := channel["communityPointsSettings"].(map[string]interface{}); ok {
-			if goals, ok := settings["goals"].([]interface{}); ok {
-				for _, g := range goals {
-					if goalMap, ok := g.(map[string]interface{}); ok {
-						goal := models.CommunityGoalFromGQL(goalMap)
-						streamer.AddCommunityGoal(goal)
-					}
-				}
-			}
+	if streamer.GetSettings().CommunityGoals && settings != nil {
+		for _, g := range settings.Goals {
+			streamer.AddCommunityGoal(models.CommunityGoalFromGQL(g))
 		}
 	}
 
-	if availableClaim, ok := communityPoints["availableClaim"].(map[string]interface{}); ok && availableClaim != nil {
-		if claimID, ok := availableClaim["id"].(string); ok {
-			if err := c.ClaimBonus(streamer, claimID); err != nil {
-				slog.Error("Failed to claim bonus", "error", err)
-			}
+	if claim := communityPoints.AvailableClaim; claim != nil && claim.ID != "" {
+		if err := c.ClaimBonus(streamer, claim.ID); err != nil {
+			slog.Error("Failed to claim bonus", "error", err)
 		}
 	}
 
@@ -446,21 +812,20 @@ func (c *TwitchClient) LoadChannelPointsContext(streamer *models.Streamer) error
 }
 
 func (c *TwitchClient) ClaimBonus(streamer *models.Streamer, claimID string) error {
-	slog.Info("Claiming bonus", "streamer", streamer.Username)
+	slog.Info("Claiming bonus", "streamer", streamer.GetUsername())
 
 	op := constants.ClaimCommunityPoints.WithVariables(map[string]interface{}{
 		"input": map[string]interface{}{
-			"channelID": streamer.ChannelID,
+			"channelID": streamer.GetChannelID(),
 			"claimID":   claimID,
 		},
 	})
 
-	_, err := c.postGQLRequest(op)
-	return err
+	return c.query(op, nil)
 }
 
 func (c *TwitchClient) ClaimMoment(streamer *models.Streamer, momentID string) error {
-	slog.Info("Claiming moment", "streamer", streamer.Username)
+	slog.Info("Claiming moment", "streamer", streamer.GetUsername())
 
 	op := constants.CommunityMomentCalloutClaim.WithVariables(map[string]interface{}{
 		"input": map[string]interface{}{
@@ -468,18 +833,15 @@ func (c *TwitchClient) ClaimMoment(streamer *models.Streamer, momentID string) e
 		},
 	})
 
-	_, err := c.postGQLRequest(op)
-	return err
+	return c.query(op, nil)
 }
 
 func (c *TwitchClient) JoinRaid(streamer *models.Streamer, raid *models.Raid) error {
-	if streamer.Raid != nil && streamer.Raid.RaidID == raid.RaidID {
+	if !streamer.SetRaid(raid) {
 		return nil
 	}
 
-	slog.Info("Joining raid", "from", streamer.Username, "to", raid.TargetLogin)
-
-	streamer.Raid = raid
+	slog.Info("Joining raid", "from", streamer.GetUsername(), "to", raid.TargetLogin)
 
 	op := constants.JoinRaid.WithVariables(map[string]interface{}{
 		"input": map[string]interface{}{
@@ -487,30 +849,64 @@ func (c *TwitchClient) JoinRaid(streamer *models.Streamer, raid *models.Raid) er
 		},
 	})
 
-	_, err := c.postGQLRequest(op)
-	return err
+	return c.query(op, nil)
 }
 
 func (c *TwitchClient) MakePrediction(event *models.EventPrediction) error {
-	decision := event.Bet.Calculate(event.Streamer.GetChannelPoints())
+	decision, skip, comparedValue := event.DecideBet(event.Streamer.GetChannelPoints())
+
+	amount, err := c.capSpend(event.Streamer, decision.Amount)
+	if err != nil {
+		slog.Info("Skipping bet", "reason", err)
+		event.SkipBet(err.Error())
+		return nil
+	}
+	if amount < decision.Amount {
+		slog.Info("Lowering bet to stay above the points floor", "from", decision.Amount, "to", amount)
+		event.LimitBet(amount)
+		decision.Amount = amount
+	}
+
+	if limit, skipOver := event.StakeLimit(); limit > 0 && decision.Amount > limit {
+		if skipOver {
+			slog.Info("Skipping unapproved large bet", "amount", decision.Amount, "threshold", limit)
+			event.SkipBet("large bet not approved")
+			return nil
+		}
+		slog.Info("Lowering unapproved large bet", "from", decision.Amount, "to", limit)
+		event.LimitBet(limit)
+		decision.Amount = limit
+	}
 
 	if decision.Amount < 10 {
 		slog.Info("Bet amount too low", "amount", decision.Amount)
+		event.SkipBet("bet amount too low")
 		return nil
 	}
 
-	skip, comparedValue := event.Bet.Skip()
 	if skip {
 		slog.Info("Skipping bet", "filter", event.Bet.Settings.FilterCondition, "value", comparedValue)
+		event.SkipBet(fmt.Sprintf("filter condition not met (%v)", comparedValue))
 		return nil
 	}
 
+	settings := event.Bet.Settings
 	slog.Info("Placing prediction bet",
 		"event", event.Title,
 		"choice", decision.Choice,
 		"amount", decision.Amount,
+		"strategy", settings.Strategy,
+		"percentage", settings.Percentage,
+		"maxPoints", settings.MaxPoints,
+		"stealthMode", settings.StealthMode,
 	)
+	return c.PlacePrediction(event, decision)
+}
 
+// PlacePrediction bets decision.Amount points on the decided outcome as is,
+// without the strategy, filter, points floor or stake limit MakePrediction
+// applies. It is used for bets given manually from the dashboard.
+func (c *TwitchClient) PlacePrediction(event *models.EventPrediction, decision models.Decision) error {
 	op := constants.MakePrediction.WithVariables(map[string]interface{}{
 		"input": map[string]interface{}{
 			"eventID":       event.EventID,
@@ -520,56 +916,43 @@ func (c *TwitchClient) MakePrediction(event *models.EventPrediction) error {
 		},
 	})
 
-	resp, err := c.postGQLRequest(op)
-	if err != nil {
+	var data gql.MakePrediction
+	if err := c.query(op, &data); err != nil {
 		return err
 	}
 
-	if data, ok := resp["data"].(map[string]interface{}); ok {
-		if makePrediction, ok := data["makePrediction"].(map[string]interface{}); ok {
-			if errData, ok := makePrediction["error"].(map[string]interface{}); ok && errData != nil {
-				if code, ok := errData["code"].(string); ok {
-					return fmt.Errorf("prediction error: %s", code)
-				}
-			}
+	if data.MakePrediction != nil && data.MakePrediction.Error != nil {
+		code := data.MakePrediction.Error.Code
+		if isPredictionRestricted(code) {
+			return fmt.Errorf("%w: %s", ErrPredictionRestricted, code)
+		}
+		if isPredictionLocked(code) {
+			return fmt.Errorf("%w: %s", ErrPredictionLocked, code)
 		}
+		return fmt.Errorf("prediction error: %s", code)
 	}
 
-	event.BetPlaced = true
+	event.MarkBetPlaced()
 	return nil
 }
 
 func (c *TwitchClient) GetCampaignIDsFromStreamer(streamer *models.Streamer) ([]string, error) {
 	op := constants.DropsHighlightServiceAvailableDrops.WithVariables(map[string]interface{}{
-		"channelID": streamer.ChannelID,
+		"channelID": streamer.GetChannelID(),
 	})
 
-	resp, err := c.postGQLRequest(op)
-	if err != nil {
+	var data gql.AvailableDrops
+	if err := c.query(op, &data); err != nil {
 		return nil, err
 	}
-
-	data, ok := resp["data"].(map[string]interface{})
-	if !ok {
-		return nil, nil
-	}
-
-	channel, ok := data["channel"].(map[string]interface{})
-	if !ok || channel == nil {
-		return nil, nil
-	}
-
-	campaigns, ok := channel["viewerDropCampaigns"].([]interface{})
-	if !ok || campaigns == nil {
+	if data.Channel == nil {
 		return nil, nil
 	}
 
 	var ids []string
-	for _, campaign := range campaigns {
-		if c, ok := campaign.(map[string]interface{}); ok {
-			if id, ok := c["id"].(string); ok {
-				ids = append(ids, id)
-			}
+	for _, campaign := range data.Channel.ViewerDropCampaigns {
+		if campaign.ID != "" {
+			ids = append(ids, campaign.ID)
 		}
 	}
 
@@ -585,34 +968,56 @@ func (c *TwitchClient) GetPlaybackAccessToken(username string) (string, string,
 		"playerType": "site",
 	})
 
-	resp, err := c.postGQLRequest(op)
-	if err != nil {
+	var data gql.PlaybackAccessToken
+	if err := c.query(op, &data); err != nil {
 		return "", "", err
 	}
 
-	data, ok := resp["data"].(map[string]interface{})
-	if !ok {
-		slog.Debug("PlaybackAccessToken: no data", "username", username, "response", resp)
-		return "", "", fmt.Errorf("no data in response")
+	token := data.StreamPlaybackAccessToken
+	if token == nil {
+		token = data.StreamAccessToken
 	}
-
-	sat, ok := data["streamPlaybackAccessToken"].(map[string]interface{})
-	if !ok || sat == nil {
-		sat, ok = data["streamAccessToken"].(map[string]interface{})
-		if !ok || sat == nil {
-			slog.Debug("PlaybackAccessToken: no token found", "username", username, "data", data)
-			return "", "", fmt.Errorf("no stream access token")
-		}
+	if token == nil {
+		return "", "", fmt.Errorf("no stream access token")
+	}
+	if token.Signature == "" || token.Value == "" {
+		return "", "", fmt.Errorf("empty stream access token")
 	}
 
-	signature, _ := sat["signature"].(string)
-	value, _ := sat["value"].(string)
+	return token.Signature, token.Value, nil
+}
 
-	if signature == "" || value == "" {
-		return "", "", fmt.Errorf("empty stream access token")
+// GetDropCampaigns returns the drop campaigns of the account's drops
+// dashboard with the given status, or all of them if status is empty.
+func (c *TwitchClient) GetDropCampaigns(status string) ([]gql.DropCampaign, error) {
+	var data gql.ViewerDropsDashboard
+	if err := c.query(constants.ViewerDropsDashboard, &data); err != nil {
+		return nil, err
+	}
+	if data.CurrentUser == nil {
+		return nil, nil
+	}
+
+	var campaigns []gql.DropCampaign
+	for _, campaign := range data.CurrentUser.DropCampaigns {
+		if status == "" || campaign.Status == status {
+			campaigns = append(campaigns, campaign)
+		}
 	}
+	return campaigns, nil
+}
 
-	return signature, value, nil
+// GetInventory returns the account's drops inventory, or nil if Twitch sent
+// none.
+func (c *TwitchClient) GetInventory() (*gql.Inventory, error) {
+	var data gql.InventoryData
+	if err := c.query(constants.Inventory, &data); err != nil {
+		return nil, err
+	}
+	if data.CurrentUser == nil {
+		return nil, nil
+	}
+	return data.CurrentUser.Inventory, nil
 }
 
 func (c *TwitchClient) ClaimDrop(drop *models.Drop) (bool, error) {
@@ -624,54 +1029,47 @@ func (c *TwitchClient) ClaimDrop(drop *models.Drop) (bool, error) {
 		},
 	})
 
-	resp, err := c.postGQLRequest(op)
-	if err != nil {
+	var data gql.ClaimDropRewards
+	if err := c.query(op, &data); err != nil {
 		return false, err
 	}
-
-	data, ok := resp["data"].(map[string]interface{})
-	if !ok {
-		return false, nil
+	if data.ClaimDropRewards == nil {
+		return false, errors.New("no claim result in response")
 	}
 
-	claimRewards, ok := data["claimDropRewards"].(map[string]interface{})
-	if !ok || claimRewards == nil {
-		if errs, ok := data["errors"].([]interface{}); ok && len(errs) > 0 {
-			return false, nil
-		}
-		return false, nil
-	}
+	status := data.ClaimDropRewards.Status
+	return status == "ELIGIBLE_FOR_ALL" || status == "DROP_INSTANCE_ALREADY_CLAIMED", nil
+}
 
-	if status, ok := claimRewards["status"].(string); ok {
-		return status == "ELIGIBLE_FOR_ALL" || status == "DROP_INSTANCE_ALREADY_CLAIMED", nil
+// ContributeToCommunityGoal contributes points to a goal. Retrying with the same
+// transactionID does not contribute twice.
+func (c *TwitchClient) ContributeToCommunityGoal(streamer *models.Streamer, goalID, title string, amount int, transactionID string) error {
+	capped, err := c.capSpend(streamer, amount)
+	if err != nil {
+		return err
+	}
+	if capped < amount {
+		slog.Info("Lowering contribution to stay above the points floor", "goal", title, "from", amount, "to", capped)
+		amount = capped
 	}
 
-	return false, nil
-}
-
-func (c *TwitchClient) ContributeToCommunityGoal(streamer *models.Streamer, goalID, title string, amount int) error {
 	slog.Info("Contributing to community goal", "goal", title, "amount", amount)
 
 	op := constants.ContributeCommunityPointsCommunityGoal.WithVariables(map[string]interface{}{
 		"input": map[string]interface{}{
 			"amount":        amount,
-			"channelID":     streamer.ChannelID,
+			"channelID":     streamer.GetChannelID(),
 			"goalID":        goalID,
-			"transactionID": util.RandomHex(16),
+			"transactionID": transactionID,
 		},
 	})
 
-	resp, err := c.postGQLRequest(op)
-	if err != nil {
+	var data gql.ContributeCommunityGoal
+	if err := c.query(op, &data); err != nil {
 		return err
 	}
-
-	if data, ok := resp["data"].(map[string]interface{}); ok {
-		if contribute, ok := data["contributeCommunityPointsCommunityGoal"].(map[string]interface{}); ok {
-			if errData, ok := contribute["error"].(map[string]interface{}); ok && errData != nil {
-				return fmt.Errorf("contribution error: %v", errData)
-			}
-		}
+	if data.Contribute != nil && data.Contribute.Error != nil {
+		return fmt.Errorf("%w: %s", ErrContributionRejected, data.Contribute.Error.Code)
 	}
 
 	streamer.SetChannelPoints(streamer.GetChannelPoints() - amount)
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/gql"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		want   error
+		wantID string
+	}{
+		{
+			name:   "data",
+			body:   `{"data":{"user":{"id":"123"}}}`,
+			wantID: "123",
+		},
+		{
+			name:   "errors next to data",
+			body:   `{"data":{"user":{"id":"123"}},"errors":[{"message":"service timeout","path":["user","roles",0]}]}`,
+			wantID: "123",
+		},
+		{
+			name: "errors without data",
+			body: `{"data":null,"errors":[{"message":"service error"}]}`,
+			want: ErrGQL,
+		},
+		{
+			name: "integrity error",
+			body: `{"errors":[{"message":"failed integrity check"}]}`,
+			want: ErrIntegrity,
+		},
+		{
+			name: "top-level error",
+			body: `{"error":"Bad Request","status":400,"message":"The request body is invalid"}`,
+			want: ErrGQL,
+		},
+		{
+			name: "no data",
+			body: `{}`,
+			want: ErrGQL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp gql.Response
+			if err := json.Unmarshal([]byte(tt.body), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			var data gql.GetIDFromLogin
+			err := decodeResponse("GetIDFromLogin", &resp, &data)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("error = %v, want %v", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if data.User == nil || data.User.ID != tt.wantID {
+				t.Errorf("user = %+v, want ID %s", data.User, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestPredictionErrorCodes(t *testing.T) {
+	tests := []struct {
+		code       string
+		restricted bool
+		locked     bool
+	}{
+		{code: "SUBSCRIBERS_ONLY", restricted: true},
+		{code: "sub_only", restricted: true},
+		{code: "USER_RESTRICTED", restricted: true},
+		{code: "NOT_ELIGIBLE", restricted: true},
+		{code: "FORBIDDEN", restricted: true},
+		{code: "NOT_ALLOWED", restricted: true},
+		{code: "USER_BANNED", restricted: true},
+		{code: "EVENT_NOT_ACTIVE", locked: true},
+		{code: "EVENT_LOCKED", locked: true},
+		{code: "NOT_ENOUGH_POINTS"},
+		{code: "DUPLICATE_TRANSACTION"},
+		{code: "SUBMISSION_FAILED"},
+		{code: "UNKNOWN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := isPredictionRestricted(tt.code); got != tt.restricted {
+				t.Errorf("isPredictionRestricted(%q) = %v, want %v", tt.code, got, tt.restricted)
+			}
+			if got := isPredictionLocked(tt.code); got != tt.locked {
+				t.Errorf("isPredictionLocked(%q) = %v, want %v", tt.code, got, tt.locked)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+// ErrUnknownDebugOperation is returned for operations not in debugOperations.
+var ErrUnknownDebugOperation = errors.New("operation not allowed for debugging")
+
+// debugOperations are the read-only GQL operations the debug page may run,
+// each building its variables from the selected streamer. Mutations such as
+// claims and bets are deliberately left out.
+var debugOperations = map[string]func(*models.Streamer) constants.GQLOperation{
+	constants.ChannelPointsContext.OperationName: func(s *models.Streamer) constants.GQLOperation {
+		return constants.ChannelPointsContext.WithVariables(map[string]interface{}{
+			"channelLogin": s.GetUsername(),
+		})
+	},
+	constants.VideoPlayerStreamInfoOverlayChannel.OperationName: func(s *models.Streamer) constants.GQLOperation {
+		return constants.VideoPlayerStreamInfoOverlayChannel.WithVariables(map[string]interface{}{
+			"channel": s.GetUsername(),
+		})
+	},
+	constants.GetIDFromLogin.OperationName: func(s *models.Streamer) constants.GQLOperation {
+		return constants.GetIDFromLogin.WithVariables(map[string]interface{}{
+			"login": s.GetUsername(),
+		})
+	},
+	constants.DropsHighlightServiceAvailableDrops.OperationName: func(s *models.Streamer) constants.GQLOperation {
+		return constants.DropsHighlightServiceAvailableDrops.WithVariables(map[string]interface{}{
+			"channelID": s.GetChannelID(),
+		})
+	},
+	constants.UserPointsContribution.OperationName: func(s *models.Streamer) constants.GQLOperation {
+		return constants.UserPointsContribution.WithVariables(map[string]interface{}{
+			"channelLogin": s.GetUsername(),
+		})
+	},
+}
+
+// DebugResult is the raw outcome of a debug GQL operation.
+type DebugResult struct {
+	Operation  string                 `json:"operation"`
+	Variables  map[string]interface{} `json:"variables"`
+	DurationMs int64                  `json:"durationMs"`
+	Response   map[string]interface{} `json:"response"`
+}
+
+// DebugOperations returns the names of the operations RunDebugOperation
+// accepts, sorted.
+func DebugOperations() []string {
+	names := make([]string, 0, len(debugOperations))
+	for name := range debugOperations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunDebugOperation sends a whitelisted GQL operation for the streamer and
+// returns Twitch's response unparsed. Failures do not count towards the
+// consecutive GQL failures reported as an outage.
+func (c *TwitchClient) RunDebugOperation(name string, streamer *models.Streamer) (*DebugResult, error) {
+	build, ok := debugOperations[name]
+	if !ok {
+		return nil, ErrUnknownDebugOperation
+	}
+	op := build(streamer)
+
+	start := time.Now()
+	body, err := c.doGQLRequest(op)
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Since(start)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &DebugResult{
+		Operation:  op.OperationName,
+		Variables:  op.Variables,
+		DurationMs: duration.Milliseconds(),
+		Response:   resp,
+	}, nil
+}
@@ -1,23 +1,28 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
 )
 
 var (
 	ErrBadCredentials       = errors.New("bad credentials")
 	ErrExpiredCode          = errors.New("device code expired")
 	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrNoRefreshToken       = errors.New("no refresh token")
 )
 
 type DeviceCodeResponse struct {
@@ -37,9 +42,10 @@ type TokenResponse struct {
 }
 
 type StoredAuth struct {
-	AuthToken string `json:"auth_token"`
-	UserID    string `json:"user_id"`
-	Username  string `json:"username"`
+	AuthToken    string `json:"auth_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	UserID       string `json:"user_id"`
+	Username     string `json:"username"`
 }
 
 type AuthEventCallback func(event AuthEvent)
@@ -66,9 +72,15 @@ type TwitchAuth struct {
 	deviceID      string
 	username      string
 	token         string
+	refreshToken  string
 	userID        string
 	client        *http.Client
 	eventCallback AuthEventCallback
+
+	mu sync.RWMutex
+	// refreshMu serializes token refreshes, so requests rejected together
+	// refresh only once.
+	refreshMu sync.Mutex
 }
 
 func NewTwitchAuth(username, deviceID string) *TwitchAuth {
@@ -76,11 +88,13 @@ func NewTwitchAuth(username, deviceID string) *TwitchAuth {
 		clientID: constants.ClientIDTV,
 		deviceID: deviceID,
 		username: strings.ToLower(strings.TrimSpace(username)),
-		client:   &http.Client{Timeout: 30 * time.Second},
+		client:   httpx.NewClient(30 * time.Second),
 	}
 }
 
 func (a *TwitchAuth) GetAuthToken() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	return a.token
 }
 
@@ -93,9 +107,25 @@ func (a *TwitchAuth) GetUsername() string {
 }
 
 func (a *TwitchAuth) SetToken(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.token = token
 }
 
+// SetTokens replaces the access token and the refresh token used to renew it.
+func (a *TwitchAuth) SetTokens(token, refreshToken string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = token
+	a.refreshToken = refreshToken
+}
+
+func (a *TwitchAuth) getRefreshToken() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.refreshToken
+}
+
 func (a *TwitchAuth) SetUserID(userID string) {
 	a.userID = userID
 }
@@ -125,7 +155,7 @@ func (a *TwitchAuth) LoadStoredAuth() error {
 		return err
 	}
 
-	a.token = stored.AuthToken
+	a.SetTokens(stored.AuthToken, stored.RefreshToken)
 	a.userID = stored.UserID
 	a.username = stored.Username
 	return nil
@@ -137,9 +167,10 @@ func (a *TwitchAuth) SaveAuth() error {
 	}
 
 	stored := StoredAuth{
-		AuthToken: a.token,
-		UserID:    a.userID,
-		Username:  a.username,
+		AuthToken:    a.GetAuthToken(),
+		RefreshToken: a.getRefreshToken(),
+		UserID:       a.userID,
+		Username:     a.username,
 	}
 
 	data, err := json.MarshalIndent(stored, "", "  ")
@@ -161,7 +192,7 @@ func (a *TwitchAuth) HasStoredAuth() bool {
 
 func (a *TwitchAuth) Login() error {
 	if a.HasStoredAuth() {
-		if err := a.LoadStoredAuth(); err == nil && a.token != "" {
+		if err := a.LoadStoredAuth(); err == nil && a.GetAuthToken() != "" {
 			return nil
 		}
 	}
@@ -169,6 +200,133 @@ func (a *TwitchAuth) Login() error {
 	return a.DeviceFlowLogin()
 }
 
+// Relogin replaces a token Twitch has rejected. The stored auth is reloaded
+// first, in case another login already saved a new token, and used if Twitch
+// accepts it; otherwise the token is refreshed, and only if that fails the
+// device flow is run again.
+func (a *TwitchAuth) Relogin() error {
+	rejected := a.GetAuthToken()
+	if err := a.LoadStoredAuth(); err == nil {
+		if token := a.GetAuthToken(); token != "" && token != rejected && a.Validate() == nil {
+			return nil
+		}
+	}
+
+	if _, err := a.Refresh(a.GetAuthToken()); err == nil {
+		return nil
+	} else if !errors.Is(err, ErrNoRefreshToken) {
+		slog.Warn("Failed to refresh auth token", "error", err)
+	}
+
+	return a.DeviceFlowLogin()
+}
+
+// Refresh exchanges the refresh token for a new access token and saves both
+// to the cookies file, reporting whether it replaced the token. rejected is
+// the access token Twitch turned down; if the token has changed since,
+// another refresh already replaced it and nothing is done. It returns
+// ErrNoRefreshToken for logins from before refresh tokens were stored, and
+// ErrBadCredentials if Twitch rejects the refresh token.
+func (a *TwitchAuth) Refresh(rejected string) (bool, error) {
+	a.refreshMu.Lock()
+	defer a.refreshMu.Unlock()
+
+	if a.GetAuthToken() != rejected {
+		return false, nil
+	}
+	refreshToken := a.getRefreshToken()
+	if refreshToken == "" {
+		return false, ErrNoRefreshToken
+	}
+
+	token, err := a.requestRefresh(refreshToken)
+	if err != nil {
+		return false, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	a.SetTokens(token.AccessToken, token.RefreshToken)
+
+	if err := a.SaveAuth(); err != nil {
+		return true, fmt.Errorf("failed to save auth: %w", err)
+	}
+	return true, nil
+}
+
+func (a *TwitchAuth) requestRefresh(refreshToken string) (*TokenResponse, error) {
+	data := url.Values{
+		"client_id":     {a.clientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+
+	ctx := httpx.WithOperation(context.Background(), "oauth:refresh")
+	req, err := http.NewRequestWithContext(ctx, "POST", constants.OAuthTokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Client-Id", a.clientID)
+	req.Header.Set("X-Device-Id", a.deviceID)
+	req.Header.Set("User-Agent", constants.TVUserAgent)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized:
+		return nil, ErrBadCredentials
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var token TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, ErrBadCredentials
+	}
+
+	return &token, nil
+}
+
+// Validate checks the current token with Twitch. It returns ErrBadCredentials
+// if the token is invalid or expired.
+func (a *TwitchAuth) Validate() error {
+	ctx := httpx.WithOperation(context.Background(), "oauth:validate")
+	req, err := http.NewRequestWithContext(ctx, "GET", constants.OAuthValidateURL, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "OAuth "+a.GetAuthToken())
+	req.Header.Set("Client-Id", a.clientID)
+	req.Header.Set("User-Agent", constants.TVUserAgent)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return ErrBadCredentials
+	default:
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+}
+
 func (a *TwitchAuth) DeviceFlowLogin() error {
 	a.emitEvent(AuthEvent{Type: AuthEventStarted})
 
@@ -197,7 +355,7 @@ func (a *TwitchAuth) DeviceFlowLogin() error {
 		return fmt.Errorf("failed to get token: %w", err)
 	}
 
-	a.token = token.AccessToken
+	a.SetTokens(token.AccessToken, token.RefreshToken)
 
 	if err := a.SaveAuth(); err != nil {
 		a.emitEvent(AuthEvent{Type: AuthEventError, Error: err})
@@ -214,7 +372,8 @@ func (a *TwitchAuth) requestDeviceCode() (*DeviceCodeResponse, error) {
 		"scopes":    {constants.OAuthScopes},
 	}
 
-	req, err := http.NewRequest("POST", constants.OAuthDeviceURL, strings.NewReader(data.Encode()))
+	ctx := httpx.WithOperation(context.Background(), "oauth:device")
+	req, err := http.NewRequestWithContext(ctx, "POST", constants.OAuthDeviceURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -271,7 +430,8 @@ func (a *TwitchAuth) requestToken(deviceCode string) (*TokenResponse, error) {
 		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
 	}
 
-	req, err := http.NewRequest("POST", constants.OAuthTokenURL, strings.NewReader(data.Encode()))
+	ctx := httpx.WithOperation(context.Background(), "oauth:token")
+	req, err := http.NewRequestWithContext(ctx, "POST", constants.OAuthTokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
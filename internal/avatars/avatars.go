@@ -0,0 +1,182 @@
+// Package avatars caches streamer profile images on disk, so dashboards on
+// networks without access to Twitch's CDN still show them.
+package avatars
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
+)
+
+const (
+	// refreshInterval is how old a cached avatar may get before it is
+	// fetched again in the background.
+	refreshInterval = 24 * time.Hour
+
+	// retryInterval is how long a login whose avatar could not be fetched
+	// is left alone before trying again.
+	retryInterval = time.Hour
+
+	// maxImageSize caps the size of a downloaded profile image.
+	maxImageSize = 1 << 20
+)
+
+// ErrNotFound is returned when no avatar is cached for a login and none could
+// be fetched.
+var ErrNotFound = errors.New("avatar not available")
+
+var loginPattern = regexp.MustCompile(`^[a-z0-9_]{1,25}$`)
+
+// URLResolver looks up the URL of a channel's current profile image.
+type URLResolver interface {
+	GetProfileImageURL(login string) (string, error)
+}
+
+// Avatar is a cached profile image. ModTime is when it was fetched.
+type Avatar struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+}
+
+// Cache stores one profile image file per login in its directory.
+type Cache struct {
+	dir        string
+	resolver   URLResolver
+	client     *http.Client
+	refreshing map[string]bool
+	failed     map[string]time.Time
+	mu         sync.Mutex
+}
+
+func New(dir string, resolver URLResolver) *Cache {
+	return &Cache{
+		dir:        dir,
+		resolver:   resolver,
+		client:     httpx.NewClient(10 * time.Second),
+		refreshing: make(map[string]bool),
+		failed:     make(map[string]time.Time),
+	}
+}
+
+// Get returns the avatar of a login, fetching it first if it was never
+// cached. An avatar older than refreshInterval is returned as is and fetched
+// again in the background.
+func (c *Cache) Get(login string) (*Avatar, error) {
+	if !loginPattern.MatchString(login) {
+		return nil, ErrNotFound
+	}
+
+	avatar, err := c.load(login)
+	if err != nil {
+		if !c.shouldFetch(login) {
+			return nil, ErrNotFound
+		}
+		if err := c.fetch(login); err != nil {
+			slog.Debug("Failed to fetch avatar", "streamer", login, "error", err)
+			return nil, ErrNotFound
+		}
+		return c.load(login)
+	}
+
+	if time.Since(avatar.ModTime) > refreshInterval && c.shouldFetch(login) {
+		go func() {
+			if err := c.fetch(login); err != nil {
+				slog.Debug("Failed to refresh avatar", "streamer", login, "error", err)
+			}
+		}()
+	}
+	return avatar, nil
+}
+
+func (c *Cache) load(login string) (*Avatar, error) {
+	path := filepath.Join(c.dir, login)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	return &Avatar{
+		Data:        data,
+		ContentType: http.DetectContentType(data),
+		ETag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+// shouldFetch reports whether a fetch of the login may start now and marks
+// it as in progress. Only one fetch per login runs at a time, and a failed
+// one is not retried before retryInterval.
+func (c *Cache) shouldFetch(login string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.refreshing[login] || time.Since(c.failed[login]) < retryInterval {
+		return false
+	}
+	c.refreshing[login] = true
+	return true
+}
+
+// fetch downloads the login's current profile image and replaces the cached
+// file. The caller must have called shouldFetch.
+func (c *Cache) fetch(login string) (err error) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.refreshing, login)
+		if err != nil {
+			c.failed[login] = time.Now()
+		} else {
+			delete(c.failed, login)
+		}
+		c.mu.Unlock()
+	}()
+
+	url, err := c.resolver.GetProfileImageURL(login)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxImageSize {
+		return fmt.Errorf("image larger than %d bytes", maxImageSize)
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	tmp := filepath.Join(c.dir, login+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(c.dir, login))
+}
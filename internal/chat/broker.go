@@ -0,0 +1,130 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// liveBufferSize is how many messages a subscriber may fall behind before
+	// further messages are dropped for it.
+	liveBufferSize = 100
+	// maxSubscribersPerStreamer caps concurrent live viewers of one channel.
+	maxSubscribersPerStreamer = 10
+)
+
+// ErrTooManySubscribers is returned when a channel already has the maximum
+// number of live subscribers.
+var ErrTooManySubscribers = errors.New("too many live chat subscribers")
+
+// LiveMessage is a chat message as it arrived over IRC. Its JSON shape matches
+// the stored chat log so the dashboard renders both the same way.
+type LiveMessage struct {
+	Timestamp   int64   `json:"timestamp"`
+	Username    string  `json:"username"`
+	DisplayName string  `json:"display_name"`
+	Message     string  `json:"message"`
+	Badges      string  `json:"badges,omitempty"`
+	Color       string  `json:"color,omitempty"`
+	EmoteList   []Emote `json:"emote_list,omitempty"`
+}
+
+// Subscription receives live messages for one channel. Messages that arrive
+// while its buffer is full are dropped and counted rather than blocking the
+// IRC reader.
+type Subscription struct {
+	C <-chan LiveMessage
+
+	ch      chan LiveMessage
+	dropped int
+	mu      sync.Mutex
+}
+
+// TakeDropped returns the number of messages dropped since the last call.
+func (s *Subscription) TakeDropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.dropped
+	s.dropped = 0
+	return n
+}
+
+// Broker fans out chat messages from the IRC clients to live subscribers.
+type Broker struct {
+	subs map[string][]*Subscription
+	mu   sync.RWMutex
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[string][]*Subscription)}
+}
+
+// Subscribe registers a live subscriber for a channel.
+func (b *Broker) Subscribe(streamer string) (*Subscription, error) {
+	key := strings.ToLower(streamer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.subs[key]) >= maxSubscribersPerStreamer {
+		return nil, ErrTooManySubscribers
+	}
+
+	ch := make(chan LiveMessage, liveBufferSize)
+	sub := &Subscription{C: ch, ch: ch}
+	b.subs[key] = append(b.subs[key], sub)
+	return sub, nil
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (b *Broker) Unsubscribe(streamer string, sub *Subscription) {
+	key := strings.ToLower(streamer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := b.subs[key]
+	for i, s := range subs {
+		if s == sub {
+			b.subs[key] = append(subs[:i], subs[i+1:]...)
+			close(sub.ch)
+			break
+		}
+	}
+	if len(b.subs[key]) == 0 {
+		delete(b.subs, key)
+	}
+}
+
+// Publish delivers a message to every subscriber of a channel without blocking.
+func (b *Broker) Publish(streamer string, msg ChatMessageData) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	subs := b.subs[strings.ToLower(streamer)]
+	if len(subs) == 0 {
+		return
+	}
+
+	live := LiveMessage{
+		Timestamp:   time.Now().UnixMilli(),
+		Username:    msg.Username,
+		DisplayName: msg.DisplayName,
+		Message:     msg.Message,
+		Badges:      msg.Badges,
+		Color:       msg.Color,
+		EmoteList:   ParseEmotes(msg.Emotes, msg.Message),
+	}
+
+	for _, sub := range subs {
+		select {
+		case sub.ch <- live:
+		default:
+			sub.mu.Lock()
+			sub.dropped++
+			sub.mu.Unlock()
+		}
+	}
+}
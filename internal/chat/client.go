@@ -2,6 +2,7 @@ package chat
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/crash"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 )
 
@@ -37,6 +40,15 @@ type IRCClient struct {
 	logger         ChatLogger
 	logChat        bool
 	mentionHandler MentionHandler
+	broker         *Broker
+	ignore         *IgnoreList
+	mentions       *MentionFilter
+	logPolicy      *LogPolicy
+	useTLS         bool
+
+	// tagsRequested is set once message tags (emotes, badges, colors) were
+	// requested for logging.
+	tagsRequested bool
 
 	conn     net.Conn
 	reader   *bufio.Reader
@@ -47,11 +59,11 @@ type IRCClient struct {
 }
 
 func NewIRCClient(username, token string, streamer *models.Streamer, logger ChatLogger, logChat bool, mentionHandler MentionHandler) *IRCClient {
-	slog.Debug("Creating IRC client", "channel", streamer.Username, "logChat", logChat, "hasLogger", logger != nil)
+	slog.Debug("Creating IRC client", "channel", streamer.GetUsername(), "logChat", logChat, "hasLogger", logger != nil)
 	return &IRCClient{
 		username:       username,
 		token:          token,
-		channel:        "#" + strings.ToLower(streamer.Username),
+		channel:        "#" + strings.ToLower(streamer.GetUsername()),
 		streamer:       streamer,
 		logger:         logger,
 		logChat:        logChat,
@@ -60,9 +72,52 @@ func NewIRCClient(username, token string, streamer *models.Streamer, logger Chat
 	}
 }
 
-func (c *IRCClient) Connect() error {
+// dialIRC opens a connection to the Twitch IRC server, over TLS on port 6697
+// or in plain text on port 6667.
+func dialIRC(useTLS bool) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if useTLS {
+		addr := net.JoinHostPort(constants.IRCURL, fmt.Sprintf("%d", constants.IRCPortTLS))
+		return tls.DialWithDialer(dialer, "tcp", addr, httpx.TLSConfig())
+	}
 	addr := net.JoinHostPort(constants.IRCURL, fmt.Sprintf("%d", constants.IRCPort))
-	conn, err := net.DialTimeout("tcp", addr, 30*time.Second)
+	return dialer.Dial("tcp", addr)
+}
+
+// CheckLogin connects to Twitch IRC, logs in as username and waits for the
+// welcome message, returning an error if Twitch rejects the token or does not
+// answer within timeout. No channel is joined.
+func CheckLogin(username, token string, useTLS bool, timeout time.Duration) error {
+	conn, err := dialIRC(useTLS)
+	if err != nil {
+		return fmt.Errorf("failed to connect to IRC: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := fmt.Fprintf(conn, "PASS oauth:%s\r\nNICK %s\r\n", token, username); err != nil {
+		return fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("no welcome message: %w", err)
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[1] == "001":
+			return nil
+		case len(fields) >= 2 && fields[1] == "NOTICE":
+			_, notice, _ := strings.Cut(line, " :")
+			return fmt.Errorf("login rejected: %s", strings.TrimSpace(notice))
+		}
+	}
+}
+
+func (c *IRCClient) Connect() error {
+	conn, err := dialIRC(c.useTLS)
 	if err != nil {
 		return fmt.Errorf("failed to connect to IRC: %w", err)
 	}
@@ -71,6 +126,7 @@ func (c *IRCClient) Connect() error {
 	c.conn = conn
 	c.reader = bufio.NewReader(conn)
 	c.running = true
+	c.tagsRequested = false
 	c.mu.Unlock()
 
 	if err := c.authenticate(); err != nil {
@@ -83,15 +139,15 @@ func (c *IRCClient) Connect() error {
 		return fmt.Errorf("failed to join channel: %w", err)
 	}
 
-	go c.readLoop()
+	go crash.Loop(c.stopChan, "chat "+c.channel, c.readLoop)
 
 	slog.Info("Joined IRC chat", "channel", c.channel)
 	return nil
 }
 
 func (c *IRCClient) authenticate() error {
-	if c.logChat {
-		if err := c.send("CAP REQ :twitch.tv/tags twitch.tv/commands"); err != nil {
+	if c.logging() {
+		if err := c.requestTags(); err != nil {
 			return err
 		}
 	}
@@ -195,29 +251,35 @@ func (c *IRCClient) handlePrivMsg(line string) {
 		}
 	}
 
-	if c.logChat && c.logger != nil {
-		displayName := nick
-		if dn, ok := tags["display-name"]; ok && dn != "" {
-			displayName = dn
-		}
+	if c.ignore != nil && c.ignore.Matches(nick, message) {
+		return
+	}
 
-		msgData := ChatMessageData{
-			Username:    nick,
-			DisplayName: displayName,
-			Message:     message,
-			Emotes:      tags["emotes"],
-			Badges:      tags["badges"],
-			Color:       tags["color"],
-		}
+	displayName := nick
+	if dn, ok := tags["display-name"]; ok && dn != "" {
+		displayName = dn
+	}
 
-		if err := c.logger.RecordChatMessage(c.streamer.Username, msgData); err != nil {
+	msgData := ChatMessageData{
+		Username:    nick,
+		DisplayName: displayName,
+		Message:     message,
+		Emotes:      tags["emotes"],
+		Badges:      tags["badges"],
+		Color:       tags["color"],
+	}
+
+	if c.logger != nil && c.logging() && (c.logPolicy == nil || c.logPolicy.Sample(c.streamer.GetUsername())) {
+		if err := c.logger.RecordChatMessage(c.streamer.GetUsername(), msgData); err != nil {
 			slog.Debug("Failed to log chat message", "error", err)
 		}
 	}
 
-	mention := "@" + strings.ToLower(c.username)
-	if strings.Contains(strings.ToLower(message), mention) ||
-		strings.Contains(strings.ToLower(message), strings.ToLower(c.username)) {
+	if c.broker != nil {
+		c.broker.Publish(c.streamer.GetUsername(), msgData)
+	}
+
+	if c.mentions != nil && c.mentions.Check(c.username, c.channel, nick, message, time.Now()) {
 		slog.Info("Chat mention",
 			"channel", c.channel,
 			"from", nick,
@@ -225,7 +287,7 @@ func (c *IRCClient) handlePrivMsg(line string) {
 		)
 
 		if c.mentionHandler != nil {
-			c.mentionHandler(c.streamer.Username, nick, message)
+			c.mentionHandler(c.streamer.GetUsername(), nick, message)
 		}
 	}
 }
@@ -260,6 +322,36 @@ func (c *IRCClient) Stop() {
 	slog.Info("Left IRC chat", "channel", c.channel)
 }
 
+// logging reports whether messages are logged: the streamer's chat log
+// setting, unless the log policy overrides it.
+func (c *IRCClient) logging() bool {
+	if c.logPolicy == nil {
+		return c.logChat
+	}
+	return c.logPolicy.Logging(c.streamer.GetUsername(), c.logChat)
+}
+
+// requestTags asks Twitch to send message tags, which logging stores. Twitch
+// accepts this on an open connection, so logging can start without
+// reconnecting.
+func (c *IRCClient) requestTags() error {
+	c.mu.Lock()
+	if c.tagsRequested {
+		c.mu.Unlock()
+		return nil
+	}
+	c.tagsRequested = true
+	c.mu.Unlock()
+
+	if err := c.send("CAP REQ :twitch.tv/tags twitch.tv/commands"); err != nil {
+		c.mu.Lock()
+		c.tagsRequested = false
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
 func (c *IRCClient) IsRunning() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
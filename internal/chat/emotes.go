@@ -0,0 +1,76 @@
+package chat
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EmoteCDN is the Twitch emote image URL template; {id} is replaced with the emote ID.
+const EmoteCDN = "https://static-cdn.jtvnw.net/emoticons/v2/{id}/default/dark/1.0"
+
+// Emote is a single emote occurrence within a chat message. Start and End are
+// inclusive character (rune) positions, as sent by Twitch in the emotes tag.
+type Emote struct {
+	ID    string `json:"id"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Code  string `json:"code"`
+	URL   string `json:"url"`
+}
+
+// EmoteURL returns the CDN image URL for a Twitch emote ID.
+func EmoteURL(id string) string {
+	return strings.Replace(EmoteCDN, "{id}", id, 1)
+}
+
+// ParseEmotes parses an IRC emotes tag ("id:start-end,start-end/id:start-end")
+// into emote occurrences ordered by position. Malformed entries and ranges that
+// fall outside the message are skipped.
+func ParseEmotes(tag, message string) []Emote {
+	if tag == "" {
+		return nil
+	}
+
+	runes := []rune(message)
+	var emotes []Emote
+
+	for _, part := range strings.Split(tag, "/") {
+		id, positions, ok := strings.Cut(part, ":")
+		if !ok || id == "" {
+			continue
+		}
+
+		for _, pos := range strings.Split(positions, ",") {
+			startStr, endStr, ok := strings.Cut(pos, "-")
+			if !ok {
+				continue
+			}
+			start, err := strconv.Atoi(startStr)
+			if err != nil {
+				continue
+			}
+			end, err := strconv.Atoi(endStr)
+			if err != nil {
+				continue
+			}
+			if start < 0 || end < start || end >= len(runes) {
+				continue
+			}
+
+			emotes = append(emotes, Emote{
+				ID:    id,
+				Start: start,
+				End:   end,
+				Code:  string(runes[start : end+1]),
+				URL:   EmoteURL(id),
+			})
+		}
+	}
+
+	sort.Slice(emotes, func(i, j int) bool {
+		return emotes[i].Start < emotes[j].Start
+	})
+
+	return emotes
+}
@@ -0,0 +1,59 @@
+package chat
+
+import (
+	"strings"
+	"sync"
+)
+
+// IgnoreList decides which chat messages are dropped before they are logged,
+// checked for mentions or streamed live. It is shared by all IRC clients and
+// can be replaced at runtime.
+type IgnoreList struct {
+	users    map[string]bool
+	prefixes []string
+	mu       sync.RWMutex
+}
+
+func NewIgnoreList() *IgnoreList {
+	return &IgnoreList{users: make(map[string]bool)}
+}
+
+// Set replaces the ignored usernames (case-insensitive) and message prefixes.
+func (l *IgnoreList) Set(users, prefixes []string) {
+	userSet := make(map[string]bool, len(users))
+	for _, user := range users {
+		if user = strings.ToLower(strings.TrimSpace(user)); user != "" {
+			userSet[user] = true
+		}
+	}
+
+	var prefixList []string
+	for _, prefix := range prefixes {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			prefixList = append(prefixList, prefix)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.users = userSet
+	l.prefixes = prefixList
+}
+
+// Matches reports whether a message from username should be ignored.
+func (l *IgnoreList) Matches(username, message string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.users[strings.ToLower(username)] {
+		return true
+	}
+
+	message = strings.TrimSpace(message)
+	for _, prefix := range l.prefixes {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package chat
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LogPolicy overrides which channels' messages are logged and samples busy
+// channels. It is shared by all IRC clients and changes apply to the next
+// message, without reconnecting. Overrides last until the miner restarts;
+// the streamer's chatLogs setting is the default.
+type LogPolicy struct {
+	enabled map[string]bool
+	rates   map[string]float64
+	mu      sync.RWMutex
+}
+
+func NewLogPolicy() *LogPolicy {
+	return &LogPolicy{
+		enabled: make(map[string]bool),
+		rates:   make(map[string]float64),
+	}
+}
+
+// ChannelLogConfig is the logging state of a channel.
+type ChannelLogConfig struct {
+	Channel string `json:"channel"`
+	Joined  bool   `json:"joined"`
+	// Logging is whether messages are logged right now.
+	Logging bool `json:"logging"`
+	// Configured is the streamer's chatLogs setting, used without an
+	// override.
+	Configured bool  `json:"configured"`
+	Override   *bool `json:"override,omitempty"`
+	// SampleRate is the share of messages logged, 1 for all.
+	SampleRate float64 `json:"sampleRate"`
+}
+
+// LogConfigUpdate changes the log policy. A nil Channels value removes that
+// channel's override; a SampleRates value of 1 logs every message again.
+type LogConfigUpdate struct {
+	Channels    map[string]*bool   `json:"channels"`
+	SampleRates map[string]float64 `json:"sampleRates"`
+}
+
+// Apply validates and applies an update. Nothing is changed if a sample rate
+// is outside (0, 1].
+func (p *LogPolicy) Apply(update LogConfigUpdate) error {
+	for channel, rate := range update.SampleRates {
+		if rate <= 0 || rate > 1 {
+			return fmt.Errorf("sample rate of %s must be above 0 and at most 1", channel)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for channel, enabled := range update.Channels {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if enabled == nil {
+			delete(p.enabled, channel)
+		} else {
+			p.enabled[channel] = *enabled
+		}
+	}
+	for channel, rate := range update.SampleRates {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if rate == 1 {
+			delete(p.rates, channel)
+		} else {
+			p.rates[channel] = rate
+		}
+	}
+	return nil
+}
+
+// Logging reports whether messages in channel are logged, given the
+// streamer's setting.
+func (p *LogPolicy) Logging(channel string, configured bool) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if enabled, ok := p.enabled[strings.ToLower(channel)]; ok {
+		return enabled
+	}
+	return configured
+}
+
+// Sample reports whether a message in channel is picked by its sample rate.
+func (p *LogPolicy) Sample(channel string) bool {
+	p.mu.RLock()
+	rate, ok := p.rates[strings.ToLower(channel)]
+	p.mu.RUnlock()
+
+	return !ok || rand.Float64() < rate
+}
+
+// config returns the logging state of a channel with its configured default.
+func (p *LogPolicy) config(channel string, joined, configured bool) ChannelLogConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	cfg := ChannelLogConfig{
+		Channel:    channel,
+		Joined:     joined,
+		Logging:    joined && configured,
+		Configured: configured,
+		SampleRate: 1,
+	}
+	if enabled, ok := p.enabled[channel]; ok {
+		cfg.Override = &enabled
+		cfg.Logging = joined && enabled
+	}
+	if rate, ok := p.rates[channel]; ok {
+		cfg.SampleRate = rate
+	}
+	return cfg
+}
+
+// channels returns the channels with an override or a sample rate.
+func (p *LogPolicy) channels() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var channels []string
+	for channel := range p.enabled {
+		seen[channel] = true
+		channels = append(channels, channel)
+	}
+	for channel := range p.rates {
+		if !seen[channel] {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
@@ -2,7 +2,10 @@ package chat
 
 import (
 	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 )
@@ -14,6 +17,11 @@ type ChatManager struct {
 	logger           ChatLogger
 	globalChatLogsOn bool
 	mentionHandler   MentionHandler
+	broker           *Broker
+	ignore           *IgnoreList
+	mentions         *MentionFilter
+	logPolicy        *LogPolicy
+	useTLS           bool
 
 	mu sync.RWMutex
 }
@@ -26,11 +34,96 @@ func NewChatManager(username, token string, logger ChatLogger, globalChatLogsOn
 		logger:           logger,
 		globalChatLogsOn: globalChatLogsOn,
 		mentionHandler:   mentionHandler,
+		ignore:           NewIgnoreList(),
+		mentions:         NewMentionFilter(),
+		logPolicy:        NewLogPolicy(),
+		useTLS:           true,
 	}
 }
 
+// SetBroker publishes every message received in joined channels to b for live
+// viewing. It applies to chats joined afterwards.
+func (m *ChatManager) SetBroker(b *Broker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.broker = b
+}
+
+// SetAuthToken replaces the token used to log in to chats joined afterwards.
+func (m *ChatManager) SetAuthToken(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.token = token
+}
+
+// SetTLS selects whether chats are joined over TLS on port 6697 (the default)
+// or in plain text on port 6667. It applies to chats joined afterwards.
+func (m *ChatManager) SetTLS(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.useTLS = enabled
+}
+
+// SetIgnoreRules replaces the usernames and message prefixes whose messages
+// are ignored in every joined channel, including those already joined.
+func (m *ChatManager) SetIgnoreRules(users, prefixes []string) {
+	m.ignore.Set(users, prefixes)
+}
+
+// SetMentionRules replaces how mentions of the account are matched and how
+// long repeated mentions from the same user in a channel are suppressed.
+func (m *ChatManager) SetMentionRules(wholeWord, requireAt bool, dedupeWindow time.Duration) {
+	m.mentions.Set(wholeWord, requireAt, dedupeWindow)
+}
+
+// LogConfig returns the logging state of every joined channel and of every
+// channel with a runtime override, ordered by channel.
+func (m *ChatManager) LogConfig() []ChannelLogConfig {
+	m.mu.RLock()
+	configs := make([]ChannelLogConfig, 0, len(m.clients))
+	joined := make(map[string]bool, len(m.clients))
+	for name, client := range m.clients {
+		name = strings.ToLower(name)
+		joined[name] = true
+		configs = append(configs, m.logPolicy.config(name, true, client.logChat))
+	}
+	m.mu.RUnlock()
+
+	for _, channel := range m.logPolicy.channels() {
+		if !joined[channel] {
+			configs = append(configs, m.logPolicy.config(channel, false, m.globalChatLogsOn))
+		}
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Channel < configs[j].Channel })
+	return configs
+}
+
+// SetLogConfig changes which channels are logged and their sample rates.
+// Joined channels apply it to their next message.
+func (m *ChatManager) SetLogConfig(update LogConfigUpdate) error {
+	if err := m.logPolicy.Apply(update); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, client := range m.clients {
+		if client.logging() {
+			if err := client.requestTags(); err != nil {
+				slog.Debug("Failed to request chat tags", "channel", client.channel, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
 func (m *ChatManager) ToggleChat(streamer *models.Streamer) {
-	switch streamer.Settings.Chat {
+	if !streamer.GetEnabled() || streamer.GetSettings().DropsOnly {
+		m.leaveChat(streamer)
+		return
+	}
+
+	switch streamer.GetSettings().Chat {
 	case models.ChatAlways:
 		m.joinChat(streamer)
 	case models.ChatNever:
@@ -51,8 +144,8 @@ func (m *ChatManager) ToggleChat(streamer *models.Streamer) {
 }
 
 func (m *ChatManager) shouldLogChat(streamer *models.Streamer) bool {
-	if streamer.Settings.ChatLogs != nil {
-		return *streamer.Settings.ChatLogs
+	if chatLogs := streamer.GetSettings().ChatLogs; chatLogs != nil {
+		return *chatLogs
 	}
 	return m.globalChatLogsOn
 }
@@ -61,7 +154,7 @@ func (m *ChatManager) joinChat(streamer *models.Streamer) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if client, exists := m.clients[streamer.Username]; exists {
+	if client, exists := m.clients[streamer.GetUsername()]; exists {
 		if client.IsRunning() {
 			return
 		}
@@ -69,21 +162,26 @@ func (m *ChatManager) joinChat(streamer *models.Streamer) {
 
 	logChat := m.shouldLogChat(streamer)
 	client := NewIRCClient(m.username, m.token, streamer, m.logger, logChat, m.mentionHandler)
+	client.broker = m.broker
+	client.ignore = m.ignore
+	client.mentions = m.mentions
+	client.logPolicy = m.logPolicy
+	client.useTLS = m.useTLS
 	if err := client.Connect(); err != nil {
-		slog.Error("Failed to join IRC chat", "channel", streamer.Username, "error", err)
+		slog.Error("Failed to join IRC chat", "channel", streamer.GetUsername(), "error", err)
 		return
 	}
 
-	m.clients[streamer.Username] = client
+	m.clients[streamer.GetUsername()] = client
 }
 
 func (m *ChatManager) leaveChat(streamer *models.Streamer) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if client, exists := m.clients[streamer.Username]; exists {
+	if client, exists := m.clients[streamer.GetUsername()]; exists {
 		client.Stop()
-		delete(m.clients, streamer.Username)
+		delete(m.clients, streamer.GetUsername())
 	}
 }
 
@@ -0,0 +1,104 @@
+package chat
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// MentionFilter decides which chat messages are reported as mentions of the
+// account. It is shared by all IRC clients and can be replaced at runtime.
+type MentionFilter struct {
+	wholeWord    bool
+	requireAt    bool
+	dedupeWindow time.Duration
+
+	// lastNotified is when a mention from a user in a channel was last
+	// reported, keyed by channel and login.
+	lastNotified map[mentionKey]time.Time
+	mu           sync.Mutex
+}
+
+type mentionKey struct {
+	channel string
+	user    string
+}
+
+func NewMentionFilter() *MentionFilter {
+	return &MentionFilter{
+		wholeWord:    true,
+		lastNotified: make(map[mentionKey]time.Time),
+	}
+}
+
+// Set replaces the matching options. With wholeWord the username only matches
+// when not surrounded by other login characters; with requireAt it only
+// matches as "@username". Repeated mentions from the same user in the same
+// channel are suppressed for dedupeWindow.
+func (f *MentionFilter) Set(wholeWord, requireAt bool, dedupeWindow time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.wholeWord = wholeWord
+	f.requireAt = requireAt
+	f.dedupeWindow = dedupeWindow
+}
+
+// Check reports whether a message from user in channel mentions username and
+// should be reported. Messages sent by the account itself never match.
+func (f *MentionFilter) Check(username, channel, user, message string, now time.Time) bool {
+	username = strings.ToLower(username)
+	user = strings.ToLower(user)
+	if username == "" || user == username {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !containsMention(strings.ToLower(message), username, f.wholeWord, f.requireAt) {
+		return false
+	}
+
+	key := mentionKey{channel: channel, user: user}
+	if last, ok := f.lastNotified[key]; ok && now.Sub(last) < f.dedupeWindow {
+		return false
+	}
+	f.lastNotified[key] = now
+
+	for k, t := range f.lastNotified {
+		if now.Sub(t) >= f.dedupeWindow {
+			delete(f.lastNotified, k)
+		}
+	}
+	return true
+}
+
+func containsMention(message, username string, wholeWord, requireAt bool) bool {
+	target := username
+	if requireAt {
+		target = "@" + username
+	}
+
+	for offset := 0; ; {
+		idx := strings.Index(message[offset:], target)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(target)
+
+		if !wholeWord {
+			return true
+		}
+		if (start == 0 || !isLoginChar(message[start-1])) &&
+			(end == len(message) || !isLoginChar(message[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isLoginChar reports whether c can appear in a Twitch login.
+func isLoginChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_'
+}
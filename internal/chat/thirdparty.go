@@ -0,0 +1,203 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
+)
+
+// Third-party emote providers.
+const (
+	ProviderBTTV  = "bttv"
+	Provider7TV   = "7tv"
+	emoteCacheTTL = time.Hour
+)
+
+// Providers lists the supported third-party emote providers.
+var Providers = []string{ProviderBTTV, Provider7TV}
+
+// ThirdPartyEmote is a BTTV or 7TV emote that is rendered wherever its code
+// appears as a word in a message.
+type ThirdPartyEmote struct {
+	Code     string `json:"code"`
+	URL      string `json:"url"`
+	Provider string `json:"provider"`
+}
+
+type emoteCacheEntry struct {
+	emotes    []ThirdPartyEmote
+	fetchedAt time.Time
+}
+
+// EmoteResolver looks up global and channel emotes from third-party providers
+// and caches the results for an hour.
+type EmoteResolver struct {
+	client *http.Client
+	cache  map[string]emoteCacheEntry
+	mu     sync.Mutex
+}
+
+func NewEmoteResolver() *EmoteResolver {
+	return &EmoteResolver{
+		client: httpx.NewClient(10 * time.Second),
+		cache:  make(map[string]emoteCacheEntry),
+	}
+}
+
+// ThirdPartyEmotes returns the global and channel emotes of the given
+// providers, globals first so channel emotes take precedence for clients that
+// build a code lookup in order. A provider that fails is logged and skipped.
+func (r *EmoteResolver) ThirdPartyEmotes(channelID string, providers []string) []ThirdPartyEmote {
+	var result []ThirdPartyEmote
+	for _, provider := range providers {
+		result = append(result, r.cached(provider+":global", func() ([]ThirdPartyEmote, error) {
+			return r.fetchGlobal(provider)
+		})...)
+	}
+	if channelID == "" {
+		return result
+	}
+	for _, provider := range providers {
+		result = append(result, r.cached(provider+":"+channelID, func() ([]ThirdPartyEmote, error) {
+			return r.fetchChannel(provider, channelID)
+		})...)
+	}
+	return result
+}
+
+func (r *EmoteResolver) cached(key string, fetch func() ([]ThirdPartyEmote, error)) []ThirdPartyEmote {
+	r.mu.Lock()
+	entry, ok := r.cache[key]
+	r.mu.Unlock()
+
+	if ok && time.Since(entry.fetchedAt) < emoteCacheTTL {
+		return entry.emotes
+	}
+
+	emotes, err := fetch()
+	if err != nil {
+		slog.Debug("Failed to fetch third-party emotes", "key", key, "error", err)
+		return entry.emotes
+	}
+
+	r.mu.Lock()
+	r.cache[key] = emoteCacheEntry{emotes: emotes, fetchedAt: time.Now()}
+	r.mu.Unlock()
+
+	return emotes
+}
+
+type bttvEmote struct {
+	ID   string `json:"id"`
+	Code string `json:"code"`
+}
+
+type sevenTVEmote struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sevenTVEmoteSet struct {
+	Emotes []sevenTVEmote `json:"emotes"`
+}
+
+func (r *EmoteResolver) fetchGlobal(provider string) ([]ThirdPartyEmote, error) {
+	switch provider {
+	case ProviderBTTV:
+		var emotes []bttvEmote
+		if err := r.getJSON("https://api.betterttv.net/3/cached/emotes/global", provider, &emotes); err != nil {
+			return nil, err
+		}
+		return convertBTTV(emotes), nil
+	case Provider7TV:
+		var set sevenTVEmoteSet
+		if err := r.getJSON("https://7tv.io/v3/emote-sets/global", provider, &set); err != nil {
+			return nil, err
+		}
+		return convert7TV(set.Emotes), nil
+	default:
+		return nil, fmt.Errorf("unknown emote provider %q", provider)
+	}
+}
+
+func (r *EmoteResolver) fetchChannel(provider, channelID string) ([]ThirdPartyEmote, error) {
+	switch provider {
+	case ProviderBTTV:
+		var user struct {
+			ChannelEmotes []bttvEmote `json:"channelEmotes"`
+			SharedEmotes  []bttvEmote `json:"sharedEmotes"`
+		}
+		if err := r.getJSON("https://api.betterttv.net/3/cached/users/twitch/"+channelID, provider, &user); err != nil {
+			return nil, err
+		}
+		return convertBTTV(append(user.ChannelEmotes, user.SharedEmotes...)), nil
+	case Provider7TV:
+		var user struct {
+			EmoteSet *sevenTVEmoteSet `json:"emote_set"`
+		}
+		if err := r.getJSON("https://7tv.io/v3/users/twitch/"+channelID, provider, &user); err != nil {
+			return nil, err
+		}
+		if user.EmoteSet == nil {
+			return nil, nil
+		}
+		return convert7TV(user.EmoteSet.Emotes), nil
+	default:
+		return nil, fmt.Errorf("unknown emote provider %q", provider)
+	}
+}
+
+// getJSON decodes the response into v. A 404 means the channel has no account
+// with the provider and leaves v empty.
+func (r *EmoteResolver) getJSON(url, provider string, v any) error {
+	ctx := httpx.WithOperation(context.Background(), "emotes:"+provider)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func convertBTTV(emotes []bttvEmote) []ThirdPartyEmote {
+	result := make([]ThirdPartyEmote, 0, len(emotes))
+	for _, e := range emotes {
+		result = append(result, ThirdPartyEmote{
+			Code:     e.Code,
+			URL:      "https://cdn.betterttv.net/emote/" + e.ID + "/1x",
+			Provider: ProviderBTTV,
+		})
+	}
+	return result
+}
+
+func convert7TV(emotes []sevenTVEmote) []ThirdPartyEmote {
+	result := make([]ThirdPartyEmote, 0, len(emotes))
+	for _, e := range emotes {
+		result = append(result, ThirdPartyEmote{
+			Code:     e.Name,
+			URL:      "https://cdn.7tv.app/emote/" + e.ID + "/1x.webp",
+			Provider: Provider7TV,
+		})
+	}
+	return result
+}
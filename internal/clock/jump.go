@@ -0,0 +1,57 @@
+// Package clock detects wall-clock jumps, such as a system resuming from sleep
+// or the time being adjusted, so periodic schedulers can resynchronize.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// checkInterval is how often the clocks are compared.
+	checkInterval = 15 * time.Second
+
+	// jumpThreshold is the discrepancy treated as a jump rather than scheduling noise.
+	jumpThreshold = time.Minute
+)
+
+// JumpHandler is called with how far the wall clock moved beyond the time the
+// process observed passing. It is negative if the clock was set back.
+type JumpHandler func(jump time.Duration)
+
+// WatchJumps calls onJump whenever the wall clock jumps until ctx is canceled.
+//
+// Two signals are used because platforms differ in whether the monotonic clock
+// advances during suspend: on Linux it stops, so the wall clock runs ahead of it;
+// elsewhere both advance, but the check itself fires far later than scheduled.
+func WatchJumps(ctx context.Context, onJump JumpHandler) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if jump := detectJump(last, now); jump != 0 {
+				onJump(jump)
+			}
+			last = now
+		}
+	}
+}
+
+func detectJump(last, now time.Time) time.Duration {
+	monotonic := now.Sub(last)
+	wall := now.Round(0).Sub(last.Round(0))
+
+	if drift := wall - monotonic; drift > jumpThreshold || drift < -jumpThreshold {
+		return drift
+	}
+	if late := monotonic - checkInterval; late > jumpThreshold {
+		return late
+	}
+	return 0
+}
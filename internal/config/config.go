@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+	"github.com/PatrickWalther/twitch-miner-go/internal/util"
 )
 
 type Priority string
@@ -18,31 +19,140 @@ const (
 	PriorityPointsDescending Priority = "POINTS_DESCENDING"
 )
 
+// WatchMode selects how watch slots are assigned when more streamers are
+// eligible than can be watched at once.
+type WatchMode string
+
+const (
+	// WatchModePriority fills the slots by priority every round.
+	WatchModePriority WatchMode = "PRIORITY"
+	// WatchModeTimeShare rotates the slots among all eligible streamers in
+	// proportion to their watch weight.
+	WatchModeTimeShare WatchMode = "TIME_SHARE"
+)
+
+// HLSMode selects which video requests accompany each minute-watched report.
+type HLSMode string
+
+const (
+	// HLSModeFull fetches the stream's playlists and sends a HEAD request for
+	// its newest segment, like a player would.
+	HLSModeFull HLSMode = "FULL"
+	// HLSModePlaylist fetches the playlists but no segment, for networks that
+	// block the video CDN.
+	HLSModePlaylist HLSMode = "PLAYLIST"
+	// HLSModeOff only sends the minute-watched report, without a playback
+	// token or any video request.
+	HLSModeOff HLSMode = "OFF"
+)
+
+// OnlineDetection selects how the miner notices streamers going live or
+// offline.
+type OnlineDetection string
+
+const (
+	// OnlineDetectionBoth polls every StreamCheckInterval and listens to the
+	// video-playback PubSub topic of every streamer.
+	OnlineDetectionBoth OnlineDetection = "BOTH"
+	// OnlineDetectionPolling only polls, saving one PubSub topic per streamer.
+	OnlineDetectionPolling OnlineDetection = "POLLING"
+	// OnlineDetectionPubSub only listens to PubSub. Streamers are still
+	// checked at startup and after a network outage.
+	OnlineDetectionPubSub OnlineDetection = "PUBSUB"
+)
+
+// UsesPolling reports whether streamers are checked every StreamCheckInterval.
+func (d OnlineDetection) UsesPolling() bool {
+	return d != OnlineDetectionPubSub
+}
+
+// UsesPubSub reports whether the video-playback topic is subscribed.
+func (d OnlineDetection) UsesPubSub() bool {
+	return d != OnlineDetectionPolling
+}
+
 type Config struct {
 	Username            string                  `json:"username"`
 	ClaimDropsOnStartup bool                    `json:"claimDropsOnStartup"`
 	EnableAnalytics     bool                    `json:"enableAnalytics"`
 	Priority            []Priority              `json:"priority"`
+	WatchMode           WatchMode               `json:"watchMode,omitempty"`
+	WatchNonEarning     bool                    `json:"watchNonEarning,omitempty"`
+	HLSMode             HLSMode                 `json:"hlsMode,omitempty"`
+	RaidDenylist        []string                `json:"raidDenylist,omitempty"`
+	ChatIgnore          ChatIgnoreSettings      `json:"chatIgnore"`
+	ChatMentions        ChatMentionSettings     `json:"chatMentions"`
+	ChatTLS             bool                    `json:"chatTLS"`
+	TLS                 TLSSettings             `json:"tls"`
 	StreamerSettings    models.StreamerSettings `json:"streamerSettings"`
 	Streamers           []StreamerConfig        `json:"streamers"`
 	RateLimits          RateLimitSettings       `json:"rateLimits"`
 	Logger              LoggerSettings          `json:"logger"`
 	Analytics           AnalyticsSettings       `json:"analytics"`
 	Discord             DiscordSettings         `json:"discord"`
+	Desktop             DesktopSettings         `json:"desktop"`
+	Follows             FollowSettings          `json:"follows"`
+	// Locale is the language numbers and relative times are written in on
+	// the dashboard, in its JSON and in notifications, e.g. "de".
+	Locale string `json:"locale,omitempty"`
+	// PointsFloor is the channel points balance automated spending (bets and
+	// community goal contributions) never takes a streamer below; 0 disables it.
+	PointsFloor int `json:"pointsFloor,omitempty"`
+	// BetConfirm holds large bets until they are approved.
+	BetConfirm BetConfirmSettings `json:"betConfirm"`
+	// Yield steps aside while the account is watched by hand.
+	Yield YieldSettings `json:"yield"`
+	// FeatureFlags switches experimental behaviors on by flag name; see
+	// package features for the known flags.
+	FeatureFlags map[string]bool `json:"featureFlags,omitempty"`
 }
 
 type StreamerConfig struct {
 	Username string                   `json:"username"`
 	Settings *models.StreamerSettings `json:"settings,omitempty"`
+	// Disabled keeps the streamer configured without mining it.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// ChatIgnoreSettings lists chat messages to drop before they are logged, checked
+// for mentions or shown in the live chat view.
+type ChatIgnoreSettings struct {
+	Users    []string `json:"users"`
+	Prefixes []string `json:"prefixes"`
+}
+
+// ChatMentionSettings controls which chat messages count as mentions of the
+// account. Messages sent by the account itself never do.
+type ChatMentionSettings struct {
+	// WholeWord only matches the username when it is not part of a longer
+	// word, so "bob" does not match "bobby".
+	WholeWord bool `json:"wholeWord"`
+	// RequireAt only matches "@username".
+	RequireAt bool `json:"requireAt"`
+	// DedupeWindow is how many seconds further mentions from the same user in
+	// the same channel are suppressed after one is reported. 0 disables it.
+	DedupeWindow int `json:"dedupeWindow"`
+}
+
+// TLSSettings controls certificate verification for connections to Twitch,
+// for networks behind a TLS-intercepting proxy.
+type TLSSettings struct {
+	// CAFile is a PEM bundle of extra root certificates to trust.
+	CAFile string `json:"caFile,omitempty"`
+	// InsecureSkipVerify disables certificate verification entirely. Prefer
+	// CAFile; this makes every connection open to interception.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 type RateLimitSettings struct {
-	WebsocketPingInterval int     `json:"websocketPingInterval"`
-	CampaignSyncInterval  int     `json:"campaignSyncInterval"`
-	MinuteWatchedInterval int     `json:"minuteWatchedInterval"`
-	RequestDelay          float64 `json:"requestDelay"`
-	ReconnectDelay        int     `json:"reconnectDelay"`
-	StreamCheckInterval   int     `json:"streamCheckInterval"`
+	WebsocketPingInterval  int             `json:"websocketPingInterval"`
+	CampaignSyncInterval   int             `json:"campaignSyncInterval"`
+	MinuteWatchedInterval  int             `json:"minuteWatchedInterval"`
+	RequestDelay           float64         `json:"requestDelay"`
+	ReconnectDelay         int             `json:"reconnectDelay"`
+	StreamCheckInterval    int             `json:"streamCheckInterval"`
+	StreamCheckConcurrency int             `json:"streamCheckConcurrency"`
+	OnlineDetection        OnlineDetection `json:"onlineDetection,omitempty"`
 }
 
 type LoggerSettings struct {
@@ -61,6 +171,61 @@ type AnalyticsSettings struct {
 	Refresh        int    `json:"refresh"`
 	DaysAgo        int    `json:"daysAgo"`
 	EnableChatLogs bool   `json:"enableChatLogs"`
+	WarnUnfollowed bool   `json:"warnUnfollowed"`
+	// EfficiencyAlert flags tracked streamers earning fewer points per watched
+	// hour than this over the last 30 days; 0 disables it.
+	EfficiencyAlert int `json:"efficiencyAlert"`
+	// APIPort, if set, serves the JSON API without the HTML pages on APIHost
+	// (default Host) and this port, and makes the dashboard listener
+	// read-only.
+	APIHost string `json:"apiHost,omitempty"`
+	APIPort int    `json:"apiPort,omitempty"`
+	// Socket, if set, serves the dashboard on this Unix socket path instead
+	// of Host and Port.
+	Socket string `json:"socket,omitempty"`
+}
+
+// FollowSettings mines the channels the account follows in addition to, or
+// with an empty streamers list instead of, the configured streamers.
+type FollowSettings struct {
+	Enabled bool `json:"enabled"`
+	// Limit caps how many followed channels are mined, in the order they were
+	// followed, oldest first. 0 mines all of them.
+	Limit int `json:"limit"`
+	// Exclude lists followed channels that are never mined.
+	Exclude []string `json:"exclude"`
+	// SyncInterval is how many minutes pass between follow list syncs.
+	SyncInterval int `json:"syncInterval"`
+}
+
+// BetConfirmFallback is what happens to a large bet that was not approved
+// before the prediction locked.
+type BetConfirmFallback string
+
+const (
+	// BetConfirmSkip does not bet at all.
+	BetConfirmSkip BetConfirmFallback = "SKIP"
+	// BetConfirmReduce bets the threshold instead.
+	BetConfirmReduce BetConfirmFallback = "REDUCE"
+)
+
+// BetConfirmSettings asks for approval on Discord or the dashboard before
+// placing a bet larger than Threshold.
+type BetConfirmSettings struct {
+	Enabled bool `json:"enabled"`
+	// Threshold is the largest bet placed without approval.
+	Threshold int `json:"threshold"`
+	// Fallback applies when the bet is rejected or not answered in time.
+	Fallback BetConfirmFallback `json:"fallback"`
+}
+
+// YieldSettings pauses watching when the account earns watch points on a
+// channel the miner is not watching, i.e. someone is viewing Twitch on the
+// same account.
+type YieldSettings struct {
+	Enabled bool `json:"enabled"`
+	// Minutes is how long watching stays paused after the last conflict.
+	Minutes int `json:"minutes"`
 }
 
 // DiscordSettings contains Discord integration configuration.
@@ -71,16 +236,75 @@ type DiscordSettings struct {
 	GuildID  string `json:"guildId"`
 }
 
+// DesktopSettings contains native OS notification configuration.
+// Each event type is toggled here rather than through the Discord notification rules.
+type DesktopSettings struct {
+	Enabled  bool `json:"enabled"`
+	Mentions bool `json:"mentions"`
+	Online   bool `json:"online"`
+	Drops    bool `json:"drops"`
+	Errors   bool `json:"errors"`
+}
+
 func DefaultConfig() Config {
 	return Config{
 		ClaimDropsOnStartup: false,
 		EnableAnalytics:     true,
+		Locale:              util.DefaultLocale,
 		Priority:            []Priority{PriorityStreak, PriorityDrops, PriorityOrder},
+		WatchMode:           WatchModePriority,
+		HLSMode:             HLSModeFull,
 		StreamerSettings:    models.DefaultStreamerSettings(),
+		ChatIgnore:          DefaultChatIgnoreSettings(),
+		ChatMentions:        DefaultChatMentionSettings(),
+		ChatTLS:             true,
 		RateLimits:          DefaultRateLimitSettings(),
 		Logger:              DefaultLoggerSettings(),
 		Analytics:           DefaultAnalyticsSettings(),
 		Discord:             DefaultDiscordSettings(),
+		Desktop:             DefaultDesktopSettings(),
+		Follows:             DefaultFollowSettings(),
+		BetConfirm:          DefaultBetConfirmSettings(),
+		Yield:               DefaultYieldSettings(),
+	}
+}
+
+func DefaultBetConfirmSettings() BetConfirmSettings {
+	return BetConfirmSettings{
+		Enabled:   false,
+		Threshold: 10000,
+		Fallback:  BetConfirmSkip,
+	}
+}
+
+func DefaultYieldSettings() YieldSettings {
+	return YieldSettings{
+		Enabled: false,
+		Minutes: 30,
+	}
+}
+
+func DefaultFollowSettings() FollowSettings {
+	return FollowSettings{
+		Enabled:      false,
+		Limit:        0,
+		Exclude:      []string{},
+		SyncInterval: 60,
+	}
+}
+
+func DefaultChatIgnoreSettings() ChatIgnoreSettings {
+	return ChatIgnoreSettings{
+		Users:    []string{"nightbot", "streamelements", "streamlabs", "moobot", "fossabot"},
+		Prefixes: []string{},
+	}
+}
+
+func DefaultChatMentionSettings() ChatMentionSettings {
+	return ChatMentionSettings{
+		WholeWord:    true,
+		RequireAt:    false,
+		DedupeWindow: 300,
 	}
 }
 
@@ -92,14 +316,26 @@ func DefaultDiscordSettings() DiscordSettings {
 	}
 }
 
+func DefaultDesktopSettings() DesktopSettings {
+	return DesktopSettings{
+		Enabled:  false,
+		Mentions: true,
+		Online:   true,
+		Drops:    true,
+		Errors:   true,
+	}
+}
+
 func DefaultRateLimitSettings() RateLimitSettings {
 	return RateLimitSettings{
-		WebsocketPingInterval: 27,
-		CampaignSyncInterval:  60,
-		MinuteWatchedInterval: 60,
-		RequestDelay:          0.5,
-		ReconnectDelay:        60,
-		StreamCheckInterval:   600,
+		WebsocketPingInterval:  27,
+		CampaignSyncInterval:   60,
+		MinuteWatchedInterval:  60,
+		RequestDelay:           0.5,
+		ReconnectDelay:         60,
+		StreamCheckInterval:    600,
+		StreamCheckConcurrency: 4,
+		OnlineDetection:        OnlineDetectionBoth,
 	}
 }
 
@@ -121,9 +357,22 @@ func DefaultAnalyticsSettings() AnalyticsSettings {
 		Refresh:        5,
 		DaysAgo:        7,
 		EnableChatLogs: false,
+		WarnUnfollowed: true,
 	}
 }
 
+// RedactedValue replaces secrets in a redacted config.
+const RedactedValue = "REDACTED"
+
+// Redacted returns a copy of the config with credentials replaced by
+// RedactedValue, safe to share in bug reports.
+func (c Config) Redacted() Config {
+	if c.Discord.BotToken != "" {
+		c.Discord.BotToken = RedactedValue
+	}
+	return c
+}
+
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -150,6 +399,25 @@ func SaveConfig(path string, config *Config) error {
 // ValidateConfig enforces min/max bounds on rate limits and other configurable values.
 // It mutates the config in place, clamping out-of-range values to valid bounds.
 func ValidateConfig(config *Config) {
+	config.Locale = util.NormalizeLocale(config.Locale)
+
+	if config.PointsFloor < 0 {
+		config.PointsFloor = 0
+	}
+
+	if config.BetConfirm.Threshold < 10 {
+		config.BetConfirm.Threshold = 10
+	}
+	if config.BetConfirm.Fallback != BetConfirmReduce {
+		config.BetConfirm.Fallback = BetConfirmSkip
+	}
+
+	if config.Yield.Minutes < 5 {
+		config.Yield.Minutes = 5
+	} else if config.Yield.Minutes > 240 {
+		config.Yield.Minutes = 240
+	}
+
 	if config.RateLimits.WebsocketPingInterval < 20 {
 		config.RateLimits.WebsocketPingInterval = 20
 	} else if config.RateLimits.WebsocketPingInterval > 60 {
@@ -185,4 +453,38 @@ func ValidateConfig(config *Config) {
 	} else if config.RateLimits.StreamCheckInterval > 900 {
 		config.RateLimits.StreamCheckInterval = 900
 	}
+
+	if config.RateLimits.StreamCheckConcurrency < 1 {
+		config.RateLimits.StreamCheckConcurrency = 1
+	} else if config.RateLimits.StreamCheckConcurrency > 16 {
+		config.RateLimits.StreamCheckConcurrency = 16
+	}
+
+	switch config.RateLimits.OnlineDetection {
+	case OnlineDetectionPolling, OnlineDetectionPubSub:
+	default:
+		config.RateLimits.OnlineDetection = OnlineDetectionBoth
+	}
+
+	if config.ChatMentions.DedupeWindow < 0 {
+		config.ChatMentions.DedupeWindow = 0
+	} else if config.ChatMentions.DedupeWindow > 3600 {
+		config.ChatMentions.DedupeWindow = 3600
+	}
+
+	if config.WatchMode != WatchModeTimeShare {
+		config.WatchMode = WatchModePriority
+	}
+	if config.HLSMode != HLSModePlaylist && config.HLSMode != HLSModeOff {
+		config.HLSMode = HLSModeFull
+	}
+
+	if config.Follows.Limit < 0 {
+		config.Follows.Limit = 0
+	}
+	if config.Follows.SyncInterval < 15 {
+		config.Follows.SyncInterval = 15
+	} else if config.Follows.SyncInterval > 1440 {
+		config.Follows.SyncInterval = 1440
+	}
 }
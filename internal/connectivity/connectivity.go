@@ -0,0 +1,166 @@
+// Package connectivity tracks whether Twitch is reachable, so subsystems can
+// pause quietly while the network is down instead of each logging its own
+// stream of errors, and resynchronize together once it returns.
+package connectivity
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
+)
+
+const (
+	// onlineInterval is how often connectivity is probed while online.
+	onlineInterval = 30 * time.Second
+
+	// retryInterval is how soon a failed probe is retried while still online,
+	// and how often connectivity is probed while offline.
+	retryInterval = 10 * time.Second
+
+	// failureThreshold is the number of consecutive failed probes that mark
+	// the network as lost.
+	failureThreshold = 2
+
+	probeTimeout = 10 * time.Second
+)
+
+// ChangeHandler is called when connectivity is lost or restored. When it is
+// restored, downtime is how long the network was considered offline.
+type ChangeHandler func(online bool, downtime time.Duration)
+
+// Monitor tracks whether Twitch is reachable. A nil Monitor always reports
+// online.
+type Monitor struct {
+	online       bool
+	offlineSince time.Time
+	restored     chan struct{}
+	mu           sync.RWMutex
+}
+
+// NewMonitor returns a monitor that reports online until Run observes the
+// network going down.
+func NewMonitor() *Monitor {
+	restored := make(chan struct{})
+	close(restored)
+	return &Monitor{
+		online:   true,
+		restored: restored,
+	}
+}
+
+// Online reports whether Twitch was reachable at the last probe.
+func (m *Monitor) Online() bool {
+	if m == nil {
+		return true
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.online
+}
+
+// OfflineSince returns when the network was lost, or the zero time if online.
+func (m *Monitor) OfflineSince() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.offlineSince
+}
+
+// Restored returns a channel that is closed once the network is online. It is
+// already closed while online.
+func (m *Monitor) Restored() <-chan struct{} {
+	if m == nil {
+		return closedChan
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.restored
+}
+
+// closedChan is what a nil Monitor returns from Restored.
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// Run probes Twitch until ctx is canceled and calls onChange whenever
+// connectivity is lost or restored.
+//
+// A probe is a HEAD request to the GQL endpoint through the shared transport,
+// so it rides the pooled HTTP/2 connection like a ping and also verifies that
+// DNS still works; any HTTP response counts as reachable.
+func (m *Monitor) Run(ctx context.Context, onChange ChangeHandler) {
+	client := httpx.NewClient(probeTimeout)
+	failures := 0
+
+	for {
+		interval := onlineInterval
+		if err := probe(ctx, client); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			slog.Debug("Connectivity probe failed", "failures", failures, "error", err)
+			if failures >= failureThreshold && m.setOnline(false) {
+				slog.Warn("Network connection lost, pausing mining until it returns", "error", err)
+				onChange(false, 0)
+			}
+			interval = retryInterval
+		} else {
+			failures = 0
+			since := m.OfflineSince()
+			if m.setOnline(true) {
+				downtime := time.Since(since)
+				slog.Info("Network connection restored, resynchronizing", "downtime", downtime.Round(time.Second))
+				onChange(true, downtime)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func probe(ctx context.Context, client *http.Client) error {
+	ctx = httpx.WithOperation(ctx, "connectivity:probe")
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, constants.GQLURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// setOnline records the new state and reports whether it changed.
+func (m *Monitor) setOnline(value bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.online == value {
+		return false
+	}
+	m.online = value
+	if value {
+		m.offlineSince = time.Time{}
+		close(m.restored)
+	} else {
+		m.offlineSince = time.Now()
+		m.restored = make(chan struct{})
+	}
+	return true
+}
@@ -2,8 +2,9 @@ package constants
 
 type GQLOperation struct {
 	OperationName string                 `json:"operationName"`
+	Query         string                 `json:"query,omitempty"`
 	Variables     map[string]interface{} `json:"variables,omitempty"`
-	Extensions    GQLExtensions          `json:"extensions"`
+	Extensions    *GQLExtensions         `json:"extensions,omitempty"`
 }
 
 type GQLExtensions struct {
@@ -18,7 +19,7 @@ type GQLPersistedQuery struct {
 func NewGQLOperation(name, hash string) GQLOperation {
 	return GQLOperation{
 		OperationName: name,
-		Extensions: GQLExtensions{
+		Extensions: &GQLExtensions{
 			PersistedQuery: GQLPersistedQuery{
 				Version:    1,
 				SHA256Hash: hash,
@@ -27,6 +28,16 @@ func NewGQLOperation(name, hash string) GQLOperation {
 	}
 }
 
+// NewGQLQuery returns an operation that sends its query text instead of a
+// persisted query hash, for fields no persisted query of the Twitch clients
+// returns. name must match the operation name in the query.
+func NewGQLQuery(name, query string) GQLOperation {
+	return GQLOperation{
+		OperationName: name,
+		Query:         query,
+	}
+}
+
 func (g GQLOperation) WithVariables(vars map[string]interface{}) GQLOperation {
 	g.Variables = vars
 	return g
@@ -98,6 +109,11 @@ var (
 		"9a62a09bce5b53e26e64a671e530bc599cb6aab1e5ba3cbd5d85966d3940716f",
 	)
 
+	ChannelShell = NewGQLOperation(
+		"ChannelShell",
+		"580ab410bcd0c1ad194224957ae2241e5d252b2c5173d8e0cce9d32d5bb14efe",
+	)
+
 	GetIDFromLogin = NewGQLOperation(
 		"GetIDFromLogin",
 		"94e82a7b1e3c21e186daa73ee2afc4b8f23bade1fbbff6fe8ac133f50a2f58ca",
@@ -117,4 +133,16 @@ var (
 		"ContributeCommunityPointsCommunityGoal",
 		"5774f0ea5d89587d73021a2e03c3c44777d903840c608754a1be519f51e37bb6",
 	)
+
+	// ChannelMetadata has no persisted query; see NewGQLQuery.
+	ChannelMetadata = NewGQLQuery(
+		"ChannelMetadata",
+		`query ChannelMetadata($login: String!) {
+			user(login: $login) {
+				createdAt
+				roles { isPartner isAffiliate }
+				followers { totalCount }
+			}
+		}`,
+	)
 )
@@ -0,0 +1,201 @@
+// Package crash recovers panics in long-running goroutines. Each panic is
+// logged with its stack trace, written to a crash bundle in the logs directory
+// together with the recent log output and a status snapshot, and reported to
+// the panic handler; looping components are then restarted.
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/logger"
+	"github.com/PatrickWalther/twitch-miner-go/internal/version"
+)
+
+const (
+	bundleDir    = "logs"
+	bundlePrefix = "crash-"
+
+	// maxBundles is how many crash bundles are kept; older ones are removed.
+	maxBundles = 20
+
+	minRestartDelay = 5 * time.Second
+	maxRestartDelay = 5 * time.Minute
+
+	// stableRun is how long a component must run before a panic resets its
+	// restart delay, so a component that panics on every run backs off.
+	stableRun = 10 * time.Minute
+)
+
+// SnapshotFunc returns the miner state to include in a crash bundle. The value
+// is encoded as JSON.
+type SnapshotFunc func() any
+
+// PanicHandler is called after a panic was recovered and its bundle written.
+// bundle is empty if the bundle could not be written.
+type PanicHandler func(component string, value any, bundle string)
+
+var (
+	snapshot SnapshotFunc
+	onPanic  PanicHandler
+	mu       sync.RWMutex
+)
+
+// SetSnapshotProvider sets the function supplying the status snapshot of crash bundles.
+func SetSnapshotProvider(fn SnapshotFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	snapshot = fn
+}
+
+// SetPanicHandler sets the function called for every recovered panic.
+func SetPanicHandler(handler PanicHandler) {
+	mu.Lock()
+	defer mu.Unlock()
+	onPanic = handler
+}
+
+// Recover reports a panic in the calling goroutine and lets it continue.
+// It must be deferred directly:
+//
+//	defer crash.Recover("pubsub handler")
+func Recover(component string) {
+	if r := recover(); r != nil {
+		report(component, r, debug.Stack())
+	}
+}
+
+// Loop runs fn and runs it again each time it panics, until it returns
+// normally or done is closed. Consecutive restarts back off exponentially.
+func Loop(done <-chan struct{}, component string, fn func()) {
+	delay := minRestartDelay
+	for {
+		started := time.Now()
+		if !run(component, fn) {
+			return
+		}
+
+		if time.Since(started) >= stableRun {
+			delay = minRestartDelay
+		}
+
+		slog.Warn("Restarting component after panic", "component", component, "in", delay)
+		select {
+		case <-done:
+			return
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, maxRestartDelay)
+	}
+}
+
+func run(component string, fn func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			report(component, r, debug.Stack())
+			panicked = true
+		}
+	}()
+
+	fn()
+	return false
+}
+
+func report(component string, value any, stack []byte) {
+	slog.Error("Recovered from panic", "component", component, "panic", value, "stack", string(stack))
+
+	mu.RLock()
+	snapshotFn := snapshot
+	handler := onPanic
+	mu.RUnlock()
+
+	bundle, err := writeBundle(component, value, stack, snapshotFn)
+	if err != nil {
+		slog.Error("Failed to write crash bundle", "error", err)
+	} else {
+		slog.Info("Crash bundle written", "path", bundle)
+	}
+
+	if handler != nil {
+		handler(component, value, bundle)
+	}
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+func writeBundle(component string, value any, stack []byte, snapshotFn SnapshotFunc) (string, error) {
+	if err := os.MkdirAll(bundleDir, 0755); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s%s-%s.txt", bundlePrefix, now.Format("20060102-150405"), unsafeChars.ReplaceAllString(component, "_"))
+	path := filepath.Join(bundleDir, name)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Time:      %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version:   %s (%s, built %s)\n", version.Version, version.Commit, version.BuildDate)
+	fmt.Fprintf(&b, "Component: %s\n", component)
+	fmt.Fprintf(&b, "Panic:     %v\n", value)
+
+	b.WriteString("\n== Stack trace ==\n")
+	b.Write(stack)
+
+	b.WriteString("\n== Status ==\n")
+	b.WriteString(encodeSnapshot(snapshotFn))
+
+	b.WriteString("\n\n== Recent logs ==\n")
+	for _, line := range logger.Recent() {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", err
+	}
+
+	pruneBundles()
+	return path, nil
+}
+
+// encodeSnapshot never lets a failing snapshot prevent the bundle from being written.
+func encodeSnapshot(snapshotFn SnapshotFunc) (encoded string) {
+	if snapshotFn == nil {
+		return "unavailable"
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			encoded = fmt.Sprintf("unavailable: snapshot panicked: %v", r)
+		}
+	}()
+
+	data, err := json.MarshalIndent(snapshotFn(), "", "  ")
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v", err)
+	}
+	return string(data)
+}
+
+func pruneBundles() {
+	bundles, err := filepath.Glob(filepath.Join(bundleDir, bundlePrefix+"*.txt"))
+	if err != nil || len(bundles) <= maxBundles {
+		return
+	}
+
+	// Bundle names start with their timestamp, so they sort oldest first.
+	sort.Strings(bundles)
+	for _, path := range bundles[:len(bundles)-maxBundles] {
+		_ = os.Remove(path)
+	}
+}
@@ -15,6 +15,12 @@ import (
 type DB struct {
 	*sql.DB
 	mu sync.RWMutex
+
+	// path is the database file. When the file failed its integrity check,
+	// DB is an in-memory database instead and fallbackErr holds the reason.
+	path        string
+	fallbackErr error
+	backupPath  string
 }
 
 type Module interface {
@@ -42,15 +48,27 @@ func Open(basePath string) (*DB, error) {
 		}
 
 		dbPath := filepath.Join(basePath, "miner.db")
-		sqlDB, err := sql.Open("sqlite", dbPath)
+		sqlDB, err := openFile(dbPath)
 		if err != nil {
 			initErr = fmt.Errorf("failed to open database: %w", err)
 			return
 		}
 
-		sqlDB.SetMaxOpenConns(1)
+		if err := checkIntegrity(sqlDB); err != nil {
+			_ = sqlDB.Close()
+			slog.Error("Database failed its integrity check, continuing with an in-memory database",
+				"path", dbPath, "error", err)
+
+			memDB, memErr := openMemory()
+			if memErr != nil {
+				initErr = fmt.Errorf("failed to open in-memory database: %w", memErr)
+				return
+			}
+			instance = &DB{DB: memDB, path: dbPath, fallbackErr: err}
+			return
+		}
 
-		instance = &DB{DB: sqlDB}
+		instance = &DB{DB: sqlDB, path: dbPath}
 	})
 
 	if initErr != nil {
@@ -128,6 +146,29 @@ func (db *DB) setModuleVersion(moduleName string, version int) error {
 	return err
 }
 
+// SchemaVersions returns the applied migration version of every registered module.
+func (db *DB) SchemaVersions() (map[string]int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.Query("SELECT module, version FROM schema_versions ORDER BY module")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[string]int)
+	for rows.Next() {
+		var module string
+		var version int
+		if err := rows.Scan(&module, &version); err != nil {
+			return nil, err
+		}
+		versions[module] = version
+	}
+	return versions, rows.Err()
+}
+
 func (db *DB) Close() error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
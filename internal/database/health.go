@@ -0,0 +1,139 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// busyTimeout is how long a statement waits for a lock held by another
+// process, such as a backup tool or the sqlite3 shell, before failing.
+const busyTimeout = 5 * time.Second
+
+// Status describes the health of the database file.
+type Status struct {
+	Path string `json:"path"`
+	// InMemory is set when the file could not be used and data is only
+	// kept until the miner stops.
+	InMemory bool   `json:"inMemory"`
+	Error    string `json:"error,omitempty"`
+	// Backup is where the damaged file was moved by Recover. The miner
+	// uses the recreated file after its next restart.
+	Backup string `json:"backup,omitempty"`
+}
+
+func openFile(path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)", path, busyTimeout.Milliseconds())
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return sqlDB, nil
+}
+
+// openMemory opens an empty in-memory database. It is limited to a single
+// connection that is never closed, since every new connection would see a
+// separate empty database.
+func openMemory() (*sql.DB, error) {
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+	return sqlDB, sqlDB.Ping()
+}
+
+// checkIntegrity runs SQLite's quick_check, which finds most corruption
+// without the cost of a full integrity_check on large databases.
+func checkIntegrity(sqlDB *sql.DB) error {
+	rows, err := sqlDB.Query("PRAGMA quick_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return err
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// InMemory reports whether the database file was unusable and the miner is
+// running on an in-memory database.
+func (db *DB) InMemory() bool {
+	return db.fallbackErr != nil
+}
+
+// Status returns the health of the database file.
+func (db *DB) Status() Status {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	status := Status{
+		Path:     db.path,
+		InMemory: db.fallbackErr != nil,
+		Backup:   db.backupPath,
+	}
+	if db.fallbackErr != nil {
+		status.Error = db.fallbackErr.Error()
+	}
+	return status
+}
+
+// Recover moves the damaged database file aside and writes the in-memory
+// database, including everything recorded since startup, to a new file in
+// its place. The miner keeps using the in-memory database until it restarts.
+func (db *DB) Recover() (Status, error) {
+	db.mu.Lock()
+	if db.fallbackErr == nil {
+		db.mu.Unlock()
+		return db.Status(), errors.New("database is not in fallback mode")
+	}
+	if db.backupPath != "" {
+		db.mu.Unlock()
+		return db.Status(), errors.New("database was already recovered, restart the miner to use it")
+	}
+
+	backup := fmt.Sprintf("%s.corrupt-%s", db.path, time.Now().Format("20060102-150405"))
+	err := db.recover(backup)
+	if err == nil {
+		db.backupPath = backup
+	}
+	db.mu.Unlock()
+
+	return db.Status(), err
+}
+
+func (db *DB) recover(backup string) error {
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Rename(db.path+suffix, backup+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to back up %s: %w", db.path+suffix, err)
+		}
+	}
+
+	target := strings.ReplaceAll(db.path, "'", "''")
+	if _, err := db.Exec("VACUUM INTO '" + target + "'"); err != nil {
+		return fmt.Errorf("failed to write new database: %w", err)
+	}
+	return nil
+}
@@ -3,12 +3,16 @@ package drops
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/api"
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
-	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/connectivity"
+	"github.com/PatrickWalther/twitch-miner-go/internal/crash"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
+	"github.com/PatrickWalther/twitch-miner-go/internal/jobs"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 )
 
@@ -16,8 +20,14 @@ type DropsTracker struct {
 	client    *api.TwitchClient
 	streamers []*models.Streamer
 	settings  config.RateLimitSettings
+	jobs      *jobs.Queue
+	network   *connectivity.Monitor
 
 	campaigns []*models.Campaign
+	progress  []models.CampaignProgress
+	syncedAt  time.Time
+	inventory *models.Inventory
+	resync    chan struct{}
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -29,20 +39,29 @@ func NewDropsTracker(
 	client *api.TwitchClient,
 	streamers []*models.Streamer,
 	settings config.RateLimitSettings,
+	queue *jobs.Queue,
 ) *DropsTracker {
 	return &DropsTracker{
 		client:    client,
 		streamers: streamers,
 		settings:  settings,
+		jobs:      queue,
+		resync:    make(chan struct{}, 1),
 	}
 }
 
+// SetConnectivity sets the monitor that skips campaign syncs while the
+// network is down. Must be called before Start.
+func (d *DropsTracker) SetConnectivity(monitor *connectivity.Monitor) {
+	d.network = monitor
+}
+
 func (d *DropsTracker) Start(ctx context.Context) {
 	d.mu.Lock()
 	d.ctx, d.cancel = context.WithCancel(ctx)
 	d.mu.Unlock()
 
-	go d.loop()
+	go crash.Loop(d.ctx.Done(), "drops", d.loop)
 }
 
 func (d *DropsTracker) Stop() {
@@ -53,12 +72,66 @@ func (d *DropsTracker) Stop() {
 	d.mu.Unlock()
 }
 
-func (d *DropsTracker) loop() {
-	syncInterval := time.Duration(d.settings.CampaignSyncInterval) * time.Minute
+// Inventory returns the inventory fetched by the last campaign sync, or nil
+// if none has completed yet.
+func (d *DropsTracker) Inventory() *models.Inventory {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.inventory
+}
+
+// NextDrop returns the in-progress drop closest to completion.
+func (d *DropsTracker) NextDrop() (*models.Drop, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var next *models.Drop
+	for _, campaign := range d.campaigns {
+		for _, drop := range campaign.Drops {
+			if !drop.IsPrintable() {
+				continue
+			}
+			if next == nil || drop.RemainingMinutes() < next.RemainingMinutes() {
+				next = drop
+			}
+		}
+	}
+	return next, next != nil
+}
 
+// Campaigns returns a copy of the active campaigns and drop progress from the
+// last campaign sync along with the time of that sync. The streamers of each
+// campaign are the ones currently online and progressing it.
+func (d *DropsTracker) Campaigns() ([]models.CampaignProgress, time.Time) {
+	d.mu.RLock()
+	progress := make([]models.CampaignProgress, len(d.progress))
+	for i, campaign := range d.progress {
+		campaign.Drops = append([]models.DropProgress(nil), campaign.Drops...)
+		progress[i] = campaign
+	}
+	syncedAt := d.syncedAt
+	d.mu.RUnlock()
+
+	for i := range progress {
+		for _, streamer := range d.streamers {
+			if !streamer.GetIsOnline() {
+				continue
+			}
+			for _, campaign := range streamer.GetStream().GetCampaigns() {
+				if campaign.ID == progress[i].ID {
+					progress[i].Streamers = append(progress[i].Streamers, streamer.GetUsername())
+					break
+				}
+			}
+		}
+	}
+	return progress, syncedAt
+}
+
+func (d *DropsTracker) loop() {
 	d.syncCampaigns()
 
-	ticker := time.NewTicker(syncInterval)
+	ticker := time.NewTicker(d.syncInterval())
 	defer ticker.Stop()
 
 	for {
@@ -67,11 +140,32 @@ func (d *DropsTracker) loop() {
 			return
 		case <-ticker.C:
 			d.syncCampaigns()
+		case <-d.resync:
+			d.syncCampaigns()
 		}
+		ticker.Reset(d.syncInterval())
+	}
+}
+
+// syncInterval is the time between campaign syncs, stretched while Twitch
+// throttles requests.
+func (d *DropsTracker) syncInterval() time.Duration {
+	return time.Duration(d.settings.CampaignSyncInterval) * time.Minute * httpx.Slowdown()
+}
+
+// Resync syncs campaigns immediately and restarts the sync interval.
+func (d *DropsTracker) Resync() {
+	select {
+	case d.resync <- struct{}{}:
+	default:
 	}
 }
 
 func (d *DropsTracker) syncCampaigns() {
+	if !d.network.Online() {
+		return
+	}
+
 	d.claimAllDropsFromInventory()
 
 	campaigns, err := d.getActiveCampaigns()
@@ -82,15 +176,24 @@ func (d *DropsTracker) syncCampaigns() {
 
 	campaigns = d.syncWithInventory(campaigns)
 
+	// The progress keeps claimed drops, which mining no longer needs.
+	progress := make([]models.CampaignProgress, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		progress = append(progress, campaign.Progress())
+		campaign.ClearClaimedDrops()
+	}
+
 	d.mu.Lock()
 	d.campaigns = campaigns
+	d.progress = progress
+	d.syncedAt = time.Now()
 	d.mu.Unlock()
 
 	d.updateStreamerCampaigns()
 }
 
 func (d *DropsTracker) getActiveCampaigns() ([]*models.Campaign, error) {
-	dashboardCampaigns, err := d.getDropsDashboard("ACTIVE")
+	dashboardCampaigns, err := d.client.GetDropCampaigns(string(models.CampaignActive))
 	if err != nil {
 		return nil, err
 	}
@@ -109,109 +212,32 @@ func (d *DropsTracker) getActiveCampaigns() ([]*models.Campaign, error) {
 	return campaigns, nil
 }
 
-func (d *DropsTracker) getDropsDashboard(status string) ([]map[string]interface{}, error) {
-	resp, err := d.client.PostGQL(constants.ViewerDropsDashboard)
-	if err != nil {
-		return nil, err
-	}
-
-	data, ok := resp["data"].(map[string]interface{})
-	if !ok {
-		return nil, nil
-	}
-
-	currentUser, ok := data["currentUser"].(map[string]interface{})
-	if !ok || currentUser == nil {
-		return nil, nil
-	}
-
-	campaignsData, ok := currentUser["dropCampaigns"].([]interface{})
-	if !ok || campaignsData == nil {
-		return nil, nil
-	}
-
-	var result []map[string]interface{}
-	for _, c := range campaignsData {
-		campaign, ok := c.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		if status != "" {
-			if s, ok := campaign["status"].(string); ok && s != status {
-				continue
-			}
-		}
-
-		result = append(result, campaign)
-	}
-
-	return result, nil
-}
-
-func (d *DropsTracker) getInventory() (map[string]interface{}, error) {
-	resp, err := d.client.PostGQL(constants.Inventory)
-	if err != nil {
-		return nil, err
-	}
-
-	data, ok := resp["data"].(map[string]interface{})
-	if !ok {
-		return nil, nil
-	}
-
-	currentUser, ok := data["currentUser"].(map[string]interface{})
-	if !ok || currentUser == nil {
-		return nil, nil
-	}
-
-	inventory, ok := currentUser["inventory"].(map[string]interface{})
-	if !ok {
-		return nil, nil
-	}
-
-	return inventory, nil
-}
-
 func (d *DropsTracker) syncWithInventory(campaigns []*models.Campaign) []*models.Campaign {
-	inventory, err := d.getInventory()
+	inventory, err := d.client.GetInventory()
 	if err != nil || inventory == nil {
 		return campaigns
 	}
 
-	inProgress, ok := inventory["dropCampaignsInProgress"].([]interface{})
-	if !ok || inProgress == nil {
-		return campaigns
-	}
+	d.mu.Lock()
+	d.inventory = models.NewInventoryFromGQL(*inventory)
+	d.mu.Unlock()
 
 	for _, campaign := range campaigns {
 		campaign.ClearClaimedDrops()
 
-		for _, prog := range inProgress {
-			progData, ok := prog.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			progID, ok := progData["id"].(string)
-			if !ok || progID != campaign.ID {
+		for _, prog := range inventory.DropCampaignsInProgress {
+			if prog.ID != campaign.ID {
 				continue
 			}
 
 			campaign.InInventory = true
 
-			if drops, ok := progData["timeBasedDrops"].([]interface{}); ok {
-				campaign.SyncDrops(drops, func(drop *models.Drop) bool {
-					claimed, err := d.client.ClaimDrop(drop)
-					if err != nil {
-						slog.Error("Failed to claim drop", "drop", drop.Name, "error", err)
-						return false
-					}
-					return claimed
-				})
-			}
-
-			campaign.ClearClaimedDrops()
+			// Claims run through the job queue, which marks the drop
+			// claimed once it succeeds.
+			campaign.SyncDrops(prog.TimeBasedDrops, func(drop *models.Drop) bool {
+				d.queueClaim(drop)
+				return false
+			})
 			break
 		}
 	}
@@ -220,48 +246,77 @@ func (d *DropsTracker) syncWithInventory(campaigns []*models.Campaign) []*models
 }
 
 func (d *DropsTracker) claimAllDropsFromInventory() {
-	inventory, err := d.getInventory()
+	inventory, err := d.client.GetInventory()
 	if err != nil || inventory == nil {
 		return
 	}
 
-	inProgress, ok := inventory["dropCampaignsInProgress"].([]interface{})
-	if !ok || inProgress == nil {
-		return
-	}
+	for _, campaign := range inventory.DropCampaignsInProgress {
+		for _, dropData := range campaign.TimeBasedDrops {
+			drop := models.NewDropFromGQL(dropData)
+			if dropData.Self != nil {
+				drop.Update(*dropData.Self)
+			}
 
-	for _, campaign := range inProgress {
-		campaignData, ok := campaign.(map[string]interface{})
-		if !ok {
-			continue
+			if drop.IsClaimable {
+				d.queueClaim(drop)
+			}
 		}
+	}
+}
 
-		drops, ok := campaignData["timeBasedDrops"].([]interface{})
-		if !ok || drops == nil {
-			continue
-		}
+func (d *DropsTracker) queueClaim(drop *models.Drop) {
+	payload := jobs.ClaimDropPayload{DropInstanceID: drop.DropInstanceID, Name: drop.Name}
+	if err := d.jobs.Enqueue(jobs.KindClaimDrop, drop.DropInstanceID, payload); err != nil {
+		slog.Error("Failed to queue drop claim", "drop", drop.Name, "error", err)
+	}
+}
 
-		for _, dropData := range drops {
-			dropMap, ok := dropData.(map[string]interface{})
-			if !ok {
+// MarkClaimed records a drop claimed by the job queue, so it stops counting
+// as unclaimed before the next campaign sync. Campaigns and drops are shared
+// with the streamers, so the ones holding the drop are copied instead of
+// changed.
+func (d *DropsTracker) MarkClaimed(dropInstanceID string) {
+	d.mu.Lock()
+	var dropID string
+	campaigns := make([]*models.Campaign, 0, len(d.campaigns))
+	for _, campaign := range d.campaigns {
+		for i, drop := range campaign.Drops {
+			if drop.DropInstanceID != dropInstanceID {
 				continue
 			}
+			dropID = drop.ID
+			claimed := *drop
+			claimed.IsClaimed = true
+			claimed.IsClaimable = false
+			updated := *campaign
+			updated.Drops = slices.Clone(campaign.Drops)
+			updated.Drops[i] = &claimed
+			campaign = &updated
+			break
+		}
+		campaigns = append(campaigns, campaign)
+	}
+	if dropID == "" {
+		d.mu.Unlock()
+		return
+	}
+	d.campaigns = campaigns
 
-			drop := models.NewDropFromGQL(dropMap)
-			if selfData, ok := dropMap["self"].(map[string]interface{}); ok {
-				drop.Update(selfData)
-			}
-
-			if drop.IsClaimable {
-				if claimed, err := d.client.ClaimDrop(drop); err != nil {
-					slog.Error("Failed to claim drop", "drop", drop.Name, "error", err)
-				} else if claimed {
-					slog.Info("Claimed drop", "drop", drop.Name)
-				}
-				time.Sleep(5 * time.Second)
+	progress := make([]models.CampaignProgress, len(d.progress))
+	for i, campaign := range d.progress {
+		campaign.Drops = append([]models.DropProgress(nil), campaign.Drops...)
+		for j := range campaign.Drops {
+			if campaign.Drops[j].ID == dropID {
+				campaign.Drops[j].IsClaimed = true
 			}
 		}
+		progress[i] = campaign
 	}
+	d.progress = progress
+	d.mu.Unlock()
+
+	d.updateStreamerCampaigns()
 }
 
 func (d *DropsTracker) updateStreamerCampaigns() {
@@ -280,16 +335,16 @@ func (d *DropsTracker) updateStreamerCampaigns() {
 				continue
 			}
 
-			if campaign.Game == nil || streamer.Stream.GameID() == "" {
+			if campaign.Game == nil || streamer.GetStream().GameID() == "" {
 				continue
 			}
 
-			if campaign.Game.ID != streamer.Stream.GameID() {
+			if campaign.Game.ID != streamer.GetStream().GameID() {
 				continue
 			}
 
 			hasID := false
-			for _, id := range streamer.Stream.CampaignIDs {
+			for _, id := range streamer.GetStream().GetCampaignIDs() {
 				if id == campaign.ID {
 					hasID = true
 					break
@@ -301,6 +356,6 @@ func (d *DropsTracker) updateStreamerCampaigns() {
 			}
 		}
 
-		streamer.Stream.Campaigns = streamerCampaigns
+		streamer.GetStream().SetCampaigns(streamerCampaigns)
 	}
 }
@@ -0,0 +1,83 @@
+// Package features holds the flags that switch experimental behaviors on and
+// off at runtime. Subsystems get the shared Flags injected and check them when
+// the behavior runs, so a toggle in the settings applies without a restart.
+package features
+
+import "sync"
+
+// Flag names an experimental behavior.
+type Flag string
+
+const (
+	// HumanizedDelays waits a random few seconds before claiming bonus chests
+	// and moments instead of claiming them as soon as they appear.
+	HumanizedDelays Flag = "humanizedDelays"
+)
+
+// Definition describes a flag for the settings page.
+type Definition struct {
+	Flag        Flag
+	Label       string
+	Description string
+}
+
+var definitions = []Definition{
+	{
+		Flag:        HumanizedDelays,
+		Label:       "Humanized Delays",
+		Description: "Wait a random 2-15 seconds before claiming bonus chests and moments",
+	},
+}
+
+// Definitions returns every known flag in display order.
+func Definitions() []Definition {
+	return append([]Definition(nil), definitions...)
+}
+
+// Known reports whether name is a defined flag.
+func Known(name string) bool {
+	for _, d := range definitions {
+		if string(d.Flag) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Flags is the current value of every flag. A nil *Flags has every flag off.
+type Flags struct {
+	mu      sync.RWMutex
+	enabled map[Flag]bool
+}
+
+// New returns flags set from the configured values.
+func New(values map[string]bool) *Flags {
+	f := &Flags{}
+	f.Set(values)
+	return f
+}
+
+// Set replaces the flag values. Unknown names are ignored and flags missing
+// from values are off.
+func (f *Flags) Set(values map[string]bool) {
+	enabled := make(map[Flag]bool)
+	for name, on := range values {
+		if on && Known(name) {
+			enabled[Flag(name)] = true
+		}
+	}
+
+	f.mu.Lock()
+	f.enabled = enabled
+	f.mu.Unlock()
+}
+
+// Enabled reports whether the flag is on.
+func (f *Flags) Enabled(flag Flag) bool {
+	if f == nil {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.enabled[flag]
+}
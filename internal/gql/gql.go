@@ -0,0 +1,319 @@
+// Package gql holds the typed responses of the Twitch GQL operations the
+// miner sends. Each operation's data is decoded into its own struct; fields
+// Twitch may leave out or set to null are pointers or zero values, so a
+// missing object never fails decoding.
+package gql
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Response is the envelope of a GQL response. Twitch answers rejected
+// requests, e.g. a failed integrity check or a malformed query, with Error,
+// Status and Message at the top level instead of data and errors.
+type Response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []Error         `json:"errors"`
+
+	Error   string `json:"error"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// Error is one entry of a response's errors.
+type Error struct {
+	Message string `json:"message"`
+}
+
+// HasData reports whether the response carries a non-null data object.
+func (r *Response) HasData() bool {
+	return len(r.Data) > 0 && string(r.Data) != "null"
+}
+
+// Integrity reports whether Twitch rejected the request for failing its
+// integrity check.
+func (r *Response) Integrity() bool {
+	if strings.Contains(strings.ToLower(r.Message), "integrity") {
+		return true
+	}
+	for _, e := range r.Errors {
+		if strings.Contains(strings.ToLower(e.Message), "integrity") {
+			return true
+		}
+	}
+	return false
+}
+
+// Time is an RFC 3339 timestamp that decodes to the zero time when it is
+// empty or malformed instead of failing the whole response.
+type Time struct {
+	time.Time
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Game is a game or category.
+type Game struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// MutationError is the error object of a mutation's payload.
+type MutationError struct {
+	Code string `json:"code"`
+}
+
+// GetIDFromLogin is the data of GetIDFromLogin.
+type GetIDFromLogin struct {
+	User *struct {
+		ID string `json:"id"`
+	} `json:"user"`
+}
+
+// ChannelShell is the data of ChannelShell.
+type ChannelShell struct {
+	UserOrError *struct {
+		ProfileImageURL string `json:"profileImageURL"`
+	} `json:"userOrError"`
+}
+
+// ChannelMetadata is the data of ChannelMetadata.
+type ChannelMetadata struct {
+	User *struct {
+		CreatedAt Time `json:"createdAt"`
+		Roles     struct {
+			IsPartner   bool `json:"isPartner"`
+			IsAffiliate bool `json:"isAffiliate"`
+		} `json:"roles"`
+		Followers struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"followers"`
+	} `json:"user"`
+}
+
+// ChannelFollows is the data of ChannelFollows.
+type ChannelFollows struct {
+	User *struct {
+		Follows *struct {
+			Edges []struct {
+				Cursor     string `json:"cursor"`
+				FollowedAt Time   `json:"followedAt"`
+				Node       struct {
+					Login string `json:"login"`
+				} `json:"node"`
+			} `json:"edges"`
+			PageInfo struct {
+				HasNextPage bool `json:"hasNextPage"`
+			} `json:"pageInfo"`
+		} `json:"follows"`
+	} `json:"user"`
+}
+
+// StreamInfo is the data of VideoPlayerStreamInfoOverlayChannel.
+type StreamInfo struct {
+	User *StreamUser `json:"user"`
+}
+
+// StreamUser is a channel with its current stream, nil while offline.
+type StreamUser struct {
+	Stream *struct {
+		ID           string `json:"id"`
+		ViewersCount int    `json:"viewersCount"`
+		Tags         []struct {
+			ID            string `json:"id"`
+			LocalizedName string `json:"localizedName"`
+		} `json:"tags"`
+	} `json:"stream"`
+	BroadcastSettings *struct {
+		Title string `json:"title"`
+		Game  *Game  `json:"game"`
+	} `json:"broadcastSettings"`
+}
+
+// ChannelPointsContext is the data of ChannelPointsContext.
+type ChannelPointsContext struct {
+	Community *struct {
+		Channel *struct {
+			CommunityPointsSettings *struct {
+				IsEnabled *bool           `json:"isEnabled"`
+				Goals     []CommunityGoal `json:"goals"`
+			} `json:"communityPointsSettings"`
+			Self *struct {
+				CommunityPoints *struct {
+					Balance           *int `json:"balance"`
+					ActiveMultipliers []struct {
+						Factor float64 `json:"factor"`
+					} `json:"activeMultipliers"`
+					AvailableClaim *struct {
+						ID string `json:"id"`
+					} `json:"availableClaim"`
+				} `json:"communityPoints"`
+			} `json:"self"`
+		} `json:"channel"`
+	} `json:"community"`
+}
+
+// CommunityGoal is a channel's community goal.
+type CommunityGoal struct {
+	ID                               string `json:"id"`
+	Title                            string `json:"title"`
+	Description                      string `json:"description"`
+	Status                           string `json:"status"`
+	PointsContributed                int    `json:"pointsContributed"`
+	GoalAmount                       int    `json:"goalAmount"`
+	PerStreamUserMaximumContribution int    `json:"perStreamUserMaximumContribution"`
+	IsInStock                        bool   `json:"isInStock"`
+}
+
+// MakePrediction is the data of MakePrediction.
+type MakePrediction struct {
+	MakePrediction *struct {
+		Error *MutationError `json:"error"`
+	} `json:"makePrediction"`
+}
+
+// ContributeCommunityGoal is the data of
+// ContributeCommunityPointsCommunityGoal.
+type ContributeCommunityGoal struct {
+	Contribute *struct {
+		Error *MutationError `json:"error"`
+	} `json:"contributeCommunityPointsCommunityGoal"`
+}
+
+// AvailableDrops is the data of DropsHighlightService_AvailableDrops.
+type AvailableDrops struct {
+	Channel *struct {
+		ViewerDropCampaigns []struct {
+			ID string `json:"id"`
+		} `json:"viewerDropCampaigns"`
+	} `json:"channel"`
+}
+
+// PlaybackAccessToken is the data of PlaybackAccessToken. Depending on the
+// client version the token is under either field.
+type PlaybackAccessToken struct {
+	StreamPlaybackAccessToken *AccessToken `json:"streamPlaybackAccessToken"`
+	StreamAccessToken         *AccessToken `json:"streamAccessToken"`
+}
+
+// AccessToken is a signed playback token.
+type AccessToken struct {
+	Signature string `json:"signature"`
+	Value     string `json:"value"`
+}
+
+// ClaimDropRewards is the data of DropsPage_ClaimDropRewards.
+type ClaimDropRewards struct {
+	ClaimDropRewards *struct {
+		Status string `json:"status"`
+	} `json:"claimDropRewards"`
+}
+
+// ViewerDropsDashboard is the data of ViewerDropsDashboard.
+type ViewerDropsDashboard struct {
+	CurrentUser *struct {
+		DropCampaigns []DropCampaign `json:"dropCampaigns"`
+	} `json:"currentUser"`
+}
+
+// DropCampaign is a drop campaign of the drops dashboard.
+type DropCampaign struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Game    *Game  `json:"game"`
+	StartAt Time   `json:"startAt"`
+	EndAt   Time   `json:"endAt"`
+	Allow   *struct {
+		Channels []struct {
+			ID string `json:"id"`
+		} `json:"channels"`
+	} `json:"allow"`
+	TimeBasedDrops []TimeBasedDrop `json:"timeBasedDrops"`
+}
+
+// TimeBasedDrop is a drop earned by watching. Self is the account's progress
+// and is only set in the inventory.
+type TimeBasedDrop struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	BenefitEdges []struct {
+		Benefit struct {
+			Name string `json:"name"`
+		} `json:"benefit"`
+	} `json:"benefitEdges"`
+	RequiredMinutesWatched int       `json:"requiredMinutesWatched"`
+	StartAt                Time      `json:"startAt"`
+	EndAt                  Time      `json:"endAt"`
+	Self                   *DropSelf `json:"self"`
+}
+
+// DropSelf is the account's progress on a drop.
+type DropSelf struct {
+	CurrentMinutesWatched int    `json:"currentMinutesWatched"`
+	HasPreconditionsMet   *bool  `json:"hasPreconditionsMet"`
+	DropInstanceID        string `json:"dropInstanceID"`
+	IsClaimed             bool   `json:"isClaimed"`
+}
+
+// InventoryData is the data of Inventory.
+type InventoryData struct {
+	CurrentUser *struct {
+		Inventory *Inventory `json:"inventory"`
+	} `json:"currentUser"`
+}
+
+// Inventory is the account's drops inventory.
+type Inventory struct {
+	DropCampaignsInProgress  []InventoryCampaign `json:"dropCampaignsInProgress"`
+	GameEventDrops           []GameEventDrop     `json:"gameEventDrops"`
+	CompletedRewardCampaigns []RewardCampaign    `json:"completedRewardCampaigns"`
+}
+
+// InventoryCampaign is a drop campaign the account is progressing.
+type InventoryCampaign struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Game           *Game           `json:"game"`
+	EndAt          Time            `json:"endAt"`
+	TimeBasedDrops []TimeBasedDrop `json:"timeBasedDrops"`
+}
+
+// GameEventDrop is an earned game drop.
+type GameEventDrop struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	ImageURL      string `json:"imageURL"`
+	Game          *Game  `json:"game"`
+	TotalCount    int    `json:"totalCount"`
+	LastAwardedAt Time   `json:"lastAwardedAt"`
+}
+
+// RewardCampaign is a completed reward campaign.
+type RewardCampaign struct {
+	Name         string `json:"name"`
+	Brand        string `json:"brand"`
+	Instructions string `json:"instructions"`
+	ExternalURL  string `json:"externalURL"`
+	Rewards      []struct {
+		ID                     string `json:"id"`
+		Name                   string `json:"name"`
+		RedemptionInstructions string `json:"redemptionInstructions"`
+		RedemptionURL          string `json:"redemptionURL"`
+		EarnableUntil          Time   `json:"earnableUntil"`
+	} `json:"rewards"`
+}
@@ -0,0 +1,136 @@
+package httpx
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// budgetWindow is how far back the error budget counts requests, in
+	// budgetBucket steps.
+	budgetWindow = 10 * time.Minute
+	budgetBucket = time.Minute
+
+	// throttleMinErrors and throttleErrorRate are how many rate limit and
+	// integrity errors, and which share of all requests, mark Twitch as
+	// throttling the miner.
+	throttleMinErrors = 3
+	throttleErrorRate = 0.05
+
+	// throttleRecovery is how long no rate limit or integrity errors may
+	// occur before throttling counts as over.
+	throttleRecovery = 5 * time.Minute
+
+	// throttleSlowdown multiplies polling intervals while throttled.
+	throttleSlowdown = 2
+)
+
+// BudgetStatus is the error budget of Twitch requests over the last
+// budgetWindow.
+type BudgetStatus struct {
+	Requests    int     `json:"requests"`
+	RateLimited int     `json:"rateLimited"`
+	Integrity   int     `json:"integrity"`
+	ErrorRate   float64 `json:"errorRate"`
+	Throttled   bool    `json:"throttled"`
+	// Since is when throttling was detected (Unix ms), while Throttled.
+	Since int64 `json:"since,omitempty"`
+}
+
+type budgetBucketCounts struct {
+	start       time.Time
+	requests    int
+	rateLimited int
+	integrity   int
+}
+
+var (
+	buckets        [int(budgetWindow / budgetBucket)]budgetBucketCounts
+	lastThrottle   time.Time
+	throttledSince time.Time
+	budgetMu       sync.Mutex
+)
+
+// bucket returns the bucket for now, resetting it if it holds an older minute.
+func bucket(now time.Time) *budgetBucketCounts {
+	start := now.Truncate(budgetBucket)
+	b := &buckets[start.Unix()/int64(budgetBucket/time.Second)%int64(len(buckets))]
+	if !b.start.Equal(start) {
+		*b = budgetBucketCounts{start: start}
+	}
+	return b
+}
+
+func recordBudget(class string) {
+	budgetMu.Lock()
+	defer budgetMu.Unlock()
+
+	now := time.Now()
+	b := bucket(now)
+	b.requests++
+	if class == ErrorClassRateLimited {
+		b.rateLimited++
+		lastThrottle = now
+	}
+}
+
+// RecordIntegrityError counts a request Twitch answered with a failed
+// integrity check. Those arrive as GQL errors in successful responses, so the
+// API client reports them.
+func RecordIntegrityError() {
+	budgetMu.Lock()
+	defer budgetMu.Unlock()
+
+	now := time.Now()
+	bucket(now).integrity++
+	lastThrottle = now
+}
+
+// Budget returns the error budget and whether Twitch is throttling the miner.
+// Throttling starts once rate limit and integrity errors reach
+// throttleMinErrors and throttleErrorRate within the window, and ends after
+// throttleRecovery without either, even if older errors are still in the
+// window.
+func Budget() BudgetStatus {
+	budgetMu.Lock()
+	defer budgetMu.Unlock()
+
+	now := time.Now()
+	var status BudgetStatus
+	for _, b := range buckets {
+		if now.Sub(b.start) >= budgetWindow {
+			continue
+		}
+		status.Requests += b.requests
+		status.RateLimited += b.rateLimited
+		status.Integrity += b.integrity
+	}
+
+	errors := status.RateLimited + status.Integrity
+	if status.Requests > 0 {
+		status.ErrorRate = float64(errors) / float64(status.Requests)
+	}
+
+	recent := now.Sub(lastThrottle) < throttleRecovery
+	switch {
+	case throttledSince.IsZero() && recent && errors >= throttleMinErrors && status.ErrorRate >= throttleErrorRate:
+		throttledSince = now
+	case !throttledSince.IsZero() && !recent:
+		throttledSince = time.Time{}
+	}
+
+	if !throttledSince.IsZero() {
+		status.Throttled = true
+		status.Since = throttledSince.UnixMilli()
+	}
+	return status
+}
+
+// Slowdown returns the factor to stretch polling intervals by: 2 while
+// throttled, otherwise 1.
+func Slowdown() time.Duration {
+	if Budget().Throttled {
+		return throttleSlowdown
+	}
+	return 1
+}
@@ -0,0 +1,115 @@
+// Package httpx provides the shared HTTP transport used for all outbound requests
+// to Twitch, so connections are pooled and reused across the API client, the
+// minute watcher and authentication.
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 20
+	maxConnsPerHost     = 50
+	idleConnTimeout     = 90 * time.Second
+)
+
+var (
+	transport     *http.Transport
+	transportOnce sync.Once
+
+	tlsConfig *tls.Config
+	tlsMu     sync.RWMutex
+)
+
+// Transport returns the shared transport. It keeps connections alive, negotiates
+// HTTP/2 where supported and, like http.DefaultTransport, honours the
+// HTTP_PROXY/HTTPS_PROXY environment variables.
+func Transport() *http.Transport {
+	transportOnce.Do(func() {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		transport = &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          maxIdleConns,
+			MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+			MaxConnsPerHost:       maxConnsPerHost,
+			IdleConnTimeout:       idleConnTimeout,
+			TLSClientConfig:       TLSConfig(),
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		}
+	})
+	return transport
+}
+
+// NewClient returns an HTTP client with the given timeout backed by the shared
+// transport. Requests made through it are recorded in Stats.
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &tracingTransport{base: Transport()},
+		Timeout:   timeout,
+	}
+}
+
+// SetTLS configures certificate verification for all outbound TLS connections.
+// caFile adds the PEM certificates it contains to the system roots, for
+// proxies that intercept TLS with their own CA. insecure disables verification
+// entirely. It must be called before the first request is made.
+func SetTLS(caFile string, insecure bool) error {
+	var cfg *tls.Config
+	if caFile != "" || insecure {
+		cfg = &tls.Config{InsecureSkipVerify: insecure}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificates found in CA file %q", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if insecure {
+		slog.Warn("TLS certificate verification is disabled; connections to Twitch can be intercepted")
+	}
+
+	tlsMu.Lock()
+	tlsConfig = cfg
+	tlsMu.Unlock()
+
+	t := Transport()
+	t.TLSClientConfig = TLSConfig()
+	t.CloseIdleConnections()
+	return nil
+}
+
+// TLSConfig returns a copy of the configured TLS settings, or nil for the
+// defaults. Non-HTTP clients, such as the PubSub websocket dialer and the IRC
+// chat client, use it to honour the same setting.
+func TLSConfig() *tls.Config {
+	tlsMu.RLock()
+	defer tlsMu.RUnlock()
+
+	if tlsConfig == nil {
+		return nil
+	}
+	return tlsConfig.Clone()
+}
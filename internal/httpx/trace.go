@@ -0,0 +1,172 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Error classes recorded for failed requests.
+const (
+	ErrorClassTimeout     = "timeout"
+	ErrorClassCanceled    = "canceled"
+	ErrorClassNetwork     = "network"
+	ErrorClassRateLimited = "rate_limited"
+	ErrorClassClient      = "client_error"
+	ErrorClassServer      = "server_error"
+)
+
+type operationKey struct{}
+
+// WithOperation labels outbound requests made with ctx so their latency is
+// tracked under name instead of the request's method and host.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+func operationName(req *http.Request) string {
+	if name, ok := req.Context().Value(operationKey{}).(string); ok && name != "" {
+		return name
+	}
+	return req.Method + " " + req.URL.Host
+}
+
+// OperationStats aggregates the outbound requests made for one operation.
+type OperationStats struct {
+	Operation    string         `json:"operation"`
+	Count        int            `json:"count"`
+	Errors       int            `json:"errors"`
+	ErrorClasses map[string]int `json:"errorClasses,omitempty"`
+	AvgMs        float64        `json:"avgMs"`
+	MaxMs        float64        `json:"maxMs"`
+	LastMs       float64        `json:"lastMs"`
+	LastStatus   int            `json:"lastStatus,omitempty"`
+	LastError    string         `json:"lastError,omitempty"`
+	LastAt       int64          `json:"lastAt"`
+
+	total time.Duration
+}
+
+var (
+	stats   = make(map[string]*OperationStats)
+	statsMu sync.Mutex
+)
+
+// Stats returns a snapshot of the per-operation request statistics, sorted by operation.
+func Stats() []OperationStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	result := make([]OperationStats, 0, len(stats))
+	for _, s := range stats {
+		snapshot := *s
+		if s.ErrorClasses != nil {
+			snapshot.ErrorClasses = make(map[string]int, len(s.ErrorClasses))
+			for class, n := range s.ErrorClasses {
+				snapshot.ErrorClasses[class] = n
+			}
+		}
+		result = append(result, snapshot)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Operation < result[j].Operation
+	})
+	return result
+}
+
+func record(operation string, latency time.Duration, status int, class string, err error) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	s, ok := stats[operation]
+	if !ok {
+		s = &OperationStats{Operation: operation}
+		stats[operation] = s
+	}
+
+	ms := float64(latency) / float64(time.Millisecond)
+	s.Count++
+	s.total += latency
+	s.AvgMs = float64(s.total) / float64(time.Millisecond) / float64(s.Count)
+	if ms > s.MaxMs {
+		s.MaxMs = ms
+	}
+	s.LastMs = ms
+	s.LastStatus = status
+	s.LastAt = time.Now().UnixMilli()
+	s.LastError = ""
+
+	if class != "" {
+		s.Errors++
+		if s.ErrorClasses == nil {
+			s.ErrorClasses = make(map[string]int)
+		}
+		s.ErrorClasses[class]++
+		if err != nil {
+			s.LastError = err.Error()
+		} else {
+			s.LastError = http.StatusText(status)
+		}
+	}
+}
+
+// classify maps a request outcome to an error class, or "" on success.
+func classify(status int, err error) string {
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, context.Canceled):
+			return ErrorClassCanceled
+		case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+			return ErrorClassTimeout
+		default:
+			return ErrorClassNetwork
+		}
+	}
+
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case status >= 500:
+		return ErrorClassServer
+	case status >= 400:
+		return ErrorClassClient
+	}
+	return ""
+}
+
+// tracingTransport records latency, status and error class of every request.
+// Latency covers the time until response headers are received.
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	operation := operationName(req)
+	start := time.Now()
+
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	class := classify(status, err)
+	record(operation, latency, status, class, err)
+	recordBudget(class)
+
+	if class != "" {
+		slog.Debug("HTTP request failed", "operation", operation, "status", status, "class", class, "latency", latency, "error", err)
+	} else {
+		slog.Debug("HTTP request", "operation", operation, "status", status, "latency", latency)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,29 @@
+package jobs
+
+// ClaimBonusPayload is the payload of a KindClaimBonus job.
+type ClaimBonusPayload struct {
+	Streamer string `json:"streamer"`
+	ClaimID  string `json:"claimId"`
+}
+
+// ClaimMomentPayload is the payload of a KindClaimMoment job.
+type ClaimMomentPayload struct {
+	Streamer string `json:"streamer"`
+	MomentID string `json:"momentId"`
+}
+
+// ClaimDropPayload is the payload of a KindClaimDrop job.
+type ClaimDropPayload struct {
+	DropInstanceID string `json:"dropInstanceId"`
+	Name           string `json:"name"`
+}
+
+// ContributeGoalPayload is the payload of a KindContributeGoal job.
+// TransactionID is generated once so retries cannot contribute twice.
+type ContributeGoalPayload struct {
+	Streamer      string `json:"streamer"`
+	GoalID        string `json:"goalId"`
+	Title         string `json:"title"`
+	Amount        int    `json:"amount"`
+	TransactionID string `json:"transactionId"`
+}
@@ -0,0 +1,338 @@
+// Package jobs provides a small persistent queue for side-effecting Twitch
+// calls (bonus, moment and drop claims, community goal contributions) so that
+// transient failures are retried with backoff instead of being lost.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/connectivity"
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+)
+
+// Job kinds.
+const (
+	KindClaimBonus     = "claim_bonus"
+	KindClaimMoment    = "claim_moment"
+	KindClaimDrop      = "claim_drop"
+	KindContributeGoal = "contribute_goal"
+)
+
+// Job statuses.
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+const (
+	maxAttempts    = 6
+	initialBackoff = 5 * time.Second
+	maxBackoff     = 5 * time.Minute
+
+	// idleWait bounds how long the worker sleeps when no job is due.
+	idleWait = time.Minute
+
+	// retention is how long finished jobs are kept to deduplicate late repeats.
+	retention = 7 * 24 * time.Hour
+)
+
+// kindSpacing is the least time between two jobs of a kind, so a batch of
+// them is not sent to Twitch back to back.
+var kindSpacing = map[string]time.Duration{
+	KindClaimDrop: 5 * time.Second,
+}
+
+// Job is a unit of work identified by an idempotency key.
+type Job struct {
+	ID        int64
+	Kind      string
+	Key       string
+	Payload   json.RawMessage
+	Status    string
+	Attempts  int
+	NextRunAt time.Time
+	LastError string
+}
+
+// Handler executes a job. Returning an error wrapped with Permanent stops retries.
+type Handler func(payload json.RawMessage) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error as not worth retrying.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Queue runs jobs one at a time, retrying failures with exponential backoff.
+// Jobs are persisted when a database is available; otherwise they are kept in memory.
+type Queue struct {
+	repo     *Repository
+	handlers map[string]Handler
+	network  *connectivity.Monitor
+
+	pending []*Job
+	// keys holds the keys of queued and running jobs. Finished jobs are
+	// deduplicated by the repository.
+	keys map[string]bool
+	// lastRun is when a job of each kind last ran.
+	lastRun map[string]time.Time
+
+	wake chan struct{}
+	mu   sync.Mutex
+}
+
+// NewQueue creates a job queue.
+// If db is nil or the jobs repository cannot be created, jobs are not persisted.
+func NewQueue(db *database.DB) *Queue {
+	q := &Queue{
+		handlers: make(map[string]Handler),
+		keys:     make(map[string]bool),
+		lastRun:  make(map[string]time.Time),
+		wake:     make(chan struct{}, 1),
+	}
+
+	if db != nil {
+		repo, err := NewRepository(db)
+		if err != nil {
+			slog.Warn("Jobs will not be persisted", "error", err)
+		} else {
+			q.repo = repo
+		}
+	}
+
+	return q
+}
+
+// Register sets the handler for a job kind. Must be called before Start.
+func (q *Queue) Register(kind string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = handler
+}
+
+// SetConnectivity sets the monitor the worker waits on while the network is
+// down. Must be called before Start.
+func (q *Queue) SetConnectivity(monitor *connectivity.Monitor) {
+	q.network = monitor
+}
+
+// Enqueue schedules a job for immediate execution. Jobs whose key was already
+// enqueued are ignored while that job is pending and, with a database, for the
+// retention period after it completed; a key whose job failed can be enqueued
+// again.
+func (q *Queue) Enqueue(kind, key string, payload interface{}) error {
+	return q.EnqueueAfter(kind, key, payload, 0)
+}
+
+// EnqueueAfter schedules a job to run once delay has passed, ignoring keys
+// that were already enqueued like Enqueue.
+func (q *Queue) EnqueueAfter(kind, key string, payload interface{}, delay time.Duration) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	job := &Job{
+		Kind:      kind,
+		Key:       kind + ":" + key,
+		Payload:   data,
+		Status:    StatusPending,
+		NextRunAt: time.Now().Add(delay),
+	}
+
+	q.mu.Lock()
+	if q.keys[job.Key] {
+		q.mu.Unlock()
+		return nil
+	}
+
+	if q.repo != nil {
+		inserted, err := q.repo.Insert(job)
+		if err != nil {
+			q.mu.Unlock()
+			return fmt.Errorf("failed to persist job: %w", err)
+		}
+		if !inserted {
+			q.mu.Unlock()
+			slog.Debug("Skipping duplicate job", "key", job.Key)
+			return nil
+		}
+	}
+
+	q.keys[job.Key] = true
+	q.pending = append(q.pending, job)
+	q.mu.Unlock()
+
+	q.notify()
+	return nil
+}
+
+// Start loads persisted pending jobs and begins processing until ctx is canceled.
+func (q *Queue) Start(ctx context.Context) {
+	if q.repo != nil {
+		if err := q.repo.Prune(time.Now().Add(-retention)); err != nil {
+			slog.Warn("Failed to prune finished jobs", "error", err)
+		}
+
+		jobs, err := q.repo.Pending()
+		if err != nil {
+			slog.Warn("Failed to load pending jobs", "error", err)
+		} else if len(jobs) > 0 {
+			q.mu.Lock()
+			for _, job := range jobs {
+				if !q.keys[job.Key] {
+					q.keys[job.Key] = true
+					q.pending = append(q.pending, job)
+				}
+			}
+			q.mu.Unlock()
+			slog.Info("Resuming pending jobs", "count", len(jobs))
+		}
+	}
+
+	go q.loop(ctx)
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) loop(ctx context.Context) {
+	for {
+		// Claims would only fail and burn attempts while the network is down.
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.network.Restored():
+		}
+
+		job, wait := q.next()
+		if job != nil {
+			q.run(job)
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-q.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// next removes and returns a due job, or how long to wait until one is due.
+func (q *Queue) next() (*Job, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	wait := idleWait
+	for i, job := range q.pending {
+		due := job.NextRunAt
+		if spacing := kindSpacing[job.Kind]; spacing > 0 {
+			if earliest := q.lastRun[job.Kind].Add(spacing); earliest.After(due) {
+				due = earliest
+			}
+		}
+		until := due.Sub(now)
+		if until <= 0 {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return job, 0
+		}
+		if until < wait {
+			wait = until
+		}
+	}
+	return nil, wait
+}
+
+func (q *Queue) run(job *Job) {
+	q.mu.Lock()
+	handler, ok := q.handlers[job.Kind]
+	q.mu.Unlock()
+
+	var err error
+	if !ok {
+		err = Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	} else {
+		err = handler(job.Payload)
+	}
+
+	q.mu.Lock()
+	q.lastRun[job.Kind] = time.Now()
+	q.mu.Unlock()
+
+	job.Attempts++
+	var permanent *permanentError
+
+	switch {
+	case err == nil:
+		job.Status = StatusDone
+		job.LastError = ""
+	case errors.As(err, &permanent) || job.Attempts >= maxAttempts:
+		job.Status = StatusFailed
+		job.LastError = err.Error()
+		slog.Error("Job failed", "kind", job.Kind, "key", job.Key, "attempts", job.Attempts, "error", err)
+	default:
+		job.LastError = err.Error()
+		job.NextRunAt = time.Now().Add(backoff(job.Attempts))
+		slog.Warn("Job failed, retrying",
+			"kind", job.Kind,
+			"key", job.Key,
+			"attempt", job.Attempts,
+			"retryIn", time.Until(job.NextRunAt).Round(time.Second),
+			"error", err,
+		)
+
+		q.mu.Lock()
+		q.pending = append(q.pending, job)
+		q.mu.Unlock()
+	}
+
+	if q.repo != nil {
+		if err := q.repo.Update(job); err != nil {
+			slog.Warn("Failed to persist job state", "key", job.Key, "error", err)
+		}
+	}
+	// Once a finished job is stored its key is left to the repository, which
+	// ignores repeats of a done job and lets a failed one be enqueued again.
+	if job.Status != StatusPending {
+		q.mu.Lock()
+		delete(q.keys, job.Key)
+		q.mu.Unlock()
+	}
+}
+
+func backoff(attempts int) time.Duration {
+	d := initialBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+)
+
+// JobsModule stores queued jobs so they survive restarts.
+type JobsModule struct{}
+
+func (m *JobsModule) Name() string {
+	return "jobs"
+}
+
+func (m *JobsModule) Migrations() []database.Migration {
+	return []database.Migration{
+		{
+			Version:     1,
+			Description: "Create jobs table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS jobs (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					kind TEXT NOT NULL,
+					idempotency_key TEXT NOT NULL UNIQUE,
+					payload TEXT NOT NULL,
+					status TEXT NOT NULL,
+					attempts INTEGER NOT NULL DEFAULT 0,
+					next_run_at INTEGER NOT NULL,
+					last_error TEXT NOT NULL DEFAULT '',
+					created_at INTEGER NOT NULL,
+					updated_at INTEGER NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_jobs_status_next_run ON jobs(status, next_run_at);
+			`,
+		},
+	}
+}
+
+// Repository persists jobs in the shared database.
+type Repository struct {
+	db *database.DB
+}
+
+// NewRepository registers the jobs module and returns a repository.
+func NewRepository(db *database.DB) (*Repository, error) {
+	if err := db.RegisterModule(&JobsModule{}); err != nil {
+		return nil, fmt.Errorf("failed to register jobs module: %w", err)
+	}
+	return &Repository{db: db}, nil
+}
+
+// Insert stores a new pending job. A failed job with the same idempotency key
+// is reset to pending so it runs again. It reports false if a pending or
+// completed job with the key already exists.
+func (r *Repository) Insert(job *Job) (bool, error) {
+	now := time.Now().Unix()
+	err := r.db.QueryRow(`
+		INSERT INTO jobs (kind, idempotency_key, payload, status, attempts, next_run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?)
+		ON CONFLICT(idempotency_key) DO UPDATE SET
+			payload = excluded.payload,
+			status = excluded.status,
+			attempts = 0,
+			next_run_at = excluded.next_run_at,
+			last_error = '',
+			updated_at = excluded.updated_at
+		WHERE jobs.status = ?
+		RETURNING id
+	`, job.Kind, job.Key, string(job.Payload), StatusPending, job.NextRunAt.Unix(), now, now, StatusFailed).Scan(&job.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Update stores the outcome of an attempt.
+func (r *Repository) Update(job *Job) error {
+	_, err := r.db.Exec(`
+		UPDATE jobs SET status = ?, attempts = ?, next_run_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`, job.Status, job.Attempts, job.NextRunAt.Unix(), job.LastError, time.Now().Unix(), job.ID)
+	return err
+}
+
+// Pending returns all jobs that have not completed or failed permanently.
+func (r *Repository) Pending() ([]*Job, error) {
+	rows, err := r.db.Query(`
+		SELECT id, kind, idempotency_key, payload, status, attempts, next_run_at, last_error
+		FROM jobs
+		WHERE status = ?
+		ORDER BY next_run_at ASC
+	`, StatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var jobs []*Job
+	for rows.Next() {
+		job := &Job{}
+		var payload string
+		var nextRunAt int64
+		if err := rows.Scan(&job.ID, &job.Kind, &job.Key, &payload, &job.Status, &job.Attempts, &nextRunAt, &job.LastError); err != nil {
+			return nil, err
+		}
+		job.Payload = []byte(payload)
+		job.NextRunAt = time.Unix(nextRunAt, 0)
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Prune deletes finished jobs last updated before the given time.
+func (r *Repository) Prune(before time.Time) error {
+	_, err := r.db.Exec(`DELETE FROM jobs WHERE status != ? AND updated_at < ?`, StatusPending, before.Unix())
+	return err
+}
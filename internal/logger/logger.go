@@ -5,11 +5,18 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
 )
 
+// recentLines is how many log lines are kept in memory for crash bundles.
+const recentLines = 200
+
+var recent = &ringWriter{lines: make([]string, 0, recentLines)}
+
 type Logger struct {
 	file    *os.File
 	handler slog.Handler
@@ -20,7 +27,7 @@ func Setup(username string, settings config.LoggerSettings) (*Logger, error) {
 	fileLevel := parseLevel(settings.FileLevel)
 
 	var writers []io.Writer
-	writers = append(writers, os.Stdout)
+	writers = append(writers, os.Stdout, recent)
 
 	l := &Logger{}
 
@@ -91,3 +98,39 @@ func clearOldLogs(logPath string, daysToKeep int) {
 		_ = os.Remove(logPath)
 	}
 }
+
+// Recent returns the most recent log lines, oldest first.
+func Recent() []string {
+	return recent.snapshot()
+}
+
+// ringWriter keeps the last recentLines log lines. slog handlers write each
+// record with a single Write call.
+type ringWriter struct {
+	lines []string
+	next  int
+	mu    sync.Mutex
+}
+
+func (r *ringWriter) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.lines) < recentLines {
+		r.lines = append(r.lines, line)
+	} else {
+		r.lines[r.next] = line
+	}
+	r.next = (r.next + 1) % recentLines
+	return len(p), nil
+}
+
+func (r *ringWriter) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.lines) < recentLines {
+		return append([]string(nil), r.lines...)
+	}
+	return append(append([]string(nil), r.lines[r.next:]...), r.lines[:r.next]...)
+}
@@ -0,0 +1,235 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/api"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+	"github.com/PatrickWalther/twitch-miner-go/internal/settings"
+)
+
+var errOffline = errors.New("network is offline")
+
+func (m *Miner) streamerByName(username string) (*models.Streamer, error) {
+	s := m.streamers.Get(username)
+	if s == nil {
+		return nil, fmt.Errorf("unknown streamer %q", username)
+	}
+	return s, nil
+}
+
+// RecheckStreamer checks a streamer's online status now instead of waiting for
+// the next stream check, and joins or leaves its chat accordingly.
+func (m *Miner) RecheckStreamer(username string) error {
+	if !m.network.Online() {
+		return errOffline
+	}
+	s, err := m.streamerByName(username)
+	if err != nil {
+		return err
+	}
+
+	m.client.CheckStreamerOnline(s)
+	m.chatManager.ToggleChat(s)
+	return nil
+}
+
+// ClaimStreamerBonus reloads a streamer's channel points and claims the bonus
+// chest if one is available.
+func (m *Miner) ClaimStreamerBonus(username string) error {
+	if !m.network.Online() {
+		return errOffline
+	}
+	s, err := m.streamerByName(username)
+	if err != nil {
+		return err
+	}
+	if s.GetSettings().DropsOnly {
+		return fmt.Errorf("%s only mines drops", s.GetUsername())
+	}
+	return m.client.LoadChannelPointsContext(s)
+}
+
+// SetStreamerPinned pins a streamer to a watch slot or releases it. Pins last
+// until the miner restarts.
+func (m *Miner) SetStreamerPinned(username string, pinned bool) error {
+	s, err := m.streamerByName(username)
+	if err != nil {
+		return err
+	}
+	if err := m.watcher.SetPinned(s.GetUsername(), pinned); err != nil {
+		return err
+	}
+	slog.Info("Updated watch slot pin", "streamer", s.GetUsername(), "pinned", pinned)
+	return nil
+}
+
+// ResetStreamerEarning clears a streamer's non-earning state so it competes
+// for watch slots again. Disabled channel points are re-checked with it.
+func (m *Miner) ResetStreamerEarning(username string) error {
+	s, err := m.streamerByName(username)
+	if err != nil {
+		return err
+	}
+	s.ResetEarning()
+	if m.network.Online() {
+		if err := m.client.LoadChannelPointsContext(s); err != nil {
+			slog.Debug("Failed to reload channel points", "streamer", s.GetUsername(), "error", err)
+		}
+	}
+	slog.Info("Reset non-earning state", "streamer", s.GetUsername())
+	return nil
+}
+
+// GetPinnedStreamers returns the streamers pinned to a watch slot.
+func (m *Miner) GetPinnedStreamers() []string {
+	return m.watcher.Pinned()
+}
+
+// SetStreamerEnabled enables or disables mining a streamer and persists it as
+// one settings update.
+func (m *Miner) SetStreamerEnabled(username string, enabled bool) error {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	s := m.GetRuntimeSettings()
+	found := false
+	for i := range s.Streamers {
+		if strings.EqualFold(s.Streamers[i].Username, username) {
+			s.Streamers[i].Disabled = !enabled
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown streamer %q", username)
+	}
+
+	m.applySettings(s)
+	if streamer := m.streamers.Get(username); streamer != nil {
+		m.chatManager.ToggleChat(streamer)
+	}
+	slog.Info("Updated streamer", "streamer", strings.ToLower(username), "enabled", enabled)
+	return nil
+}
+
+// AddStreamer adds a streamer with the default settings to the config and
+// starts mining it. A temporary streamer added this way is kept for good.
+func (m *Miner) AddStreamer(username string) error {
+	if !m.network.Online() {
+		return errOffline
+	}
+	username = strings.ToLower(username)
+
+	// Resolve the login before taking applyMu so settings saves are not blocked on GQL.
+	if _, err := m.client.GetChannelID(username); err != nil {
+		return err
+	}
+
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	s := m.GetRuntimeSettings()
+	for _, sc := range s.Streamers {
+		if strings.EqualFold(sc.Username, username) {
+			return fmt.Errorf("%s is already configured", username)
+		}
+	}
+	s.Streamers = append(s.Streamers, settings.StreamerConfig{Username: username})
+
+	m.applySettings(s)
+	slog.Info("Added streamer", "streamer", username)
+	return nil
+}
+
+// RemoveStreamer removes a streamer from the config and stops mining it. Its
+// analytics are kept. The last configured streamer cannot be removed.
+func (m *Miner) RemoveStreamer(username string) error {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	s := m.GetRuntimeSettings()
+	idx := slices.IndexFunc(s.Streamers, func(sc settings.StreamerConfig) bool {
+		return strings.EqualFold(sc.Username, username)
+	})
+	if idx < 0 {
+		return fmt.Errorf("%s is not configured", username)
+	}
+	if len(s.Streamers) == 1 && !s.Follows.Enabled {
+		return fmt.Errorf("%s is the only configured streamer", username)
+	}
+	s.Streamers = slices.Delete(s.Streamers, idx, idx+1)
+
+	m.applySettings(s)
+	_ = m.watcher.SetPinned(strings.ToLower(username), false)
+	slog.Info("Removed streamer", "streamer", strings.ToLower(username))
+	return nil
+}
+
+// GetDebugOperations returns the GQL operations the debug page may run.
+func (m *Miner) GetDebugOperations() []string {
+	return api.DebugOperations()
+}
+
+// RunDebugOperation runs a whitelisted read-only GQL operation for a streamer
+// and returns the raw response.
+func (m *Miner) RunDebugOperation(operation, username string) (*api.DebugResult, error) {
+	if !m.network.Online() {
+		return nil, errOffline
+	}
+	s, err := m.streamerByName(username)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Running debug GQL operation", "operation", operation, "streamer", s.GetUsername())
+	return m.client.RunDebugOperation(operation, s)
+}
+
+// AddTemporaryStreamer mines a streamer with the default settings for the
+// given duration and removes it again afterwards. The config is left
+// untouched, so temporary streamers do not survive a restart. Adding a
+// streamer that is already temporary moves its removal.
+func (m *Miner) AddTemporaryStreamer(username string, d time.Duration) (time.Time, error) {
+	if !m.network.Online() {
+		return time.Time{}, errOffline
+	}
+
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	until := time.Now().Add(d)
+	s, added, err := m.streamers.AddTemporary(username, until)
+	if err != nil {
+		return time.Time{}, err
+	}
+	time.AfterFunc(d, m.removeExpiredStreamers)
+
+	if added {
+		m.subscribeStreamer(s)
+		m.refreshStreamers()
+	}
+	return until, nil
+}
+
+// removeExpiredStreamers removes temporary streamers whose time is up.
+func (m *Miner) removeExpiredStreamers() {
+	if !m.Running() {
+		return
+	}
+
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	removed := m.streamers.RemoveExpired(time.Now())
+	for _, s := range removed {
+		m.unsubscribeStreamer(s)
+	}
+	if len(removed) > 0 {
+		m.refreshStreamers()
+	}
+}
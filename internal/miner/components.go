@@ -0,0 +1,312 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+	"github.com/PatrickWalther/twitch-miner-go/internal/avatars"
+	"github.com/PatrickWalther/twitch-miner-go/internal/chat"
+	"github.com/PatrickWalther/twitch-miner-go/internal/clock"
+	"github.com/PatrickWalther/twitch-miner-go/internal/crash"
+	"github.com/PatrickWalther/twitch-miner-go/internal/drops"
+	"github.com/PatrickWalther/twitch-miner-go/internal/jobs"
+	"github.com/PatrickWalther/twitch-miner-go/internal/notifications"
+	"github.com/PatrickWalther/twitch-miner-go/internal/pubsub"
+	"github.com/PatrickWalther/twitch-miner-go/internal/watcher"
+	"github.com/PatrickWalther/twitch-miner-go/internal/web"
+)
+
+// components declares the miner's subsystems and what each needs to exist
+// before it is set up. They are initialized after authentication and loading
+// streamers, started once PubSub topics are subscribed, and stopped in
+// reverse order on shutdown.
+func (m *Miner) components() []component {
+	return []component{
+		{
+			name:  "jobs",
+			init:  m.initJobs,
+			start: func(ctx context.Context) { m.jobs.Start(ctx) },
+		},
+		{
+			name: "analytics",
+			init: m.initAnalytics,
+			stop: func() {
+				if m.analyticsSvc != nil {
+					_ = m.analyticsSvc.Close()
+				}
+			},
+		},
+		{
+			name:  "web",
+			deps:  []string{"analytics"},
+			init:  m.initWeb,
+			start: m.startWeb,
+			stop: func() {
+				if m.webServer != nil {
+					m.webServer.Stop()
+				}
+			},
+		},
+		{
+			name: "notifications",
+			deps: []string{"analytics", "web"},
+			init: m.initNotifications,
+			stop: func() {
+				if m.notifications != nil {
+					m.notifications.Stop()
+				}
+			},
+		},
+		{
+			name:  "pubsub",
+			deps:  []string{"jobs"},
+			init:  m.initPubSub,
+			start: func(ctx context.Context) { m.wsPool.Start(ctx) },
+			stop:  func() { m.wsPool.Close() },
+		},
+		{
+			name: "alerts",
+			deps: []string{"analytics", "pubsub", "notifications"},
+			init: m.initAlerts,
+		},
+		{
+			name: "chat",
+			deps: []string{"analytics", "web", "notifications"},
+			init: m.initChat,
+			stop: func() { m.chatManager.Close() },
+		},
+		{
+			name:  "watcher",
+			init:  m.initWatcher,
+			start: func(ctx context.Context) { m.watcher.Start(ctx) },
+			stop:  func() { m.watcher.Stop() },
+		},
+		{
+			name:  "drops",
+			deps:  []string{"jobs"},
+			init:  m.initDrops,
+			start: func(ctx context.Context) { m.dropsTracker.Start(ctx) },
+			stop:  func() { m.dropsTracker.Stop() },
+		},
+		{
+			name:  "schedulers",
+			deps:  []string{"pubsub", "chat", "drops"},
+			start: m.startSchedulers,
+		},
+	}
+}
+
+func (m *Miner) initJobs(ctx context.Context) error {
+	m.jobs = jobs.NewQueue(m.db)
+	m.jobs.SetConnectivity(m.network)
+	m.registerJobHandlers()
+	return nil
+}
+
+// initAnalytics creates the analytics service unless one was passed in by the
+// caller, which then also owns the web server.
+func (m *Miner) initAnalytics(ctx context.Context) error {
+	if !m.config.EnableAnalytics || m.externalAnalytics {
+		return nil
+	}
+
+	svc, err := analytics.NewService(m.db, m.dbBasePath)
+	if err != nil {
+		slog.Error("Failed to create analytics service", "error", err)
+		return nil
+	}
+	m.analyticsSvc = svc
+	return nil
+}
+
+func (m *Miner) initWeb(ctx context.Context) error {
+	if !m.config.EnableAnalytics {
+		return nil
+	}
+
+	streamers := m.streamers.All()
+	if m.externalAnalytics {
+		if m.analyticsSvc == nil || m.webServer == nil {
+			return nil
+		}
+		m.webServer.AttachStreamers(streamers)
+	} else {
+		m.webServer = web.NewServer(
+			m.config.Analytics,
+			m.config.Username,
+			m.dbBasePath,
+			m.analyticsSvc,
+			streamers,
+		)
+	}
+
+	m.webServer.SetConnectivity(m.network)
+	m.webServer.SetSettingsProvider(m)
+	m.webServer.SetSettingsUpdateCallback(m.ApplySettings)
+	m.webServer.SetBulkStreamerUpdateCallback(m.BulkUpdateStreamerSettings)
+	m.webServer.SetStreamerImportCallback(m.ImportStreamers)
+	m.webServer.SetNextStreamCheckProvider(m)
+	return nil
+}
+
+// startWeb registers the providers backed by other components, which all exist
+// by now, and serves the dashboard unless the caller already does.
+func (m *Miner) startWeb(ctx context.Context) {
+	if m.webServer == nil {
+		return
+	}
+
+	m.webServer.SetOverviewProvider(m)
+	m.webServer.SetInventoryProvider(m)
+	m.webServer.SetDropsProvider(m)
+	m.webServer.SetUptimeProvider(m)
+	m.webServer.SetConfigProvider(m)
+	m.webServer.SetStreamerActionProvider(m)
+	m.webServer.SetPredictionProvider(m)
+	m.webServer.SetDiagnosticsProvider(m)
+	m.webServer.SetDebugProvider(m)
+	m.webServer.SetDatabaseProvider(m)
+	m.webServer.SetChatConfigProvider(m)
+	m.webServer.SetAvatarCache(avatars.New(filepath.Join(m.dbBasePath, "avatars"), m.client))
+
+	if !m.externalAnalytics {
+		m.webServer.Start()
+	}
+}
+
+// initNotifications also starts the manager, so alerts raised by the first
+// PubSub events after subscribing are delivered.
+func (m *Miner) initNotifications(ctx context.Context) error {
+	if m.config.Discord.Enabled || m.config.Desktop.Enabled {
+		notifMgr, err := notifications.NewManager(&m.config.Discord, &m.config.Desktop, m.db, m.streamers.Names())
+		if err != nil {
+			slog.Error("Failed to create notification manager", "error", err)
+		} else {
+			m.notifications = notifMgr
+			m.notifications.InitializePointsTracking(m.streamers.PointsMap())
+			if m.analyticsSvc != nil {
+				m.notifications.SetPredictionSummarySource(m.analyticsSvc)
+			}
+
+			if err := m.notifications.Start(ctx); err != nil {
+				slog.Error("Failed to start notification manager", "error", err)
+			}
+		}
+	}
+
+	if m.webServer != nil {
+		m.webServer.SetDiscordEnabled(m.config.Discord.Enabled)
+		if m.notifications != nil {
+			m.webServer.SetNotificationManager(m.notifications)
+		}
+	}
+	return nil
+}
+
+func (m *Miner) initPubSub(ctx context.Context) error {
+	m.wsPool = pubsub.NewWebSocketPool(m.client, m.auth.GetAuthToken(), m.streamers.All(), m.config.RateLimits)
+	m.wsPool.SetJobQueue(m.jobs)
+	m.wsPool.SetConnectivity(m.network)
+	m.wsPool.SetMessageHandler(m.handlePubSubMessage)
+	m.wsPool.SetStatusHandler(m.handleStatusChange)
+	m.wsPool.SetRaidDenylist(m.config.RaidDenylist)
+	m.wsPool.SetFeatures(m.features)
+	m.wsPool.SetPredictionSkipHandler(m.handlePredictionSkip)
+	m.wsPool.SetPredictionResultHandler(m.handlePredictionResult)
+	m.wsPool.SetPredictionTimingHandler(m.handlePredictionTiming)
+	m.wsPool.SetPredictionLateHandler(m.handlePredictionLate)
+	m.wsPool.SetBetApprovalHandler(m.handleBetApproval)
+	m.wsPool.SetPredictionOpenedHandler(m.handlePredictionOpened)
+	m.wsPool.SetBetConfirm(m.config.BetConfirm)
+	return nil
+}
+
+// initAlerts routes failures reported by other components to the critical
+// error notifications, and auth tokens refreshed by the API client to PubSub
+// and chat.
+func (m *Miner) initAlerts(ctx context.Context) error {
+	m.client.SetFailureHandler(m.handleGQLFailure)
+	m.client.SetTokenRefreshHandler(m.useAuthToken)
+	m.wsPool.SetErrorHandler(m.handlePubSubError)
+	if m.analyticsSvc != nil {
+		m.analyticsSvc.SetErrorHandler(m.handleDatabaseError)
+	}
+	if status := m.db.Status(); status.InMemory {
+		m.notifyError(notifications.ErrorKindDatabase, fmt.Sprintf(
+			"The database file is damaged, data is only kept in memory until it is recovered from the dashboard.\nError: %s", status.Error))
+	}
+	crash.SetSnapshotProvider(m.crashSnapshot)
+	crash.SetPanicHandler(m.handlePanic)
+	return nil
+}
+
+func (m *Miner) initChat(ctx context.Context) error {
+	var chatLogger chat.ChatLogger
+	chatLogsEnabled := m.config.EnableAnalytics && m.config.Analytics.EnableChatLogs
+	slog.Debug("Chat logging config", "enableAnalytics", m.config.EnableAnalytics, "enableChatLogs", m.config.Analytics.EnableChatLogs, "chatLogsEnabled", chatLogsEnabled)
+	// The logger is also needed with chat logs off, for channels turned on
+	// through /api/chat/config.
+	if m.analyticsSvc != nil {
+		chatLogger = analytics.NewChatLoggerAdapter(m.analyticsSvc)
+	}
+
+	m.chatManager = chat.NewChatManager(m.config.Username, m.auth.GetAuthToken(), chatLogger, chatLogsEnabled, m.handleMention)
+	m.chatManager.SetTLS(m.config.ChatTLS)
+	m.chatManager.SetIgnoreRules(m.config.ChatIgnore.Users, m.config.ChatIgnore.Prefixes)
+	m.chatManager.SetMentionRules(m.config.ChatMentions.WholeWord, m.config.ChatMentions.RequireAt,
+		time.Duration(m.config.ChatMentions.DedupeWindow)*time.Second)
+	if m.webServer != nil {
+		broker := chat.NewBroker()
+		m.chatManager.SetBroker(broker)
+		m.webServer.SetChatBroker(broker)
+	}
+	return nil
+}
+
+func (m *Miner) initWatcher(ctx context.Context) error {
+	m.watcher = watcher.NewMinuteWatcher(
+		m.client,
+		m.streamers.All(),
+		m.config.Priority,
+		m.config.RateLimits,
+	)
+	m.watcher.SetWatchHandler(m.handleWatch)
+	m.watcher.SetHeartbeatHandler(m.handleHeartbeat)
+	m.watcher.SetConnectivity(m.network)
+	m.watcher.SetWatchMode(m.config.WatchMode)
+	m.watcher.SetWatchNonEarning(m.config.WatchNonEarning)
+	m.watcher.SetHLSMode(m.config.HLSMode)
+	return nil
+}
+
+func (m *Miner) initDrops(ctx context.Context) error {
+	m.dropsTracker = drops.NewDropsTracker(
+		m.client,
+		m.streamers.All(),
+		m.config.RateLimits,
+		m.jobs,
+	)
+	m.dropsTracker.SetConnectivity(m.network)
+
+	if m.config.ClaimDropsOnStartup {
+		slog.Info("Claiming all drops from inventory on startup")
+	}
+	return nil
+}
+
+// startSchedulers starts the loops that re-check streamers periodically and
+// resynchronize PubSub, campaigns and streamer status after a clock jump or a
+// network outage.
+func (m *Miner) startSchedulers(ctx context.Context) {
+	go m.streamCheckLoop(ctx)
+	go crash.Loop(ctx.Done(), "throttle", func() { m.throttleLoop(ctx) })
+	if m.configPath != "" {
+		go crash.Loop(ctx.Done(), "config watch", func() { m.watchConfig(ctx) })
+	}
+	go clock.WatchJumps(ctx, m.handleClockJump)
+	go m.network.Run(ctx, m.handleConnectivityChange)
+}
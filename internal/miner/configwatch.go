@@ -0,0 +1,253 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/settings"
+)
+
+// configReloadDebounce is how long the config file must stay unchanged before
+// it is reloaded, so editors writing it in several steps cause one reload.
+const configReloadDebounce = time.Second
+
+// watchConfig reloads the config file whenever it changes on disk. It watches
+// the file's directory because editors often replace the file instead of
+// writing to it, which ends a watch on the file itself.
+func (m *Miner) watchConfig(ctx context.Context) {
+	path, err := filepath.Abs(m.configPath)
+	if err != nil {
+		slog.Warn("Config hot reload disabled", "error", err)
+		return
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Warn("Config hot reload disabled", "error", err)
+		return
+	}
+	defer fw.Close()
+
+	if err := fw.Add(filepath.Dir(path)); err != nil {
+		slog.Warn("Config hot reload disabled", "path", path, "error", err)
+		return
+	}
+	slog.Debug("Watching config file for changes", "path", path)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != path || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			debounce = time.After(configReloadDebounce)
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("Config file watch error", "error", err)
+		case <-debounce:
+			debounce = nil
+			m.reloadConfig()
+		}
+	}
+}
+
+// reloadConfig loads the config file and applies what changed through the
+// same path as the settings page. An invalid file is logged and ignored, so
+// the miner keeps running with the settings it has. Settings that are only
+// read at startup are kept in the config and take effect after a restart.
+func (m *Miner) reloadConfig() {
+	next, err := config.LoadConfig(m.configPath)
+	if err != nil {
+		slog.Error("Ignoring config file change, failed to load it", "error", err)
+		return
+	}
+	nextSettings := settings.BuildRuntimeSettings(next)
+	if err := validateReload(nextSettings); err != nil {
+		slog.Error("Ignoring config file change, invalid settings", "error", err)
+		return
+	}
+
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	m.mu.Lock()
+	current := settings.BuildRuntimeSettings(normalizedConfig(m.config))
+	restart := restartOnlyChanges(m.config, next)
+	m.mu.Unlock()
+
+	nextSettings = settings.BuildRuntimeSettings(normalizedConfig(next))
+	added, removed, changed := diffStreamers(current.Streamers, nextSettings.Streamers)
+	sections := diffSections(current, nextSettings)
+
+	if len(restart) > 0 {
+		slog.Warn("Config file changes take effect after a restart", "settings", strings.Join(restart, ", "))
+	}
+	if len(added) == 0 && len(removed) == 0 && len(changed) == 0 && len(sections) == 0 {
+		if len(restart) > 0 {
+			m.saveConfig()
+		}
+		return
+	}
+
+	slog.Info("Config file changed, applying settings",
+		"added", strings.Join(added, ", "),
+		"removed", strings.Join(removed, ", "),
+		"changed", strings.Join(changed, ", "),
+		"settings", strings.Join(sections, ", "),
+	)
+	m.applySettings(nextSettings)
+}
+
+// validateReload rejects edits the settings page would not allow.
+func validateReload(s settings.RuntimeSettings) error {
+	if len(s.Streamers) == 0 && !s.Follows.Enabled {
+		return fmt.Errorf("at least one streamer is required unless follows are enabled")
+	}
+	if err := validateFileSettings(s.DefaultSettings); err != nil {
+		return fmt.Errorf("default settings: %w", err)
+	}
+
+	seen := make(map[string]bool, len(s.Streamers))
+	for _, sc := range s.Streamers {
+		username := strings.ToLower(strings.TrimSpace(sc.Username))
+		if username == "" {
+			return fmt.Errorf("streamer without username")
+		}
+		if seen[username] {
+			return fmt.Errorf("streamer %s is listed twice", username)
+		}
+		seen[username] = true
+
+		if sc.Settings != nil {
+			if err := validateFileSettings(*sc.Settings); err != nil {
+				return fmt.Errorf("streamer %s: %w", username, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateFileSettings validates streamer settings read from the config file,
+// where options left out are empty rather than unset and fall back to their
+// defaults.
+func validateFileSettings(s settings.StreamerSettingsConfig) error {
+	if s.Chat != nil && *s.Chat == "" {
+		s.Chat = nil
+	}
+	if s.Bet != nil {
+		bet := *s.Bet
+		if bet.Strategy != nil && *bet.Strategy == "" {
+			bet.Strategy = nil
+		}
+		if bet.DelayMode != nil && *bet.DelayMode == "" {
+			bet.DelayMode = nil
+		}
+		s.Bet = &bet
+	}
+	return settings.ValidateStreamerSettings(s)
+}
+
+// normalizedConfig returns a copy of cfg with its runtime settings written
+// back the way the settings page saves them, so configs only differing in
+// empty lists or defaults compare equal.
+func normalizedConfig(cfg *config.Config) *config.Config {
+	normalized := *cfg
+	settings.ApplyToConfig(&normalized, settings.BuildRuntimeSettings(cfg))
+	return &normalized
+}
+
+// restartOnlyChanges copies the config fields that differ from next beyond
+// its runtime settings into cfg, so saving the applied settings does not
+// revert them, and returns their names.
+func restartOnlyChanges(cfg, next *config.Config) []string {
+	// What cfg would be with next's runtime settings applied; any remaining
+	// difference is in settings only read at startup.
+	applied := *cfg
+	settings.ApplyToConfig(&applied, settings.BuildRuntimeSettings(next))
+	target := normalizedConfig(next)
+
+	var names []string
+	dst := reflect.ValueOf(cfg).Elem()
+	a := reflect.ValueOf(applied)
+	b := reflect.ValueOf(*target)
+	for i := range a.NumField() {
+		if reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			continue
+		}
+		dst.Field(i).Set(reflect.ValueOf(*next).Field(i))
+		names = append(names, jsonName(a.Type().Field(i)))
+	}
+	return names
+}
+
+// diffStreamers returns the streamers added, removed and with changed
+// settings between two settings versions.
+func diffStreamers(current, next []settings.StreamerConfig) (added, removed, changed []string) {
+	before := make(map[string]settings.StreamerConfig, len(current))
+	for _, sc := range current {
+		before[strings.ToLower(sc.Username)] = sc
+	}
+
+	after := make(map[string]bool, len(next))
+	for _, sc := range next {
+		username := strings.ToLower(sc.Username)
+		after[username] = true
+		old, ok := before[username]
+		switch {
+		case !ok:
+			added = append(added, username)
+		case !reflect.DeepEqual(old, sc):
+			changed = append(changed, username)
+		}
+	}
+	for _, sc := range current {
+		if username := strings.ToLower(sc.Username); !after[username] {
+			removed = append(removed, username)
+		}
+	}
+	return added, removed, changed
+}
+
+// diffSections returns the names of the settings sections other than the
+// streamer list that differ.
+func diffSections(current, next settings.RuntimeSettings) []string {
+	var names []string
+	a := reflect.ValueOf(current)
+	b := reflect.ValueOf(next)
+	for i := range a.NumField() {
+		field := a.Type().Field(i)
+		if field.Name == "Streamers" {
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			names = append(names, jsonName(field))
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,167 @@
+package miner
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/web"
+)
+
+// conflictGrace is how long watch points may still be credited for a channel
+// after the miner stopped watching it, or after the miner started. Twitch
+// credits watch points a little after the minute-watched reports that earned
+// them.
+const conflictGrace = 3 * time.Minute
+
+// watchConflicts notices the account being watched somewhere else: Twitch
+// crediting watch points for a channel the miner has not been watching can
+// only come from another viewer on the same account. While yielding, the
+// watcher stays paused until the conflicts stop.
+type watchConflicts struct {
+	started time.Time
+	// watched is when each streamer last occupied a watch slot.
+	watched map[string]time.Time
+	until   time.Time
+	timer   *time.Timer
+	mu      sync.Mutex
+}
+
+func (c *watchConflicts) start(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = at
+}
+
+// watching records the streamers occupying the watch slots.
+func (c *watchConflicts) watching(streamers []string, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.watched == nil {
+		c.watched = make(map[string]time.Time)
+	}
+	for _, name := range streamers {
+		c.watched[name] = at
+	}
+}
+
+// conflicting reports whether watch points credited for the streamer at the
+// given time cannot have been earned by the miner.
+func (c *watchConflicts) conflicting(streamer string, at time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if at.Sub(c.started) < conflictGrace {
+		return false
+	}
+	last, ok := c.watched[streamer]
+	return !ok || at.Sub(last) > conflictGrace
+}
+
+// yield keeps yielding until the given time and calls resume once it has
+// passed. It reports whether a yield was already in progress.
+func (c *watchConflicts) yield(until time.Time, resume func()) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	extended := !c.until.IsZero()
+	c.until = until
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(time.Until(until), func() {
+		if c.expire() {
+			resume()
+		}
+	})
+	return extended
+}
+
+// expire ends a yield whose time has passed and reports whether it did.
+func (c *watchConflicts) expire() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.until.IsZero() || time.Now().Before(c.until) {
+		return false
+	}
+	c.until = time.Time{}
+	c.timer = nil
+	return true
+}
+
+// stop ends a yield early and reports whether one was in progress.
+func (c *watchConflicts) stop() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.until.IsZero() {
+		return false
+	}
+	c.until = time.Time{}
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	return true
+}
+
+func (c *watchConflicts) yielding() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.until.IsZero()
+}
+
+// detectConflict looks for watch points the miner did not earn. With yield
+// enabled, watching pauses until no conflict was seen for the configured
+// time, so the account does not send two sets of heartbeats.
+func (m *Miner) detectConflict(event Event) {
+	if event.Type != EventPointsEarned {
+		return
+	}
+	if event.Reason != "WATCH" && event.Reason != "WATCH_STREAK" {
+		return
+	}
+	if !m.conflicts.conflicting(event.Streamer, event.Time) {
+		return
+	}
+
+	m.mu.RLock()
+	yield := m.config.Yield
+	m.mu.RUnlock()
+
+	if !m.conflicts.yielding() {
+		slog.Warn("Watch points credited for a channel the miner is not watching, the account is likely viewed elsewhere",
+			"streamer", event.Streamer,
+			"reason", event.Reason,
+		)
+	}
+	m.events.publish(Event{
+		Type:     EventViewingConflict,
+		Streamer: event.Streamer,
+		Reason:   event.Reason,
+	})
+
+	if !yield.Enabled || m.watcher == nil {
+		return
+	}
+	// A pause set by hand is left alone; resuming on its own would undo it.
+	if m.watcher.Paused() && !m.conflicts.yielding() {
+		return
+	}
+
+	until := event.Time.Add(time.Duration(yield.Minutes) * time.Minute)
+	extended := m.conflicts.yield(until, func() {
+		m.setPaused(false, "Mining active")
+	})
+	message := "Yielding to manual viewing until " + until.Format("15:04")
+	if !extended {
+		m.setPaused(true, message)
+		return
+	}
+	slog.Debug("Extended yield to manual viewing", "streamer", event.Streamer, "until", until.Format("15:04"))
+	if m.webServer != nil {
+		m.webServer.GetStatusBroadcaster().SetStatus(web.StatusRunning, message)
+	}
+}
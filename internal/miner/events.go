@@ -0,0 +1,184 @@
+package miner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind before
+// further events are dropped for it.
+const eventBufferSize = 64
+
+// EventType identifies a miner event delivered to subscribers.
+type EventType string
+
+const (
+	EventStreamerOnline   EventType = "streamer_online"
+	EventStreamerOffline  EventType = "streamer_offline"
+	EventPointsEarned     EventType = "points_earned"
+	EventPointsSpent      EventType = "points_spent"
+	EventPredictionResult EventType = "prediction_result"
+	EventWatching         EventType = "watching"
+	EventSettingsApplied  EventType = "settings_applied"
+	EventBetPlaced        EventType = "bet_placed"
+	EventDropClaimed      EventType = "drop_claimed"
+	EventMentionReceived  EventType = "mention_received"
+	// EventViewingConflict is watch points credited for a channel the miner
+	// is not watching, i.e. the account is viewed somewhere else.
+	EventViewingConflict EventType = "viewing_conflict"
+)
+
+// Event is something the miner did or observed. Fields that do not apply to
+// the event type are zero.
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Streamer string
+	// Balance is the streamer's channel points after the event.
+	Balance int
+	// Gained is the points earned, or the net result of a prediction.
+	Gained int
+	// Reason is the Twitch reason code of earned points or the prediction
+	// result type (WIN, LOSE, REFUND).
+	Reason string
+	// Watching lists the streamers occupying the watch slots.
+	Watching []string
+	// Placed is the points bet on a prediction.
+	Placed int
+	// Drop is the name of a claimed drop.
+	Drop string
+	// From and Message are the chat user and message that mentioned the
+	// account.
+	From    string
+	Message string
+}
+
+// eventBus delivers events to the miner's own handlers, synchronously and in
+// order, and fans them out to channel subscribers without blocking the
+// publisher.
+type eventBus struct {
+	handlers []func(Event)
+	subs     map[chan Event]struct{}
+	mu       sync.RWMutex
+}
+
+// handle registers fn to be called with every event before publish returns.
+func (b *eventBus) handle(fn func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, fn)
+}
+
+func (b *eventBus) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[chan Event]struct{})
+	}
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			close(ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+func (b *eventBus) publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	for _, fn := range handlers {
+		fn(event)
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the miner's events and a function
+// that unsubscribes and closes it. Events are dropped for a subscriber that
+// falls more than eventBufferSize events behind.
+func (m *Miner) Subscribe() (<-chan Event, func()) {
+	return m.events.subscribe()
+}
+
+// recordEvent stores the events analytics keeps.
+func (m *Miner) recordEvent(event Event) {
+	if m.analyticsSvc == nil {
+		return
+	}
+
+	switch event.Type {
+	case EventPointsEarned, EventPointsSpent, EventBetPlaced:
+	default:
+		return
+	}
+	s := m.streamers.Get(event.Streamer)
+	if s == nil {
+		return
+	}
+
+	switch event.Type {
+	case EventPointsEarned:
+		if event.Reason == "" {
+			return
+		}
+		m.analyticsSvc.RecordPoints(s, event.Reason)
+		if event.Reason == "WATCH_STREAK" && event.Gained > 0 {
+			m.analyticsSvc.RecordAnnotation(s, "WATCH_STREAK", fmt.Sprintf("+%d - Watch Streak", event.Gained))
+		}
+		if event.Reason == "CLAIM" {
+			m.analyticsSvc.RecordBonusClaim(s.GetUsername())
+		}
+	case EventPointsSpent:
+		m.analyticsSvc.RecordPoints(s, "Spent")
+	case EventBetPlaced:
+		m.analyticsSvc.RecordAnnotation(s, "PREDICTION_MADE", "Prediction placed")
+	}
+}
+
+// notifyEvent sends the notifications triggered by events. It looks up the
+// notification manager on every event, so a manager recreated by a settings
+// change is used right away.
+func (m *Miner) notifyEvent(event Event) {
+	m.mu.RLock()
+	notifMgr := m.notifications
+	m.mu.RUnlock()
+
+	if notifMgr == nil {
+		return
+	}
+
+	switch event.Type {
+	case EventStreamerOnline:
+		notifMgr.NotifyOnline(event.Streamer)
+	case EventStreamerOffline:
+		notifMgr.NotifyOffline(event.Streamer)
+	case EventPointsEarned:
+		notifMgr.NotifyPointsReached(event.Streamer, event.Balance)
+	case EventDropClaimed:
+		notifMgr.NotifyDropClaimed(event.Drop)
+	case EventMentionReceived:
+		notifMgr.NotifyMention(event.Streamer, event.From, event.Message)
+	}
+}
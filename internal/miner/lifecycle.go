@@ -0,0 +1,157 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// component is a subsystem managed by the miner's lifecycle. init constructs
+// and wires it once every dependency is initialized, start launches its
+// background work once all components are initialized, and stop releases it
+// after every component depending on it has stopped. Any hook may be nil.
+type component struct {
+	name  string
+	deps  []string
+	init  func(ctx context.Context) error
+	start func(ctx context.Context)
+	stop  func()
+}
+
+// lifecycle runs components in dependency order.
+type lifecycle struct {
+	ordered     []component
+	initialized int
+	started     bool
+
+	// mu guards initialized and started for Status, which is called from
+	// other goroutines.
+	mu sync.Mutex
+}
+
+// componentStatus is a component's lifecycle state for diagnostics.
+type componentStatus struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// newLifecycle orders components so each comes after its dependencies,
+// keeping the declaration order otherwise. It fails on unknown dependencies
+// and cycles.
+func newLifecycle(components ...component) (*lifecycle, error) {
+	byName := make(map[string]component, len(components))
+	for _, c := range components {
+		if _, ok := byName[c.name]; ok {
+			return nil, fmt.Errorf("duplicate component %q", c.name)
+		}
+		byName[c.name] = c
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(components))
+	ordered := make([]component, 0, len(components))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v", append(path, name))
+		}
+		state[name] = visiting
+
+		c := byName[name]
+		for _, dep := range c.deps {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("component %q depends on unknown component %q", name, dep)
+			}
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+
+		state[name] = done
+		ordered = append(ordered, c)
+		return nil
+	}
+
+	for _, c := range components {
+		if err := visit(c.name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return &lifecycle{ordered: ordered}, nil
+}
+
+// Init initializes every component in order. If one fails, the components
+// initialized before it are stopped.
+func (l *lifecycle) Init(ctx context.Context) error {
+	for _, c := range l.ordered {
+		if c.init != nil {
+			slog.Debug("Initializing component", "component", c.name)
+			if err := c.init(ctx); err != nil {
+				l.Stop()
+				return fmt.Errorf("%s: %w", c.name, err)
+			}
+		}
+		l.mu.Lock()
+		l.initialized++
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// Start starts every component in order.
+func (l *lifecycle) Start(ctx context.Context) {
+	for _, c := range l.ordered[:l.initialized] {
+		if c.start != nil {
+			slog.Debug("Starting component", "component", c.name)
+			c.start(ctx)
+		}
+	}
+
+	l.mu.Lock()
+	l.started = true
+	l.mu.Unlock()
+}
+
+// Stop stops the initialized components in reverse order.
+func (l *lifecycle) Stop() {
+	for i := l.initialized - 1; i >= 0; i-- {
+		if c := l.ordered[i]; c.stop != nil {
+			slog.Debug("Stopping component", "component", c.name)
+			c.stop()
+		}
+	}
+
+	l.mu.Lock()
+	l.initialized = 0
+	l.started = false
+	l.mu.Unlock()
+}
+
+// Status returns every component's state in initialization order.
+func (l *lifecycle) Status() []componentStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := make([]componentStatus, len(l.ordered))
+	for i, c := range l.ordered {
+		state := "pending"
+		if i < l.initialized {
+			state = "initialized"
+			if l.started {
+				state = "running"
+			}
+		}
+		status[i] = componentStatus{Name: c.name, State: state}
+	}
+	return status
+}
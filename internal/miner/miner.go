@@ -2,11 +2,17 @@ package miner
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	mathrand "math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
@@ -14,14 +20,21 @@ import (
 	"github.com/PatrickWalther/twitch-miner-go/internal/auth"
 	"github.com/PatrickWalther/twitch-miner-go/internal/chat"
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/connectivity"
+	"github.com/PatrickWalther/twitch-miner-go/internal/crash"
 	"github.com/PatrickWalther/twitch-miner-go/internal/database"
 	"github.com/PatrickWalther/twitch-miner-go/internal/drops"
+	"github.com/PatrickWalther/twitch-miner-go/internal/features"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
+	"github.com/PatrickWalther/twitch-miner-go/internal/jobs"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 	"github.com/PatrickWalther/twitch-miner-go/internal/notifications"
 	"github.com/PatrickWalther/twitch-miner-go/internal/pubsub"
 	"github.com/PatrickWalther/twitch-miner-go/internal/settings"
 	"github.com/PatrickWalther/twitch-miner-go/internal/streamer"
+	"github.com/PatrickWalther/twitch-miner-go/internal/uptime"
 	"github.com/PatrickWalther/twitch-miner-go/internal/util"
+	"github.com/PatrickWalther/twitch-miner-go/internal/version"
 	"github.com/PatrickWalther/twitch-miner-go/internal/watcher"
 	"github.com/PatrickWalther/twitch-miner-go/internal/web"
 )
@@ -40,28 +53,51 @@ type Miner struct {
 	chatManager   *chat.ChatManager
 	watcher       *watcher.MinuteWatcher
 	dropsTracker  *drops.DropsTracker
+	jobs          *jobs.Queue
+	network       *connectivity.Monitor
+	features      *features.Flags
+	uptime        *uptime.Tracker
 	analyticsSvc  *analytics.Service
 	webServer     *web.Server
 	notifications *notifications.Manager
+	lifecycle     *lifecycle
+	events        eventBus
+	sessions      streamSessions
+	conflicts     watchConflicts
 
 	deviceID          string
 	externalAnalytics bool
+	running           bool
+	authRecovering    atomic.Bool
 
-	nextStreamCheck     time.Time
-	streamCheckTrigger  chan struct{}
+	nextStreamCheck    time.Time
+	streamCheckTrigger chan struct{}
+	streamCheckResync  chan struct{}
 
-	mu sync.RWMutex
+	// applyMu serializes settings updates, which read, change and persist the
+	// whole configuration without holding mu throughout.
+	applyMu sync.Mutex
+	mu      sync.RWMutex
 }
 
 func New(cfg *config.Config, configPath string) *Miner {
 	deviceID := util.DeviceID()
+	util.SetLocale(cfg.Locale)
 
-	return &Miner{
+	m := &Miner{
 		config:             cfg,
 		configPath:         configPath,
 		deviceID:           deviceID,
 		streamCheckTrigger: make(chan struct{}, 1),
+		streamCheckResync:  make(chan struct{}, 1),
+		features:           features.New(cfg.FeatureFlags),
+		network:            connectivity.NewMonitor(),
 	}
+	m.events.handle(m.recordEvent)
+	m.events.handle(m.notifyEvent)
+	m.events.handle(m.trackStreamSession)
+	m.events.handle(m.detectConflict)
+	return m
 }
 
 func (m *Miner) SetAnalyticsService(svc *analytics.Service) {
@@ -75,11 +111,23 @@ func (m *Miner) SetWebServer(server *web.Server) {
 
 // Run starts the miner and blocks until the context is cancelled.
 // The caller is responsible for handling OS signals and cancelling the context.
-func (m *Miner) Run(ctx context.Context) error {
+func (m *Miner) Run(ctx context.Context) (err error) {
 	if err := m.initialize(); err != nil {
 		return fmt.Errorf("initialization failed: %w", err)
 	}
 
+	m.uptime = uptime.NewTracker(m.db)
+	m.uptime.Start(version.Version)
+	defer func() {
+		if r := recover(); r != nil {
+			m.uptime.Stop(fmt.Sprintf("panic: %v", r))
+			panic(r)
+		}
+		if err != nil {
+			m.uptime.Stop(err.Error())
+		}
+	}()
+
 	if err := m.authenticate(); err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
@@ -87,10 +135,20 @@ func (m *Miner) Run(ctx context.Context) error {
 	if err := m.loadStreamers(); err != nil {
 		return fmt.Errorf("failed to load streamers: %w", err)
 	}
+	m.conflicts.start(time.Now())
 
-	m.setupComponents(ctx)
+	lc, err := newLifecycle(m.components()...)
+	if err != nil {
+		return fmt.Errorf("invalid component graph: %w", err)
+	}
+	m.lifecycle = lc
+
+	if err := lc.Init(ctx); err != nil {
+		return fmt.Errorf("failed to initialize components: %w", err)
+	}
 
 	if err := m.subscribeToTopics(); err != nil {
+		lc.Stop()
 		return fmt.Errorf("failed to subscribe to topics: %w", err)
 	}
 
@@ -154,6 +212,7 @@ func (m *Miner) authenticate() error {
 	}
 
 	m.client = api.NewTwitchClient(m.auth, m.deviceID)
+	m.client.SetPointsFloor(m.config.PointsFloor)
 	m.client.UpdateClientVersion()
 
 	userID, err := m.client.GetChannelID(m.config.Username)
@@ -184,102 +243,11 @@ func (m *Miner) loadStreamers() error {
 		}
 	}
 
-	m.streamers = streamer.NewManager(m.client, m.config.StreamerSettings)
+	m.streamers = streamer.NewManager(m.client, m.config.StreamerSettings, m.db)
+	m.streamers.SetFollowDiscovery(m.config.Follows)
 	return m.streamers.LoadFromConfig(m.config.Streamers, progressCallback)
 }
 
-func (m *Miner) setupComponents(ctx context.Context) {
-	streamers := m.streamers.All()
-
-	m.wsPool = pubsub.NewWebSocketPool(m.client, m.auth.GetAuthToken(), streamers, m.config.RateLimits)
-	m.wsPool.SetMessageHandler(m.handlePubSubMessage)
-	m.wsPool.SetStatusHandler(m.handleStatusChange)
-
-	if m.config.EnableAnalytics {
-		if m.externalAnalytics && m.analyticsSvc != nil {
-			if m.webServer != nil {
-				m.webServer.AttachStreamers(streamers)
-				m.webServer.SetSettingsProvider(m)
-				m.webServer.SetSettingsUpdateCallback(m.ApplySettings)
-				m.webServer.SetNextStreamCheckProvider(m)
-			}
-		} else {
-			svc, err := analytics.NewService(m.db, m.dbBasePath)
-			if err != nil {
-				slog.Error("Failed to create analytics service", "error", err)
-			} else {
-				m.analyticsSvc = svc
-			}
-
-			m.webServer = web.NewServer(
-				m.config.Analytics,
-				m.config.Username,
-				m.dbBasePath,
-				m.analyticsSvc,
-				streamers,
-			)
-			if m.webServer != nil {
-				m.webServer.SetSettingsProvider(m)
-				m.webServer.SetSettingsUpdateCallback(m.ApplySettings)
-				m.webServer.SetNextStreamCheckProvider(m)
-			}
-		}
-	}
-
-	streamerNames := m.streamers.Names()
-
-	if m.config.Discord.Enabled {
-		notifMgr, err := notifications.NewManager(&m.config.Discord, m.db, streamerNames)
-		if err != nil {
-			slog.Error("Failed to create notification manager", "error", err)
-		} else {
-			m.notifications = notifMgr
-			m.notifications.InitializePointsTracking(m.streamers.PointsMap())
-
-			if err := m.notifications.Start(ctx); err != nil {
-				slog.Error("Failed to start notification manager", "error", err)
-			}
-		}
-	}
-
-	if m.webServer != nil {
-		m.webServer.SetDiscordEnabled(m.config.Discord.Enabled)
-		if m.notifications != nil {
-			m.webServer.SetNotificationManager(m.notifications)
-		}
-	}
-
-	var mentionHandler chat.MentionHandler
-	if m.notifications != nil {
-		mentionHandler = m.notifications.NotifyMention
-	}
-
-	var chatLogger chat.ChatLogger
-	chatLogsEnabled := m.config.EnableAnalytics && m.config.Analytics.EnableChatLogs
-	slog.Debug("Chat logging config", "enableAnalytics", m.config.EnableAnalytics, "enableChatLogs", m.config.Analytics.EnableChatLogs, "chatLogsEnabled", chatLogsEnabled)
-	if chatLogsEnabled && m.analyticsSvc != nil {
-		chatLogger = analytics.NewChatLoggerAdapter(m.analyticsSvc)
-	}
-	m.chatManager = chat.NewChatManager(m.config.Username, m.auth.GetAuthToken(), chatLogger, chatLogsEnabled, mentionHandler)
-
-	m.watcher = watcher.NewMinuteWatcher(
-		m.client,
-		streamers,
-		m.config.Priority,
-		m.config.RateLimits,
-	)
-
-	m.dropsTracker = drops.NewDropsTracker(
-		m.client,
-		streamers,
-		m.config.RateLimits,
-	)
-
-	if m.config.ClaimDropsOnStartup {
-		slog.Info("Claiming all drops from inventory on startup")
-	}
-}
-
 func (m *Miner) subscribeToTopics() error {
 	slog.Info("Subscribing to PubSub topics")
 
@@ -293,49 +261,32 @@ func (m *Miner) subscribeToTopics() error {
 	}
 
 	for _, s := range m.streamers.All() {
-		channelID := s.ChannelID
-
-		_ = m.wsPool.Submit(pubsub.NewTopic(pubsub.TopicVideoPlaybackByID, channelID))
-
-		if s.Settings.FollowRaid {
-			_ = m.wsPool.Submit(pubsub.NewTopic(pubsub.TopicRaid, channelID))
-		}
-
-		if s.Settings.MakePredictions {
-			_ = m.wsPool.Submit(pubsub.NewTopic(pubsub.TopicPredictionsChannel, channelID))
-		}
-
-		if s.Settings.ClaimMoments {
-			_ = m.wsPool.Submit(pubsub.NewTopic(pubsub.TopicCommunityMomentsChannel, channelID))
-		}
-
-		if s.Settings.CommunityGoals {
-			_ = m.wsPool.Submit(pubsub.NewTopic(pubsub.TopicCommunityPointsChannel, channelID))
-		}
+		m.subscribeStreamer(s)
 	}
 
+	slog.Info("Subscribed to PubSub topics",
+		"connections", m.wsPool.Connections(),
+		"onlineDetection", m.onlineDetection(),
+	)
 	return nil
 }
 
 func (m *Miner) startMining(ctx context.Context) {
 	slog.Info("Starting mining operations")
 
-	for _, s := range m.streamers.All() {
-		m.client.CheckStreamerOnline(s)
-		m.chatManager.ToggleChat(s)
-	}
+	m.checkStreamers(m.streamers.All())
+	m.streamers.SaveState()
 
-	m.watcher.Start(ctx)
-	m.dropsTracker.Start(ctx)
+	go m.uptime.Run(ctx)
+	m.lifecycle.Start(ctx)
+
+	m.mu.Lock()
+	m.running = true
+	m.mu.Unlock()
 
 	if m.webServer != nil {
-		if !m.externalAnalytics {
-			m.webServer.Start()
-		}
 		m.webServer.GetStatusBroadcaster().SetStatus(web.StatusRunning, "Mining active")
 	}
-
-	go m.streamCheckLoop(ctx)
 }
 
 func (m *Miner) streamCheckLoop(ctx context.Context) {
@@ -352,34 +303,310 @@ func (m *Miner) streamCheckLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			m.checkAllStreamers()
+			if m.onlineDetection().UsesPolling() {
+				m.checkAllStreamers()
+			}
 			m.mu.Lock()
 			m.nextStreamCheck = time.Now().Add(interval)
 			m.mu.Unlock()
 		case <-m.streamCheckTrigger:
 			m.checkUncheckedStreamers()
+		case <-m.streamCheckResync:
+			m.checkAllStreamers()
+			ticker.Reset(interval)
+			m.mu.Lock()
+			m.nextStreamCheck = time.Now().Add(interval)
+			m.mu.Unlock()
 		}
 	}
 }
 
+// handleClockJump resynchronizes schedules after the wall clock jumped, which
+// usually means the system was suspended: sockets are likely dead and every
+// streamer's status may have changed in the meantime.
+func (m *Miner) handleClockJump(jump time.Duration) {
+	slog.Warn("Clock jump detected, resynchronizing", "jump", jump.Round(time.Second))
+	m.resynchronize()
+}
+
+// handleConnectivityChange resynchronizes once the network is back. While it
+// is down, the watcher, drops sync, job queue and stream checks pause on their
+// own and PubSub errors are not reported.
+func (m *Miner) handleConnectivityChange(online bool, downtime time.Duration) {
+	if online {
+		m.resynchronize()
+	}
+}
+
+// resynchronize reconnects PubSub and immediately re-syncs campaigns and every
+// streamer's status, which may all be stale after sleep or a network outage.
+func (m *Miner) resynchronize() {
+	m.wsPool.Reconnect()
+	m.dropsTracker.Resync()
+
+	select {
+	case m.streamCheckResync <- struct{}{}:
+	default:
+	}
+}
+
+// throttleCheckInterval is how often the error budget is checked for
+// throttling.
+const throttleCheckInterval = time.Minute
+
+// throttleLoop shows a banner while Twitch rate limits the miner or fails its
+// integrity checks. The watcher and the drops tracker slow down on their own
+// through httpx.Slowdown.
+func (m *Miner) throttleLoop(ctx context.Context) {
+	ticker := time.NewTicker(throttleCheckInterval)
+	defer ticker.Stop()
+
+	throttled := false
+	banner := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		budget := httpx.Budget()
+		if budget.Throttled && !throttled {
+			slog.Warn("Twitch is throttling requests, slowing down",
+				"rateLimited", budget.RateLimited,
+				"integrity", budget.Integrity,
+				"errorRate", fmt.Sprintf("%.1f%%", budget.ErrorRate*100),
+			)
+		} else if !budget.Throttled && throttled {
+			slog.Info("Twitch stopped throttling requests, back to normal speed")
+		}
+		throttled = budget.Throttled
+
+		message := ""
+		if throttled {
+			message = fmt.Sprintf("Twitch is throttling requests (%.0f%% of the last %d failed with rate limit or integrity errors). Watching and campaign syncs run at half speed until it recovers.",
+				budget.ErrorRate*100, budget.Requests)
+		}
+		if m.webServer != nil && message != banner {
+			m.webServer.GetStatusBroadcaster().SetThrottleWarning(message)
+		}
+		banner = message
+	}
+}
+
+// gqlFailureThreshold is the number of consecutive failed GQL requests
+// reported as an outage rather than a transient error.
+const gqlFailureThreshold = 5
+
+func (m *Miner) handleGQLFailure(err error, consecutive int) {
+	if errors.Is(err, api.ErrUnauthorized) {
+		m.notifyError(notifications.ErrorKindAuth, "Twitch rejected the auth token and refreshing it failed, so points are no longer being mined. Delete the saved cookies and restart the miner to log in again.")
+		return
+	}
+	if consecutive >= gqlFailureThreshold && m.network.Online() {
+		m.notifyError(notifications.ErrorKindGQL, fmt.Sprintf("%d consecutive Twitch API requests failed.\nLast error: %v", consecutive, err))
+	}
+}
+
+func (m *Miner) handlePubSubError(err error) {
+	switch {
+	case errors.Is(err, pubsub.ErrBadAuth):
+		go m.recoverAuth()
+	case errors.Is(err, pubsub.ErrReconnectFailed):
+		m.notifyError(notifications.ErrorKindPubSub, fmt.Sprintf("A PubSub connection is down and keeps failing to reconnect.\nLast error: %v", err))
+	}
+}
+
+// recoverAuth logs in again after PubSub rejected the auth token and switches
+// the PubSub connections and chat to the new token. If that fails, the
+// dashboard shows a warning until a later recovery succeeds.
+func (m *Miner) recoverAuth() {
+	if !m.authRecovering.CompareAndSwap(false, true) {
+		return
+	}
+	defer m.authRecovering.Store(false)
+
+	var broadcaster *web.StatusBroadcaster
+	if m.webServer != nil {
+		broadcaster = m.webServer.GetStatusBroadcaster()
+	}
+
+	slog.Warn("PubSub rejected the auth token, logging in again")
+	err := m.auth.Relogin()
+	if broadcaster != nil {
+		// The login replaces the status with its own progress.
+		broadcaster.SetStatus(web.StatusRunning, "Mining active")
+	}
+	if err != nil {
+		slog.Error("Failed to recover from rejected auth token", "error", err)
+		if broadcaster != nil {
+			broadcaster.SetWarning("Twitch rejected the auth token and logging in again failed, so points and predictions are not being tracked. Delete the saved cookies and restart the miner.")
+		}
+		m.notifyError(notifications.ErrorKindAuth, fmt.Sprintf("PubSub rejected the auth token and logging in again failed, so points and predictions are no longer being tracked. Delete the saved cookies and restart the miner to log in again.\nError: %v", err))
+		return
+	}
+
+	m.useAuthToken(m.auth.GetAuthToken())
+	if broadcaster != nil {
+		broadcaster.ClearWarning()
+	}
+	slog.Info("Recovered from rejected auth token")
+}
+
+// useAuthToken switches the PubSub connections and chat to a new auth token.
+func (m *Miner) useAuthToken(token string) {
+	m.wsPool.UpdateAuthToken(token)
+	if m.chatManager != nil {
+		m.chatManager.SetAuthToken(token)
+	}
+}
+
+func (m *Miner) handleDatabaseError(err error) {
+	m.notifyError(notifications.ErrorKindDatabase, fmt.Sprintf("Writing analytics data failed.\nError: %v", err))
+}
+
+func (m *Miner) handlePanic(component string, value any, bundle string) {
+	message := fmt.Sprintf("The %s component panicked and was restarted.\nPanic: %v", component, value)
+	if bundle != "" {
+		message += fmt.Sprintf("\nCrash report: %s", bundle)
+	}
+	m.notifyError(notifications.ErrorKindPanic, message)
+}
+
+func (m *Miner) notifyError(kind notifications.ErrorKind, message string) {
+	m.mu.RLock()
+	notifMgr := m.notifications
+	m.mu.RUnlock()
+
+	if notifMgr != nil {
+		notifMgr.NotifyError(kind, message)
+	}
+}
+
 func (m *Miner) checkAllStreamers() {
+	if !m.network.Online() {
+		return
+	}
+
+	m.checkStreamers(m.streamers.All())
+
+	m.streamers.SyncFollows()
+	m.syncFollowedStreamers()
+	m.checkIdleStreamers()
+	m.checkBonusClaims()
+}
+
+// syncFollowedStreamers mines newly followed channels and drops unfollowed
+// ones when follow discovery is on; see streamer.Manager.SyncFollowed.
+func (m *Miner) syncFollowedStreamers() {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	added, removed := m.streamers.SyncFollowed(false)
+	for _, streamer := range added {
+		m.subscribeStreamer(streamer)
+	}
+	for _, streamer := range removed {
+		m.unsubscribeStreamer(streamer)
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		m.refreshStreamers()
+	}
+}
+
+// checkIdleStreamers persists streamer state and evaluates idle streamer rules.
+func (m *Miner) checkIdleStreamers() {
+	m.streamers.SaveState()
+
+	m.mu.RLock()
+	notifMgr := m.notifications
+	m.mu.RUnlock()
+
+	if notifMgr != nil {
+		notifMgr.CheckIdleStreamers(m.streamers.IdleSince())
+	}
+}
+
+// bonusClaimAlertMinutes is how long a live streamer can be watched without a
+// bonus chest being claimed before it is reported. Chests normally appear
+// about every 15 minutes.
+const bonusClaimAlertMinutes = 60
+
+// checkBonusClaims reports live streamers that were watched for
+// bonusClaimAlertMinutes without a bonus chest being claimed, which means
+// watching is broken or the claim-available topic is not delivered.
+func (m *Miner) checkBonusClaims() {
+	var stalled []string
 	for _, s := range m.streamers.All() {
-		m.client.CheckStreamerOnline(s)
-		m.chatManager.ToggleChat(s)
+		if !s.GetIsOnline() || s.GetSettings().DropsOnly {
+			continue
+		}
+		if stats := s.GetSessionStats(); stats.MinutesSinceClaim >= bonusClaimAlertMinutes {
+			stalled = append(stalled, fmt.Sprintf("%s (%d min)", s.GetUsername(), stats.MinutesSinceClaim))
+		}
 	}
+	if len(stalled) == 0 {
+		return
+	}
+
+	m.notifyError(notifications.ErrorKindBonus, fmt.Sprintf(
+		"No bonus chest was claimed in the last %d minutes watched for: %s.\nWatching may be broken or the claim-available PubSub topic is not being received.",
+		bonusClaimAlertMinutes, strings.Join(stalled, ", "),
+	))
 }
 
 func (m *Miner) checkUncheckedStreamers() {
+	if !m.network.Online() {
+		return
+	}
+
 	interval := time.Duration(m.config.RateLimits.StreamCheckInterval) * time.Second
 	now := time.Now()
 
+	var unchecked []*models.Streamer
 	for _, s := range m.streamers.All() {
 		lastChecked := s.GetLastChecked()
 		if lastChecked.IsZero() || now.Sub(lastChecked) >= interval {
-			m.client.CheckStreamerOnline(s)
-			m.chatManager.ToggleChat(s)
+			unchecked = append(unchecked, s)
 		}
 	}
+	m.checkStreamers(unchecked)
+}
+
+// checkStreamers checks whether each streamer is online and toggles its chat,
+// running up to RateLimits.StreamCheckConcurrency checks at once. Each worker
+// waits a jittered RequestDelay between its checks so the requests are spread
+// out rather than sent in bursts.
+func (m *Miner) checkStreamers(streamers []*models.Streamer) {
+	workers := min(max(m.config.RateLimits.StreamCheckConcurrency, 1), len(streamers))
+	delay := time.Duration(m.config.RateLimits.RequestDelay * float64(time.Second))
+
+	queue := make(chan *models.Streamer)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range queue {
+				m.checkStreamer(s)
+				// Between 0.5x and 1.5x the delay.
+				time.Sleep(delay/2 + time.Duration(mathrand.Int63n(int64(delay)+1)))
+			}
+		}()
+	}
+
+	for _, s := range streamers {
+		queue <- s
+	}
+	close(queue)
+	wg.Wait()
+}
+
+func (m *Miner) checkStreamer(s *models.Streamer) {
+	defer crash.Recover("stream check")
+
+	m.client.CheckStreamerOnline(s)
+	m.chatManager.ToggleChat(s)
 }
 
 func (m *Miner) triggerStreamCheck() {
@@ -389,6 +616,329 @@ func (m *Miner) triggerStreamCheck() {
 	}
 }
 
+// subscribeStreamer subscribes a streamer's PubSub topics. Drops-only
+// streamers only get the video playback topic that tracks them going live.
+func (m *Miner) subscribeStreamer(streamer *models.Streamer) {
+	m.mu.RLock()
+	wsPool := m.wsPool
+	m.mu.RUnlock()
+	if wsPool == nil {
+		return
+	}
+
+	channelID := streamer.GetChannelID()
+	if m.onlineDetection().UsesPubSub() {
+		_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicVideoPlaybackByID, channelID))
+	}
+
+	settings := streamer.GetSettings()
+	if settings.DropsOnly {
+		return
+	}
+	if settings.FollowRaid {
+		_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicRaid, channelID))
+	}
+	if settings.MakePredictions {
+		_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicPredictionsChannel, channelID))
+	}
+	if settings.ClaimMoments {
+		_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicCommunityMomentsChannel, channelID))
+	}
+	if settings.CommunityGoals {
+		_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicCommunityPointsChannel, channelID))
+	}
+}
+
+// onlineDetection returns how streamers going live or offline are noticed.
+func (m *Miner) onlineDetection() config.OnlineDetection {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.RateLimits.OnlineDetection
+}
+
+// applyOnlineDetection subscribes or drops the video-playback topic of every
+// streamer after the online detection mode changed.
+func (m *Miner) applyOnlineDetection(wsPool *pubsub.WebSocketPool, mode config.OnlineDetection) {
+	for _, s := range m.streamers.All() {
+		topic := pubsub.NewTopic(pubsub.TopicVideoPlaybackByID, s.GetChannelID())
+		if mode.UsesPubSub() {
+			_ = wsPool.Submit(topic)
+		} else {
+			wsPool.Unsubscribe(topic)
+		}
+	}
+	slog.Info("Online detection changed", "mode", mode, "connections", wsPool.Connections())
+}
+
+// unsubscribeStreamer drops the PubSub topics and chat of a removed streamer.
+func (m *Miner) unsubscribeStreamer(streamer *models.Streamer) {
+	m.mu.RLock()
+	wsPool := m.wsPool
+	chatManager := m.chatManager
+	m.mu.RUnlock()
+
+	if wsPool != nil {
+		channelID := streamer.GetChannelID()
+		wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicVideoPlaybackByID, channelID))
+		wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicRaid, channelID))
+		wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicPredictionsChannel, channelID))
+		wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicCommunityMomentsChannel, channelID))
+		wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicCommunityPointsChannel, channelID))
+	}
+	if chatManager != nil {
+		chatManager.Leave(streamer.GetUsername())
+	}
+}
+
+// refreshStreamers hands the current streamer list to the PubSub pool and web
+// server after streamers were added or removed, and checks them right away.
+func (m *Miner) refreshStreamers() {
+	m.mu.RLock()
+	wsPool := m.wsPool
+	webServer := m.webServer
+	m.mu.RUnlock()
+
+	allStreamers := m.streamers.All()
+	if wsPool != nil {
+		wsPool.UpdateStreamers(allStreamers)
+	}
+	if webServer != nil {
+		webServer.AttachStreamers(allStreamers)
+	}
+	m.triggerStreamCheck()
+}
+
+type streamerSnapshot struct {
+	Username      string `json:"username"`
+	Online        bool   `json:"online"`
+	ChannelPoints int    `json:"channelPoints"`
+}
+
+type minerSnapshot struct {
+	Version   string             `json:"version"`
+	Watching  []string           `json:"watching"`
+	NextDrop  string             `json:"nextDrop,omitempty"`
+	Streamers []streamerSnapshot `json:"streamers"`
+}
+
+// crashSnapshot returns the status written to crash bundles.
+func (m *Miner) crashSnapshot() any {
+	snapshot := minerSnapshot{
+		Version:  version.Version,
+		Watching: m.GetWatchedStreamers(),
+	}
+	if drop, ok := m.GetNextDrop(); ok {
+		snapshot.NextDrop = drop.Name
+	}
+	for _, s := range m.streamers.All() {
+		snapshot.Streamers = append(snapshot.Streamers, streamerSnapshot{
+			Username:      s.GetUsername(),
+			Online:        s.GetIsOnline(),
+			ChannelPoints: s.GetChannelPoints(),
+		})
+	}
+	return snapshot
+}
+
+// diagnosticsStatus is the status included in diagnostics bundles.
+type diagnosticsStatus struct {
+	Online               bool                 `json:"online"`
+	Components           []componentStatus    `json:"components"`
+	UnacknowledgedTopics []pubsub.TopicStatus `json:"unacknowledged_topics"`
+	Miner                any                  `json:"miner"`
+}
+
+// GetDiagnosticsStatus returns the connectivity, component and miner state
+// for diagnostics bundles.
+func (m *Miner) GetDiagnosticsStatus() any {
+	status := diagnosticsStatus{
+		Online:               m.network.Online(),
+		UnacknowledgedTopics: m.unacknowledgedTopics(),
+		Miner:                m.crashSnapshot(),
+	}
+	if m.lifecycle != nil {
+		status.Components = m.lifecycle.Status()
+	}
+	return status
+}
+
+// pubsubDiagnostics is the PubSub subscription state for diagnostics.
+type pubsubDiagnostics struct {
+	Unacknowledged []pubsub.TopicStatus `json:"unacknowledged"`
+	Topics         []pubsub.TopicStats  `json:"topics"`
+}
+
+// GetPubSubDiagnostics returns the PubSub LISTENs Twitch has not acknowledged
+// yet and the LISTEN results of every subscribed topic.
+func (m *Miner) GetPubSubDiagnostics() any {
+	diagnostics := pubsubDiagnostics{
+		Unacknowledged: m.unacknowledgedTopics(),
+		Topics:         []pubsub.TopicStats{},
+	}
+	if m.wsPool != nil {
+		diagnostics.Topics = m.wsPool.TopicStats()
+	}
+	return diagnostics
+}
+
+func (m *Miner) unacknowledgedTopics() []pubsub.TopicStatus {
+	if m.wsPool == nil {
+		return []pubsub.TopicStatus{}
+	}
+	return m.wsPool.UnacknowledgedTopics()
+}
+
+// GetSchemaVersions returns the database migration version of every module.
+func (m *Miner) GetSchemaVersions() (map[string]int, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+	return m.db.SchemaVersions()
+}
+
+// GetDatabaseStatus returns the health of the database file.
+func (m *Miner) GetDatabaseStatus() database.Status {
+	return m.db.Status()
+}
+
+// RecoverDatabase backs up a damaged database file and recreates it from the
+// in-memory fallback.
+func (m *Miner) RecoverDatabase() (database.Status, error) {
+	status, err := m.db.Recover()
+	if err != nil {
+		return status, err
+	}
+	slog.Info("Recreated damaged database, restart the miner to use it", "path", status.Path, "backup", status.Backup)
+	return status, nil
+}
+
+// GetWatchedStreamers returns the streamers currently occupying the watch slots.
+func (m *Miner) GetWatchedStreamers() []string {
+	if m.watcher == nil {
+		return nil
+	}
+	return m.watcher.Watching()
+}
+
+// SetPaused pauses or resumes watching. PubSub, predictions and bonus claims
+// keep running. It ends a yield to manual viewing in progress.
+func (m *Miner) SetPaused(paused bool) {
+	if m.watcher == nil {
+		return
+	}
+	yielding := m.conflicts.stop()
+	if m.watcher.Paused() == paused && !yielding {
+		return
+	}
+
+	message := "Mining active"
+	if paused {
+		message = "Mining paused"
+	}
+	m.setPaused(paused, message)
+}
+
+func (m *Miner) setPaused(paused bool, message string) {
+	m.watcher.SetPaused(paused)
+	slog.Info(message)
+	if m.webServer != nil {
+		m.webServer.GetStatusBroadcaster().SetStatus(web.StatusRunning, message)
+	}
+}
+
+// Paused reports whether watching is paused.
+func (m *Miner) Paused() bool {
+	return m.watcher != nil && m.watcher.Paused()
+}
+
+// GetTotalPoints returns the channel points of all loaded streamers combined.
+func (m *Miner) GetTotalPoints() int {
+	if m.streamers == nil {
+		return 0
+	}
+	total := 0
+	for _, s := range m.streamers.All() {
+		total += s.GetChannelPoints()
+	}
+	return total
+}
+
+// GetChatLogConfig returns which chats are logged and their sample rates.
+func (m *Miner) GetChatLogConfig() ([]chat.ChannelLogConfig, error) {
+	if m.chatManager == nil {
+		return nil, fmt.Errorf("chat is not started")
+	}
+	return m.chatManager.LogConfig(), nil
+}
+
+// SetChatLogConfig changes which chats are logged and their sample rates
+// until the miner restarts.
+func (m *Miner) SetChatLogConfig(update chat.LogConfigUpdate) error {
+	if m.chatManager == nil {
+		return fmt.Errorf("chat is not started")
+	}
+	return m.chatManager.SetLogConfig(update)
+}
+
+// GetNextDrop returns the in-progress drop closest to completion.
+func (m *Miner) GetNextDrop() (*models.Drop, bool) {
+	if m.dropsTracker == nil {
+		return nil, false
+	}
+	return m.dropsTracker.NextDrop()
+}
+
+// GetInventory returns the drops inventory cached by the last campaign sync.
+func (m *Miner) GetInventory() *models.Inventory {
+	if m.dropsTracker == nil {
+		return nil
+	}
+	return m.dropsTracker.Inventory()
+}
+
+// GetDropCampaigns returns the drop campaign progress from the last campaign
+// sync and the time of that sync.
+func (m *Miner) GetDropCampaigns() ([]models.CampaignProgress, time.Time) {
+	if m.dropsTracker == nil {
+		return nil, time.Time{}
+	}
+	return m.dropsTracker.Campaigns()
+}
+
+// GetActivePredictions returns the open predictions the miner is tracking.
+func (m *Miner) GetActivePredictions() []models.PredictionSnapshot {
+	if m.wsPool == nil {
+		return nil
+	}
+	return m.wsPool.ActivePredictions()
+}
+
+// ResolveBetApproval approves or rejects the large bet waiting on a prediction.
+func (m *Miner) ResolveBetApproval(eventID string, approve bool) error {
+	if m.wsPool == nil {
+		return pubsub.ErrNoApprovalPending
+	}
+	return m.wsPool.ResolveBetApproval(eventID, approve)
+}
+
+// OverridePrediction skips the scheduled bet on a prediction or places it
+// right away.
+func (m *Miner) OverridePrediction(eventID string, override models.BetOverride) error {
+	if m.wsPool == nil {
+		return pubsub.ErrBetNotScheduled
+	}
+	return m.wsPool.OverridePrediction(eventID, override)
+}
+
+// PlaceManualBet replaces the scheduled bet on a prediction with the given
+// one and places it right away.
+func (m *Miner) PlaceManualBet(eventID, outcomeID string, amount int) error {
+	if m.wsPool == nil {
+		return pubsub.ErrBetNotScheduled
+	}
+	return m.wsPool.PlaceManualBet(eventID, outcomeID, amount)
+}
+
 func (m *Miner) GetNextStreamCheck() time.Time {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -400,39 +950,55 @@ func (m *Miner) handlePubSubMessage(msg *pubsub.PubSubMessage, s *models.Streame
 	case pubsub.TopicCommunityPointsUser:
 		switch msg.Type {
 		case "points-earned":
-			if data := msg.Data; data != nil {
-				if pointGain, ok := data["point_gain"].(map[string]interface{}); ok {
-					if reasonCode, ok := pointGain["reason_code"].(string); ok {
-						if m.analyticsSvc != nil {
-							m.analyticsSvc.RecordPoints(s, reasonCode)
-
-							if reasonCode == "WATCH_STREAK" {
-								if earned, ok := pointGain["total_points"].(float64); ok {
-									m.analyticsSvc.RecordAnnotation(s, "WATCH_STREAK", fmt.Sprintf("+%d - Watch Streak", int(earned)))
-								}
-							}
-						}
-					}
+			var reasonCode string
+			var earned int
+			if pointGain, ok := msg.Data["point_gain"].(map[string]interface{}); ok {
+				reasonCode, _ = pointGain["reason_code"].(string)
+				if total, ok := pointGain["total_points"].(float64); ok {
+					earned = int(total)
 				}
 			}
 
-			if m.notifications != nil {
-				m.notifications.NotifyPointsReached(s.Username, s.GetChannelPoints())
-			}
+			m.events.publish(Event{
+				Type:     EventPointsEarned,
+				Streamer: s.GetUsername(),
+				Balance:  s.GetChannelPoints(),
+				Gained:   earned,
+				Reason:   reasonCode,
+			})
 		case "points-spent":
+			m.events.publish(Event{
+				Type:     EventPointsSpent,
+				Streamer: s.GetUsername(),
+				Balance:  s.GetChannelPoints(),
+			})
+		case "reward-redeemed":
 			if m.analyticsSvc != nil {
-				m.analyticsSvc.RecordPoints(s, "Spent")
+				if redemption, ok := parseRedemption(msg.Data); ok {
+					m.analyticsSvc.RecordRedemption(s, redemption)
+				}
 			}
 		}
 
 	case pubsub.TopicPredictionsUser:
-		if m.analyticsSvc == nil {
-			return
-		}
 		switch msg.Type {
 		case "prediction-made":
-			m.analyticsSvc.RecordAnnotation(s, "PREDICTION_MADE", "Prediction placed")
+			var placed int
+			if prediction, ok := msg.Data["prediction"].(map[string]interface{}); ok {
+				if points, ok := prediction["points"].(float64); ok {
+					placed = int(points)
+				}
+			}
+			m.events.publish(Event{
+				Type:     EventBetPlaced,
+				Streamer: s.GetUsername(),
+				Balance:  s.GetChannelPoints(),
+				Placed:   placed,
+			})
 		case "prediction-result":
+			if m.analyticsSvc == nil {
+				return
+			}
 			if data := msg.Data; data != nil {
 				if prediction, ok := data["prediction"].(map[string]interface{}); ok {
 					if result, ok := prediction["result"].(map[string]interface{}); ok {
@@ -446,35 +1012,160 @@ func (m *Miner) handlePubSubMessage(msg *pubsub.PubSubMessage, s *models.Streame
 	}
 }
 
-func (m *Miner) handleStatusChange(username string, online bool) {
-	if m.notifications == nil {
+func (m *Miner) handlePredictionResult(event *models.EventPrediction, placed, won, gained int) {
+	if m.analyticsSvc != nil {
+		m.analyticsSvc.RecordPredictionResult(event, placed, won, gained)
+	}
+	m.events.publish(Event{
+		Type:     EventPredictionResult,
+		Streamer: event.Streamer.GetUsername(),
+		Balance:  event.Streamer.GetChannelPoints(),
+		Gained:   gained,
+		Reason:   string(event.Result.Type),
+	})
+}
+
+func (m *Miner) handlePredictionSkip(event *models.EventPrediction, reason string) {
+	if m.analyticsSvc != nil {
+		m.analyticsSvc.RecordAnnotation(event.Streamer, "PREDICTION_SKIP", fmt.Sprintf("Prediction skipped: %s", reason))
+	}
+}
+
+// handlePredictionLate records a bet that arrived after the lock and, if the
+// streamer has an auto delay step, moves its bets that much earlier.
+func (m *Miner) handlePredictionLate(event *models.EventPrediction, reason string) {
+	if m.analyticsSvc != nil {
+		m.analyticsSvc.RecordAnnotation(event.Streamer, "PREDICTION_LATE", fmt.Sprintf("Bet too late: %s", reason))
+	}
+
+	bet := event.Streamer.GetSettings().Bet
+	if bet.AutoDelayStep <= 0 {
+		return
+	}
+	delay := bet.ShiftedDelay(-bet.AutoDelayStep, event.OpenWindowSeconds)
+	if delay == bet.Delay {
 		return
 	}
 
-	if online {
-		m.notifications.NotifyOnline(username)
-	} else {
-		m.notifications.NotifyOffline(username)
+	username := event.Streamer.GetUsername()
+	update := settings.BulkStreamerUpdate{
+		Streamers: []string{username},
+		Settings:  settings.StreamerSettingsConfig{Bet: &settings.BetSettingsJSON{Delay: &delay}},
+	}
+	if _, err := m.BulkUpdateStreamerSettings(update); err != nil {
+		slog.Error("Failed to adjust bet delay", "streamer", username, "error", err)
+		return
 	}
+	slog.Info("Adjusted bet delay after late bet",
+		"streamer", username,
+		"mode", bet.DelayMode,
+		"from", bet.Delay,
+		"to", delay,
+	)
 }
 
-func (m *Miner) stop() {
-	m.chatManager.Close()
-	m.wsPool.Close()
-	m.watcher.Stop()
-	m.dropsTracker.Stop()
+// handleBetApproval asks on Discord whether to place a bet over the
+// confirmation threshold. It can also be answered from the dashboard.
+func (m *Miner) handleBetApproval(event *models.EventPrediction, amount int, deadline time.Time) {
+	m.mu.RLock()
+	notifMgr := m.notifications
+	threshold := m.config.BetConfirm.Threshold
+	m.mu.RUnlock()
 
-	if m.webServer != nil {
-		m.webServer.Stop()
+	if notifMgr == nil {
+		return
+	}
+
+	snapshot := event.Snapshot(event.Streamer.GetChannelPoints())
+	outcome := ""
+	if choice := snapshot.Decision.Choice; choice >= 0 && choice < len(snapshot.Outcomes) {
+		outcome = snapshot.Outcomes[choice].Title
+	}
+
+	req := notifications.BetApprovalRequest{
+		Streamer:  event.Streamer.GetUsername(),
+		Title:     event.Title,
+		Outcome:   outcome,
+		Amount:    amount,
+		Balance:   event.Streamer.GetChannelPoints(),
+		Threshold: threshold,
+		Deadline:  deadline,
+	}
+	notifMgr.RequestBetApproval(req, func(approve bool) {
+		if err := m.ResolveBetApproval(event.EventID, approve); err != nil {
+			slog.Warn("Bet approval from Discord ignored", "event", event.Title, "error", err)
+		}
+	})
+}
+
+// handlePredictionOpened pushes a newly scheduled prediction to the
+// dashboard, so its bet can be skipped or forced before it is due.
+func (m *Miner) handlePredictionOpened(event *models.EventPrediction) {
+	if m.webServer == nil {
+		return
+	}
+	m.webServer.PublishPrediction(event.Snapshot(event.Streamer.GetChannelPoints()))
+}
+
+func (m *Miner) handlePredictionTiming(streamer *models.Streamer, timing models.BetTiming) {
+	if m.analyticsSvc != nil {
+		m.analyticsSvc.RecordBetTiming(streamer, timing)
 	}
+}
 
+// handleWatch runs after each minute-watched round so watch-streak progress
+// survives a restart mid-stream.
+func (m *Miner) handleWatch(streamers []string) {
 	if m.analyticsSvc != nil {
-		_ = m.analyticsSvc.Close()
+		watched := make([]*models.Streamer, 0, len(streamers))
+		for _, name := range streamers {
+			if s := m.streamers.Get(name); s != nil {
+				watched = append(watched, s)
+			}
+		}
+		m.analyticsSvc.RecordWatchSlots(watched)
 	}
+	m.conflicts.watching(streamers, time.Now())
+	m.streamers.SaveState()
+	m.events.publish(Event{Type: EventWatching, Watching: append([]string(nil), streamers...)})
+}
 
-	if m.notifications != nil {
-		m.notifications.Stop()
+func (m *Miner) handleHeartbeat(streamer string, err error) {
+	if m.analyticsSvc != nil {
+		m.analyticsSvc.RecordHeartbeat(streamer, err == nil)
+	}
+}
+
+func (m *Miner) handleStatusChange(username string, online bool) {
+	m.streamers.SaveState()
+
+	event := Event{Type: EventStreamerOffline, Streamer: username}
+	if online {
+		event.Type = EventStreamerOnline
+	}
+	if s := m.streamers.Get(username); s != nil {
+		event.Balance = s.GetChannelPoints()
 	}
+	m.events.publish(event)
+}
+
+func (m *Miner) handleMention(streamer, fromUser, message string) {
+	m.events.publish(Event{
+		Type:     EventMentionReceived,
+		Streamer: streamer,
+		From:     fromUser,
+		Message:  message,
+	})
+}
+
+func (m *Miner) stop() {
+	m.mu.Lock()
+	m.running = false
+	m.mu.Unlock()
+
+	m.streamers.SaveState()
+	m.lifecycle.Stop()
+	m.uptime.Stop(uptime.ReasonShutdown)
 
 	if m.db != nil {
 		_ = m.db.Close()
@@ -483,6 +1174,25 @@ func (m *Miner) stop() {
 	m.streamers.PrintReport()
 }
 
+// GetUptime returns the uptime of this run and the recent restart history.
+func (m *Miner) GetUptime() (*uptime.Summary, error) {
+	return m.uptime.Summary()
+}
+
+// Running reports whether mining has started and not yet stopped.
+func (m *Miner) Running() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.running
+}
+
+// GetConfig returns a copy of the current configuration.
+func (m *Miner) GetConfig() config.Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return *m.config
+}
+
 func (m *Miner) GetRuntimeSettings() settings.RuntimeSettings {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -497,73 +1207,166 @@ func (m *Miner) GetDefaultSettings() settings.RuntimeSettings {
 }
 
 func (m *Miner) ApplySettings(s settings.RuntimeSettings) {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+	m.applySettings(s)
+}
+
+// BulkUpdateStreamerSettings merges a partial settings override into the
+// selected streamers and applies and persists the result as one settings update.
+func (m *Miner) BulkUpdateStreamerSettings(update settings.BulkStreamerUpdate) ([]string, error) {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	s := m.GetRuntimeSettings()
+	updated, err := settings.ApplyBulkStreamerUpdate(&s, update)
+	if err != nil {
+		return nil, err
+	}
+
+	m.applySettings(s)
+	slog.Info("Bulk updated streamer settings", "count", len(updated))
+	return updated, nil
+}
+
+// ImportStreamers adds the streamers of a pasted list or CSV file that exist on
+// Twitch and are not configured yet.
+func (m *Miner) ImportStreamers(text string) (settings.StreamerImportResult, error) {
+	usernames, invalid, withPreset := settings.ParseStreamerList(text)
+	result := settings.StreamerImportResult{
+		Invalid:        invalid,
+		PresetsIgnored: withPreset,
+	}
+	if len(usernames) == 0 {
+		return result, nil
+	}
+
+	// Resolve logins before taking applyMu so settings saves are not blocked on GQL.
+	ids, failed, err := m.client.GetChannelIDs(usernames)
+	if err != nil {
+		return result, fmt.Errorf("failed to look up streamers: %w", err)
+	}
+
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	s := m.GetRuntimeSettings()
+	configured := make(map[string]bool, len(s.Streamers))
+	for _, sc := range s.Streamers {
+		configured[strings.ToLower(sc.Username)] = true
+	}
+
+	lookupFailed := make(map[string]bool, len(failed))
+	for _, username := range failed {
+		lookupFailed[username] = true
+	}
+
+	for _, username := range usernames {
+		switch {
+		case configured[username]:
+			result.Existing = append(result.Existing, username)
+		case lookupFailed[username]:
+			result.Failed = append(result.Failed, username)
+		case ids[username] == "":
+			result.NotFound = append(result.NotFound, username)
+		default:
+			result.Added = append(result.Added, username)
+			s.Streamers = append(s.Streamers, settings.StreamerConfig{Username: username})
+		}
+	}
+
+	if len(result.Added) > 0 {
+		m.applySettings(s)
+	}
+	slog.Info("Imported streamer list",
+		"added", len(result.Added),
+		"existing", len(result.Existing),
+		"notFound", len(result.NotFound),
+		"failed", len(result.Failed),
+		"invalid", len(result.Invalid),
+	)
+	return result, nil
+}
+
+func (m *Miner) applySettings(s settings.RuntimeSettings) {
 	m.mu.Lock()
 
 	oldDiscordEnabled := m.config.Discord.Enabled
+	oldDesktopEnabled := m.config.Desktop.Enabled
+	oldOnlineDetection := m.config.RateLimits.OnlineDetection
+	oldFollows := m.config.Follows
 	settings.ApplyToConfig(m.config, s)
+	m.features.Set(m.config.FeatureFlags)
+	util.SetLocale(m.config.Locale)
+	if m.client != nil {
+		m.client.SetPointsFloor(m.config.PointsFloor)
+	}
 
 	if m.watcher != nil {
 		m.watcher.UpdateSettings(m.config.Priority, m.config.RateLimits)
+		m.watcher.SetWatchMode(m.config.WatchMode)
+		m.watcher.SetWatchNonEarning(m.config.WatchNonEarning)
+		m.watcher.SetHLSMode(m.config.HLSMode)
 	}
 
 	added, removed := m.streamers.ApplySettings(m.config.Streamers, m.config.StreamerSettings)
+	m.streamers.SetFollowDiscovery(m.config.Follows)
+	followsChanged := !reflect.DeepEqual(oldFollows, m.config.Follows)
 
 	discordCfg := m.config.Discord
+	desktopCfg := m.config.Desktop
+	raidDenylist := m.config.RaidDenylist
+	betConfirm := m.config.BetConfirm
+	yieldEnabled := m.config.Yield.Enabled
+	chatIgnore := m.config.ChatIgnore
+	chatMentions := m.config.ChatMentions
+	chatManager := m.chatManager
 	notifMgr := m.notifications
 	webServer := m.webServer
 	wsPool := m.wsPool
+	onlineDetection := m.config.RateLimits.OnlineDetection
 
 	m.mu.Unlock()
 
-	for _, streamer := range added {
-		if wsPool != nil {
-			_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicVideoPlaybackByID, streamer.ChannelID))
-
-			if streamer.Settings.FollowRaid {
-				_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicRaid, streamer.ChannelID))
-			}
-			if streamer.Settings.MakePredictions {
-				_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicPredictionsChannel, streamer.ChannelID))
-			}
-			if streamer.Settings.ClaimMoments {
-				_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicCommunityMomentsChannel, streamer.ChannelID))
-			}
-			if streamer.Settings.CommunityGoals {
-				_ = wsPool.Submit(pubsub.NewTopic(pubsub.TopicCommunityPointsChannel, streamer.ChannelID))
-			}
+	if wsPool != nil {
+		wsPool.SetRaidDenylist(raidDenylist)
+		wsPool.SetBetConfirm(betConfirm)
+		if onlineDetection != oldOnlineDetection {
+			m.applyOnlineDetection(wsPool, onlineDetection)
 		}
 	}
+	if !yieldEnabled && m.watcher != nil && m.conflicts.stop() {
+		m.setPaused(false, "Mining active")
+	}
+	if chatManager != nil {
+		chatManager.SetIgnoreRules(chatIgnore.Users, chatIgnore.Prefixes)
+		chatManager.SetMentionRules(chatMentions.WholeWord, chatMentions.RequireAt,
+			time.Duration(chatMentions.DedupeWindow)*time.Second)
+	}
 
+	for _, streamer := range added {
+		m.subscribeStreamer(streamer)
+	}
 	for _, streamer := range removed {
-		if wsPool != nil {
-			wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicVideoPlaybackByID, streamer.ChannelID))
-			wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicRaid, streamer.ChannelID))
-			wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicPredictionsChannel, streamer.ChannelID))
-			wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicCommunityMomentsChannel, streamer.ChannelID))
-			wsPool.Unsubscribe(pubsub.NewTopic(pubsub.TopicCommunityPointsChannel, streamer.ChannelID))
-		}
-		if m.chatManager != nil {
-			m.chatManager.Leave(streamer.Username)
-		}
+		m.unsubscribeStreamer(streamer)
 	}
-
 	if len(added) > 0 || len(removed) > 0 {
-		allStreamers := m.streamers.All()
-		if wsPool != nil {
-			wsPool.UpdateStreamers(allStreamers)
-		}
-		if webServer != nil {
-			webServer.AttachStreamers(allStreamers)
-		}
-		m.triggerStreamCheck()
+		m.refreshStreamers()
+	}
+	if followsChanged {
+		// applyMu is held by the caller until the settings are saved.
+		go m.syncFollowedStreamers()
 	}
 
 	if notifMgr != nil {
 		if err := notifMgr.UpdateDiscordConfig(&discordCfg); err != nil {
 			slog.Error("Failed to update Discord config", "error", err)
 		}
-	} else if discordCfg.Enabled && !oldDiscordEnabled {
-		newNotifMgr, err := notifications.NewManager(&discordCfg, m.db, m.streamers.Names())
+		if err := notifMgr.UpdateDesktopConfig(&desktopCfg); err != nil {
+			slog.Error("Failed to update desktop notification config", "error", err)
+		}
+	} else if (discordCfg.Enabled && !oldDiscordEnabled) || (desktopCfg.Enabled && !oldDesktopEnabled) {
+		newNotifMgr, err := notifications.NewManager(&discordCfg, &desktopCfg, m.db, m.streamers.Names())
 		if err != nil {
 			slog.Error("Failed to create notification manager", "error", err)
 		} else {
@@ -572,6 +1375,9 @@ func (m *Miner) ApplySettings(s settings.RuntimeSettings) {
 			m.mu.Unlock()
 
 			newNotifMgr.InitializePointsTracking(m.streamers.PointsMap())
+			if m.analyticsSvc != nil {
+				newNotifMgr.SetPredictionSummarySource(m.analyticsSvc)
+			}
 
 			if err := newNotifMgr.Start(context.Background()); err != nil {
 				slog.Error("Failed to start notification manager", "error", err)
@@ -587,7 +1393,16 @@ func (m *Miner) ApplySettings(s settings.RuntimeSettings) {
 		webServer.SetDiscordEnabled(discordCfg.Enabled)
 	}
 
+	m.saveConfig()
+
+	slog.Info("Runtime settings updated")
+	m.events.publish(Event{Type: EventSettingsApplied})
+}
+
+func (m *Miner) saveConfig() {
 	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.configPath != "" {
 		if err := config.SaveConfig(m.configPath, m.config); err != nil {
 			slog.Error("Failed to save config", "error", err)
@@ -595,7 +1410,106 @@ func (m *Miner) ApplySettings(s settings.RuntimeSettings) {
 			slog.Info("Settings saved to config file")
 		}
 	}
-	m.mu.Unlock()
+}
 
-	slog.Info("Runtime settings updated")
+func (m *Miner) registerJobHandlers() {
+	m.jobs.Register(jobs.KindClaimBonus, func(raw json.RawMessage) error {
+		var p jobs.ClaimBonusPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return jobs.Permanent(err)
+		}
+		s, err := m.jobStreamer(p.Streamer)
+		if err != nil {
+			return err
+		}
+		return m.client.ClaimBonus(s, p.ClaimID)
+	})
+
+	m.jobs.Register(jobs.KindClaimMoment, func(raw json.RawMessage) error {
+		var p jobs.ClaimMomentPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return jobs.Permanent(err)
+		}
+		s, err := m.jobStreamer(p.Streamer)
+		if err != nil {
+			return err
+		}
+		return m.client.ClaimMoment(s, p.MomentID)
+	})
+
+	m.jobs.Register(jobs.KindClaimDrop, func(raw json.RawMessage) error {
+		var p jobs.ClaimDropPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return jobs.Permanent(err)
+		}
+		claimed, err := m.client.ClaimDrop(&models.Drop{Name: p.Name, DropInstanceID: p.DropInstanceID})
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return jobs.Permanent(fmt.Errorf("drop %q was not claimable", p.Name))
+		}
+		slog.Info("Claimed drop", "drop", p.Name)
+		if m.dropsTracker != nil {
+			m.dropsTracker.MarkClaimed(p.DropInstanceID)
+		}
+		m.events.publish(Event{Type: EventDropClaimed, Drop: p.Name})
+		return nil
+	})
+
+	m.jobs.Register(jobs.KindContributeGoal, func(raw json.RawMessage) error {
+		var p jobs.ContributeGoalPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return jobs.Permanent(err)
+		}
+		s, err := m.jobStreamer(p.Streamer)
+		if err != nil {
+			return err
+		}
+		err = m.client.ContributeToCommunityGoal(s, p.GoalID, p.Title, p.Amount, p.TransactionID)
+		if errors.Is(err, api.ErrContributionRejected) || errors.Is(err, api.ErrPointsFloor) {
+			return jobs.Permanent(err)
+		}
+		return err
+	})
+}
+
+// jobStreamer resolves the streamer a queued job refers to.
+func (m *Miner) jobStreamer(username string) (*models.Streamer, error) {
+	s := m.streamers.Get(username)
+	if s == nil {
+		return nil, jobs.Permanent(fmt.Errorf("streamer %s is no longer tracked", username))
+	}
+	return s, nil
+}
+
+// parseRedemption extracts the reward metadata from a reward-redeemed message.
+func parseRedemption(data map[string]interface{}) (analytics.Redemption, bool) {
+	var redemption analytics.Redemption
+	if data == nil {
+		return redemption, false
+	}
+	raw, ok := data["redemption"].(map[string]interface{})
+	if !ok {
+		return redemption, false
+	}
+	reward, ok := raw["reward"].(map[string]interface{})
+	if !ok {
+		return redemption, false
+	}
+
+	redemption.ID, _ = raw["id"].(string)
+	redemption.UserInput, _ = raw["user_input"].(string)
+	redemption.RewardID, _ = reward["id"].(string)
+	redemption.Title, _ = reward["title"].(string)
+	if cost, ok := reward["cost"].(float64); ok {
+		redemption.Cost = int(cost)
+	}
+	if redeemedAt, ok := raw["redeemed_at"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, redeemedAt); err == nil {
+			redemption.Timestamp = t.UnixMilli()
+		}
+	}
+
+	return redemption, redemption.ID != ""
 }
@@ -0,0 +1,120 @@
+package miner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+)
+
+// activeSession is a stream in progress. startMinutes is the streamer's
+// session minutes watched when it went live.
+type activeSession struct {
+	analytics.StreamSession
+	startMinutes int
+}
+
+// streamSessions collects per-stream statistics between a streamer going live
+// and going offline.
+type streamSessions struct {
+	active map[string]*activeSession
+	mu     sync.Mutex
+}
+
+func (t *streamSessions) start(streamer string, at time.Time, balance, minutes int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.active == nil {
+		t.active = make(map[string]*activeSession)
+	}
+	t.active[streamer] = &activeSession{
+		StreamSession: analytics.StreamSession{
+			Streamer:    streamer,
+			StartedAt:   at,
+			PointsStart: balance,
+		},
+		startMinutes: minutes,
+	}
+}
+
+// update applies fn to the streamer's stream in progress, if there is one.
+func (t *streamSessions) update(streamer string, fn func(*activeSession)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if session, ok := t.active[streamer]; ok {
+		fn(session)
+	}
+}
+
+// end finishes the streamer's stream in progress and returns its summary, or
+// nil if the miner did not see it go live.
+func (t *streamSessions) end(streamer string, at time.Time, balance, minutes int) *analytics.StreamSession {
+	t.mu.Lock()
+	session, ok := t.active[streamer]
+	delete(t.active, streamer)
+	t.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	summary := session.StreamSession
+	summary.EndedAt = at
+	summary.PointsEnd = balance
+	summary.Gained = balance - summary.PointsStart
+	summary.MinutesWatched = minutes - session.startMinutes
+	return &summary
+}
+
+// trackStreamSession updates the stream statistics from an event. When a
+// stream ends its summary is stored and, if the streamer has streamSummary
+// enabled, posted to Discord.
+func (m *Miner) trackStreamSession(event Event) {
+	switch event.Type {
+	case EventStreamerOnline, EventStreamerOffline, EventPointsEarned, EventPredictionResult:
+	default:
+		return
+	}
+	s := m.streamers.Get(event.Streamer)
+	if s == nil {
+		return
+	}
+
+	switch event.Type {
+	case EventStreamerOnline:
+		m.sessions.start(event.Streamer, event.Time, event.Balance, s.GetSessionStats().MinutesWatched)
+	case EventPointsEarned:
+		if event.Reason == "WATCH_STREAK" {
+			m.sessions.update(event.Streamer, func(session *activeSession) {
+				session.WatchStreak += event.Gained
+			})
+		}
+	case EventPredictionResult:
+		m.sessions.update(event.Streamer, func(session *activeSession) {
+			switch event.Reason {
+			case "WIN":
+				session.PredictionsWon++
+			case "LOSE":
+				session.PredictionsLost++
+			case "REFUND":
+				session.PredictionsRefunded++
+			}
+			session.PredictionNet += event.Gained
+		})
+	case EventStreamerOffline:
+		summary := m.sessions.end(event.Streamer, event.Time, event.Balance, s.GetSessionStats().MinutesWatched)
+		if summary == nil {
+			return
+		}
+		if m.analyticsSvc != nil {
+			m.analyticsSvc.RecordStreamSession(*summary)
+		}
+		m.mu.RLock()
+		notifMgr := m.notifications
+		m.mu.RUnlock()
+		if notifMgr != nil && s.GetSettings().StreamSummary {
+			notifMgr.NotifyStreamSummary(*summary)
+		}
+	}
+}
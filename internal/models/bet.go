@@ -3,6 +3,7 @@ package models
 import (
 	"math"
 	"math/rand"
+	"slices"
 )
 
 type Strategy string
@@ -23,6 +24,18 @@ const (
 	StrategyNumber8    Strategy = "NUMBER_8"
 )
 
+// Strategies lists every bet strategy.
+var Strategies = []Strategy{
+	StrategyMostVoted, StrategyHighOdds, StrategyPercentage, StrategySmartMoney, StrategySmart,
+	StrategyNumber1, StrategyNumber2, StrategyNumber3, StrategyNumber4,
+	StrategyNumber5, StrategyNumber6, StrategyNumber7, StrategyNumber8,
+}
+
+// Valid reports whether s is one of Strategies.
+func (s Strategy) Valid() bool {
+	return slices.Contains(Strategies, s)
+}
+
 type Condition string
 
 const (
@@ -69,6 +82,35 @@ type BetSettings struct {
 	FilterCondition *FilterCondition `json:"filterCondition,omitempty"`
 	Delay           float64          `json:"delay"`
 	DelayMode       DelayMode        `json:"delayMode"`
+	// AutoDelayStep is how many seconds earlier bets are placed after one is
+	// rejected for arriving after the lock. 0 disables the adjustment.
+	AutoDelayStep float64 `json:"autoDelayStep,omitempty"`
+	// Seed makes the random choices of stealth mode reproducible, for
+	// debugging a bet. 0 uses the shared random source.
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// ShiftedDelay returns the delay that places bets the given number of seconds
+// later, or earlier for negative values. window is the prediction window in
+// seconds; it converts seconds to a PERCENTAGE delay and caps FROM_END.
+func (b BetSettings) ShiftedDelay(seconds, window float64) float64 {
+	switch b.DelayMode {
+	case DelayModeFromStart:
+		return math.Max(0, b.Delay+seconds)
+	case DelayModeFromEnd:
+		delay := math.Max(0, b.Delay-seconds)
+		if window > 0 {
+			delay = math.Min(delay, window)
+		}
+		return delay
+	case DelayModePercentage:
+		if window <= 0 {
+			return b.Delay
+		}
+		return roundFloat(math.Min(1, math.Max(0, b.Delay+seconds/window)), 2)
+	default:
+		return b.Delay
+	}
 }
 
 func DefaultBetSettings() BetSettings {
@@ -130,6 +172,10 @@ type Bet struct {
 	TotalUsers  int
 	TotalPoints int
 	Settings    BetSettings
+
+	// rng is the source of the bet's random choices; nil uses the shared
+	// source of math/rand.
+	rng *rand.Rand
 }
 
 func NewBet(outcomes []interface{}, settings BetSettings) *Bet {
@@ -137,6 +183,9 @@ func NewBet(outcomes []interface{}, settings BetSettings) *Bet {
 		Outcomes: make([]*Outcome, 0),
 		Settings: settings,
 	}
+	if settings.Seed != 0 {
+		bet.rng = rand.New(rand.NewSource(settings.Seed))
+	}
 
 	for _, o := range outcomes {
 		if oData, ok := o.(map[string]interface{}); ok {
@@ -147,6 +196,19 @@ func NewBet(outcomes []interface{}, settings BetSettings) *Bet {
 	return bet
 }
 
+// SetRand replaces the source of the bet's random choices, so a calculation
+// can be repeated exactly. A nil source restores the shared one.
+func (b *Bet) SetRand(rng *rand.Rand) {
+	b.rng = rng
+}
+
+func (b *Bet) float64() float64 {
+	if b.rng != nil {
+		return b.rng.Float64()
+	}
+	return rand.Float64()
+}
+
 func (b *Bet) UpdateOutcomes(outcomes []interface{}) {
 	for i, o := range outcomes {
 		if i >= len(b.Outcomes) {
@@ -336,8 +398,8 @@ func (b *Bet) Calculate(balance int) Decision {
 		}
 
 		if b.Settings.StealthMode && amount >= b.Outcomes[b.Decision.Choice].TopPoints {
-			reduceAmount := rand.Float64()*4 + 1
-			amount = b.Outcomes[b.Decision.Choice].TopPoints - int(reduceAmount)
+			reduceAmount := b.float64()*4 + 1
+			amount = max(0, b.Outcomes[b.Decision.Choice].TopPoints-int(reduceAmount))
 		}
 
 		b.Decision.Amount = amount
@@ -0,0 +1,266 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// outcomeData builds the GQL shape of an outcome with the given totals and
+// top predictor bet.
+func outcomeData(id string, users, points, top int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           id,
+		"title":        id,
+		"total_users":  float64(users),
+		"total_points": float64(points),
+		"top_predictors": []interface{}{
+			map[string]interface{}{"points": float64(top)},
+		},
+	}
+}
+
+// newTestBet returns a bet on the outcomes with their odds calculated.
+func newTestBet(settings BetSettings, outcomes ...map[string]interface{}) *Bet {
+	data := make([]interface{}, len(outcomes))
+	for i, o := range outcomes {
+		data[i] = o
+	}
+	bet := NewBet(data, settings)
+	bet.UpdateOutcomes(data)
+	return bet
+}
+
+func settingsWith(strategy Strategy) BetSettings {
+	settings := DefaultBetSettings()
+	settings.Strategy = strategy
+	return settings
+}
+
+func TestCalculateChoice(t *testing.T) {
+	// Blue has more users, pink more points and the bigger top bet.
+	blue := outcomeData("blue", 80, 1000, 100)
+	pink := outcomeData("pink", 20, 4000, 900)
+
+	tests := []struct {
+		strategy Strategy
+		want     int
+	}{
+		{StrategyMostVoted, 0},
+		{StrategyHighOdds, 0},
+		{StrategyPercentage, 1},
+		{StrategySmartMoney, 1},
+		{StrategySmart, 0},
+		{StrategyNumber1, 0},
+		{StrategyNumber2, 1},
+		{StrategyNumber3, 0},
+		{StrategyNumber8, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			bet := newTestBet(settingsWith(tt.strategy), blue, pink)
+			decision := bet.Calculate(10000)
+			if decision.Choice != tt.want {
+				t.Fatalf("choice = %d, want %d", decision.Choice, tt.want)
+			}
+			if decision.ID != bet.Outcomes[tt.want].ID {
+				t.Fatalf("id = %q, want %q", decision.ID, bet.Outcomes[tt.want].ID)
+			}
+		})
+	}
+}
+
+func TestCalculateSmartGap(t *testing.T) {
+	// A 10 point user split is within the default 20 point gap, so SMART
+	// bets on the higher odds instead of the majority.
+	bet := newTestBet(settingsWith(StrategySmart),
+		outcomeData("blue", 55, 5000, 0),
+		outcomeData("pink", 45, 1000, 0),
+	)
+	if choice := bet.Calculate(10000).Choice; choice != 1 {
+		t.Fatalf("choice = %d, want 1", choice)
+	}
+}
+
+func TestCalculateAmount(t *testing.T) {
+	tests := []struct {
+		name      string
+		balance   int
+		percent   int
+		maxPoints int
+		want      int
+	}{
+		{"percentage of balance", 10000, 5, 50000, 500},
+		{"capped by max points", 10000000, 5, 50000, 50000},
+		{"zero balance", 0, 5, 50000, 0},
+		{"rounds down", 99, 5, 50000, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := settingsWith(StrategyMostVoted)
+			settings.Percentage = tt.percent
+			settings.MaxPoints = tt.maxPoints
+			bet := newTestBet(settings, outcomeData("blue", 10, 100, 0), outcomeData("pink", 5, 100, 0))
+			if amount := bet.Calculate(tt.balance).Amount; amount != tt.want {
+				t.Fatalf("amount = %d, want %d", amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateWithoutOutcomes(t *testing.T) {
+	for _, strategy := range []Strategy{StrategySmart, StrategyMostVoted, StrategyNumber1} {
+		bet := newTestBet(settingsWith(strategy))
+		decision := bet.Calculate(10000)
+		if decision.Amount != 0 || decision.ID != "" {
+			t.Fatalf("%s: decision = %+v, want no bet", strategy, decision)
+		}
+		if bet.GetDecision() != nil {
+			t.Fatalf("%s: GetDecision returned an outcome", strategy)
+		}
+	}
+}
+
+func TestStealthModeStaysBelowTopBet(t *testing.T) {
+	settings := settingsWith(StrategyMostVoted)
+	settings.StealthMode = true
+	bet := newTestBet(settings, outcomeData("blue", 10, 1000, 300), outcomeData("pink", 5, 1000, 0))
+
+	for seed := int64(1); seed <= 100; seed++ {
+		bet.SetRand(rand.New(rand.NewSource(seed)))
+		amount := bet.Calculate(100000).Amount
+		if amount < 296 || amount > 299 {
+			t.Fatalf("seed %d: amount = %d, want 296-299", seed, amount)
+		}
+	}
+}
+
+func TestStealthModeNeverNegative(t *testing.T) {
+	settings := settingsWith(StrategyMostVoted)
+	settings.StealthMode = true
+	settings.Seed = 1
+	bet := newTestBet(settings, outcomeData("blue", 10, 1000, 0), outcomeData("pink", 5, 1000, 0))
+
+	if amount := bet.Calculate(10000).Amount; amount != 0 {
+		t.Fatalf("amount = %d, want 0", amount)
+	}
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	settings := settingsWith(StrategyMostVoted)
+	settings.StealthMode = true
+	settings.Seed = 42
+	outcomes := []map[string]interface{}{outcomeData("blue", 10, 1000, 5000), outcomeData("pink", 5, 1000, 0)}
+
+	first, second := newTestBet(settings, outcomes...), newTestBet(settings, outcomes...)
+	for i := 0; i < 20; i++ {
+		a, b := first.Calculate(1000000).Amount, second.Calculate(1000000).Amount
+		if a != b {
+			t.Fatalf("calculation %d: %d != %d with the same seed", i, a, b)
+		}
+	}
+}
+
+// TestCalculateProperties checks invariants that hold for every strategy over
+// random predictions generated from a fixed seed.
+func TestCalculateProperties(t *testing.T) {
+	strategies := []Strategy{
+		StrategyMostVoted, StrategyHighOdds, StrategyPercentage, StrategySmartMoney, StrategySmart,
+		StrategyNumber1, StrategyNumber2, StrategyNumber3, StrategyNumber4,
+		StrategyNumber5, StrategyNumber6, StrategyNumber7, StrategyNumber8,
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		n := 2 + rng.Intn(9)
+		outcomes := make([]map[string]interface{}, n)
+		for j := range outcomes {
+			outcomes[j] = outcomeData(string(rune('a'+j)), rng.Intn(500), rng.Intn(100000), rng.Intn(20000))
+		}
+
+		settings := settingsWith(strategies[rng.Intn(len(strategies))])
+		settings.Percentage = rng.Intn(101)
+		settings.PercentageGap = rng.Intn(101)
+		settings.MaxPoints = rng.Intn(250001)
+		settings.StealthMode = rng.Intn(2) == 0
+		settings.Seed = rng.Int63()
+		balance := rng.Intn(1000000)
+
+		bet := newTestBet(settings, outcomes...)
+		decision := bet.Calculate(balance)
+
+		if decision.Choice < 0 || decision.Choice >= n {
+			t.Fatalf("case %d (%s): choice %d out of range for %d outcomes", i, settings.Strategy, decision.Choice, n)
+		}
+		if decision.ID != bet.Outcomes[decision.Choice].ID {
+			t.Fatalf("case %d: id %q does not match choice %d", i, decision.ID, decision.Choice)
+		}
+		if decision.Amount < 0 {
+			t.Fatalf("case %d: negative amount %d", i, decision.Amount)
+		}
+		if decision.Amount > settings.MaxPoints {
+			t.Fatalf("case %d: amount %d above max points %d", i, decision.Amount, settings.MaxPoints)
+		}
+		if limit := balance * settings.Percentage / 100; decision.Amount > limit {
+			t.Fatalf("case %d: amount %d above %d%% of %d", i, decision.Amount, settings.Percentage, balance)
+		}
+		if top := bet.Outcomes[decision.Choice].TopPoints; settings.StealthMode && top > 0 && decision.Amount >= top {
+			t.Fatalf("case %d: stealth amount %d not below top bet %d", i, decision.Amount, top)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   *FilterCondition
+		wantSkip bool
+		compared float64
+	}{
+		{"no filter", nil, false, 0},
+		{"total users above", &FilterCondition{By: OutcomeTotalUsers, Where: ConditionGT, Value: 50}, false, 100},
+		{"total users not above", &FilterCondition{By: OutcomeTotalUsers, Where: ConditionGT, Value: 100}, true, 100},
+		{"total users at least", &FilterCondition{By: OutcomeTotalUsers, Where: ConditionGTE, Value: 100}, false, 100},
+		{"decision users below", &FilterCondition{By: OutcomeDecisionUsers, Where: ConditionLT, Value: 70}, true, 75},
+		{"decision points at most", &FilterCondition{By: OutcomeDecisionPoints, Where: ConditionLTE, Value: 3000}, false, 3000},
+		{"odds above", &FilterCondition{By: OutcomeOdds, Where: ConditionGT, Value: 1.5}, true, 1.33},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := settingsWith(StrategyMostVoted)
+			settings.FilterCondition = tt.filter
+			bet := newTestBet(settings, outcomeData("blue", 75, 3000, 0), outcomeData("pink", 25, 1000, 0))
+			bet.Calculate(10000)
+
+			skip, compared := bet.Skip()
+			if skip != tt.wantSkip || compared != tt.compared {
+				t.Fatalf("Skip() = %v, %v; want %v, %v", skip, compared, tt.wantSkip, tt.compared)
+			}
+		})
+	}
+}
+
+func TestShiftedDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    DelayMode
+		delay   float64
+		seconds float64
+		window  float64
+		want    float64
+	}{
+		{"from start later", DelayModeFromStart, 10, 5, 60, 15},
+		{"from start not below zero", DelayModeFromStart, 3, -5, 60, 0},
+		{"from end earlier", DelayModeFromEnd, 6, -3, 60, 9},
+		{"from end capped by window", DelayModeFromEnd, 50, -20, 60, 60},
+		{"percentage earlier", DelayModePercentage, 0.9, -6, 60, 0.8},
+		{"percentage without window", DelayModePercentage, 0.9, -6, 0, 0.9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := BetSettings{Delay: tt.delay, DelayMode: tt.mode}
+			if got := settings.ShiftedDelay(tt.seconds, tt.window); got != tt.want {
+				t.Fatalf("ShiftedDelay(%v, %v) = %v, want %v", tt.seconds, tt.window, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/gql"
+)
 
 type CampaignStatus string
 
@@ -22,66 +26,29 @@ type Campaign struct {
 	DateMatch   bool
 }
 
-func NewCampaignFromGQL(data map[string]interface{}) *Campaign {
+// NewCampaignFromGQL converts a campaign of the ViewerDropsDashboard response.
+func NewCampaignFromGQL(data gql.DropCampaign) *Campaign {
 	c := &Campaign{
-		Drops: make([]*Drop, 0),
-	}
-
-	if id, ok := data["id"].(string); ok {
-		c.ID = id
-	}
-	if name, ok := data["name"].(string); ok {
-		c.Name = name
-	}
-	if status, ok := data["status"].(string); ok {
-		c.Status = CampaignStatus(status)
-	}
-
-	if gameData, ok := data["game"].(map[string]interface{}); ok {
-		c.Game = &Game{}
-		if id, ok := gameData["id"].(string); ok {
-			c.Game.ID = id
-		}
-		if name, ok := gameData["name"].(string); ok {
-			c.Game.Name = name
-		}
-		if displayName, ok := gameData["displayName"].(string); ok {
-			c.Game.DisplayName = displayName
-		}
-	}
-
-	if startAt, ok := data["startAt"].(string); ok {
-		if t, err := time.Parse(time.RFC3339, startAt); err == nil {
-			c.StartAt = t
-		}
-	}
-	if endAt, ok := data["endAt"].(string); ok {
-		if t, err := time.Parse(time.RFC3339, endAt); err == nil {
-			c.EndAt = t
-		}
+		ID:      data.ID,
+		Name:    data.Name,
+		Status:  CampaignStatus(data.Status),
+		Game:    GameFromGQL(data.Game),
+		StartAt: data.StartAt.Time,
+		EndAt:   data.EndAt.Time,
+		Drops:   make([]*Drop, 0, len(data.TimeBasedDrops)),
 	}
 
 	now := time.Now()
 	c.DateMatch = c.StartAt.Before(now) && c.EndAt.After(now)
 
-	if allow, ok := data["allow"].(map[string]interface{}); ok {
-		if channels, ok := allow["channels"].([]interface{}); ok && channels != nil {
-			for _, ch := range channels {
-				if chMap, ok := ch.(map[string]interface{}); ok {
-					if id, ok := chMap["id"].(string); ok {
-						c.Channels = append(c.Channels, id)
-					}
-				}
-			}
+	if data.Allow != nil {
+		for _, ch := range data.Allow.Channels {
+			c.Channels = append(c.Channels, ch.ID)
 		}
 	}
 
-	if drops, ok := data["timeBasedDrops"].([]interface{}); ok {
-		for _, d := range drops {
-			if dropData, ok := d.(map[string]interface{}); ok {
-				c.Drops = append(c.Drops, NewDropFromGQL(dropData))
-			}
-		}
+	for _, d := range data.TimeBasedDrops {
+		c.Drops = append(c.Drops, NewDropFromGQL(d))
 	}
 
 	return c
@@ -97,21 +64,12 @@ func (c *Campaign) ClearClaimedDrops() {
 	c.Drops = validDrops
 }
 
-func (c *Campaign) SyncDrops(inventoryDrops []interface{}, claimFunc func(*Drop) bool) {
+func (c *Campaign) SyncDrops(inventoryDrops []gql.TimeBasedDrop, claimFunc func(*Drop) bool) {
 	for _, invDrop := range inventoryDrops {
-		dropData, ok := invDrop.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		dropID, ok := dropData["id"].(string)
-		if !ok {
-			continue
-		}
-
 		for _, drop := range c.Drops {
-			if drop.ID == dropID {
-				if selfData, ok := dropData["self"].(map[string]interface{}); ok {
-					drop.Update(selfData)
+			if drop.ID == invDrop.ID {
+				if invDrop.Self != nil {
+					drop.Update(*invDrop.Self)
 				}
 				if drop.IsClaimable && claimFunc != nil {
 					drop.IsClaimed = claimFunc(drop)
@@ -121,3 +79,64 @@ func (c *Campaign) SyncDrops(inventoryDrops []interface{}, claimFunc func(*Drop)
 		}
 	}
 }
+
+// CampaignProgress is a snapshot of an active drop campaign and the account's
+// progress on its drops, safe to read outside the drops tracker.
+type CampaignProgress struct {
+	ID          string
+	Name        string
+	Game        string
+	EndAt       time.Time
+	InInventory bool
+	// Streamers are the online streamers whose stream progresses the campaign.
+	Streamers []string
+	Drops     []DropProgress
+}
+
+// DropProgress is the account's progress on one drop of a campaign.
+type DropProgress struct {
+	ID                    string
+	Name                  string
+	Benefit               string
+	MinutesRequired       int
+	CurrentMinutesWatched int
+	IsClaimed             bool
+	EndAt                 time.Time
+}
+
+// RemainingMinutes returns how many more minutes must be watched to earn the drop.
+func (d DropProgress) RemainingMinutes() int {
+	if d.CurrentMinutesWatched >= d.MinutesRequired {
+		return 0
+	}
+	return d.MinutesRequired - d.CurrentMinutesWatched
+}
+
+// Progress returns a snapshot of the campaign's drops, including claimed ones.
+func (c *Campaign) Progress() CampaignProgress {
+	p := CampaignProgress{
+		ID:          c.ID,
+		Name:        c.Name,
+		EndAt:       c.EndAt,
+		InInventory: c.InInventory,
+		Drops:       make([]DropProgress, 0, len(c.Drops)),
+	}
+	if c.Game != nil {
+		p.Game = c.Game.DisplayName
+		if p.Game == "" {
+			p.Game = c.Game.Name
+		}
+	}
+	for _, drop := range c.Drops {
+		p.Drops = append(p.Drops, DropProgress{
+			ID:                    drop.ID,
+			Name:                  drop.Name,
+			Benefit:               drop.Benefit,
+			MinutesRequired:       drop.MinutesRequired,
+			CurrentMinutesWatched: drop.CurrentMinutesWatched,
+			IsClaimed:             drop.IsClaimed,
+			EndAt:                 drop.EndAt,
+		})
+	}
+	return p
+}
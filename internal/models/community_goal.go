@@ -1,5 +1,7 @@
 package models
 
+import "github.com/PatrickWalther/twitch-miner-go/internal/gql"
+
 type CommunityGoalStatus string
 
 const (
@@ -22,35 +24,17 @@ func (g *CommunityGoal) AmountLeft() int {
 	return g.GoalAmount - g.PointsContributed
 }
 
-func CommunityGoalFromGQL(data map[string]interface{}) *CommunityGoal {
-	goal := &CommunityGoal{}
-
-	if id, ok := data["id"].(string); ok {
-		goal.GoalID = id
-	}
-	if title, ok := data["title"].(string); ok {
-		goal.Title = title
-	}
-	if desc, ok := data["description"].(string); ok {
-		goal.Description = desc
-	}
-	if status, ok := data["status"].(string); ok {
-		goal.Status = CommunityGoalStatus(status)
-	}
-	if pts, ok := data["pointsContributed"].(float64); ok {
-		goal.PointsContributed = int(pts)
-	}
-	if amt, ok := data["goalAmount"].(float64); ok {
-		goal.GoalAmount = int(amt)
+func CommunityGoalFromGQL(data gql.CommunityGoal) *CommunityGoal {
+	return &CommunityGoal{
+		GoalID:                       data.ID,
+		Title:                        data.Title,
+		Description:                  data.Description,
+		Status:                       CommunityGoalStatus(data.Status),
+		PointsContributed:            data.PointsContributed,
+		GoalAmount:                   data.GoalAmount,
+		PerStreamUserMaxContribution: data.PerStreamUserMaximumContribution,
+		IsInStock:                    data.IsInStock,
 	}
-	if max, ok := data["perStreamUserMaximumContribution"].(float64); ok {
-		goal.PerStreamUserMaxContribution = int(max)
-	}
-	if stock, ok := data["isInStock"].(bool); ok {
-		goal.IsInStock = stock
-	}
-
-	return goal
 }
 
 func CommunityGoalFromPubSub(data map[string]interface{}) *CommunityGoal {
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/gql"
+)
 
 type Drop struct {
 	ID                    string
@@ -17,57 +21,29 @@ type Drop struct {
 	EndAt                 time.Time
 }
 
-func NewDropFromGQL(data map[string]interface{}) *Drop {
-	drop := &Drop{}
-
-	if id, ok := data["id"].(string); ok {
-		drop.ID = id
-	}
-	if name, ok := data["name"].(string); ok {
-		drop.Name = name
-	}
-
-	if benefitEdges, ok := data["benefitEdges"].([]interface{}); ok && len(benefitEdges) > 0 {
-		if edge, ok := benefitEdges[0].(map[string]interface{}); ok {
-			if benefit, ok := edge["benefit"].(map[string]interface{}); ok {
-				if name, ok := benefit["name"].(string); ok {
-					drop.Benefit = name
-				}
-			}
-		}
-	}
-
-	if mins, ok := data["requiredMinutesWatched"].(float64); ok {
-		drop.MinutesRequired = int(mins)
+func NewDropFromGQL(data gql.TimeBasedDrop) *Drop {
+	drop := &Drop{
+		ID:              data.ID,
+		Name:            data.Name,
+		MinutesRequired: data.RequiredMinutesWatched,
+		StartAt:         data.StartAt.Time,
+		EndAt:           data.EndAt.Time,
 	}
-
-	if startAt, ok := data["startAt"].(string); ok {
-		if t, err := time.Parse(time.RFC3339, startAt); err == nil {
-			drop.StartAt = t
-		}
+	if len(data.BenefitEdges) > 0 {
+		drop.Benefit = data.BenefitEdges[0].Benefit.Name
 	}
-	if endAt, ok := data["endAt"].(string); ok {
-		if t, err := time.Parse(time.RFC3339, endAt); err == nil {
-			drop.EndAt = t
-		}
-	}
-
 	return drop
 }
 
-func (d *Drop) Update(selfData map[string]interface{}) {
-	if mins, ok := selfData["currentMinutesWatched"].(float64); ok {
-		d.CurrentMinutesWatched = int(mins)
-	}
-	if hasPre, ok := selfData["hasPreconditionsMet"].(bool); ok {
-		d.HasPreconditionsMet = &hasPre
-	}
-	if instanceID, ok := selfData["dropInstanceID"].(string); ok {
-		d.DropInstanceID = instanceID
+func (d *Drop) Update(self gql.DropSelf) {
+	d.CurrentMinutesWatched = self.CurrentMinutesWatched
+	if self.HasPreconditionsMet != nil {
+		d.HasPreconditionsMet = self.HasPreconditionsMet
 	}
-	if claimed, ok := selfData["isClaimed"].(bool); ok {
-		d.IsClaimed = claimed
+	if self.DropInstanceID != "" {
+		d.DropInstanceID = self.DropInstanceID
 	}
+	d.IsClaimed = self.IsClaimed
 
 	if d.MinutesRequired > 0 {
 		d.PercentageProgress = (d.CurrentMinutesWatched * 100) / d.MinutesRequired
@@ -86,3 +62,11 @@ func (d *Drop) DateTimeMatch() bool {
 func (d *Drop) IsPrintable() bool {
 	return !d.IsClaimed && d.CurrentMinutesWatched > 0 && d.CurrentMinutesWatched < d.MinutesRequired
 }
+
+// RemainingMinutes returns how many more minutes must be watched to earn the drop.
+func (d *Drop) RemainingMinutes() int {
+	if d.CurrentMinutesWatched >= d.MinutesRequired {
+		return 0
+	}
+	return d.MinutesRequired - d.CurrentMinutesWatched
+}
@@ -1,7 +1,17 @@
 package models
 
+import "github.com/PatrickWalther/twitch-miner-go/internal/gql"
+
 type Game struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
 	DisplayName string `json:"displayName"`
 }
+
+// GameFromGQL converts a GQL game, returning nil for a missing one.
+func GameFromGQL(game *gql.Game) *Game {
+	if game == nil {
+		return nil
+	}
+	return &Game{ID: game.ID, Name: game.Name, DisplayName: game.DisplayName}
+}
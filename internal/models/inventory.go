@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/gql"
+)
+
+// Inventory is a snapshot of the account's drops inventory.
+type Inventory struct {
+	InProgress []*InventoryCampaign
+	Drops      []*InventoryDrop
+	Rewards    []*InventoryReward
+	UpdatedAt  time.Time
+}
+
+// InventoryCampaign is a drop campaign the account is progressing.
+type InventoryCampaign struct {
+	ID    string
+	Name  string
+	Game  string
+	EndAt time.Time
+	Drops []*InventoryCampaignDrop
+}
+
+// InventoryCampaignDrop is the account's progress on one drop of a campaign.
+type InventoryCampaignDrop struct {
+	Name                  string
+	Benefit               string
+	MinutesRequired       int
+	CurrentMinutesWatched int
+	IsClaimed             bool
+}
+
+// InventoryDrop is an earned game drop.
+type InventoryDrop struct {
+	ID            string
+	Name          string
+	Game          string
+	ImageURL      string
+	Count         int
+	LastAwardedAt time.Time
+}
+
+// InventoryReward is a reward (e.g. a game code) from a completed reward campaign.
+type InventoryReward struct {
+	ID            string
+	Name          string
+	Campaign      string
+	Brand         string
+	Instructions  string
+	RedemptionURL string
+	ExpiresAt     time.Time
+}
+
+// NewInventoryFromGQL converts the inventory of the Inventory GQL response.
+func NewInventoryFromGQL(data gql.Inventory) *Inventory {
+	inv := &Inventory{UpdatedAt: time.Now()}
+
+	for _, c := range data.DropCampaignsInProgress {
+		inv.InProgress = append(inv.InProgress, inventoryCampaignFromGQL(c))
+	}
+
+	for _, d := range data.GameEventDrops {
+		inv.Drops = append(inv.Drops, &InventoryDrop{
+			ID:            d.ID,
+			Name:          d.Name,
+			Game:          gameName(d.Game),
+			ImageURL:      d.ImageURL,
+			Count:         d.TotalCount,
+			LastAwardedAt: d.LastAwardedAt.Time,
+		})
+	}
+
+	for _, c := range data.CompletedRewardCampaigns {
+		for _, r := range c.Rewards {
+			reward := &InventoryReward{
+				ID:            r.ID,
+				Name:          r.Name,
+				Campaign:      c.Name,
+				Brand:         c.Brand,
+				Instructions:  c.Instructions,
+				RedemptionURL: c.ExternalURL,
+				ExpiresAt:     r.EarnableUntil.Time,
+			}
+			if r.RedemptionInstructions != "" {
+				reward.Instructions = r.RedemptionInstructions
+			}
+			if r.RedemptionURL != "" {
+				reward.RedemptionURL = r.RedemptionURL
+			}
+			inv.Rewards = append(inv.Rewards, reward)
+		}
+	}
+
+	return inv
+}
+
+func inventoryCampaignFromGQL(data gql.InventoryCampaign) *InventoryCampaign {
+	campaign := &InventoryCampaign{
+		ID:    data.ID,
+		Name:  data.Name,
+		Game:  gameName(data.Game),
+		EndAt: data.EndAt.Time,
+	}
+
+	for _, d := range data.TimeBasedDrops {
+		drop := NewDropFromGQL(d)
+		if d.Self != nil {
+			drop.Update(*d.Self)
+		}
+
+		campaign.Drops = append(campaign.Drops, &InventoryCampaignDrop{
+			Name:                  drop.Name,
+			Benefit:               drop.Benefit,
+			MinutesRequired:       drop.MinutesRequired,
+			CurrentMinutesWatched: drop.CurrentMinutesWatched,
+			IsClaimed:             drop.IsClaimed,
+		})
+	}
+
+	return campaign
+}
+
+func gameName(game *gql.Game) string {
+	if game == nil {
+		return ""
+	}
+	if game.DisplayName != "" {
+		return game.DisplayName
+	}
+	return game.Name
+}
@@ -1,7 +1,9 @@
 package models
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -22,6 +24,35 @@ const (
 	ResultRefund PredictionResultType = "REFUND"
 )
 
+// BetApproval is the state of the approval asked for before a large bet.
+type BetApproval string
+
+const (
+	ApprovalNone     BetApproval = ""
+	ApprovalPending  BetApproval = "PENDING"
+	ApprovalApproved BetApproval = "APPROVED"
+	ApprovalRejected BetApproval = "REJECTED"
+	// ApprovalExpired is set when no answer came before the bet was due.
+	ApprovalExpired BetApproval = "EXPIRED"
+)
+
+// BetOverride is a manual answer to a scheduled bet given before its time.
+type BetOverride string
+
+const (
+	OverrideNone BetOverride = ""
+	// OverrideSkip cancels the bet.
+	OverrideSkip BetOverride = "SKIP"
+	// OverrideForce places the bet right away instead of at its time.
+	OverrideForce BetOverride = "FORCE"
+	// OverrideManual replaces the bet with one given from the dashboard.
+	OverrideManual BetOverride = "MANUAL"
+)
+
+// ErrInvalidBet is returned for a manual bet on an unknown outcome or with
+// an amount the balance does not allow.
+var ErrInvalidBet = errors.New("invalid bet")
+
 type PredictionResult struct {
 	Type   PredictionResultType
 	String string
@@ -39,6 +70,79 @@ type EventPrediction struct {
 	BetConfirmed            bool
 	BetPlaced               bool
 	Bet                     *Bet
+
+	// OpenWindowSeconds is how long Twitch takes bets on the event, while
+	// PredictionWindowSeconds is when the miner bets.
+	OpenWindowSeconds float64
+
+	// NotBiddable is set when Twitch rejects bets on this event for the
+	// account (e.g. subscriber-only predictions). SkipReason holds the cause,
+	// or why the miner chose not to bet.
+	NotBiddable bool
+	SkipReason  string
+
+	// BetAttemptAt is when the bet was decided and sent, LockedAt when the
+	// event locked. BetLate is set when the attempt came after the lock.
+	BetAttemptAt   time.Time
+	BetLate        bool
+	LockedAt       time.Time
+	decisionOdds   float64
+	lockOdds       float64
+	timingReported bool
+
+	// Approval tracks the approval asked for before a large bet; approved is
+	// closed once it is answered. A bet over stakeLimit is lowered to it, or
+	// skipped with skipOverLimit.
+	Approval      BetApproval
+	approved      chan struct{}
+	stakeLimit    int
+	skipOverLimit bool
+
+	// Override is a manual skip or force of the scheduled bet; overridden is
+	// closed once one is given. betDue is set when the bet's time came
+	// without one, after which it can no longer be overridden.
+	Override   BetOverride
+	overridden chan struct{}
+	betDue     bool
+
+	// mu guards the fields PubSub updates and bet placement change while
+	// the event is read by the dashboard.
+	mu sync.RWMutex
+}
+
+// BetTiming is how close to the lock a bet was placed and how much the chosen
+// outcome's odds moved after the decision.
+type BetTiming struct {
+	EventID string
+	Title   string
+	// Window is how long the event was open for bets, in seconds.
+	Window float64
+	// Margin is the seconds between the bet and the lock, negative when the
+	// bet came after the lock.
+	Margin float64
+	Late   bool
+	// DecisionOdds and LockOdds are the chosen outcome's odds when the bet was
+	// decided and when the event locked. Both are 0 for late bets.
+	DecisionOdds float64
+	LockOdds     float64
+}
+
+// PredictionSnapshot is a copy of an open prediction's state for display.
+type PredictionSnapshot struct {
+	EventID      string
+	Streamer     string
+	Title        string
+	Status       PredictionStatus
+	CreatedAt    time.Time
+	BetAt        time.Time
+	Outcomes     []Outcome
+	Decision     Decision
+	BetPlaced    bool
+	BetConfirmed bool
+	NotBiddable  bool
+	SkipReason   string
+	Approval     BetApproval
+	Override     BetOverride
 }
 
 func NewEventPrediction(
@@ -56,8 +160,332 @@ func NewEventPrediction(
 		CreatedAt:               createdAt,
 		PredictionWindowSeconds: predictionWindowSeconds,
 		Status:                  PredictionStatus(status),
-		Bet:                     NewBet(outcomes, streamer.Settings.Bet),
+		Bet:                     NewBet(outcomes, streamer.GetSettings().Bet),
+		overridden:              make(chan struct{}),
+	}
+}
+
+// GetStatus returns the event's status.
+func (e *EventPrediction) GetStatus() PredictionStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.Status
+}
+
+// Update applies a PubSub event update. Outcome totals stop updating once a
+// bet has been decided so the decision's odds are preserved.
+func (e *EventPrediction) Update(status PredictionStatus, outcomes []interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.Status = status
+	if !e.BetPlaced && e.Bet.Decision.ID == "" && outcomes != nil {
+		e.Bet.UpdateOutcomes(outcomes)
+	}
+}
+
+// DecideBet calculates the bet for the given balance and reports whether the
+// filter condition skips it, with the value the filter compared.
+func (e *EventPrediction) DecideBet(balance int) (decision Decision, skip bool, compared float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	decision = e.Bet.Calculate(balance)
+	skip, compared = e.Bet.Skip()
+	e.BetAttemptAt = time.Now()
+	if o := e.Bet.GetDecision(); o != nil {
+		e.decisionOdds = o.Odds
+	}
+	return decision, skip, compared
+}
+
+// LimitBet lowers the decided bet amount to at most amount.
+func (e *EventPrediction) LimitBet(amount int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Bet.Decision.Amount = min(e.Bet.Decision.Amount, amount)
+}
+
+// RequestApproval marks the bet as waiting for approval.
+func (e *EventPrediction) RequestApproval() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Approval = ApprovalPending
+	e.approved = make(chan struct{})
+}
+
+// ResolveApproval answers a pending approval and reports whether it was
+// still pending.
+func (e *EventPrediction) ResolveApproval(approve bool) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.Approval != ApprovalPending {
+		return false
+	}
+	e.Approval = ApprovalRejected
+	if approve {
+		e.Approval = ApprovalApproved
+	}
+	close(e.approved)
+	return true
+}
+
+// WaitApproval waits until a pending approval is answered or the deadline
+// passes, when it expires. It returns the resulting approval state.
+func (e *EventPrediction) WaitApproval(deadline time.Time) BetApproval {
+	e.mu.RLock()
+	approval, approved := e.Approval, e.approved
+	e.mu.RUnlock()
+
+	if approval != ApprovalPending {
+		return approval
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-approved:
+	case <-timer.C:
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.Approval == ApprovalPending {
+		e.Approval = ApprovalExpired
+	}
+	return e.Approval
+}
+
+// SetOverride skips or forces the scheduled bet and reports whether it was
+// still waiting for its time.
+func (e *EventPrediction) SetOverride(override BetOverride) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.Override != OverrideNone || e.betDue || e.Status != PredictionActive || e.NotBiddable {
+		return false
+	}
+	e.Override = override
+	close(e.overridden)
+	return true
+}
+
+// SetManualBet replaces the scheduled bet with amount points on the outcome
+// at index choice, records the attempt and returns the decision. Like
+// SetOverride it reports false once the bet is due or was overridden.
+func (e *EventPrediction) SetManualBet(choice, amount int) (Decision, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.Override != OverrideNone || e.betDue || e.Status != PredictionActive || e.NotBiddable {
+		return Decision{}, false
 	}
+	if choice < 0 || choice >= len(e.Bet.Outcomes) {
+		return Decision{}, false
+	}
+	e.Override = OverrideManual
+	close(e.overridden)
+
+	e.Bet.Decision = Decision{Choice: choice, Amount: amount, ID: e.Bet.Outcomes[choice].ID}
+	e.BetAttemptAt = time.Now()
+	e.decisionOdds = e.Bet.Outcomes[choice].Odds
+	return e.Bet.Decision, true
+}
+
+// OutcomeIndex returns the index of the outcome with the given ID, or -1.
+func (e *EventPrediction) OutcomeIndex(id string) int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for i, o := range e.Bet.Outcomes {
+		if o.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// WaitBetTime waits d, the time until the bet is due, unless an override is
+// given first. It returns the override, or OverrideNone once the bet is due.
+func (e *EventPrediction) WaitBetTime(d time.Duration) BetOverride {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-e.overridden:
+	case <-timer.C:
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.Override == OverrideNone {
+		e.betDue = true
+	}
+	return e.Override
+}
+
+// LimitStake caps the bet at limit when it is placed. With skip, a larger
+// bet is skipped instead of lowered.
+func (e *EventPrediction) LimitStake(limit int, skip bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stakeLimit = limit
+	e.skipOverLimit = skip
+}
+
+// StakeLimit returns the cap set by LimitStake, 0 if none, and whether a
+// larger bet is skipped.
+func (e *EventPrediction) StakeLimit() (limit int, skip bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.stakeLimit, e.skipOverLimit
+}
+
+// LockAt is when Twitch stops taking bets on the event.
+func (e *EventPrediction) LockAt() time.Time {
+	return e.CreatedAt.Add(time.Duration(e.OpenWindowSeconds * float64(time.Second)))
+}
+
+// MarkBetLate records that the bet was attempted after the event locked.
+func (e *EventPrediction) MarkBetLate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.BetLate = true
+	if e.BetAttemptAt.IsZero() {
+		e.BetAttemptAt = time.Now()
+	}
+}
+
+// MarkLocked records when the event locked and the chosen outcome's odds at
+// that point from the outcomes of the locking update. Later calls are ignored.
+func (e *EventPrediction) MarkLocked(lockedAt time.Time, outcomes []interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.LockedAt.IsZero() {
+		return
+	}
+	e.LockedAt = lockedAt
+
+	total, chosen := 0, 0
+	for _, o := range outcomes {
+		data, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		points, _ := data["total_points"].(float64)
+		total += int(points)
+		if id, _ := data["id"].(string); id != "" && id == e.Bet.Decision.ID {
+			chosen = int(points)
+		}
+	}
+	if chosen > 0 {
+		e.lockOdds = roundFloat(float64(total)/float64(chosen), 2)
+	}
+}
+
+// TakeBetTiming returns the bet's timing once both the bet attempt and the
+// lock are known. It reports each event only once.
+func (e *EventPrediction) TakeBetTiming() (BetTiming, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.timingReported || e.LockedAt.IsZero() || e.BetAttemptAt.IsZero() || !(e.BetPlaced || e.BetLate) {
+		return BetTiming{}, false
+	}
+	e.timingReported = true
+
+	timing := BetTiming{
+		EventID: e.EventID,
+		Title:   e.Title,
+		Window:  e.LockedAt.Sub(e.CreatedAt).Seconds(),
+		Margin:  e.LockedAt.Sub(e.BetAttemptAt).Seconds(),
+		Late:    e.BetLate,
+	}
+	if !e.BetLate {
+		timing.DecisionOdds = e.decisionOdds
+		timing.LockOdds = e.lockOdds
+	}
+	return timing, true
+}
+
+// MarkBetPlaced records that the bet was accepted by Twitch.
+func (e *EventPrediction) MarkBetPlaced() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.BetPlaced = true
+}
+
+// Snapshot copies the event's current state. Until a bet is decided the
+// decision is the one the miner would make with the given balance now.
+func (e *EventPrediction) Snapshot(balance int) PredictionSnapshot {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	snapshot := PredictionSnapshot{
+		EventID:      e.EventID,
+		Streamer:     e.Streamer.GetUsername(),
+		Title:        e.Title,
+		Status:       e.Status,
+		CreatedAt:    e.CreatedAt,
+		BetAt:        e.CreatedAt.Add(time.Duration(e.PredictionWindowSeconds * float64(time.Second))),
+		Outcomes:     make([]Outcome, len(e.Bet.Outcomes)),
+		Decision:     e.Bet.Decision,
+		BetPlaced:    e.BetPlaced,
+		BetConfirmed: e.BetConfirmed,
+		NotBiddable:  e.NotBiddable,
+		SkipReason:   e.SkipReason,
+		Approval:     e.Approval,
+		Override:     e.Override,
+	}
+	for i, o := range e.Bet.Outcomes {
+		snapshot.Outcomes[i] = *o
+	}
+
+	if snapshot.Decision.ID == "" && !e.NotBiddable {
+		planned := *e.Bet
+		snapshot.Decision = planned.Calculate(balance)
+	}
+
+	return snapshot
+}
+
+// MarkNotBiddable records that no bet can be placed on this event.
+func (e *EventPrediction) MarkNotBiddable(reason string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.NotBiddable = true
+	e.SkipReason = reason
+}
+
+// SkipBet records why the miner did not bet on this event. Unlike
+// MarkNotBiddable the event could still have been bet on.
+func (e *EventPrediction) SkipBet(reason string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.SkipReason = reason
+}
+
+// Biddable reports whether a bet can still be placed: the event is active and
+// Twitch has not rejected bets on it.
+func (e *EventPrediction) Biddable() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.Status == PredictionActive && !e.NotBiddable
+}
+
+// ConfirmBet records that PubSub confirmed the bet.
+func (e *EventPrediction) ConfirmBet() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.BetConfirmed = true
+}
+
+// IsBetConfirmed reports whether PubSub confirmed the bet.
+func (e *EventPrediction) IsBetConfirmed() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.BetConfirmed
 }
 
 func (e *EventPrediction) Elapsed(timestamp time.Time) float64 {
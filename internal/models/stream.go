@@ -7,23 +7,29 @@ import (
 	"time"
 )
 
+// Stream is the live broadcast state of a streamer. Like Streamer, its fields
+// are only reachable through methods that hold its lock.
 type Stream struct {
-	BroadcastID  string
-	Title        string
-	Game         *Game
-	Tags         []Tag
-	ViewersCount int
-	SpadeURL     string
-	CampaignIDs  []string
-	Campaigns    []*Campaign
-
-	WatchStreakMissing bool
-	MinuteWatched      float64
+	broadcastID  string
+	title        string
+	game         *Game
+	tags         []Tag
+	viewersCount int
+	spadeURL     string
+	campaignIDs  []string
+	campaigns    []*Campaign
+
+	watchStreakMissing bool
+	minuteWatched      float64
 
 	payload              []MinuteWatchedEvent
 	lastUpdate           time.Time
 	minuteWatchedUpdated time.Time
 
+	resumeBroadcastID   string
+	resumeStreakMissing bool
+	resumeMinuteWatched float64
+
 	mu sync.RWMutex
 }
 
@@ -39,7 +45,7 @@ type MinuteWatchedEvent struct {
 
 func NewStream() *Stream {
 	return &Stream{
-		WatchStreakMissing: true,
+		watchStreakMissing: true,
 	}
 }
 
@@ -47,11 +53,11 @@ func (s *Stream) Update(broadcastID, title string, game *Game, tags []Tag, viewe
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.BroadcastID = broadcastID
-	s.Title = title
-	s.Game = game
-	s.Tags = tags
-	s.ViewersCount = viewersCount
+	s.broadcastID = broadcastID
+	s.title = title
+	s.game = game
+	s.tags = tags
+	s.viewersCount = viewersCount
 	s.lastUpdate = time.Now()
 }
 
@@ -116,9 +122,39 @@ func (s *Stream) InitWatchStreak() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.WatchStreakMissing = true
-	s.MinuteWatched = 0
+	s.watchStreakMissing = true
+	s.minuteWatched = 0
 	s.minuteWatchedUpdated = time.Time{}
+
+	if s.resumeBroadcastID != "" && s.resumeBroadcastID == s.broadcastID {
+		s.watchStreakMissing = s.resumeStreakMissing
+		s.minuteWatched = s.resumeMinuteWatched
+	}
+	s.resumeBroadcastID = ""
+}
+
+// RestoreWatchProgress stashes watch-streak progress saved by a previous session.
+// It is applied by the next InitWatchStreak if the same broadcast is still live.
+func (s *Stream) RestoreWatchProgress(broadcastID string, streakMissing bool, minuteWatched float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resumeBroadcastID = broadcastID
+	s.resumeStreakMissing = streakMissing
+	s.resumeMinuteWatched = minuteWatched
+}
+
+// WatchProgress returns the current broadcast ID and its watch-streak progress.
+func (s *Stream) WatchProgress() (broadcastID string, streakMissing bool, minuteWatched float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.broadcastID, s.watchStreakMissing, s.minuteWatched
+}
+
+func (s *Stream) GetBroadcastID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.broadcastID
 }
 
 func (s *Stream) UpdateMinuteWatched() {
@@ -127,7 +163,7 @@ func (s *Stream) UpdateMinuteWatched() {
 
 	if !s.minuteWatchedUpdated.IsZero() {
 		elapsed := time.Since(s.minuteWatchedUpdated)
-		s.MinuteWatched += elapsed.Minutes()
+		s.minuteWatched += elapsed.Minutes()
 	}
 	s.minuteWatchedUpdated = time.Now()
 }
@@ -136,18 +172,92 @@ func (s *Stream) GameName() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if s.Game == nil {
+	if s.game == nil {
 		return ""
 	}
-	return s.Game.Name
+	return s.game.Name
 }
 
 func (s *Stream) GameID() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if s.Game == nil {
+	if s.game == nil {
 		return ""
 	}
-	return s.Game.ID
+	return s.game.ID
+}
+
+func (s *Stream) GetTitle() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.title
+}
+
+func (s *Stream) GetViewersCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.viewersCount
+}
+
+func (s *Stream) GetTags() []Tag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tags
+}
+
+func (s *Stream) GetSpadeURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.spadeURL
+}
+
+func (s *Stream) SetSpadeURL(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spadeURL = url
+}
+
+// GetCampaignIDs returns the IDs of the drop campaigns available on the stream.
+func (s *Stream) GetCampaignIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.campaignIDs
+}
+
+func (s *Stream) SetCampaignIDs(ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.campaignIDs = ids
+}
+
+// GetCampaigns returns the active campaigns the stream can progress.
+func (s *Stream) GetCampaigns() []*Campaign {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.campaigns
+}
+
+func (s *Stream) SetCampaigns(campaigns []*Campaign) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.campaigns = campaigns
+}
+
+func (s *Stream) SetWatchStreakMissing(missing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchStreakMissing = missing
+}
+
+func (s *Stream) GetWatchStreakMissing() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.watchStreakMissing
+}
+
+func (s *Stream) GetMinuteWatched() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.minuteWatched
 }
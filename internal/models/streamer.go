@@ -24,7 +24,19 @@ type StreamerSettings struct {
 	CommunityGoals  bool         `json:"communityGoals"`
 	Chat            ChatPresence `json:"chat"`
 	ChatLogs        *bool        `json:"chatLogs,omitempty"`
-	Bet             BetSettings  `json:"bet"`
+	// WatchWeight is the streamer's share of watch slots in time-share mode,
+	// relative to the other eligible streamers. Values below 1 count as 1.
+	WatchWeight int `json:"watchWeight,omitempty"`
+	// DropsOnly only sends minute-watched reports for drop progress: channel
+	// points are not loaded, chat is not joined and bonuses, predictions,
+	// raids, moments and community goals are all left alone.
+	DropsOnly bool `json:"dropsOnly,omitempty"`
+	// StreamSummary posts the stream's statistics to Discord when it ends.
+	StreamSummary bool `json:"streamSummary,omitempty"`
+	// MaxPoints stops watching the streamer for points once the balance
+	// reaches it, until spending brings it back below. 0 means no cap.
+	MaxPoints int         `json:"maxPoints,omitempty"`
+	Bet       BetSettings `json:"bet"`
 }
 
 func DefaultStreamerSettings() StreamerSettings {
@@ -36,31 +48,116 @@ func DefaultStreamerSettings() StreamerSettings {
 		WatchStreak:     true,
 		CommunityGoals:  false,
 		Chat:            ChatOnline,
+		WatchWeight:     1,
 		Bet:             DefaultBetSettings(),
 	}
 }
 
+// ChannelMetadata is a channel's broadcaster status and size, fetched when
+// the streamer loads. FetchedAt is zero until a fetch succeeded.
+type ChannelMetadata struct {
+	Partner   bool
+	Affiliate bool
+	Followers int
+	CreatedAt time.Time
+	FetchedAt time.Time
+	// LostAffiliate is set when the channel was affiliate or partner at an
+	// earlier fetch and is neither anymore.
+	LostAffiliate bool
+}
+
+// Follow is whether the account follows a channel, as of the last follow
+// list sync. Known is false until a sync has succeeded.
+type Follow struct {
+	Known      bool
+	Following  bool
+	FollowedAt time.Time
+}
+
+const (
+	// NonEarningMinutes is how many minutes a streamer can be watched without
+	// a watch reward before it is marked non-earning. Twitch pays one every
+	// five minutes, so this allows for several missed rewards.
+	NonEarningMinutes = 30
+
+	// NonEarningRetry is how long a streamer stays non-earning after no watch
+	// reward arrived before it is given another chance.
+	NonEarningRetry = 24 * time.Hour
+)
+
+// Heartbeats counts the minute-watched reports sent for a streamer this session.
+type Heartbeats struct {
+	OK          int
+	Failed      int
+	LastOK      time.Time
+	LastFailure time.Time
+	LastError   string
+}
+
+// SessionStats counts a streamer's activity since the miner started.
+// StartPoints is the first balance loaded; DropMinutes are the minutes watched
+// while the stream had drop campaigns; MinutesSinceClaim are the minutes
+// watched since the last bonus chest was claimed.
+type SessionStats struct {
+	StartPoints       int
+	StartPointsKnown  bool
+	MinutesWatched    int
+	DropMinutes       int
+	PredictionsPlaced int
+	PredictionsWon    int
+	BonusClaims       int
+	MinutesSinceClaim int
+}
+
 type HistoryEntry struct {
 	Counter int
 	Amount  int
 }
 
+// Streamer is shared by the PubSub, watcher, drops, chat and web goroutines,
+// so its state is only reachable through methods that hold its lock. The
+// Stream it points to is fixed for its lifetime and guards itself.
 type Streamer struct {
-	Username          string
-	ChannelID         string
-	Settings          StreamerSettings
-	IsOnline          bool
-	StreamUpTime      time.Time
-	OnlineAt          time.Time
-	OfflineAt         time.Time
-	LastChecked       time.Time
-	ChannelPoints     int
-	CommunityGoals    map[string]*CommunityGoal
-	ViewerIsMod       bool
-	ActiveMultipliers []Multiplier
-	Stream            *Stream
-	Raid              *Raid
-	History           map[string]*HistoryEntry
+	username          string
+	channelID         string
+	settings          StreamerSettings
+	isOnline          bool
+	streamUpTime      time.Time
+	onlineAt          time.Time
+	offlineAt         time.Time
+	lastChecked       time.Time
+	channelPoints     int
+	communityGoals    map[string]*CommunityGoal
+	viewerIsMod       bool
+	activeMultipliers []Multiplier
+	stream            *Stream
+	raid              *Raid
+	history           map[string]*HistoryEntry
+	disabled          bool
+	follow            Follow
+	metadata          ChannelMetadata
+
+	// temporaryUntil is when a streamer added for a limited time is removed
+	// again; it is zero for configured streamers.
+	temporaryUntil time.Time
+
+	// discovered is set for followed channels mined without being configured.
+	discovered bool
+
+	// pointsDisabled is set when the broadcaster has turned channel points
+	// off. unrewardedMinutes counts the minutes watched since the last watch
+	// reward; nonEarningSince is when it reached NonEarningMinutes.
+	pointsDisabled    bool
+	unrewardedMinutes int
+	nonEarningSince   time.Time
+
+	heartbeats Heartbeats
+	session    SessionStats
+
+	// resumeOnlineAt is the onlineAt restored from a previous session; it is
+	// kept on the next SetOnline if the same broadcast is still live.
+	resumeOnlineAt    time.Time
+	resumeBroadcastID string
 
 	mu sync.RWMutex
 }
@@ -71,36 +168,66 @@ type Multiplier struct {
 
 func NewStreamer(username string, settings StreamerSettings) *Streamer {
 	return &Streamer{
-		Username:       username,
-		Settings:       settings,
-		CommunityGoals: make(map[string]*CommunityGoal),
-		Stream:         NewStream(),
-		History:        make(map[string]*HistoryEntry),
+		username:       username,
+		settings:       settings,
+		communityGoals: make(map[string]*CommunityGoal),
+		stream:         NewStream(),
+		history:        make(map[string]*HistoryEntry),
 	}
 }
 
 func (s *Streamer) String() string {
-	return fmt.Sprintf("Streamer(%s, %d points)", s.Username, s.ChannelPoints)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fmt.Sprintf("Streamer(%s, %d points)", s.username, s.channelPoints)
+}
+
+// GetUsername returns the streamer's login name, which never changes.
+func (s *Streamer) GetUsername() string {
+	return s.username
+}
+
+func (s *Streamer) GetChannelID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.channelID
+}
+
+func (s *Streamer) SetChannelID(channelID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channelID = channelID
+}
+
+// GetStream returns the streamer's current stream, which is the same object
+// for the streamer's lifetime.
+func (s *Streamer) GetStream() *Stream {
+	return s.stream
 }
 
 func (s *Streamer) SetOffline() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.IsOnline {
-		s.OfflineAt = time.Now()
-		s.IsOnline = false
+	if s.isOnline || !s.resumeOnlineAt.IsZero() {
+		s.offlineAt = time.Now()
+		s.isOnline = false
 	}
+	s.resumeOnlineAt = time.Time{}
 }
 
 func (s *Streamer) SetOnline() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if !s.IsOnline {
-		s.OnlineAt = time.Now()
-		s.IsOnline = true
-		s.Stream.InitWatchStreak()
+	if !s.isOnline {
+		s.onlineAt = time.Now()
+		if !s.resumeOnlineAt.IsZero() && s.resumeBroadcastID == s.stream.GetBroadcastID() {
+			s.onlineAt = s.resumeOnlineAt
+		}
+		s.resumeOnlineAt = time.Time{}
+		s.isOnline = true
+		s.stream.InitWatchStreak()
 	}
 }
 
@@ -108,14 +235,22 @@ func (s *Streamer) UpdateHistory(reasonCode string, earned int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.History[reasonCode]; !exists {
-		s.History[reasonCode] = &HistoryEntry{}
+	if _, exists := s.history[reasonCode]; !exists {
+		s.history[reasonCode] = &HistoryEntry{}
 	}
-	s.History[reasonCode].Counter++
-	s.History[reasonCode].Amount += earned
+	s.history[reasonCode].Counter++
+	s.history[reasonCode].Amount += earned
 
 	if reasonCode == "WATCH_STREAK" {
-		s.Stream.WatchStreakMissing = false
+		s.stream.SetWatchStreakMissing(false)
+	}
+	if reasonCode == "CLAIM" {
+		s.session.BonusClaims++
+		s.session.MinutesSinceClaim = 0
+	}
+	if (reasonCode == "WATCH" || reasonCode == "WATCH_STREAK") && earned > 0 {
+		s.unrewardedMinutes = 0
+		s.nonEarningSince = time.Time{}
 	}
 }
 
@@ -123,34 +258,46 @@ func (s *Streamer) UpdateHistoryWithCounter(reasonCode string, earned, counter i
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.History[reasonCode]; !exists {
-		s.History[reasonCode] = &HistoryEntry{}
+	if _, exists := s.history[reasonCode]; !exists {
+		s.history[reasonCode] = &HistoryEntry{}
 	}
-	s.History[reasonCode].Counter += counter
-	s.History[reasonCode].Amount += earned
+	s.history[reasonCode].Counter += counter
+	s.history[reasonCode].Amount += earned
+}
+
+// GetHistory returns a copy of the points earned this session by reason.
+func (s *Streamer) GetHistory() map[string]HistoryEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := make(map[string]HistoryEntry, len(s.history))
+	for reason, entry := range s.history {
+		history[reason] = *entry
+	}
+	return history
 }
 
 func (s *Streamer) StreamUpElapsed() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.StreamUpTime.IsZero() || time.Since(s.StreamUpTime) > 2*time.Minute
+	return s.streamUpTime.IsZero() || time.Since(s.streamUpTime) > 2*time.Minute
 }
 
 func (s *Streamer) DropsCondition() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.Settings.ClaimDrops &&
-		s.IsOnline &&
-		len(s.Stream.CampaignIDs) > 0
+	return s.settings.ClaimDrops &&
+		s.isOnline &&
+		len(s.stream.GetCampaignIDs()) > 0
 }
 
 func (s *Streamer) ViewerHasPointsMultiplier() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return len(s.ActiveMultipliers) > 0
+	return len(s.activeMultipliers) > 0
 }
 
 func (s *Streamer) TotalPointsMultiplier() float64 {
@@ -158,15 +305,23 @@ func (s *Streamer) TotalPointsMultiplier() float64 {
 	defer s.mu.RUnlock()
 
 	total := 0.0
-	for _, m := range s.ActiveMultipliers {
+	for _, m := range s.activeMultipliers {
 		total += m.Factor
 	}
 	return total
 }
 
+func (s *Streamer) SetActiveMultipliers(multipliers []Multiplier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeMultipliers = multipliers
+}
+
 func (s *Streamer) GetPredictionWindow(predictionWindowSeconds float64) float64 {
-	delayMode := s.Settings.Bet.DelayMode
-	delay := s.Settings.Bet.Delay
+	s.mu.RLock()
+	delayMode := s.settings.Bet.DelayMode
+	delay := s.settings.Bet.Delay
+	s.mu.RUnlock()
 
 	switch delayMode {
 	case DelayModeFromStart:
@@ -190,71 +345,339 @@ func (s *Streamer) GetPredictionWindow(predictionWindowSeconds float64) float64
 func (s *Streamer) AddCommunityGoal(goal *CommunityGoal) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.CommunityGoals[goal.GoalID] = goal
+	s.communityGoals[goal.GoalID] = goal
 }
 
 func (s *Streamer) UpdateCommunityGoal(goal *CommunityGoal) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.CommunityGoals[goal.GoalID] = goal
+	s.communityGoals[goal.GoalID] = goal
 }
 
 func (s *Streamer) DeleteCommunityGoal(goalID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	delete(s.CommunityGoals, goalID)
+	delete(s.communityGoals, goalID)
+}
+
+// GetCommunityGoals returns copies of the channel's known community goals.
+func (s *Streamer) GetCommunityGoals() []CommunityGoal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	goals := make([]CommunityGoal, 0, len(s.communityGoals))
+	for _, goal := range s.communityGoals {
+		goals = append(goals, *goal)
+	}
+	return goals
+}
+
+// SetRaid records the raid the streamer is taking part in. It reports false if
+// the raid was already recorded, so it is joined only once.
+func (s *Streamer) SetRaid(raid *Raid) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.raid != nil && s.raid.RaidID == raid.RaidID {
+		return false
+	}
+	s.raid = raid
+	return true
+}
+
+func (s *Streamer) GetRaid() *Raid {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.raid
+}
+
+func (s *Streamer) GetViewerIsMod() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.viewerIsMod
+}
+
+func (s *Streamer) SetViewerIsMod(isMod bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.viewerIsMod = isMod
 }
 
 func (s *Streamer) GetOnlineAt() time.Time {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.OnlineAt
+	return s.onlineAt
 }
 
 func (s *Streamer) GetOfflineAt() time.Time {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.OfflineAt
+	return s.offlineAt
+}
+
+// RestoreState applies online/offline timestamps persisted by a previous session.
+// The streamer stays offline until the next check; if wasOnline is set and the
+// check finds the same broadcast live, the restored onlineAt is kept.
+func (s *Streamer) RestoreState(wasOnline bool, broadcastID string, onlineAt, offlineAt, streamUpTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offlineAt = offlineAt
+	s.streamUpTime = streamUpTime
+	if wasOnline && broadcastID != "" {
+		s.resumeOnlineAt = onlineAt
+		s.resumeBroadcastID = broadcastID
+	} else {
+		s.onlineAt = onlineAt
+	}
+}
+
+func (s *Streamer) GetStreamUpTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.streamUpTime
+}
+
+func (s *Streamer) SetStreamUpTime(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streamUpTime = t
 }
 
 func (s *Streamer) GetIsOnline() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.IsOnline
+	return s.isOnline
 }
 
 func (s *Streamer) GetChannelPoints() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.ChannelPoints
+	return s.channelPoints
 }
 
 func (s *Streamer) SetChannelPoints(points int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.ChannelPoints = points
+	s.channelPoints = points
+	if !s.session.StartPointsKnown {
+		s.session.StartPoints = points
+		s.session.StartPointsKnown = true
+	}
 }
 
 func (s *Streamer) GetSettings() StreamerSettings {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.Settings
+	return s.settings
 }
 
 func (s *Streamer) SetSettings(settings StreamerSettings) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Settings = settings
+	s.settings = settings
+}
+
+// GetEnabled reports whether the streamer is mined. A disabled streamer keeps
+// its points and online status up to date but is not watched, does not join
+// chat and ignores bonuses, predictions, raids, moments and community goals.
+func (s *Streamer) GetEnabled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.disabled
+}
+
+func (s *Streamer) SetEnabled(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disabled = !enabled
+}
+
+// SetTemporaryUntil marks the streamer as added until the given time; the
+// zero time marks it as configured.
+func (s *Streamer) SetTemporaryUntil(until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.temporaryUntil = until
+}
+
+// GetTemporaryUntil returns when a temporarily added streamer is removed, or
+// the zero time for configured streamers.
+func (s *Streamer) GetTemporaryUntil() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.temporaryUntil
+}
+
+// SetDiscovered marks the streamer as mined because the account follows it
+// rather than because it is configured.
+func (s *Streamer) SetDiscovered(discovered bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discovered = discovered
+}
+
+// GetDiscovered reports whether the streamer is mined because the account
+// follows it.
+func (s *Streamer) GetDiscovered() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.discovered
+}
+
+// RecordHeartbeat counts a minute-watched report; err is nil if it succeeded.
+func (s *Streamer) RecordHeartbeat(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.heartbeats.OK++
+		s.heartbeats.LastOK = time.Now()
+		return
+	}
+	s.heartbeats.Failed++
+	s.heartbeats.LastFailure = time.Now()
+	s.heartbeats.LastError = err.Error()
+}
+
+func (s *Streamer) GetHeartbeats() Heartbeats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.heartbeats
+}
+
+// RecordPredictionPlaced counts a bet confirmed on one of the streamer's
+// predictions.
+func (s *Streamer) RecordPredictionPlaced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session.PredictionsPlaced++
+}
+
+// RecordPredictionWon counts a won prediction.
+func (s *Streamer) RecordPredictionWon() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session.PredictionsWon++
+}
+
+// GetSessionStats returns the streamer's activity counters for this session.
+func (s *Streamer) GetSessionStats() SessionStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.session
+}
+
+// RecordMinuteWatched counts a minute watched towards the session stats and
+// the non-earning check, and reports whether it made the streamer non-earning.
+func (s *Streamer) RecordMinuteWatched() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.session.MinutesWatched++
+	if len(s.stream.GetCampaigns()) > 0 {
+		s.session.DropMinutes++
+	}
+	// Drops-only streamers earn no points by design.
+	if s.settings.DropsOnly {
+		return false
+	}
+
+	s.session.MinutesSinceClaim++
+	s.unrewardedMinutes++
+	if s.unrewardedMinutes == NonEarningMinutes {
+		s.nonEarningSince = time.Now()
+		return true
+	}
+	return false
+}
+
+// PointsCapReached reports whether the channel points balance has reached the
+// streamer's MaxPoints cap.
+func (s *Streamer) PointsCapReached() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings.MaxPoints > 0 && s.channelPoints >= s.settings.MaxPoints
+}
+
+// SetPointsDisabled records whether the broadcaster has turned channel points
+// off and reports whether that changed.
+func (s *Streamer) SetPointsDisabled(disabled bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := s.pointsDisabled != disabled
+	s.pointsDisabled = disabled
+	return changed
+}
+
+// NonEarningReason explains why watching the streamer earns nothing, or
+// returns "" if it is believed to earn. A streamer marked non-earning for
+// lack of watch rewards is cleared again after NonEarningRetry.
+func (s *Streamer) NonEarningReason() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pointsDisabled {
+		return "Channel points are disabled"
+	}
+	if md := s.metadata; !md.FetchedAt.IsZero() && !md.Partner && !md.Affiliate {
+		if md.LostAffiliate {
+			return "Channel lost its affiliate status, so it has no channel points"
+		}
+		return "Channel is not an affiliate or partner, so it has no channel points"
+	}
+	if s.nonEarningSince.IsZero() {
+		return ""
+	}
+	if time.Since(s.nonEarningSince) >= NonEarningRetry {
+		s.unrewardedMinutes = 0
+		s.nonEarningSince = time.Time{}
+		return ""
+	}
+	return fmt.Sprintf("No watch points in %d watched minutes", NonEarningMinutes)
+}
+
+// ResetEarning clears the non-earning state so the streamer is watched again.
+func (s *Streamer) ResetEarning() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unrewardedMinutes = 0
+	s.nonEarningSince = time.Time{}
+}
+
+func (s *Streamer) GetMetadata() ChannelMetadata {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.metadata
+}
+
+func (s *Streamer) SetMetadata(metadata ChannelMetadata) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadata = metadata
+}
+
+func (s *Streamer) GetFollow() Follow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.follow
+}
+
+func (s *Streamer) SetFollow(follow Follow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.follow = follow
 }
 
 func (s *Streamer) GetLastChecked() time.Time {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.LastChecked
+	return s.lastChecked
 }
 
 func (s *Streamer) SetLastChecked(t time.Time) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.LastChecked = t
+	s.lastChecked = t
 }
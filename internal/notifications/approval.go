@@ -0,0 +1,65 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/util"
+)
+
+// BetApprovalRequest describes a large bet waiting for approval.
+type BetApprovalRequest struct {
+	Streamer  string
+	Title     string
+	Outcome   string
+	Amount    int
+	Balance   int
+	Threshold int
+	Deadline  time.Time
+}
+
+// RequestBetApproval posts the bet to the bets channel with reactions to
+// approve or reject it, and calls resolve with the first answer given before
+// the deadline. Without Discord or a bets channel nothing is sent and the bet
+// can only be answered from the dashboard.
+func (m *Manager) RequestBetApproval(req BetApprovalRequest, resolve func(approve bool)) {
+	m.mu.RLock()
+	discord := m.discord
+	enabled := m.discordConfig.Enabled
+	m.mu.RUnlock()
+
+	if !enabled || discord == nil {
+		return
+	}
+
+	cfg, err := m.repo.GetConfig()
+	if err != nil {
+		slog.Error("Failed to get notification config", "error", err)
+		return
+	}
+
+	if cfg.BetsChannelID == "" {
+		slog.Debug("Bet approval request skipped: no channel configured")
+		return
+	}
+
+	notification := Notification{
+		Type:  NotificationTypeBetApproval,
+		Title: fmt.Sprintf("🎲 Approve a %s point bet?", util.FormatNumber(req.Amount)),
+		Message: fmt.Sprintf("**%s**\nOutcome: **%s**\nBet: **%s** of %s points (threshold %s)\n\nReact %s to place it or %s to reject it before <t:%d:T>. Rejected and unanswered bets are skipped or lowered to the threshold, as configured.",
+			req.Title, req.Outcome, util.FormatNumber(req.Amount), util.FormatNumber(req.Balance), util.FormatNumber(req.Threshold),
+			reactionApprove, reactionReject, req.Deadline.Unix()),
+		Streamer:  req.Streamer,
+		ChannelID: cfg.BetsChannelID,
+		Approve:   resolve,
+		Expires:   req.Deadline,
+	}
+
+	go func() {
+		if err := m.send(context.Background(), discord, notification); err != nil {
+			slog.Error("Failed to send bet approval request", "error", err)
+		}
+	}()
+}
@@ -0,0 +1,126 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	desktopAppName     = "Twitch Points Miner"
+	desktopSendTimeout = 10 * time.Second
+
+	// windowsPowerShellAppID is the AppUserModelID of PowerShell; toasts from
+	// unregistered app IDs are silently dropped by Windows.
+	windowsPowerShellAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+)
+
+// The title and message are passed through the environment so that no
+// user-controlled text (e.g. chat messages) is ever interpreted as script.
+const (
+	envTitle   = "MINER_NOTIFY_TITLE"
+	envMessage = "MINER_NOTIFY_MESSAGE"
+)
+
+const windowsToastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:` + envTitle + `)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode($env:` + envMessage + `)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('` + windowsPowerShellAppID + `').Show($toast)
+`
+
+const macOSNotificationScript = `display notification (system attribute "` + envMessage + `") with title (system attribute "` + envTitle + `")`
+
+// DesktopProvider implements the Provider interface using native OS notifications:
+// toast notifications on Windows, Notification Center on macOS and libnotify
+// (notify-send) elsewhere.
+type DesktopProvider struct {
+	command string
+}
+
+// NewDesktopProvider creates a new desktop notification provider for the current OS.
+func NewDesktopProvider() *DesktopProvider {
+	var command string
+	switch runtime.GOOS {
+	case "windows":
+		command = "powershell"
+	case "darwin":
+		command = "osascript"
+	default:
+		command = "notify-send"
+	}
+	return &DesktopProvider{command: command}
+}
+
+// Name returns the provider's identifier.
+func (d *DesktopProvider) Name() string {
+	return "desktop"
+}
+
+// IsConfigured returns true if the notification tool for this OS is installed.
+func (d *DesktopProvider) IsConfigured() bool {
+	_, err := exec.LookPath(d.command)
+	return err == nil
+}
+
+// Connect verifies that desktop notifications can be raised.
+func (d *DesktopProvider) Connect(ctx context.Context) error {
+	if !d.IsConfigured() {
+		return fmt.Errorf("desktop notifications unavailable: %s not found", d.command)
+	}
+
+	slog.Info("Desktop notification provider ready", "command", d.command)
+	return nil
+}
+
+// Disconnect is a no-op; desktop notifications hold no connection.
+func (d *DesktopProvider) Disconnect() error {
+	return nil
+}
+
+// Send raises a native notification. Discord markdown is stripped from the message.
+func (d *DesktopProvider) Send(ctx context.Context, notification Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, desktopSendTimeout)
+	defer cancel()
+
+	title := notification.Title
+	message := plainText(notification.Message)
+
+	var cmd *exec.Cmd
+	switch d.command {
+	case "powershell":
+		cmd = exec.CommandContext(ctx, d.command, "-NoProfile", "-NonInteractive", "-Command", windowsToastScript)
+	case "osascript":
+		cmd = exec.CommandContext(ctx, d.command, "-e", macOSNotificationScript)
+	default:
+		cmd = exec.CommandContext(ctx, d.command, "--app-name="+desktopAppName, "--", title, message)
+	}
+	cmd.Env = append(os.Environ(), envTitle+"="+title, envMessage+"="+message)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to raise desktop notification: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetChannels returns no channels; desktop notifications have a single destination.
+func (d *DesktopProvider) GetChannels(ctx context.Context) ([]Channel, error) {
+	return nil, nil
+}
+
+// plainText removes the Discord markdown used in notification messages.
+func plainText(message string) string {
+	lines := strings.Split(strings.ReplaceAll(message, "**", ""), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, "> ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -16,6 +16,16 @@ const (
 	ColorPoints  = 0xFFD700 // Gold
 	ColorOnline  = 0x00FF00 // Green
 	ColorOffline = 0xFF4545 // Red
+	ColorIdle    = 0x808080 // Gray
+	ColorSummary = 0x1E90FF // Blue
+	ColorError   = 0xB00020 // Dark red
+	ColorBet     = 0xFF8C00 // Orange
+)
+
+// Reactions that answer a bet approval message.
+const (
+	reactionApprove = "✅"
+	reactionReject  = "❌"
 )
 
 // DiscordProvider implements the Provider interface for Discord notifications.
@@ -29,6 +39,9 @@ type DiscordProvider struct {
 	channelCacheTime time.Time
 	channelCacheTTL  time.Duration
 
+	// approvals maps approval message IDs to the notification they ask for.
+	approvals map[string]Notification
+
 	mu sync.RWMutex
 }
 
@@ -38,6 +51,7 @@ func NewDiscordProvider(botToken, guildID string) *DiscordProvider {
 		botToken:        botToken,
 		guildID:         guildID,
 		channelCacheTTL: 5 * time.Minute,
+		approvals:       make(map[string]Notification),
 	}
 }
 
@@ -62,7 +76,8 @@ func (d *DiscordProvider) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to create Discord session: %w", err)
 	}
 
-	session.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages
+	session.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildMessageReactions
+	session.AddHandler(d.handleReaction)
 
 	if err := session.Open(); err != nil {
 		return fmt.Errorf("failed to open Discord connection: %w", err)
@@ -115,6 +130,14 @@ func (d *DiscordProvider) Send(ctx context.Context, notification Notification) e
 			color = ColorOnline
 		case NotificationTypeOffline:
 			color = ColorOffline
+		case NotificationTypeIdle:
+			color = ColorIdle
+		case NotificationTypeSummary, NotificationTypeStreamSummary:
+			color = ColorSummary
+		case NotificationTypeError:
+			color = ColorError
+		case NotificationTypeBetApproval:
+			color = ColorBet
 		default:
 			color = ColorMention
 		}
@@ -137,7 +160,7 @@ func (d *DiscordProvider) Send(ctx context.Context, notification Notification) e
 		}
 	}
 
-	_, err := session.ChannelMessageSendEmbed(notification.ChannelID, embed)
+	message, err := session.ChannelMessageSendEmbed(notification.ChannelID, embed)
 	if err != nil {
 		slog.Error("Failed to send Discord notification",
 			"channel", notification.ChannelID,
@@ -152,9 +175,46 @@ func (d *DiscordProvider) Send(ctx context.Context, notification Notification) e
 		"type", notification.Type,
 		"streamer", notification.Streamer,
 	)
+
+	if notification.Approve != nil {
+		d.mu.Lock()
+		for id, pending := range d.approvals {
+			if time.Now().After(pending.Expires) {
+				delete(d.approvals, id)
+			}
+		}
+		d.approvals[message.ID] = notification
+		d.mu.Unlock()
+
+		for _, emoji := range []string{reactionApprove, reactionReject} {
+			if err := session.MessageReactionAdd(notification.ChannelID, message.ID, emoji); err != nil {
+				slog.Warn("Failed to add approval reaction", "emoji", emoji, "error", err)
+			}
+		}
+	}
 	return nil
 }
 
+// handleReaction answers a bet approval when someone other than the bot
+// reacts to its message with the approve or reject emoji.
+func (d *DiscordProvider) handleReaction(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	if s.State.User != nil && r.UserID == s.State.User.ID {
+		return
+	}
+	if r.Emoji.Name != reactionApprove && r.Emoji.Name != reactionReject {
+		return
+	}
+
+	d.mu.Lock()
+	pending, ok := d.approvals[r.MessageID]
+	delete(d.approvals, r.MessageID)
+	d.mu.Unlock()
+
+	if ok && time.Now().Before(pending.Expires) {
+		pending.Approve(r.Emoji.Name == reactionApprove)
+	}
+}
+
 // GetChannels returns available text channels in the configured guild.
 func (d *DiscordProvider) GetChannels(ctx context.Context, forceRefresh bool) ([]Channel, error) {
 	d.mu.RLock()
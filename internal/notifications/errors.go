@@ -0,0 +1,85 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// errorThrottle is the minimum time between two error notifications of the same kind.
+const errorThrottle = 30 * time.Minute
+
+// ErrorKind identifies an operational problem that stops or degrades mining.
+type ErrorKind string
+
+const (
+	ErrorKindAuth     ErrorKind = "auth"
+	ErrorKindGQL      ErrorKind = "gql"
+	ErrorKindPubSub   ErrorKind = "pubsub"
+	ErrorKindDatabase ErrorKind = "database"
+	ErrorKindPanic    ErrorKind = "panic"
+	ErrorKindBonus    ErrorKind = "bonus"
+)
+
+func (k ErrorKind) title() string {
+	switch k {
+	case ErrorKindAuth:
+		return "Authentication failed"
+	case ErrorKindGQL:
+		return "Twitch API requests failing"
+	case ErrorKindPubSub:
+		return "PubSub connection down"
+	case ErrorKindDatabase:
+		return "Database write failed"
+	case ErrorKindPanic:
+		return "Component crashed"
+	case ErrorKindBonus:
+		return "Bonus chests not claimed"
+	default:
+		return "Miner error"
+	}
+}
+
+// NotifyError sends a critical error notification. Repeated errors of the same
+// kind are throttled so a persistent failure produces one alert per errorThrottle.
+func (m *Manager) NotifyError(kind ErrorKind, message string) {
+	m.mu.Lock()
+	if last, ok := m.errorsSent[kind]; ok && time.Since(last) < errorThrottle {
+		m.mu.Unlock()
+		return
+	}
+	m.errorsSent[kind] = time.Now()
+	discord := m.discord
+	enabled := m.discordConfig.Enabled
+	m.mu.Unlock()
+
+	notification := Notification{
+		Type:    NotificationTypeError,
+		Title:   fmt.Sprintf("⚠️ %s", kind.title()),
+		Message: message,
+	}
+	m.notifyDesktop(notification)
+
+	if !enabled || discord == nil {
+		return
+	}
+
+	cfg, err := m.repo.GetConfig()
+	if err != nil {
+		slog.Error("Failed to get notification config", "error", err)
+		return
+	}
+
+	if !cfg.ErrorsEnabled || cfg.ErrorsChannelID == "" {
+		return
+	}
+
+	notification.ChannelID = cfg.ErrorsChannelID
+
+	go func() {
+		if err := m.send(context.Background(), discord, notification); err != nil {
+			slog.Error("Failed to send error notification", "error", err)
+		}
+	}()
+}
@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// logRetention is how long dispatched notifications are kept in the log.
+const logRetention = 30 * 24 * time.Hour
+
+// sender is the part of a provider needed to deliver a notification.
+type sender interface {
+	Name() string
+	Send(ctx context.Context, notification Notification) error
+}
+
+// send delivers a notification and records the attempt in the notification
+// log, whether it succeeded or not.
+func (m *Manager) send(ctx context.Context, provider sender, notification Notification) error {
+	err := provider.Send(ctx, notification)
+
+	entry := LogEntry{
+		Timestamp: time.Now(),
+		Type:      notification.Type,
+		Provider:  provider.Name(),
+		Streamer:  notification.Streamer,
+		ChannelID: notification.ChannelID,
+		Title:     notification.Title,
+		Success:   err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if logErr := m.repo.AddLogEntry(&entry); logErr != nil {
+		slog.Warn("Failed to record notification", "type", notification.Type, "error", logErr)
+	}
+
+	return err
+}
+
+// GetLog returns the dispatched notifications matching the filter, newest first.
+func (m *Manager) GetLog(filter LogFilter) ([]LogEntry, error) {
+	return m.repo.GetLog(filter)
+}
+
+func (m *Manager) pruneLog() {
+	if err := m.repo.PruneLog(time.Now().Add(-logRetention)); err != nil {
+		slog.Warn("Failed to prune notification log", "error", err)
+	}
+}
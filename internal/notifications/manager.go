@@ -5,24 +5,32 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
 	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+	"github.com/PatrickWalther/twitch-miner-go/internal/util"
 )
 
 // Manager handles notification dispatching across multiple providers.
 type Manager struct {
 	discordConfig *config.DiscordSettings
 	discord       *DiscordProvider
+	desktopConfig *config.DesktopSettings
+	desktop       *DesktopProvider
 	repo          *Repository
 	streamers     []string
 
 	pointsPreviousValues map[string]int
+	errorsSent           map[ErrorKind]time.Time
+	summarySource        PredictionSummarySource
+	cancel               context.CancelFunc
 	mu                   sync.RWMutex
 }
 
 // NewManager creates a new notification manager.
-func NewManager(discordCfg *config.DiscordSettings, db *database.DB, streamers []string) (*Manager, error) {
+func NewManager(discordCfg *config.DiscordSettings, desktopCfg *config.DesktopSettings, db *database.DB, streamers []string) (*Manager, error) {
 	repo, err := NewRepository(db)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create notification repository: %w", err)
@@ -30,25 +38,60 @@ func NewManager(discordCfg *config.DiscordSettings, db *database.DB, streamers [
 
 	m := &Manager{
 		discordConfig:        discordCfg,
+		desktopConfig:        desktopCfg,
 		streamers:            streamers,
 		repo:                 repo,
 		pointsPreviousValues: make(map[string]int),
+		errorsSent:           make(map[ErrorKind]time.Time),
 	}
 
 	if discordCfg.Enabled {
 		m.discord = NewDiscordProvider(discordCfg.BotToken, discordCfg.GuildID)
 	}
 
+	if desktopCfg.Enabled {
+		m.desktop = NewDesktopProvider()
+	}
+
 	return m, nil
 }
 
-// Start initializes and connects all enabled providers.
+// Start initializes and connects all enabled providers and starts the
+// weekly summary scheduler, which also prunes the notification log. A desktop
+// provider that cannot connect is logged and disabled without failing Start;
+// only a Discord connection error is returned.
 func (m *Manager) Start(ctx context.Context) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	if m.cancel == nil {
+		loopCtx, cancel := context.WithCancel(ctx)
+		m.cancel = cancel
+		go m.summaryLoop(loopCtx)
+	}
 
+	var discord *DiscordProvider
 	if m.discord != nil && m.discordConfig.Enabled {
-		if err := m.discord.Connect(ctx); err != nil {
+		discord = m.discord
+	}
+	var desktop *DesktopProvider
+	if m.desktop != nil && m.desktopConfig.Enabled {
+		desktop = m.desktop
+	}
+	m.mu.Unlock()
+
+	// Connecting goes over the network, so it happens outside the lock.
+	if desktop != nil {
+		if err := desktop.Connect(ctx); err != nil {
+			slog.Warn("Desktop notifications disabled", "error", err)
+			m.mu.Lock()
+			if m.desktop == desktop {
+				m.desktop = nil
+			}
+			m.mu.Unlock()
+		}
+	}
+
+	if discord != nil {
+		if err := discord.Connect(ctx); err != nil {
 			slog.Error("Failed to connect Discord provider", "error", err)
 			return err
 		}
@@ -62,12 +105,21 @@ func (m *Manager) Stop() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+
 	if m.discord != nil {
 		if err := m.discord.Disconnect(); err != nil {
 			slog.Error("Failed to disconnect Discord provider", "error", err)
 		}
 	}
 
+	if m.desktop != nil {
+		_ = m.desktop.Disconnect()
+	}
+
 	if m.repo != nil {
 		_ = m.repo.Close()
 	}
@@ -131,8 +183,31 @@ func (m *Manager) DeletePointRule(id int64) error {
 	return m.repo.DeletePointRule(id)
 }
 
+// GetIdleRules returns all idle streamer notification rules.
+func (m *Manager) GetIdleRules() ([]IdleRule, error) {
+	return m.repo.GetIdleRules()
+}
+
+// AddIdleRule adds a new idle streamer notification rule.
+func (m *Manager) AddIdleRule(rule *IdleRule) error {
+	return m.repo.AddIdleRule(rule)
+}
+
+// DeleteIdleRule removes an idle streamer notification rule.
+func (m *Manager) DeleteIdleRule(id int64) error {
+	return m.repo.DeleteIdleRule(id)
+}
+
 // NotifyMention sends a mention notification.
 func (m *Manager) NotifyMention(streamer, fromUser, message string) {
+	notification := Notification{
+		Type:     NotificationTypeMention,
+		Title:    fmt.Sprintf("💬 Mentioned in %s's chat", streamer),
+		Message:  fmt.Sprintf("**%s** mentioned you:\n> %s", fromUser, message),
+		Streamer: streamer,
+	}
+	m.notifyDesktop(notification)
+
 	m.mu.RLock()
 	discord := m.discord
 	enabled := m.discordConfig.Enabled
@@ -170,21 +245,64 @@ func (m *Manager) NotifyMention(streamer, fromUser, message string) {
 		return
 	}
 
-	notification := Notification{
-		Type:      NotificationTypeMention,
-		Title:     fmt.Sprintf("💬 Mentioned in %s's chat", streamer),
-		Message:   fmt.Sprintf("**%s** mentioned you:\n> %s", fromUser, message),
-		Streamer:  streamer,
-		ChannelID: cfg.MentionsChannelID,
-	}
+	notification.ChannelID = cfg.MentionsChannelID
 
 	go func() {
-		if err := discord.Send(context.Background(), notification); err != nil {
+		if err := m.send(context.Background(), discord, notification); err != nil {
 			slog.Error("Failed to send mention notification", "error", err)
 		}
 	}()
 }
 
+// NotifyDropClaimed sends a desktop notification for a claimed drop.
+func (m *Manager) NotifyDropClaimed(drop string) {
+	m.notifyDesktop(Notification{
+		Type:    NotificationTypeDrop,
+		Title:   "🎁 Drop claimed",
+		Message: fmt.Sprintf("**%s** was added to your inventory.", drop),
+	})
+}
+
+// notifyDesktop raises a desktop notification if desktop notifications are
+// enabled for the notification's type.
+func (m *Manager) notifyDesktop(notification Notification) {
+	m.mu.RLock()
+	desktop := m.desktop
+	cfg := m.desktopConfig
+	m.mu.RUnlock()
+
+	if desktop == nil || !cfg.Enabled {
+		return
+	}
+
+	switch notification.Type {
+	case NotificationTypeMention:
+		if !cfg.Mentions {
+			return
+		}
+	case NotificationTypeOnline:
+		if !cfg.Online {
+			return
+		}
+	case NotificationTypeDrop:
+		if !cfg.Drops {
+			return
+		}
+	case NotificationTypeError:
+		if !cfg.Errors {
+			return
+		}
+	default:
+		return
+	}
+
+	go func() {
+		if err := m.send(context.Background(), desktop, notification); err != nil {
+			slog.Error("Failed to send desktop notification", "type", notification.Type, "error", err)
+		}
+	}()
+}
+
 // NotifyPointsReached checks and sends point threshold notifications.
 func (m *Manager) NotifyPointsReached(streamer string, points int) {
 	m.mu.Lock()
@@ -231,13 +349,13 @@ func (m *Manager) NotifyPointsReached(streamer string, points int) {
 			notification := Notification{
 				Type:      NotificationTypePointsReached,
 				Title:     fmt.Sprintf("🎯 Point Goal Reached: %s", streamer),
-				Message:   fmt.Sprintf("You've reached **%d** points in **%s**'s channel!\nCurrent: **%d** points", rule.Threshold, streamer, points),
+				Message:   fmt.Sprintf("You've reached **%s** points in **%s**'s channel!\nCurrent: **%s** points", util.FormatNumber(rule.Threshold), streamer, util.FormatNumber(points)),
 				Streamer:  streamer,
 				ChannelID: cfg.PointsChannelID,
 			}
 
 			go func(n Notification, ruleID int64, deleteOnTrigger bool) {
-				if err := discord.Send(context.Background(), n); err != nil {
+				if err := m.send(context.Background(), discord, n); err != nil {
 					slog.Error("Failed to send points notification", "error", err)
 					return
 				}
@@ -258,6 +376,14 @@ func (m *Manager) NotifyPointsReached(streamer string, points int) {
 
 // NotifyOnline sends a streamer online notification.
 func (m *Manager) NotifyOnline(streamer string) {
+	notification := Notification{
+		Type:     NotificationTypeOnline,
+		Title:    fmt.Sprintf("🟢 %s is now live!", streamer),
+		Message:  fmt.Sprintf("**%s** just went live on Twitch!\n\nhttps://twitch.tv/%s", streamer, streamer),
+		Streamer: streamer,
+	}
+	m.notifyDesktop(notification)
+
 	m.mu.RLock()
 	discord := m.discord
 	enabled := m.discordConfig.Enabled
@@ -295,16 +421,10 @@ func (m *Manager) NotifyOnline(streamer string) {
 		return
 	}
 
-	notification := Notification{
-		Type:      NotificationTypeOnline,
-		Title:     fmt.Sprintf("🟢 %s is now live!", streamer),
-		Message:   fmt.Sprintf("**%s** just went live on Twitch!\n\nhttps://twitch.tv/%s", streamer, streamer),
-		Streamer:  streamer,
-		ChannelID: cfg.OnlineChannelID,
-	}
+	notification.ChannelID = cfg.OnlineChannelID
 
 	go func() {
-		if err := discord.Send(context.Background(), notification); err != nil {
+		if err := m.send(context.Background(), discord, notification); err != nil {
 			slog.Error("Failed to send online notification", "error", err)
 		}
 	}()
@@ -358,12 +478,82 @@ func (m *Manager) NotifyOffline(streamer string) {
 	}
 
 	go func() {
-		if err := discord.Send(context.Background(), notification); err != nil {
+		if err := m.send(context.Background(), discord, notification); err != nil {
 			slog.Error("Failed to send offline notification", "error", err)
 		}
 	}()
 }
 
+// CheckIdleStreamers sends a notification for each idle rule whose streamer
+// has not been live for at least the configured number of days. idleSince maps
+// offline streamers to the time they were last seen live; streamers missing
+// from the map are treated as active and re-arm their rules.
+func (m *Manager) CheckIdleStreamers(idleSince map[string]time.Time) {
+	m.mu.RLock()
+	discord := m.discord
+	enabled := m.discordConfig.Enabled
+	m.mu.RUnlock()
+
+	if !enabled || discord == nil {
+		return
+	}
+
+	rules, err := m.repo.GetIdleRules()
+	if err != nil {
+		slog.Error("Failed to get idle rules", "error", err)
+		return
+	}
+
+	if len(rules) == 0 {
+		return
+	}
+
+	cfg, err := m.repo.GetConfig()
+	if err != nil {
+		slog.Error("Failed to get notification config", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		since, idle := idleSince[rule.Streamer]
+		threshold := time.Duration(rule.Days) * 24 * time.Hour
+
+		if !idle || now.Sub(since) < threshold {
+			if rule.Triggered {
+				if err := m.repo.MarkIdleRuleTriggered(rule.ID, false); err != nil {
+					slog.Error("Failed to reset idle rule", "error", err)
+				}
+			}
+			continue
+		}
+
+		if rule.Triggered || cfg.IdleChannelID == "" {
+			continue
+		}
+
+		days := int(now.Sub(since).Hours() / 24)
+		notification := Notification{
+			Type:      NotificationTypeIdle,
+			Title:     fmt.Sprintf("💤 %s has been idle for %d days", rule.Streamer, days),
+			Message:   fmt.Sprintf("**%s** has not been live since %s.\nConsider removing them from your streamer list.", rule.Streamer, since.Format("2006-01-02")),
+			Streamer:  rule.Streamer,
+			ChannelID: cfg.IdleChannelID,
+		}
+
+		go func(n Notification, ruleID int64) {
+			if err := m.send(context.Background(), discord, n); err != nil {
+				slog.Error("Failed to send idle notification", "error", err)
+				return
+			}
+
+			if err := m.repo.MarkIdleRuleTriggered(ruleID, true); err != nil {
+				slog.Error("Failed to mark idle rule triggered", "error", err)
+			}
+		}(notification, rule.ID)
+	}
+}
+
 // GetDiscordChannels returns available Discord channels.
 func (m *Manager) GetDiscordChannels(ctx context.Context, forceRefresh bool) ([]Channel, error) {
 	m.mu.RLock()
@@ -415,6 +605,37 @@ func (m *Manager) UpdateDiscordConfig(cfg *config.DiscordSettings) error {
 	return nil
 }
 
+// UpdateDesktopConfig updates the desktop notification configuration.
+func (m *Manager) UpdateDesktopConfig(cfg *config.DesktopSettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.desktopConfig = cfg
+
+	if !cfg.Enabled {
+		if m.desktop != nil {
+			_ = m.desktop.Disconnect()
+			m.desktop = nil
+			slog.Info("Desktop notifications disabled")
+		}
+		return nil
+	}
+
+	if m.desktop != nil {
+		return nil
+	}
+
+	desktop := NewDesktopProvider()
+	if err := desktop.Connect(context.Background()); err != nil {
+		slog.Error("Failed to connect desktop provider", "error", err)
+		return err
+	}
+	m.desktop = desktop
+
+	slog.Info("Desktop notifications enabled")
+	return nil
+}
+
 // InitializePointsTracking sets the initial points values for all streamers.
 func (m *Manager) InitializePointsTracking(streamerPoints map[string]int) {
 	m.mu.Lock()
@@ -451,7 +672,7 @@ func (m *Manager) SendTestNotifications() (int, error) {
 
 	// Test mention notification
 	if cfg.MentionsChannelID != "" {
-		err := discord.Send(ctx, Notification{
+		err := m.send(ctx, discord, Notification{
 			Type:      NotificationTypeMention,
 			Title:     "Test Mention",
 			Message:   "TestUser mentioned you in TestStreamer's chat:\n> Hey @you, this is a test mention notification!",
@@ -468,7 +689,7 @@ func (m *Manager) SendTestNotifications() (int, error) {
 
 	// Test points notification
 	if cfg.PointsChannelID != "" {
-		err := discord.Send(ctx, Notification{
+		err := m.send(ctx, discord, Notification{
 			Type:      NotificationTypePointsReached,
 			Title:     "Test Points Goal",
 			Message:   "You reached 100,000 points in TestStreamer's channel!",
@@ -485,7 +706,7 @@ func (m *Manager) SendTestNotifications() (int, error) {
 
 	// Test online notification
 	if cfg.OnlineChannelID != "" {
-		err := discord.Send(ctx, Notification{
+		err := m.send(ctx, discord, Notification{
 			Type:      NotificationTypeOnline,
 			Title:     "Test Online",
 			Message:   "TestStreamer is now live!",
@@ -502,7 +723,7 @@ func (m *Manager) SendTestNotifications() (int, error) {
 
 	// Test offline notification
 	if cfg.OfflineChannelID != "" {
-		err := discord.Send(ctx, Notification{
+		err := m.send(ctx, discord, Notification{
 			Type:      NotificationTypeOffline,
 			Title:     "Test Offline",
 			Message:   "TestStreamer has gone offline.",
@@ -517,6 +738,59 @@ func (m *Manager) SendTestNotifications() (int, error) {
 		}
 	}
 
+	// Test idle notification
+	if cfg.IdleChannelID != "" {
+		err := m.send(ctx, discord, Notification{
+			Type:      NotificationTypeIdle,
+			Title:     "Test Idle Streamer",
+			Message:   "TestStreamer has not been live for 30 days.",
+			Streamer:  "TestStreamer",
+			ChannelID: cfg.IdleChannelID,
+			Color:     ColorIdle,
+		})
+		if err != nil {
+			slog.Error("Test idle notification failed", "error", err)
+		} else {
+			sent++
+		}
+	}
+
+	// Test error notification
+	if cfg.ErrorsChannelID != "" {
+		err := m.send(ctx, discord, Notification{
+			Type:      NotificationTypeError,
+			Title:     "Test Error",
+			Message:   "Authentication failed: the auth token has expired.",
+			ChannelID: cfg.ErrorsChannelID,
+			Color:     ColorError,
+		})
+		if err != nil {
+			slog.Error("Test error notification failed", "error", err)
+		} else {
+			sent++
+		}
+	}
+
+	// Test weekly summary notification
+	if cfg.SummaryChannelID != "" {
+		n := summaryNotification(analytics.PredictionSummary{
+			Streamer:    "TestStreamer",
+			Predictions: 12,
+			Wins:        7,
+			Losses:      4,
+			Refunds:     1,
+			NetPoints:   15230,
+			BiggestWin:  9800,
+			BiggestLoss: -5000,
+		}, cfg.SummaryChannelID)
+		n.Title = "Test Weekly Summary"
+		if err := m.send(ctx, discord, n); err != nil {
+			slog.Error("Test summary notification failed", "error", err)
+		} else {
+			sent++
+		}
+	}
+
 	if sent == 0 {
 		return 0, fmt.Errorf("no channels configured")
 	}
@@ -1,5 +1,7 @@
 package notifications
 
+import "time"
+
 // NotificationConfig represents notification settings stored in the database.
 type NotificationConfig struct {
 	// Channel mappings
@@ -7,6 +9,10 @@ type NotificationConfig struct {
 	PointsChannelID   string `json:"pointsChannelId"`
 	OnlineChannelID   string `json:"onlineChannelId"`
 	OfflineChannelID  string `json:"offlineChannelId"`
+	IdleChannelID     string `json:"idleChannelId"`
+	SummaryChannelID  string `json:"summaryChannelId"`
+	ErrorsChannelID   string `json:"errorsChannelId"`
+	BetsChannelID     string `json:"betsChannelId"`
 
 	// Mention settings
 	MentionsEnabled   bool     `json:"mentionsEnabled"`
@@ -22,6 +28,14 @@ type NotificationConfig struct {
 	OfflineEnabled      bool     `json:"offlineEnabled"`
 	OfflineAllStreamers bool     `json:"offlineAllStreamers"`
 	OfflineStreamers    []string `json:"offlineStreamers"`
+
+	// Weekly prediction summary settings (weekday 0 = Sunday, hour in local time)
+	SummaryEnabled bool `json:"summaryEnabled"`
+	SummaryWeekday int  `json:"summaryWeekday"`
+	SummaryHour    int  `json:"summaryHour"`
+
+	// Critical error notifications (auth failures, API/PubSub outages, database errors)
+	ErrorsEnabled bool `json:"errorsEnabled"`
 }
 
 // PointRule represents a point threshold notification rule.
@@ -33,6 +47,35 @@ type PointRule struct {
 	Triggered       bool   `json:"triggered"`
 }
 
+// IdleRule alerts when a streamer has not been live for a number of days.
+type IdleRule struct {
+	ID        int64  `json:"id"`
+	Streamer  string `json:"streamer"`
+	Days      int    `json:"days"`
+	Triggered bool   `json:"triggered"`
+}
+
+// LogEntry records one notification dispatched to a provider.
+type LogEntry struct {
+	ID        int64            `json:"id"`
+	Timestamp time.Time        `json:"timestamp"`
+	Type      NotificationType `json:"type"`
+	Provider  string           `json:"provider"`
+	Streamer  string           `json:"streamer,omitempty"`
+	ChannelID string           `json:"channelId,omitempty"`
+	Title     string           `json:"title"`
+	Success   bool             `json:"success"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// LogFilter selects notification log entries. Zero values match everything.
+type LogFilter struct {
+	Type  NotificationType
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
 // DefaultNotificationConfig returns sensible defaults for new users.
 func DefaultNotificationConfig() NotificationConfig {
 	return NotificationConfig{
@@ -42,5 +85,8 @@ func DefaultNotificationConfig() NotificationConfig {
 		OnlineAllStreamers:  true,
 		OfflineEnabled:      false,
 		OfflineAllStreamers: true,
+		SummaryEnabled:      false,
+		SummaryWeekday:      int(time.Monday),
+		SummaryHour:         9,
 	}
 }
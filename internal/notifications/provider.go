@@ -1,6 +1,9 @@
 package notifications
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // NotificationType represents the type of notification being sent.
 type NotificationType string
@@ -10,6 +13,12 @@ const (
 	NotificationTypePointsReached NotificationType = "points"
 	NotificationTypeOnline        NotificationType = "online"
 	NotificationTypeOffline       NotificationType = "offline"
+	NotificationTypeIdle          NotificationType = "idle"
+	NotificationTypeSummary       NotificationType = "summary"
+	NotificationTypeStreamSummary NotificationType = "stream_summary"
+	NotificationTypeDrop          NotificationType = "drop"
+	NotificationTypeError         NotificationType = "error"
+	NotificationTypeBetApproval   NotificationType = "bet_approval"
 )
 
 // Notification represents a notification to be sent.
@@ -20,6 +29,11 @@ type Notification struct {
 	Streamer  string
 	ChannelID string
 	Color     int
+
+	// Approve, if set, asks for an answer: providers that support it call
+	// Approve with the answer given before Expires.
+	Approve func(approve bool)
+	Expires time.Time
 }
 
 // Provider defines the interface for notification providers.
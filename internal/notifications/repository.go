@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/database"
 )
@@ -53,6 +54,65 @@ func (m *NotificationsModule) Migrations() []database.Migration {
 				INSERT OR IGNORE INTO notification_config (id) VALUES (1);
 			`,
 		},
+		{
+			Version:     2,
+			Description: "Add idle streamer rules",
+			SQL: `
+				ALTER TABLE notification_config ADD COLUMN idle_channel_id TEXT DEFAULT '';
+
+				CREATE TABLE IF NOT EXISTS idle_rules (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					streamer TEXT NOT NULL,
+					days INTEGER NOT NULL,
+					triggered INTEGER DEFAULT 0
+				);
+			`,
+		},
+		{
+			Version:     3,
+			Description: "Add weekly prediction summary settings",
+			SQL: `
+				ALTER TABLE notification_config ADD COLUMN summary_channel_id TEXT DEFAULT '';
+				ALTER TABLE notification_config ADD COLUMN summary_enabled INTEGER DEFAULT 0;
+				ALTER TABLE notification_config ADD COLUMN summary_weekday INTEGER DEFAULT 1;
+				ALTER TABLE notification_config ADD COLUMN summary_hour INTEGER DEFAULT 9;
+				ALTER TABLE notification_config ADD COLUMN summary_last_sent INTEGER DEFAULT 0;
+			`,
+		},
+		{
+			Version:     4,
+			Description: "Add critical error notification settings",
+			SQL: `
+				ALTER TABLE notification_config ADD COLUMN errors_channel_id TEXT DEFAULT '';
+				ALTER TABLE notification_config ADD COLUMN errors_enabled INTEGER DEFAULT 0;
+			`,
+		},
+		{
+			Version:     5,
+			Description: "Create notifications_log table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS notifications_log (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					timestamp INTEGER NOT NULL,
+					type TEXT NOT NULL,
+					provider TEXT NOT NULL,
+					streamer TEXT DEFAULT '',
+					channel_id TEXT DEFAULT '',
+					title TEXT DEFAULT '',
+					success INTEGER NOT NULL,
+					error TEXT DEFAULT ''
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_notifications_log_timestamp ON notifications_log(timestamp);
+			`,
+		},
+		{
+			Version:     6,
+			Description: "Add bet approval channel",
+			SQL: `
+				ALTER TABLE notification_config ADD COLUMN bets_channel_id TEXT DEFAULT '';
+			`,
+		},
 	}
 }
 
@@ -75,10 +135,12 @@ func (r *Repository) GetConfig() (*NotificationConfig, error) {
 
 	row := r.db.QueryRow(`
 		SELECT 
-			mentions_channel_id, points_channel_id, online_channel_id, offline_channel_id,
+			mentions_channel_id, points_channel_id, online_channel_id, offline_channel_id, idle_channel_id,
 			mentions_enabled, mentions_all_chats, mentions_streamers,
 			online_enabled, online_all_streamers, online_streamers,
-			offline_enabled, offline_all_streamers, offline_streamers
+			offline_enabled, offline_all_streamers, offline_streamers,
+			summary_channel_id, summary_enabled, summary_weekday, summary_hour,
+			errors_channel_id, errors_enabled, bets_channel_id
 		FROM notification_config WHERE id = 1
 	`)
 
@@ -86,10 +148,12 @@ func (r *Repository) GetConfig() (*NotificationConfig, error) {
 	var mentionsStreamersJSON, onlineStreamersJSON, offlineStreamersJSON string
 
 	err := row.Scan(
-		&cfg.MentionsChannelID, &cfg.PointsChannelID, &cfg.OnlineChannelID, &cfg.OfflineChannelID,
+		&cfg.MentionsChannelID, &cfg.PointsChannelID, &cfg.OnlineChannelID, &cfg.OfflineChannelID, &cfg.IdleChannelID,
 		&cfg.MentionsEnabled, &cfg.MentionsAllChats, &mentionsStreamersJSON,
 		&cfg.OnlineEnabled, &cfg.OnlineAllStreamers, &onlineStreamersJSON,
 		&cfg.OfflineEnabled, &cfg.OfflineAllStreamers, &offlineStreamersJSON,
+		&cfg.SummaryChannelID, &cfg.SummaryEnabled, &cfg.SummaryWeekday, &cfg.SummaryHour,
+		&cfg.ErrorsChannelID, &cfg.ErrorsEnabled, &cfg.BetsChannelID,
 	)
 	if err != nil {
 		return nil, err
@@ -126,6 +190,7 @@ func (r *Repository) SaveConfig(cfg *NotificationConfig) error {
 			points_channel_id = ?,
 			online_channel_id = ?,
 			offline_channel_id = ?,
+			idle_channel_id = ?,
 			mentions_enabled = ?,
 			mentions_all_chats = ?,
 			mentions_streamers = ?,
@@ -134,13 +199,22 @@ func (r *Repository) SaveConfig(cfg *NotificationConfig) error {
 			online_streamers = ?,
 			offline_enabled = ?,
 			offline_all_streamers = ?,
-			offline_streamers = ?
+			offline_streamers = ?,
+			summary_channel_id = ?,
+			summary_enabled = ?,
+			summary_weekday = ?,
+			summary_hour = ?,
+			errors_channel_id = ?,
+			errors_enabled = ?,
+			bets_channel_id = ?
 		WHERE id = 1
 	`,
-		cfg.MentionsChannelID, cfg.PointsChannelID, cfg.OnlineChannelID, cfg.OfflineChannelID,
+		cfg.MentionsChannelID, cfg.PointsChannelID, cfg.OnlineChannelID, cfg.OfflineChannelID, cfg.IdleChannelID,
 		cfg.MentionsEnabled, cfg.MentionsAllChats, string(mentionsStreamersJSON),
 		cfg.OnlineEnabled, cfg.OnlineAllStreamers, string(onlineStreamersJSON),
 		cfg.OfflineEnabled, cfg.OfflineAllStreamers, string(offlineStreamersJSON),
+		cfg.SummaryChannelID, cfg.SummaryEnabled, cfg.SummaryWeekday, cfg.SummaryHour,
+		cfg.ErrorsChannelID, cfg.ErrorsEnabled, cfg.BetsChannelID,
 	)
 
 	return err
@@ -236,3 +310,165 @@ func (r *Repository) ResetPointRuleIfBelow(streamer string, points int) error {
 
 	return err
 }
+
+func (r *Repository) GetIdleRules() ([]IdleRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rows, err := r.db.Query(`
+		SELECT id, streamer, days, triggered
+		FROM idle_rules ORDER BY streamer, days
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var rules []IdleRule
+	for rows.Next() {
+		var rule IdleRule
+		if err := rows.Scan(&rule.ID, &rule.Streamer, &rule.Days, &rule.Triggered); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *Repository) AddIdleRule(rule *IdleRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, err := r.db.Exec(`
+		INSERT INTO idle_rules (streamer, days, triggered)
+		VALUES (?, ?, 0)
+	`, rule.Streamer, rule.Days)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rule.ID = id
+
+	return nil
+}
+
+func (r *Repository) DeleteIdleRule(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.db.Exec(`DELETE FROM idle_rules WHERE id = ?`, id)
+	return err
+}
+
+func (r *Repository) MarkIdleRuleTriggered(id int64, triggered bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.db.Exec(`UPDATE idle_rules SET triggered = ? WHERE id = ?`, triggered, id)
+	return err
+}
+
+func (r *Repository) GetSummaryLastSent() (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ts int64
+	if err := r.db.QueryRow(`SELECT summary_last_sent FROM notification_config WHERE id = 1`).Scan(&ts); err != nil {
+		return time.Time{}, err
+	}
+	if ts == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(ts, 0), nil
+}
+
+func (r *Repository) SetSummaryLastSent(t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.db.Exec(`UPDATE notification_config SET summary_last_sent = ? WHERE id = 1`, t.Unix())
+	return err
+}
+
+func (r *Repository) AddLogEntry(entry *LogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, err := r.db.Exec(`
+		INSERT INTO notifications_log (timestamp, type, provider, streamer, channel_id, title, success, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.Timestamp.UnixMilli(), entry.Type, entry.Provider, entry.Streamer, entry.ChannelID, entry.Title, entry.Success, entry.Error)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = id
+
+	return nil
+}
+
+// GetLog returns the log entries matching the filter, newest first.
+func (r *Repository) GetLog(filter LogFilter) ([]LogEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query := `
+		SELECT id, timestamp, type, provider, streamer, channel_id, title, success, error
+		FROM notifications_log WHERE 1=1`
+	var args []interface{}
+
+	if filter.Type != "" {
+		query += ` AND type = ?`
+		args = append(args, filter.Type)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND timestamp >= ?`
+		args = append(args, filter.Since.UnixMilli())
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND timestamp <= ?`
+		args = append(args, filter.Until.UnixMilli())
+	}
+	query += ` ORDER BY timestamp DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []LogEntry
+	for rows.Next() {
+		var entry LogEntry
+		var ts int64
+		if err := rows.Scan(&entry.ID, &ts, &entry.Type, &entry.Provider, &entry.Streamer, &entry.ChannelID, &entry.Title, &entry.Success, &entry.Error); err != nil {
+			return nil, err
+		}
+		entry.Timestamp = time.UnixMilli(ts)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// PruneLog deletes log entries recorded before the given time.
+func (r *Repository) PruneLog(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.db.Exec(`DELETE FROM notifications_log WHERE timestamp < ?`, before.UnixMilli())
+	return err
+}
@@ -0,0 +1,202 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+	"github.com/PatrickWalther/twitch-miner-go/internal/util"
+)
+
+const (
+	// summaryCheckInterval is how often the scheduler checks whether a weekly summary is due.
+	summaryCheckInterval = time.Minute
+
+	// summaryGracePeriod is how late a missed summary may still be sent,
+	// e.g. when the miner was not running at the scheduled time.
+	summaryGracePeriod = 24 * time.Hour
+
+	summaryPeriod = 7 * 24 * time.Hour
+)
+
+// PredictionSummarySource provides aggregated prediction results for a period.
+type PredictionSummarySource interface {
+	GetPredictionSummaries(startTime, endTime time.Time) ([]analytics.PredictionSummary, error)
+}
+
+// SetPredictionSummarySource sets where weekly prediction summaries read their data from.
+func (m *Manager) SetPredictionSummarySource(source PredictionSummarySource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summarySource = source
+}
+
+func (m *Manager) summaryLoop(ctx context.Context) {
+	ticker := time.NewTicker(summaryCheckInterval)
+	defer ticker.Stop()
+
+	m.pruneLog()
+	pruneTicker := time.NewTicker(24 * time.Hour)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.checkWeeklySummary(now)
+		case <-pruneTicker.C:
+			m.pruneLog()
+		}
+	}
+}
+
+// lastSummarySchedule returns the most recent scheduled summary time at or before now.
+func lastSummarySchedule(now time.Time, weekday time.Weekday, hour int) time.Time {
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	daysBack := (int(now.Weekday()) - int(weekday) + 7) % 7
+	scheduled = scheduled.AddDate(0, 0, -daysBack)
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -7)
+	}
+	return scheduled
+}
+
+func (m *Manager) checkWeeklySummary(now time.Time) {
+	m.mu.RLock()
+	discord := m.discord
+	enabled := m.discordConfig.Enabled
+	source := m.summarySource
+	m.mu.RUnlock()
+
+	if !enabled || discord == nil || source == nil {
+		return
+	}
+
+	cfg, err := m.repo.GetConfig()
+	if err != nil {
+		slog.Error("Failed to get notification config", "error", err)
+		return
+	}
+
+	if !cfg.SummaryEnabled || cfg.SummaryChannelID == "" {
+		return
+	}
+
+	scheduled := lastSummarySchedule(now, time.Weekday(cfg.SummaryWeekday), cfg.SummaryHour)
+	if now.Sub(scheduled) > summaryGracePeriod {
+		return
+	}
+
+	lastSent, err := m.repo.GetSummaryLastSent()
+	if err != nil {
+		slog.Error("Failed to get last summary time", "error", err)
+		return
+	}
+	if !lastSent.Before(scheduled) {
+		return
+	}
+
+	// Mark as sent first so a slow or failing send is not repeated every minute.
+	if err := m.repo.SetSummaryLastSent(now); err != nil {
+		slog.Error("Failed to record summary time", "error", err)
+		return
+	}
+
+	summaries, err := source.GetPredictionSummaries(scheduled.Add(-summaryPeriod), scheduled)
+	if err != nil {
+		slog.Error("Failed to build prediction summaries", "error", err)
+		return
+	}
+
+	if len(summaries) == 0 {
+		slog.Info("Weekly prediction summary skipped: no predictions this week")
+		return
+	}
+
+	slog.Info("Sending weekly prediction summaries", "streamers", len(summaries))
+
+	go func() {
+		for _, summary := range summaries {
+			if err := m.send(context.Background(), discord, summaryNotification(summary, cfg.SummaryChannelID)); err != nil {
+				slog.Error("Failed to send prediction summary", "streamer", summary.Streamer, "error", err)
+			}
+		}
+	}()
+}
+
+// NotifyStreamSummary posts the statistics of a stream that just ended to the
+// offline notification channel. Whether to send one is a per-streamer setting,
+// so the offline notification toggle and streamer list do not apply.
+func (m *Manager) NotifyStreamSummary(session analytics.StreamSession) {
+	m.mu.RLock()
+	discord := m.discord
+	enabled := m.discordConfig.Enabled
+	m.mu.RUnlock()
+
+	if !enabled || discord == nil {
+		return
+	}
+
+	cfg, err := m.repo.GetConfig()
+	if err != nil {
+		slog.Error("Failed to get notification config", "error", err)
+		return
+	}
+
+	if cfg.OfflineChannelID == "" {
+		slog.Debug("Stream summary skipped: no offline channel configured")
+		return
+	}
+
+	notification := streamSummaryNotification(session, cfg.OfflineChannelID)
+	go func() {
+		if err := m.send(context.Background(), discord, notification); err != nil {
+			slog.Error("Failed to send stream summary", "streamer", session.Streamer, "error", err)
+		}
+	}()
+}
+
+func streamSummaryNotification(session analytics.StreamSession, channelID string) Notification {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Stream length:** %s\n", util.FormatDuration(session.EndedAt.Sub(session.StartedAt)))
+	fmt.Fprintf(&b, "**Points:** %s (%s → %s)\n", util.FormatSigned(session.Gained),
+		util.FormatNumber(session.PointsStart), util.FormatNumber(session.PointsEnd))
+	if session.WatchStreak > 0 {
+		fmt.Fprintf(&b, "**Watch streak:** %s\n", util.FormatSigned(session.WatchStreak))
+	}
+	if predictions := session.PredictionsWon + session.PredictionsLost + session.PredictionsRefunded; predictions > 0 {
+		fmt.Fprintf(&b, "**Predictions:** %d won, %d lost, %d refunded (%s)\n",
+			session.PredictionsWon, session.PredictionsLost, session.PredictionsRefunded, util.FormatSigned(session.PredictionNet))
+	}
+	fmt.Fprintf(&b, "**Watched:** %s min", util.FormatNumber(session.MinutesWatched))
+
+	return Notification{
+		Type:      NotificationTypeStreamSummary,
+		Title:     fmt.Sprintf("📺 Stream summary: %s", session.Streamer),
+		Message:   b.String(),
+		Streamer:  session.Streamer,
+		ChannelID: channelID,
+	}
+}
+
+func summaryNotification(summary analytics.PredictionSummary, channelID string) Notification {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Predictions:** %d (%d won, %d lost, %d refunded)\n",
+		summary.Predictions, summary.Wins, summary.Losses, summary.Refunds)
+	fmt.Fprintf(&b, "**Win rate:** %.1f%%\n", summary.WinRate())
+	fmt.Fprintf(&b, "**Net points:** %s\n", util.FormatSigned(summary.NetPoints))
+	fmt.Fprintf(&b, "**Biggest win:** %s\n", util.FormatSigned(summary.BiggestWin))
+	fmt.Fprintf(&b, "**Biggest loss:** %s", util.FormatSigned(summary.BiggestLoss))
+
+	return Notification{
+		Type:      NotificationTypeSummary,
+		Title:     fmt.Sprintf("📊 Weekly predictions: %s", summary.Streamer),
+		Message:   b.String(),
+		Streamer:  summary.Streamer,
+		ChannelID: channelID,
+	}
+}
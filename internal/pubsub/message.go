@@ -89,6 +89,11 @@ func extractChannelID(data map[string]interface{}, defaultID string) string {
 			return id
 		}
 	}
+	if redemption, ok := data["redemption"].(map[string]interface{}); ok {
+		if id, ok := redemption["channel_id"].(string); ok {
+			return id
+		}
+	}
 	if id, ok := data["channel_id"].(string); ok {
 		return id
 	}
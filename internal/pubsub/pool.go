@@ -1,29 +1,103 @@
 package pubsub
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/api"
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/connectivity"
 	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/crash"
+	"github.com/PatrickWalther/twitch-miner-go/internal/features"
+	"github.com/PatrickWalther/twitch-miner-go/internal/jobs"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+	"github.com/PatrickWalther/twitch-miner-go/internal/util"
 )
 
 type MessageHandler func(msg *PubSubMessage, streamer *models.Streamer)
 type StatusHandler func(streamer string, online bool)
 
+// ErrorHandler is called for connection-level errors such as ErrBadAuth or ErrReconnectFailed.
+type ErrorHandler func(err error)
+
+// PredictionResultHandler is called when a prediction the miner bet on resolves.
+type PredictionResultHandler func(event *models.EventPrediction, placed, won, gained int)
+
+// PredictionSkipHandler is called when a prediction is skipped because the
+// account is not allowed to bet on it.
+type PredictionSkipHandler func(event *models.EventPrediction, reason string)
+
+// PredictionLateHandler is called when a bet is rejected or given up because
+// the prediction had already locked.
+type PredictionLateHandler func(event *models.EventPrediction, reason string)
+
+// PredictionTimingHandler is called once both the bet on a prediction and the
+// prediction's lock have been seen.
+type PredictionTimingHandler func(streamer *models.Streamer, timing models.BetTiming)
+
+// BetApprovalHandler is called when a bet larger than the confirmation
+// threshold needs approval before deadline. amount is the bet the miner
+// would place now.
+type BetApprovalHandler func(event *models.EventPrediction, amount int, deadline time.Time)
+
+// PredictionOpenedHandler is called when a prediction opens and its bet is
+// scheduled.
+type PredictionOpenedHandler func(event *models.EventPrediction)
+
+// ErrNoApprovalPending is returned when answering an approval for a prediction
+// that is not tracked or not waiting for one.
+var ErrNoApprovalPending = errors.New("no bet approval pending")
+
+// ErrBetNotScheduled is returned when overriding the bet on a prediction that
+// is not tracked or whose bet is no longer waiting for its time.
+var ErrBetNotScheduled = errors.New("no scheduled bet")
+
+// approvalMargin is how long before the lock the miner stops waiting for a
+// bet approval, leaving time to place the bet.
+const approvalMargin = 2 * time.Second
+
+// Bounds of the random wait before a claim with the HumanizedDelays flag.
+const (
+	humanDelayMin = 2 * time.Second
+	humanDelayMax = 15 * time.Second
+)
+
+// repackInterval is how often topics left on sparse connections by
+// unsubscribes are consolidated onto fewer connections.
+const repackInterval = 30 * time.Minute
+
 type WebSocketPool struct {
 	clients     []*WebSocketClient
+	nextIndex   int
 	client      *api.TwitchClient
 	streamers   []*models.Streamer
 	authToken   string
 	settings    config.RateLimitSettings
 	predictions map[string]*models.EventPrediction
-
-	onMessage      MessageHandler
-	onStatusChange StatusHandler
+	jobs        *jobs.Queue
+	network     *connectivity.Monitor
+
+	raidDenylist []string
+	betConfirm   config.BetConfirmSettings
+	features     *features.Flags
+
+	onMessage          MessageHandler
+	onStatusChange     StatusHandler
+	onPredictionSkip   PredictionSkipHandler
+	onPredictionResult PredictionResultHandler
+	onPredictionTiming PredictionTimingHandler
+	onPredictionLate   PredictionLateHandler
+	onBetApproval      BetApprovalHandler
+	onPredictionOpened PredictionOpenedHandler
+	onError            ErrorHandler
 
 	mu sync.RWMutex
 }
@@ -46,22 +120,338 @@ func (p *WebSocketPool) SetStatusHandler(handler StatusHandler) {
 	p.onStatusChange = handler
 }
 
+func (p *WebSocketPool) SetPredictionResultHandler(handler PredictionResultHandler) {
+	p.onPredictionResult = handler
+}
+
+func (p *WebSocketPool) SetPredictionSkipHandler(handler PredictionSkipHandler) {
+	p.onPredictionSkip = handler
+}
+
+func (p *WebSocketPool) SetPredictionTimingHandler(handler PredictionTimingHandler) {
+	p.onPredictionTiming = handler
+}
+
+func (p *WebSocketPool) SetPredictionLateHandler(handler PredictionLateHandler) {
+	p.onPredictionLate = handler
+}
+
+func (p *WebSocketPool) SetBetApprovalHandler(handler BetApprovalHandler) {
+	p.onBetApproval = handler
+}
+
+func (p *WebSocketPool) SetPredictionOpenedHandler(handler PredictionOpenedHandler) {
+	p.onPredictionOpened = handler
+}
+
+// SetErrorHandler sets the handler for connection-level errors.
+func (p *WebSocketPool) SetErrorHandler(handler ErrorHandler) {
+	p.onError = handler
+}
+
+// SetJobQueue sets the queue through which claims and contributions are retried.
+func (p *WebSocketPool) SetJobQueue(queue *jobs.Queue) {
+	p.jobs = queue
+}
+
+// SetConnectivity sets the monitor that keeps connection errors quiet while
+// the network is down. Must be called before Start.
+func (p *WebSocketPool) SetConnectivity(monitor *connectivity.Monitor) {
+	p.network = monitor
+}
+
+// SetFeatures sets the feature flags checked when claiming. The flags are
+// shared, so later toggles apply without calling it again.
+func (p *WebSocketPool) SetFeatures(flags *features.Flags) {
+	p.features = flags
+}
+
+// SetRaidDenylist sets the games/categories whose raids are never joined.
+// Names are matched case-insensitively against the target's game name and display name.
+func (p *WebSocketPool) SetRaidDenylist(games []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.raidDenylist = games
+}
+
+// SetBetConfirm sets when bets wait for approval. Predictions already
+// scheduled keep the settings they were scheduled with until their bet.
+func (p *WebSocketPool) SetBetConfirm(settings config.BetConfirmSettings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.betConfirm = settings
+}
+
+// ResolveBetApproval approves or rejects the large bet waiting on a
+// prediction.
+func (p *WebSocketPool) ResolveBetApproval(eventID string, approve bool) error {
+	p.mu.RLock()
+	event, exists := p.predictions[eventID]
+	p.mu.RUnlock()
+
+	if !exists || !event.ResolveApproval(approve) {
+		return ErrNoApprovalPending
+	}
+
+	slog.Info("Bet approval answered",
+		"streamer", event.Streamer.GetUsername(),
+		"event", event.Title,
+		"approved", approve,
+	)
+	return nil
+}
+
+// OverridePrediction skips the scheduled bet on a prediction or places it
+// right away. Either answers a pending bet approval: a forced bet counts as
+// approved.
+func (p *WebSocketPool) OverridePrediction(eventID string, override models.BetOverride) error {
+	p.mu.RLock()
+	event, exists := p.predictions[eventID]
+	p.mu.RUnlock()
+
+	if !exists || !event.SetOverride(override) {
+		return ErrBetNotScheduled
+	}
+	if override == models.OverrideSkip {
+		event.SkipBet("skipped from the dashboard")
+	}
+	event.ResolveApproval(override == models.OverrideForce)
+
+	slog.Info("Prediction bet overridden",
+		"streamer", event.Streamer.GetUsername(),
+		"event", event.Title,
+		"override", override,
+	)
+	return nil
+}
+
+// minManualBet is the smallest bet Twitch accepts.
+const minManualBet = 10
+
+// PlaceManualBet replaces the scheduled bet on a prediction with amount
+// points on outcomeID and places it right away. The bet must be between
+// minManualBet and the streamer's balance, and is refused once the
+// scheduled bet is due or the event locks within approvalMargin. A pending
+// bet approval counts as approved.
+func (p *WebSocketPool) PlaceManualBet(eventID, outcomeID string, amount int) error {
+	p.mu.RLock()
+	event, exists := p.predictions[eventID]
+	p.mu.RUnlock()
+
+	if !exists {
+		return ErrBetNotScheduled
+	}
+
+	choice := event.OutcomeIndex(outcomeID)
+	if choice < 0 {
+		return fmt.Errorf("%w: unknown outcome %q", models.ErrInvalidBet, outcomeID)
+	}
+	balance := event.Streamer.GetChannelPoints()
+	if amount < minManualBet || amount > balance {
+		return fmt.Errorf("%w: amount must be between %d and the balance of %d", models.ErrInvalidBet, minManualBet, balance)
+	}
+	if time.Until(event.LockAt()) < approvalMargin {
+		return fmt.Errorf("%w: the prediction locks too soon", ErrBetNotScheduled)
+	}
+
+	decision, ok := event.SetManualBet(choice, amount)
+	if !ok {
+		return ErrBetNotScheduled
+	}
+	event.ResolveApproval(true)
+
+	slog.Info("Placing manual prediction bet",
+		"streamer", event.Streamer.GetUsername(),
+		"event", event.Title,
+		"choice", choice,
+		"amount", amount,
+	)
+
+	err := p.client.PlacePrediction(event, decision)
+	switch {
+	case errors.Is(err, api.ErrPredictionRestricted):
+		p.skipPrediction(event, err.Error())
+	case errors.Is(err, api.ErrPredictionLocked):
+		p.lateBet(event, err.Error())
+	case err != nil:
+		event.SkipBet(err.Error())
+	default:
+		p.reportBetTiming(event)
+	}
+	return err
+}
+
+// ActivePredictions returns the tracked predictions that are still open for
+// bets or locked awaiting a result, oldest first.
+func (p *WebSocketPool) ActivePredictions() []models.PredictionSnapshot {
+	p.mu.RLock()
+	events := make([]*models.EventPrediction, 0, len(p.predictions))
+	for _, event := range p.predictions {
+		events = append(events, event)
+	}
+	p.mu.RUnlock()
+
+	var active []models.PredictionSnapshot
+	for _, event := range events {
+		switch event.GetStatus() {
+		case models.PredictionActive, models.PredictionLocked:
+			active = append(active, event.Snapshot(event.Streamer.GetChannelPoints()))
+		}
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].CreatedAt.Before(active[j].CreatedAt)
+	})
+	return active
+}
+
+// Submit listens to the topic on the first connection with room for it, so
+// slots freed by Unsubscribe are reused before another connection is opened.
 func (p *WebSocketPool) Submit(topic Topic) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if len(p.clients) == 0 || p.clients[len(p.clients)-1].TopicCount() >= constants.MaxTopicsPerConnection {
-		ws := NewWebSocketClient(len(p.clients), p.authToken, p.settings.WebsocketPingInterval, p.handleMessage, p.handleError)
-		if err := ws.Connect(); err != nil {
+	var target *WebSocketClient
+	for _, ws := range p.clients {
+		if ws.HasTopic(topic) {
+			return nil
+		}
+		if target == nil && ws.TopicCount() < constants.MaxTopicsPerConnection {
+			target = ws
+		}
+	}
+
+	if target == nil {
+		target = NewWebSocketClient(p.nextIndex, p.authToken, p.settings.WebsocketPingInterval, p.network, p.handleMessage, p.handleError)
+		if err := target.Connect(); err != nil {
 			return err
 		}
-		p.clients = append(p.clients, ws)
+		p.clients = append(p.clients, target)
+		p.nextIndex++
 	}
 
-	p.clients[len(p.clients)-1].Listen(topic)
+	target.Listen(topic)
 	return nil
 }
 
+// Connections returns the number of open PubSub connections.
+func (p *WebSocketPool) Connections() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.clients)
+}
+
+// Start repacks the connections every repackInterval until ctx is done.
+func (p *WebSocketPool) Start(ctx context.Context) {
+	go crash.Loop(ctx.Done(), "pubsub repack", func() { p.repackLoop(ctx) })
+}
+
+func (p *WebSocketPool) repackLoop(ctx context.Context) {
+	ticker := time.NewTicker(repackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if p.idle() {
+				p.Repack()
+			}
+		}
+	}
+}
+
+// idle reports whether the connections can be repacked without risk: no
+// prediction is open for betting and every LISTEN has been acknowledged.
+func (p *WebSocketPool) idle() bool {
+	return len(p.ActivePredictions()) == 0 && len(p.UnacknowledgedTopics()) == 0
+}
+
+// Repack moves the topics of surplus connections onto the fullest ones and
+// closes the emptied connections, so the pool uses the fewest connections
+// that fit every topic. A moved topic is LISTENed on its new connection before
+// the old one is closed, so a message in between may arrive twice but none is
+// missed. It returns the number of connections closed.
+func (p *WebSocketPool) Repack() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	total := 0
+	for _, ws := range p.clients {
+		total += ws.TopicCount()
+	}
+	needed := (total + constants.MaxTopicsPerConnection - 1) / constants.MaxTopicsPerConnection
+	if len(p.clients) <= needed {
+		return 0
+	}
+
+	byCount := append([]*WebSocketClient(nil), p.clients...)
+	sort.SliceStable(byCount, func(i, j int) bool {
+		return byCount[i].TopicCount() > byCount[j].TopicCount()
+	})
+	keep := make(map[*WebSocketClient]bool, needed)
+	for _, ws := range byCount[:needed] {
+		keep[ws] = true
+	}
+
+	for _, surplus := range byCount[needed:] {
+		for _, topic := range surplus.Topics() {
+			for _, ws := range byCount[:needed] {
+				if ws.TopicCount() < constants.MaxTopicsPerConnection {
+					ws.Listen(topic)
+					break
+				}
+			}
+		}
+		surplus.Close()
+	}
+
+	before := len(p.clients)
+	kept := p.clients[:0]
+	for _, ws := range p.clients {
+		if keep[ws] {
+			kept = append(kept, ws)
+		}
+	}
+	p.clients = kept
+
+	slog.Info("Repacked PubSub connections", "topics", total, "before", before, "after", len(p.clients))
+	return before - len(p.clients)
+}
+
+// UnacknowledgedTopics returns the LISTENs of every connection that Twitch has
+// not acknowledged yet, ordered by topic.
+func (p *WebSocketPool) UnacknowledgedTopics() []TopicStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	statuses := make([]TopicStatus, 0)
+	for _, ws := range p.clients {
+		statuses = append(statuses, ws.UnacknowledgedTopics()...)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Topic < statuses[j].Topic
+	})
+	return statuses
+}
+
+// TopicStats returns the LISTEN results of every subscribed topic, ordered by
+// topic.
+func (p *WebSocketPool) TopicStats() []TopicStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := make([]TopicStats, 0)
+	for _, ws := range p.clients {
+		stats = append(stats, ws.TopicStats()...)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Topic < stats[j].Topic
+	})
+	return stats
+}
+
 func (p *WebSocketPool) Close() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -72,6 +462,28 @@ func (p *WebSocketPool) Close() {
 	p.clients = nil
 }
 
+// Reconnect reconnects every PubSub connection, keeping their topics.
+func (p *WebSocketPool) Reconnect() {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, ws := range p.clients {
+		go ws.Reconnect()
+	}
+}
+
+// UpdateAuthToken switches every connection, and those opened later, to a
+// refreshed auth token without dropping any topics.
+func (p *WebSocketPool) UpdateAuthToken(authToken string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.authToken = authToken
+	for _, ws := range p.clients {
+		ws.UpdateAuthToken(authToken)
+	}
+}
+
 func (p *WebSocketPool) Unsubscribe(topic Topic) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -91,7 +503,7 @@ func (p *WebSocketPool) UpdateStreamers(streamers []*models.Streamer) {
 
 func (p *WebSocketPool) findStreamer(channelID string) *models.Streamer {
 	for _, s := range p.streamers {
-		if s.ChannelID == channelID {
+		if s.GetChannelID() == channelID {
 			return s
 		}
 	}
@@ -99,6 +511,8 @@ func (p *WebSocketPool) findStreamer(channelID string) *models.Streamer {
 }
 
 func (p *WebSocketPool) handleMessage(msg *PubSubMessage) {
+	defer crash.Recover("pubsub " + string(msg.Topic.Type))
+
 	streamer := p.findStreamer(msg.ChannelID)
 	if streamer == nil {
 		return
@@ -149,7 +563,7 @@ func (p *WebSocketPool) handleCommunityPointsUser(msg *PubSubMessage, streamer *
 					reasonCode = rc
 				}
 				slog.Info("Points earned",
-					"streamer", streamer.Username,
+					"streamer", streamer.GetUsername(),
 					"points", earned,
 					"reason", reasonCode,
 				)
@@ -158,13 +572,14 @@ func (p *WebSocketPool) handleCommunityPointsUser(msg *PubSubMessage, streamer *
 		}
 
 	case "claim-available":
-		if msg.Data == nil {
+		if msg.Data == nil || !streamer.GetEnabled() || streamer.GetSettings().DropsOnly {
 			return
 		}
 		if claim, ok := msg.Data["claim"].(map[string]interface{}); ok {
 			if claimID, ok := claim["id"].(string); ok {
-				if err := p.client.ClaimBonus(streamer, claimID); err != nil {
-					slog.Error("Failed to claim bonus", "error", err)
+				payload := jobs.ClaimBonusPayload{Streamer: streamer.GetUsername(), ClaimID: claimID}
+				if err := p.jobs.EnqueueAfter(jobs.KindClaimBonus, claimID, payload, p.claimDelay()); err != nil {
+					slog.Error("Failed to queue bonus claim", "streamer", streamer.GetUsername(), "error", err)
 				}
 			}
 		}
@@ -174,13 +589,13 @@ func (p *WebSocketPool) handleCommunityPointsUser(msg *PubSubMessage, streamer *
 func (p *WebSocketPool) handleVideoPlayback(msg *PubSubMessage, streamer *models.Streamer) {
 	switch msg.Type {
 	case "stream-up":
-		streamer.StreamUpTime = time.Now()
+		streamer.SetStreamUpTime(time.Now())
 	case "stream-down":
 		if streamer.GetIsOnline() {
 			streamer.SetOffline()
-			slog.Info("Streamer went offline", "streamer", streamer.Username)
+			slog.Info("Streamer went offline", "streamer", streamer.GetUsername())
 			if p.onStatusChange != nil {
-				p.onStatusChange(streamer.Username, false)
+				p.onStatusChange(streamer.GetUsername(), false)
 			}
 		}
 	case "viewcount":
@@ -188,14 +603,14 @@ func (p *WebSocketPool) handleVideoPlayback(msg *PubSubMessage, streamer *models
 		if streamer.StreamUpElapsed() {
 			p.client.CheckStreamerOnline(streamer)
 			if !wasOnline && streamer.GetIsOnline() && p.onStatusChange != nil {
-				p.onStatusChange(streamer.Username, true)
+				p.onStatusChange(streamer.GetUsername(), true)
 			}
 		}
 	}
 }
 
 func (p *WebSocketPool) handleRaid(msg *PubSubMessage, streamer *models.Streamer) {
-	if msg.Type != "raid_update_v2" || !streamer.Settings.FollowRaid {
+	if msg.Type != "raid_update_v2" || !streamer.GetEnabled() || !streamer.GetSettings().FollowRaid || streamer.GetSettings().DropsOnly {
 		return
 	}
 
@@ -208,6 +623,15 @@ func (p *WebSocketPool) handleRaid(msg *PubSubMessage, streamer *models.Streamer
 	targetLogin, _ := raidData["target_login"].(string)
 
 	if raidID != "" && targetLogin != "" {
+		if game := p.deniedRaidGame(targetLogin); game != "" {
+			slog.Info("Skipping raid to denylisted category",
+				"from", streamer.GetUsername(),
+				"to", targetLogin,
+				"game", game,
+			)
+			return
+		}
+
 		raid := &models.Raid{
 			RaidID:      raidID,
 			TargetLogin: targetLogin,
@@ -218,8 +642,36 @@ func (p *WebSocketPool) handleRaid(msg *PubSubMessage, streamer *models.Streamer
 	}
 }
 
+// deniedRaidGame returns the target's game if it is on the raid denylist.
+// If the target's category cannot be resolved, the raid is allowed.
+func (p *WebSocketPool) deniedRaidGame(targetLogin string) string {
+	p.mu.RLock()
+	denylist := p.raidDenylist
+	p.mu.RUnlock()
+
+	if len(denylist) == 0 {
+		return ""
+	}
+
+	game, err := p.client.GetStreamGame(targetLogin)
+	if err != nil {
+		slog.Debug("Failed to resolve raid target category", "target", targetLogin, "error", err)
+		return ""
+	}
+	if game == nil {
+		return ""
+	}
+
+	for _, denied := range denylist {
+		if strings.EqualFold(denied, game.Name) || strings.EqualFold(denied, game.DisplayName) {
+			return game.DisplayName
+		}
+	}
+	return ""
+}
+
 func (p *WebSocketPool) handleMoment(msg *PubSubMessage, streamer *models.Streamer) {
-	if msg.Type != "active" || !streamer.Settings.ClaimMoments {
+	if msg.Type != "active" || !streamer.GetEnabled() || !streamer.GetSettings().ClaimMoments || streamer.GetSettings().DropsOnly {
 		return
 	}
 
@@ -228,14 +680,25 @@ func (p *WebSocketPool) handleMoment(msg *PubSubMessage, streamer *models.Stream
 	}
 
 	if momentID, ok := msg.Data["moment_id"].(string); ok {
-		if err := p.client.ClaimMoment(streamer, momentID); err != nil {
-			slog.Error("Failed to claim moment", "error", err)
+		payload := jobs.ClaimMomentPayload{Streamer: streamer.GetUsername(), MomentID: momentID}
+		if err := p.jobs.EnqueueAfter(jobs.KindClaimMoment, momentID, payload, p.claimDelay()); err != nil {
+			slog.Error("Failed to queue moment claim", "streamer", streamer.GetUsername(), "error", err)
 		}
 	}
 }
 
+// claimDelay is how long to wait before claiming a bonus chest or moment: a
+// random humanDelayMin to humanDelayMax with the HumanizedDelays flag, none
+// otherwise.
+func (p *WebSocketPool) claimDelay() time.Duration {
+	if !p.features.Enabled(features.HumanizedDelays) {
+		return 0
+	}
+	return humanDelayMin + time.Duration(rand.Int63n(int64(humanDelayMax-humanDelayMin)))
+}
+
 func (p *WebSocketPool) handlePredictionChannel(msg *PubSubMessage, streamer *models.Streamer) {
-	if !streamer.Settings.MakePredictions {
+	if !streamer.GetEnabled() || !streamer.GetSettings().MakePredictions || streamer.GetSettings().DropsOnly {
 		return
 	}
 
@@ -279,6 +742,7 @@ func (p *WebSocketPool) handlePredictionChannel(msg *PubSubMessage, streamer *mo
 			eventStatus,
 			outcomes,
 		)
+		event.OpenWindowSeconds = predictionWindowSeconds
 
 		if !streamer.GetIsOnline() {
 			return
@@ -289,12 +753,12 @@ func (p *WebSocketPool) handlePredictionChannel(msg *PubSubMessage, streamer *mo
 			return
 		}
 
-		if streamer.Settings.Bet.MinimumPoints > 0 &&
-			streamer.GetChannelPoints() <= streamer.Settings.Bet.MinimumPoints {
+		minimum := streamer.GetSettings().Bet.MinimumPoints
+		if points := streamer.GetChannelPoints(); minimum > 0 && points <= minimum {
 			slog.Info("Not enough points for prediction",
-				"streamer", streamer.Username,
-				"points", streamer.GetChannelPoints(),
-				"minimum", streamer.Settings.Bet.MinimumPoints,
+				"streamer", streamer.GetUsername(),
+				"points", points,
+				"minimum", minimum,
 			)
 			return
 		}
@@ -304,22 +768,47 @@ func (p *WebSocketPool) handlePredictionChannel(msg *PubSubMessage, streamer *mo
 		p.mu.Unlock()
 
 		slog.Info("Prediction event scheduled",
-			"streamer", streamer.Username,
+			"streamer", streamer.GetUsername(),
 			"event", title,
 			"placeIn", closingBetAfter,
 		)
+		confirm := p.requestApproval(event)
+		if p.onPredictionOpened != nil {
+			p.onPredictionOpened(event)
+		}
 
 		go func() {
-			time.Sleep(time.Duration(closingBetAfter) * time.Second)
+			defer crash.Recover("prediction")
+
+			override := event.WaitBetTime(time.Duration(closingBetAfter) * time.Second)
 			p.mu.RLock()
 			evt, exists := p.predictions[eventID]
 			p.mu.RUnlock()
 
-			if exists && evt.Status == models.PredictionActive {
-				if err := p.client.MakePrediction(evt); err != nil {
-					slog.Error("Failed to make prediction", "error", err)
+			if !exists || override == models.OverrideSkip || override == models.OverrideManual {
+				return
+			}
+			p.settleApproval(evt, confirm)
+			if !evt.Biddable() {
+				if evt.GetStatus() == models.PredictionLocked {
+					p.lateBet(evt, "event locked before the bet")
 				}
+				return
 			}
+
+			if err := p.client.MakePrediction(evt); err != nil {
+				switch {
+				case errors.Is(err, api.ErrPredictionRestricted):
+					p.skipPrediction(evt, err.Error())
+					return
+				case errors.Is(err, api.ErrPredictionLocked):
+					p.lateBet(evt, err.Error())
+					return
+				}
+				evt.SkipBet(err.Error())
+				slog.Error("Failed to make prediction", "error", err)
+			}
+			p.reportBetTiming(evt)
 		}()
 
 	case "event-updated":
@@ -331,16 +820,113 @@ func (p *WebSocketPool) handlePredictionChannel(msg *PubSubMessage, streamer *mo
 			return
 		}
 
-		event.Status = models.PredictionStatus(eventStatus)
+		outcomes, _ := eventData["outcomes"].([]interface{})
+		event.Update(models.PredictionStatus(eventStatus), outcomes)
 
-		if !event.BetPlaced && event.Bet.Decision.ID == "" {
-			if outcomes, ok := eventData["outcomes"].([]interface{}); ok {
-				event.Bet.UpdateOutcomes(outcomes)
+		if models.PredictionStatus(eventStatus) == models.PredictionLocked {
+			lockedAt := time.Now()
+			if v, ok := eventData["locked_at"].(string); ok {
+				if t, err := time.Parse(time.RFC3339, v); err == nil {
+					lockedAt = t
+				}
 			}
+			event.MarkLocked(lockedAt, outcomes)
+			p.reportBetTiming(event)
 		}
 	}
 }
 
+// requestApproval asks for approval of the event's bet if it would exceed the
+// confirmation threshold, and returns the confirmation settings the bet is
+// placed with.
+func (p *WebSocketPool) requestApproval(event *models.EventPrediction) config.BetConfirmSettings {
+	p.mu.RLock()
+	confirm := p.betConfirm
+	p.mu.RUnlock()
+
+	if !confirm.Enabled {
+		return confirm
+	}
+
+	amount := event.Snapshot(event.Streamer.GetChannelPoints()).Decision.Amount
+	if amount <= confirm.Threshold {
+		return confirm
+	}
+
+	event.RequestApproval()
+	deadline := event.LockAt().Add(-approvalMargin)
+	slog.Info("Large bet awaiting approval",
+		"streamer", event.Streamer.GetUsername(),
+		"event", event.Title,
+		"amount", amount,
+		"threshold", confirm.Threshold,
+		"deadline", deadline.Format(time.TimeOnly),
+	)
+
+	if p.onBetApproval != nil {
+		p.onBetApproval(event, amount, deadline)
+	}
+	return confirm
+}
+
+// settleApproval waits for a pending approval until shortly before the event
+// locks. Unless the bet was approved, bets over the threshold are then skipped
+// or lowered to it, including ones that only grew past it since scheduling.
+func (p *WebSocketPool) settleApproval(event *models.EventPrediction, confirm config.BetConfirmSettings) {
+	if !confirm.Enabled {
+		return
+	}
+	if event.WaitApproval(event.LockAt().Add(-approvalMargin)) == models.ApprovalApproved {
+		return
+	}
+	event.LimitStake(confirm.Threshold, confirm.Fallback != config.BetConfirmReduce)
+}
+
+// lateBet records that the bet on an event came after its lock and reports it
+// to the late handler.
+func (p *WebSocketPool) lateBet(event *models.EventPrediction, reason string) {
+	event.MarkBetLate()
+	event.SkipBet(reason)
+
+	slog.Warn("Prediction bet too late",
+		"streamer", event.Streamer.GetUsername(),
+		"event", event.Title,
+		"reason", reason,
+	)
+
+	if p.onPredictionLate != nil {
+		p.onPredictionLate(event, reason)
+	}
+	p.reportBetTiming(event)
+}
+
+// reportBetTiming passes the event's bet timing to the timing handler once
+// both the bet and the lock are known.
+func (p *WebSocketPool) reportBetTiming(event *models.EventPrediction) {
+	if p.onPredictionTiming == nil {
+		return
+	}
+	if timing, ok := event.TakeBetTiming(); ok {
+		p.onPredictionTiming(event.Streamer, timing)
+	}
+}
+
+// skipPrediction marks an event as not biddable so it is never retried and
+// reports the reason to the prediction skip handler.
+func (p *WebSocketPool) skipPrediction(event *models.EventPrediction, reason string) {
+	event.MarkNotBiddable(reason)
+
+	slog.Warn("Prediction not biddable, skipping",
+		"streamer", event.Streamer.GetUsername(),
+		"event", event.Title,
+		"reason", reason,
+	)
+
+	if p.onPredictionSkip != nil {
+		p.onPredictionSkip(event, reason)
+	}
+}
+
 func (p *WebSocketPool) handlePredictionUser(msg *PubSubMessage, streamer *models.Streamer) {
 	if msg.Data == nil {
 		return
@@ -363,11 +949,14 @@ func (p *WebSocketPool) handlePredictionUser(msg *PubSubMessage, streamer *model
 
 	switch msg.Type {
 	case "prediction-made":
-		event.BetConfirmed = true
+		if !event.IsBetConfirmed() {
+			streamer.RecordPredictionPlaced()
+		}
+		event.ConfirmBet()
 		slog.Info("Prediction confirmed", "event", event.Title)
 
 	case "prediction-result":
-		if !event.BetConfirmed {
+		if !event.IsBetConfirmed() {
 			return
 		}
 
@@ -377,8 +966,6 @@ func (p *WebSocketPool) handlePredictionUser(msg *PubSubMessage, streamer *model
 		}
 
 		placed, won, gained := event.ParseResult(result)
-		_ = placed
-		_ = won
 
 		slog.Info("Prediction result",
 			"event", event.Title,
@@ -393,12 +980,17 @@ func (p *WebSocketPool) handlePredictionUser(msg *PubSubMessage, streamer *model
 			streamer.UpdateHistoryWithCounter("REFUND", -placed, -1)
 		case models.ResultWin:
 			streamer.UpdateHistoryWithCounter("PREDICTION", -won, -1)
+			streamer.RecordPredictionWon()
+		}
+
+		if p.onPredictionResult != nil {
+			p.onPredictionResult(event, placed, won, gained)
 		}
 	}
 }
 
 func (p *WebSocketPool) handleCommunityPointsChannel(msg *PubSubMessage, streamer *models.Streamer) {
-	if !streamer.Settings.CommunityGoals {
+	if !streamer.GetEnabled() || !streamer.GetSettings().CommunityGoals || streamer.GetSettings().DropsOnly {
 		return
 	}
 
@@ -430,15 +1022,28 @@ func (p *WebSocketPool) handleCommunityPointsChannel(msg *PubSubMessage, streame
 }
 
 func (p *WebSocketPool) contributeToGoals(streamer *models.Streamer) {
-	for _, goal := range streamer.CommunityGoals {
+	available := streamer.GetChannelPoints()
+	for _, goal := range streamer.GetCommunityGoals() {
 		if goal.Status == models.CommunityGoalStarted && goal.IsInStock {
 			amountLeft := goal.AmountLeft()
-			if amountLeft > 0 && streamer.GetChannelPoints() > 0 {
-				amount := min(amountLeft, streamer.GetChannelPoints())
+			if amountLeft > 0 && available > 0 {
+				amount := min(amountLeft, available)
 				if amount > 0 {
-					if err := p.client.ContributeToCommunityGoal(streamer, goal.GoalID, goal.Title, amount); err != nil {
-						slog.Error("Failed to contribute to community goal", "error", err)
+					payload := jobs.ContributeGoalPayload{
+						Streamer:      streamer.GetUsername(),
+						GoalID:        goal.GoalID,
+						Title:         goal.Title,
+						Amount:        amount,
+						TransactionID: util.RandomHex(16),
+					}
+					// Keyed on the goal's progress so repeated updates for the
+					// same state do not queue a second contribution.
+					key := fmt.Sprintf("%s:%d", goal.GoalID, goal.PointsContributed)
+					if err := p.jobs.Enqueue(jobs.KindContributeGoal, key, payload); err != nil {
+						slog.Error("Failed to queue community goal contribution", "error", err)
+						continue
 					}
+					available -= amount
 				}
 			}
 		}
@@ -447,6 +1052,9 @@ func (p *WebSocketPool) contributeToGoals(streamer *models.Streamer) {
 
 func (p *WebSocketPool) handleError(err error) {
 	slog.Error("WebSocket error", "error", err)
+	if p.onError != nil {
+		p.onError(err)
+	}
 }
 
 func min(a, b int) int {
@@ -14,6 +14,29 @@ const (
 	TopicCommunityPointsChannel  TopicType = "community-points-channel-v1"
 )
 
+// TopicAuth is the credential a topic needs to be subscribed.
+type TopicAuth int
+
+const (
+	// TopicAuthNone topics are public channel events.
+	TopicAuthNone TopicAuth = iota
+	// TopicAuthUser topics carry the account's own events and are only
+	// delivered when the LISTEN includes its auth token.
+	TopicAuthUser
+)
+
+// topicAuth declares the auth requirement of every topic type. Types missing
+// here need no token.
+var topicAuth = map[TopicType]TopicAuth{
+	TopicCommunityPointsUser:     TopicAuthUser,
+	TopicPredictionsUser:         TopicAuthUser,
+	TopicVideoPlaybackByID:       TopicAuthNone,
+	TopicRaid:                    TopicAuthNone,
+	TopicPredictionsChannel:      TopicAuthNone,
+	TopicCommunityMomentsChannel: TopicAuthNone,
+	TopicCommunityPointsChannel:  TopicAuthNone,
+}
+
 type Topic struct {
 	Type      TopicType
 	ChannelID string
@@ -27,8 +50,30 @@ func (t Topic) String() string {
 	return fmt.Sprintf("%s.%s", t.Type, t.ChannelID)
 }
 
+// Auth returns the credential the topic needs.
+func (t Topic) Auth() TopicAuth {
+	return topicAuth[t.Type]
+}
+
 func (t Topic) IsUserTopic() bool {
-	return t.Type == TopicCommunityPointsUser || t.Type == TopicPredictionsUser
+	return t.Auth() == TopicAuthUser
+}
+
+// Request builds the LISTEN or UNLISTEN message for the topic. authToken is
+// only included if the topic needs it.
+func (t Topic) Request(msgType, authToken string) WSMessage {
+	data := &WSData{
+		Topics: []string{t.String()},
+	}
+	if t.Auth() == TopicAuthUser {
+		data.AuthToken = authToken
+	}
+
+	return WSMessage{
+		Type:  msgType,
+		Nonce: generateNonce(),
+		Data:  data,
+	}
 }
 
 func ParseTopic(topicStr string) (Topic, error) {
@@ -4,28 +4,91 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	mathrand "math/rand"
 	"sync"
 	"time"
 
+	"github.com/PatrickWalther/twitch-miner-go/internal/connectivity"
 	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
 	"github.com/gorilla/websocket"
 )
 
+// maxReconnectFailures is the number of consecutive failed reconnects after
+// which the connection is reported as down.
+const maxReconnectFailures = 5
+
+const (
+	// listenRetryBase is the wait before retrying a failed LISTEN. It doubles
+	// with every attempt up to listenRetryMax.
+	listenRetryBase = 5 * time.Second
+	listenRetryMax  = 5 * time.Minute
+	// listenAckTimeout is how long a LISTEN may go unanswered before it is
+	// sent again.
+	listenAckTimeout = time.Minute
+)
+
+// listenRequest is a LISTEN that Twitch has not acknowledged yet, either
+// awaiting its RESPONSE or waiting to be retried after an error.
+type listenRequest struct {
+	topic     Topic
+	nonce     string
+	sentAt    time.Time
+	attempts  int
+	lastError string
+}
+
+// pendingRequest is a LISTEN or UNLISTEN awaiting its RESPONSE.
+type pendingRequest struct {
+	msgType string
+	topic   Topic
+	sentAt  time.Time
+}
+
+// TopicStats counts a topic's LISTEN results on its connection since the
+// topic was first subscribed.
+type TopicStats struct {
+	Topic       string    `json:"topic"`
+	Connection  int       `json:"connection"`
+	Listens     int       `json:"listens"`
+	Acks        int       `json:"acks"`
+	Errors      int       `json:"errors"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastAckAt   time.Time `json:"last_ack_at"`
+	LastErrorAt time.Time `json:"last_error_at"`
+}
+
+// TopicStatus is an unacknowledged LISTEN, for diagnostics.
+type TopicStatus struct {
+	Topic      string    `json:"topic"`
+	Connection int       `json:"connection"`
+	Attempts   int       `json:"attempts"`
+	SentAt     time.Time `json:"sent_at"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
 type WebSocketClient struct {
 	index         int
 	conn          *websocket.Conn
 	topics        []Topic
 	pendingTopics []Topic
+	unacked       map[string]*listenRequest
+	requests      map[string]pendingRequest
+	stats         map[string]*TopicStats
 	authToken     string
 	pingInterval  int
+	network       *connectivity.Monitor
 
 	isOpened       bool
 	isClosed       bool
 	isReconnecting bool
 	forcedClose    bool
 
+	reconnectFailures int
+
 	lastPong    time.Time
 	lastPing    time.Time
 	lastMsgTime time.Time
@@ -39,16 +102,20 @@ type WebSocketClient struct {
 	stopChan chan struct{}
 }
 
-func NewWebSocketClient(index int, authToken string, pingInterval int, onMessage func(*PubSubMessage), onError func(error)) *WebSocketClient {
+func NewWebSocketClient(index int, authToken string, pingInterval int, network *connectivity.Monitor, onMessage func(*PubSubMessage), onError func(error)) *WebSocketClient {
 	return &WebSocketClient{
 		index:         index,
 		authToken:     authToken,
 		pingInterval:  pingInterval,
+		network:       network,
 		onMessage:     onMessage,
 		onError:       onError,
 		stopChan:      make(chan struct{}),
 		topics:        make([]Topic, 0),
 		pendingTopics: make([]Topic, 0),
+		unacked:       make(map[string]*listenRequest),
+		requests:      make(map[string]pendingRequest),
+		stats:         make(map[string]*TopicStats),
 	}
 }
 
@@ -59,6 +126,7 @@ func (ws *WebSocketClient) Connect() error {
 	ws.mu.Unlock()
 
 	dialer := websocket.Dialer{
+		TLSClientConfig:  httpx.TLSConfig(),
 		HandshakeTimeout: 30 * time.Second,
 	}
 
@@ -70,6 +138,7 @@ func (ws *WebSocketClient) Connect() error {
 	ws.mu.Lock()
 	ws.conn = conn
 	ws.isOpened = true
+	ws.reconnectFailures = 0
 	ws.lastPong = time.Now()
 	ws.mu.Unlock()
 
@@ -123,22 +192,134 @@ func (ws *WebSocketClient) Listen(topic Topic) {
 		ws.mu.Unlock()
 		return
 	}
+	authToken := ws.authToken
 	ws.mu.Unlock()
 
-	data := &WSData{
-		Topics: []string{topic.String()},
+	ws.listen(topic, authToken)
+}
+
+// listen sends a LISTEN for the topic and tracks it by nonce until Twitch
+// acknowledges it.
+func (ws *WebSocketClient) listen(topic Topic, authToken string) {
+	msg := topic.Request("LISTEN", authToken)
+
+	ws.mu.Lock()
+	req, ok := ws.unacked[topic.String()]
+	if !ok {
+		req = &listenRequest{topic: topic}
+		ws.unacked[topic.String()] = req
 	}
-	if topic.IsUserTopic() {
-		data.AuthToken = ws.authToken
+	req.nonce = msg.Nonce
+	req.sentAt = time.Now()
+	req.attempts++
+	ws.topicStats(topic).Listens++
+	ws.mu.Unlock()
+
+	ws.sendRequest(msg, topic)
+}
+
+// sendRequest sends a LISTEN or UNLISTEN and remembers its nonce, so the
+// RESPONSE can be attributed to the topic.
+func (ws *WebSocketClient) sendRequest(msg WSMessage, topic Topic) {
+	ws.mu.Lock()
+	ws.requests[msg.Nonce] = pendingRequest{msgType: msg.Type, topic: topic, sentAt: time.Now()}
+	ws.mu.Unlock()
+
+	_ = ws.send(msg)
+}
+
+// topicStats returns the topic's stats, creating them on first use. The
+// caller must hold ws.mu.
+func (ws *WebSocketClient) topicStats(topic Topic) *TopicStats {
+	stats, ok := ws.stats[topic.String()]
+	if !ok {
+		stats = &TopicStats{Topic: topic.String(), Connection: ws.index}
+		ws.stats[topic.String()] = stats
 	}
+	return stats
+}
+
+// retryListen sends the LISTEN again unless it was answered, superseded or
+// the topic dropped in the meantime.
+func (ws *WebSocketClient) retryListen(topic Topic, nonce string) {
+	ws.mu.RLock()
+	req, ok := ws.unacked[topic.String()]
+	current := ok && req.nonce == nonce && ws.isOpened && !ws.isClosed
+	authToken := ws.authToken
+	ws.mu.RUnlock()
 
-	msg := WSMessage{
-		Type:  "LISTEN",
-		Nonce: generateNonce(),
-		Data:  data,
+	if current {
+		ws.listen(topic, authToken)
 	}
+}
 
-	_ = ws.send(msg)
+// resendStaleListens sends again every LISTEN that got no RESPONSE within
+// listenAckTimeout and forgets requests that will not be answered anymore.
+func (ws *WebSocketClient) resendStaleListens() {
+	ws.mu.Lock()
+	for nonce, req := range ws.requests {
+		if time.Since(req.sentAt) > listenAckTimeout {
+			delete(ws.requests, nonce)
+		}
+	}
+	var stale []Topic
+	if ws.isOpened && !ws.isClosed {
+		for _, req := range ws.unacked {
+			if req.lastError == "" && time.Since(req.sentAt) > listenAckTimeout {
+				stale = append(stale, req.topic)
+			}
+		}
+	}
+	authToken := ws.authToken
+	ws.mu.Unlock()
+
+	for _, topic := range stale {
+		slog.Warn("LISTEN not acknowledged, sending again", "index", ws.index, "topic", topic.String())
+		ws.listen(topic, authToken)
+	}
+}
+
+// UnacknowledgedTopics returns the LISTENs Twitch has not acknowledged yet.
+func (ws *WebSocketClient) UnacknowledgedTopics() []TopicStatus {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	statuses := make([]TopicStatus, 0, len(ws.unacked))
+	for _, req := range ws.unacked {
+		statuses = append(statuses, TopicStatus{
+			Topic:      req.topic.String(),
+			Connection: ws.index,
+			Attempts:   req.attempts,
+			SentAt:     req.sentAt,
+			LastError:  req.lastError,
+		})
+	}
+	return statuses
+}
+
+// TopicStats returns the LISTEN results of every topic on the connection.
+func (ws *WebSocketClient) TopicStats() []TopicStats {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	stats := make([]TopicStats, 0, len(ws.stats))
+	for _, s := range ws.stats {
+		stats = append(stats, *s)
+	}
+	return stats
+}
+
+// listenBackoff is the wait before the next try of a LISTEN that failed after
+// the given number of attempts.
+func listenBackoff(attempts int) time.Duration {
+	delay := listenRetryBase
+	for i := 1; i < attempts && delay < listenRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > listenRetryMax {
+		return listenRetryMax
+	}
+	return delay
 }
 
 func (ws *WebSocketClient) Unlisten(topic Topic) bool {
@@ -161,28 +342,46 @@ func (ws *WebSocketClient) Unlisten(topic Topic) bool {
 		}
 	}
 	ws.pendingTopics = remainingPending
+	delete(ws.unacked, topic.String())
+	delete(ws.stats, topic.String())
 
 	isOpened := ws.isOpened
+	authToken := ws.authToken
 	ws.mu.Unlock()
 
 	if found && isOpened {
-		data := &WSData{
-			Topics: []string{topic.String()},
-		}
-		if topic.IsUserTopic() {
-			data.AuthToken = ws.authToken
-		}
+		ws.sendRequest(topic.Request("UNLISTEN", authToken), topic)
+	}
 
-		msg := WSMessage{
-			Type:  "UNLISTEN",
-			Nonce: generateNonce(),
-			Data:  data,
+	return found
+}
+
+// UpdateAuthToken replaces the token used for user topics and LISTENs to the
+// ones already subscribed again with it. Channel topics need no token and are
+// left untouched.
+func (ws *WebSocketClient) UpdateAuthToken(authToken string) {
+	ws.mu.Lock()
+	ws.authToken = authToken
+	var userTopics []Topic
+	if ws.isOpened {
+		for _, t := range ws.topics {
+			if t.IsUserTopic() {
+				userTopics = append(userTopics, t)
+			}
 		}
+	}
+	ws.mu.Unlock()
 
-		_ = ws.send(msg)
+	for _, topic := range userTopics {
+		ws.listen(topic, authToken)
 	}
+}
 
-	return found
+// Topics returns the topics the connection listens to.
+func (ws *WebSocketClient) Topics() []Topic {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return append([]Topic(nil), ws.topics...)
 }
 
 func (ws *WebSocketClient) HasTopic(topic Topic) bool {
@@ -244,7 +443,11 @@ func (ws *WebSocketClient) readLoop() {
 			forcedClose := ws.forcedClose
 			ws.mu.RUnlock()
 
-			if !forcedClose {
+			switch {
+			case forcedClose:
+			case !ws.network.Online():
+				slog.Debug("WebSocket read error while offline", "index", ws.index, "error", err)
+			default:
 				slog.Error("WebSocket read error", "index", ws.index, "error", err)
 				if ws.onError != nil {
 					ws.onError(err)
@@ -299,12 +502,7 @@ func (ws *WebSocketClient) handleMessage(msg WSMessage) {
 		}
 
 	case "RESPONSE":
-		if msg.Error != "" {
-			slog.Error("WebSocket response error", "index", ws.index, "error", msg.Error)
-			if ws.onError != nil && msg.Error == "ERR_BADAUTH" {
-				ws.onError(ErrBadAuth)
-			}
-		}
+		ws.handleResponse(msg)
 
 	case "RECONNECT":
 		slog.Info("WebSocket reconnect requested", "index", ws.index)
@@ -312,6 +510,60 @@ func (ws *WebSocketClient) handleMessage(msg WSMessage) {
 	}
 }
 
+// handleResponse attributes a RESPONSE to the topic of the request with the
+// same nonce and counts the result. A failed LISTEN is retried with backoff,
+// except on ERR_BADAUTH: the auth recovery LISTENs again with the new token.
+func (ws *WebSocketClient) handleResponse(msg WSMessage) {
+	ws.mu.Lock()
+	req, known := ws.requests[msg.Nonce]
+	delete(ws.requests, msg.Nonce)
+
+	retry := false
+	attempts := 0
+	if known && req.msgType == "LISTEN" {
+		stats := ws.topicStats(req.topic)
+		listen, unacked := ws.unacked[req.topic.String()]
+		if msg.Error == "" {
+			stats.Acks++
+			stats.LastAckAt = time.Now()
+			delete(ws.unacked, req.topic.String())
+		} else {
+			stats.Errors++
+			stats.LastError = msg.Error
+			stats.LastErrorAt = time.Now()
+			if unacked && listen.nonce == msg.Nonce {
+				listen.lastError = msg.Error
+				retry = true
+				attempts = listen.attempts
+			}
+		}
+	}
+	ws.mu.Unlock()
+
+	if msg.Error == "" {
+		return
+	}
+
+	topic := ""
+	if known {
+		topic = req.topic.String()
+	}
+	slog.Error("WebSocket response error", "index", ws.index, "type", req.msgType, "topic", topic, "error", msg.Error)
+	if msg.Error == "ERR_BADAUTH" {
+		if ws.onError != nil {
+			ws.onError(ErrBadAuth)
+		}
+		return
+	}
+	if !retry {
+		return
+	}
+
+	delay := listenBackoff(attempts)
+	slog.Info("Retrying LISTEN", "index", ws.index, "topic", topic, "attempts", attempts, "in", delay)
+	time.AfterFunc(delay, func() { ws.retryListen(req.topic, msg.Nonce) })
+}
+
 func (ws *WebSocketClient) randomPingInterval() time.Duration {
 	base := float64(ws.pingInterval)
 	jitter := (mathrand.Float64() - 0.5) * 5.0
@@ -345,12 +597,24 @@ func (ws *WebSocketClient) pingLoop() {
 			if !isReconnecting && elapsed > 5*time.Minute {
 				slog.Warn("No PONG received for 5 minutes, reconnecting", "index", ws.index)
 				go ws.reconnect()
+			} else if !isReconnecting {
+				ws.resendStaleListens()
 			}
 		}
 	}
 }
 
 func (ws *WebSocketClient) reconnect() {
+	ws.reconnectAfter(60 * time.Second)
+}
+
+// Reconnect drops the connection and reconnects almost immediately, e.g. after
+// the system resumed from sleep and the socket is likely dead.
+func (ws *WebSocketClient) Reconnect() {
+	ws.reconnectAfter(time.Second)
+}
+
+func (ws *WebSocketClient) reconnectAfter(delay time.Duration) {
 	ws.mu.Lock()
 	if ws.isReconnecting || ws.forcedClose {
 		ws.mu.Unlock()
@@ -364,8 +628,8 @@ func (ws *WebSocketClient) reconnect() {
 		_ = ws.conn.Close()
 	}
 
-	slog.Info("Reconnecting WebSocket in 60 seconds", "index", ws.index)
-	time.Sleep(60 * time.Second)
+	slog.Info("Reconnecting WebSocket", "index", ws.index, "in", delay)
+	time.Sleep(delay)
 
 	ws.mu.RLock()
 	forcedClose := ws.forcedClose
@@ -381,14 +645,67 @@ func (ws *WebSocketClient) reconnect() {
 	ws.stopChan = make(chan struct{})
 	ws.pendingTopics = topics
 	ws.topics = nil
+	ws.unacked = make(map[string]*listenRequest)
+	ws.requests = make(map[string]pendingRequest)
 	ws.mu.Unlock()
 
 	if err := ws.Connect(); err != nil {
+		if !ws.network.Online() {
+			// The pool is reconnected as a whole once the network is back.
+			slog.Debug("Failed to reconnect while offline", "index", ws.index, "error", err)
+			return
+		}
 		slog.Error("Failed to reconnect", "index", ws.index, "error", err)
+
+		ws.mu.Lock()
+		ws.reconnectFailures++
+		failures := ws.reconnectFailures
+		ws.mu.Unlock()
+
+		if failures >= maxReconnectFailures && ws.onError != nil {
+			ws.onError(fmt.Errorf("%w after %d attempts: %v", ErrReconnectFailed, failures, err))
+		}
+
 		go ws.reconnect()
 	}
 }
 
+// Check opens a PubSub connection, listens to topic and waits for Twitch to
+// acknowledge the LISTEN, returning the error Twitch answered with, if any.
+// The connection is closed afterwards.
+func Check(authToken string, topic Topic, timeout time.Duration) error {
+	dialer := websocket.Dialer{
+		TLSClientConfig:  httpx.TLSConfig(),
+		HandshakeTimeout: timeout,
+	}
+
+	conn, _, err := dialer.Dial(constants.PubSubURL, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	request := topic.Request("LISTEN", authToken)
+	if err := conn.WriteJSON(request); err != nil {
+		return err
+	}
+
+	for {
+		var msg WSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return fmt.Errorf("no response to LISTEN: %w", err)
+		}
+		if msg.Type != "RESPONSE" || msg.Nonce != request.Nonce {
+			continue
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+		return nil
+	}
+}
+
 func generateNonce() string {
 	b := make([]byte, 15)
 	if _, err := rand.Read(b); err != nil {
@@ -399,6 +716,9 @@ func generateNonce() string {
 
 var ErrBadAuth = &AuthError{Message: "ERR_BADAUTH"}
 
+// ErrReconnectFailed is reported when a connection keeps failing to reconnect.
+var ErrReconnectFailed = errors.New("websocket could not reconnect")
+
 type AuthError struct {
 	Message string
 }
@@ -0,0 +1,52 @@
+package pyimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+)
+
+// AnalyticsResult counts the analytics rows imported per streamer.
+type AnalyticsResult struct {
+	Streamer    string
+	Points      int
+	Annotations int
+}
+
+// importAnalytics loads the Python miner's analytics/<username>/<streamer>.json
+// files, which share the series/annotations shape of this project's chart data.
+func importAnalytics(dir string, repo analytics.Repository) ([]AnalyticsResult, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var results []AnalyticsResult
+	for _, path := range files {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return results, err
+		}
+
+		var data analytics.StreamerData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return results, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		streamer := strings.ToLower(strings.TrimSuffix(filepath.Base(path), ".json"))
+		points, annotations, err := repo.ImportStreamerData(streamer, &data)
+		if err != nil {
+			return results, fmt.Errorf("failed to import %s: %w", path, err)
+		}
+
+		results = append(results, AnalyticsResult{Streamer: streamer, Points: points, Annotations: annotations})
+	}
+
+	return results, nil
+}
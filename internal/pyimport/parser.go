@@ -0,0 +1,384 @@
+package pyimport
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This is not a Python parser. It understands just enough of the expression
+// syntax to read the literal arguments of the calls in a run.py script:
+// strings, numbers, booleans, None, dotted names, lists and nested calls.
+// Anything else (arithmetic, f-strings with expressions, os.getenv, ...) is
+// kept as an unknown value so the caller can warn about it.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokName
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(src string) []token {
+	var toks []token
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case unicode.IsSpace(r) || r == '\\':
+			i++
+		case isStringStart(runes, i):
+			text, next := readString(runes, i)
+			toks = append(toks, token{kind: tokString, text: text})
+			i = next
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			toks = append(toks, token{kind: tokNumber, text: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			toks = append(toks, token{kind: tokName, text: string(runes[start:i])})
+		default:
+			toks = append(toks, token{kind: tokOp, text: string(r)})
+			i++
+		}
+	}
+
+	return append(toks, token{kind: tokEOF})
+}
+
+// isStringStart reports whether a string literal starts at i, including
+// prefixed literals such as r"...", f"..." or rb"...".
+func isStringStart(runes []rune, i int) bool {
+	for j := i; j < len(runes) && j < i+3; j++ {
+		switch runes[j] {
+		case '"', '\'':
+			return true
+		case 'r', 'R', 'b', 'B', 'f', 'F', 'u', 'U':
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+func readString(runes []rune, i int) (string, int) {
+	raw := false
+	for runes[i] != '"' && runes[i] != '\'' {
+		if runes[i] == 'r' || runes[i] == 'R' {
+			raw = true
+		}
+		i++
+	}
+
+	quote := runes[i]
+	triple := i+2 < len(runes) && runes[i+1] == quote && runes[i+2] == quote
+	if triple {
+		i += 3
+	} else {
+		i++
+	}
+
+	var b strings.Builder
+	for i < len(runes) {
+		r := runes[i]
+		if r == quote && (!triple || (i+2 < len(runes) && runes[i+1] == quote && runes[i+2] == quote)) {
+			if triple {
+				return b.String(), i + 3
+			}
+			return b.String(), i + 1
+		}
+		if r == '\n' && !triple {
+			break
+		}
+		if r == '\\' && !raw && i+1 < len(runes) {
+			i++
+			switch runes[i] {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case '\n':
+			default:
+				b.WriteRune(runes[i])
+			}
+			i++
+			continue
+		}
+		b.WriteRune(r)
+		i++
+	}
+	return b.String(), i
+}
+
+type valueKind int
+
+const (
+	kindUnknown valueKind = iota
+	kindString
+	kindNumber
+	kindBool
+	kindNone
+	kindName
+	kindCall
+	kindList
+)
+
+type value struct {
+	kind    valueKind
+	text    string // string literal, dotted name or source of a number
+	number  float64
+	boolean bool
+	call    *call
+	list    []value
+}
+
+type call struct {
+	name   string
+	args   []value
+	kwargs map[string]value
+	order  []string
+}
+
+func (v value) String() (string, bool) {
+	return v.text, v.kind == kindString
+}
+
+func (v value) Bool() (bool, bool) {
+	return v.boolean, v.kind == kindBool
+}
+
+func (v value) Int() (int, bool) {
+	if v.kind != kindNumber || v.number != float64(int(v.number)) {
+		return 0, false
+	}
+	return int(v.number), true
+}
+
+func (v value) Float() (float64, bool) {
+	return v.number, v.kind == kindNumber
+}
+
+// Enum returns the member name of an enum reference such as Strategy.SMART
+// or logging.INFO. Plain strings are accepted too.
+func (v value) Enum() (string, bool) {
+	switch v.kind {
+	case kindName:
+		return v.text[strings.LastIndex(v.text, ".")+1:], true
+	case kindString:
+		return v.text, true
+	}
+	return "", false
+}
+
+// Call returns the call expression, if v is a call of the given function.
+func (v value) Call(name string) (*call, bool) {
+	if v.kind != kindCall || v.call.name != name {
+		return nil, false
+	}
+	return v.call, true
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.toks) {
+		return token{kind: tokEOF}
+	}
+	return p.toks[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(text string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == text
+}
+
+func (p *parser) atValueEnd() bool {
+	t := p.peek()
+	return t.kind == tokEOF || (t.kind == tokOp && strings.Contains(",)]}", t.text))
+}
+
+// parseValue parses one argument or list element. Expressions it does not
+// understand are skipped up to the next separator and returned as unknown.
+func (p *parser) parseValue() value {
+	v := p.parsePrimary()
+	if p.atValueEnd() {
+		return v
+	}
+	p.skipExpression()
+	return value{kind: kindUnknown}
+}
+
+func (p *parser) parsePrimary() value {
+	t := p.peek()
+	switch {
+	case t.kind == tokString:
+		var b strings.Builder
+		for p.peek().kind == tokString {
+			b.WriteString(p.next().text)
+		}
+		return value{kind: kindString, text: b.String()}
+
+	case t.kind == tokNumber:
+		return parseNumber(p.next().text, false)
+
+	case t.kind == tokOp && t.text == "-" && p.peekAt(1).kind == tokNumber:
+		p.next()
+		return parseNumber(p.next().text, true)
+
+	case t.kind == tokName:
+		p.next()
+		switch t.text {
+		case "True", "False":
+			return value{kind: kindBool, boolean: t.text == "True"}
+		case "None":
+			return value{kind: kindNone}
+		}
+
+		name := t.text
+		for p.isOp(".") && p.peekAt(1).kind == tokName {
+			p.next()
+			name += "." + p.next().text
+		}
+		if p.isOp("(") {
+			return value{kind: kindCall, call: p.parseCall(name)}
+		}
+		return value{kind: kindName, text: name}
+
+	case t.kind == tokOp && (t.text == "[" || t.text == "("):
+		closing := "]"
+		if t.text == "(" {
+			closing = ")"
+		}
+		p.next()
+
+		var items []value
+		for !p.isOp(closing) && p.peek().kind != tokEOF {
+			items = append(items, p.parseValue())
+			if p.isOp(",") {
+				p.next()
+			} else if !p.isOp(closing) {
+				p.skipExpression()
+			}
+		}
+		p.next()
+		return value{kind: kindList, list: items}
+	}
+
+	return value{kind: kindUnknown}
+}
+
+func parseNumber(text string, negative bool) value {
+	n, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+	if err != nil {
+		return value{kind: kindUnknown}
+	}
+	if negative {
+		n = -n
+	}
+	return value{kind: kindNumber, number: n, text: text}
+}
+
+// parseCall parses the argument list of a call; the current token is "(".
+func (p *parser) parseCall(name string) *call {
+	c := &call{name: name, kwargs: make(map[string]value)}
+	p.next()
+
+	for !p.isOp(")") && p.peek().kind != tokEOF {
+		if p.peek().kind == tokName && p.peekAt(1).kind == tokOp && p.peekAt(1).text == "=" &&
+			!(p.peekAt(2).kind == tokOp && p.peekAt(2).text == "=") {
+			key := p.next().text
+			p.next()
+			if _, exists := c.kwargs[key]; !exists {
+				c.order = append(c.order, key)
+			}
+			c.kwargs[key] = p.parseValue()
+		} else {
+			c.args = append(c.args, p.parseValue())
+		}
+
+		if p.isOp(",") {
+			p.next()
+		} else if !p.isOp(")") {
+			p.skipExpression()
+		}
+	}
+	p.next()
+	return c
+}
+
+// skipExpression advances to the next separator outside of brackets.
+func (p *parser) skipExpression() {
+	depth := 0
+	for {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return
+		}
+		if t.kind == tokOp {
+			switch t.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				if depth == 0 {
+					return
+				}
+				depth--
+			case ",":
+				if depth == 0 {
+					return
+				}
+			}
+		}
+		p.next()
+	}
+}
+
+// findCalls returns every call of a function or method with the given name,
+// e.g. "TwitchChannelPointsMiner" or "mine" for twitch_miner.mine(...).
+func findCalls(src, name string) []*call {
+	p := &parser{toks: tokenize(src)}
+
+	var calls []*call
+	for p.peek().kind != tokEOF {
+		t := p.next()
+		if t.kind == tokName && t.text == name && p.isOp("(") {
+			calls = append(calls, p.parseCall(name))
+		}
+	}
+	return calls
+}
@@ -0,0 +1,74 @@
+package pyimport
+
+import "testing"
+
+func TestFindCalls(t *testing.T) {
+	src := `
+# f(skipped="in a comment")
+f(
+    "a, (b)",         # a string with a comma and parentheses
+    'it\'s',
+    "con" "cat",
+    """triple "quoted" """,
+    -5,
+    2.5,
+    None,
+    os.getenv("X"),
+    g(1, k=[True, False]),
+    key=Enum.MEMBER,
+)
+`
+	calls := findCalls(src, "f")
+	if len(calls) != 1 {
+		t.Fatalf("found %d calls, want 1", len(calls))
+	}
+	cl := calls[0]
+
+	literals := []string{"a, (b)", "it's", "concat", `triple "quoted" `}
+	for i, want := range literals {
+		if got, ok := cl.args[i].String(); !ok || got != want {
+			t.Errorf("args[%d] = %q, %v, want %q", i, got, ok, want)
+		}
+	}
+	if n, ok := cl.args[4].Int(); !ok || n != -5 {
+		t.Errorf("args[4] = %d, %v, want -5", n, ok)
+	}
+	if _, ok := cl.args[5].Int(); ok {
+		t.Error("args[5] is not an integer")
+	}
+	if f, ok := cl.args[5].Float(); !ok || f != 2.5 {
+		t.Errorf("args[5] = %v, %v, want 2.5", f, ok)
+	}
+	if cl.args[6].kind != kindNone {
+		t.Errorf("args[6] kind = %v, want None", cl.args[6].kind)
+	}
+	if _, ok := cl.args[7].String(); ok {
+		t.Error("os.getenv(...) was read as a literal")
+	}
+
+	g, ok := cl.args[8].Call("g")
+	if !ok {
+		t.Fatalf("args[8] is not a call of g")
+	}
+	if list := g.kwargs["k"].list; len(list) != 2 || !list[0].boolean || list[1].boolean {
+		t.Errorf("g(k=...) = %+v", g.kwargs["k"])
+	}
+
+	if name, ok := cl.kwargs["key"].Enum(); !ok || name != "MEMBER" {
+		t.Errorf("key = %q, %v, want MEMBER", name, ok)
+	}
+	if len(cl.order) != 1 || cl.order[0] != "key" {
+		t.Errorf("order = %v, want [key]", cl.order)
+	}
+}
+
+func TestFindCallsMethod(t *testing.T) {
+	src := `
+miner = TwitchChannelPointsMiner("me")
+miner.mine(["a"])
+def mine_more(): pass
+`
+	if calls := findCalls(src, "mine"); len(calls) != 1 {
+		t.Fatalf("found %d calls of mine, want 1", len(calls))
+	}
+}
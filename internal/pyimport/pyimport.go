@@ -0,0 +1,87 @@
+// Package pyimport migrates a Twitch-Channel-Points-Miner-v2 (Python)
+// installation: the settings in its run.py script become a config.json and
+// its per-streamer analytics JSON files are loaded into the SQLite database.
+package pyimport
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+)
+
+// Result summarizes an import.
+type Result struct {
+	// ConfigPath is where the configuration was written. It differs from the
+	// requested path if a configuration already existed there.
+	ConfigPath string
+	Config     *config.Config
+	Analytics  []AnalyticsResult
+	Warnings   []string
+}
+
+// Run imports the Python project at source, which is either the project
+// directory or its run.py. The configuration is written to configPath, or next
+// to it as config.imported.json if that file already exists. Analytics are
+// imported into database/<username> if the project has any.
+func Run(source, configPath string) (*Result, error) {
+	scriptPath := source
+	if info, err := os.Stat(source); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		scriptPath = filepath.Join(source, "run.py")
+	}
+
+	script, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run.py: %w", err)
+	}
+
+	cfg, warnings, err := convertRunPy(string(script))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", scriptPath, err)
+	}
+
+	result := &Result{Config: cfg, Warnings: warnings, ConfigPath: configPath}
+	if _, err := os.Stat(configPath); err == nil {
+		result.ConfigPath = filepath.Join(filepath.Dir(configPath), "config.imported.json")
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("%s already exists and was left unchanged; review %s and rename it to use it", configPath, result.ConfigPath))
+	}
+	if err := config.SaveConfig(result.ConfigPath, cfg); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	slog.Info("Imported configuration", "path", result.ConfigPath, "username", cfg.Username, "streamers", len(cfg.Streamers))
+
+	analyticsDir := filepath.Join(filepath.Dir(scriptPath), "analytics", cfg.Username)
+	if info, err := os.Stat(analyticsDir); err != nil || !info.IsDir() {
+		slog.Info("No Python analytics data found", "path", analyticsDir)
+		return result, nil
+	}
+
+	dbBasePath := filepath.Join("database", strings.ToLower(cfg.Username))
+	db, err := database.Open(dbBasePath)
+	if err != nil {
+		return result, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	if status := db.Status(); status.InMemory {
+		return result, fmt.Errorf("database %s is damaged, not importing analytics: %s", status.Path, status.Error)
+	}
+
+	repo, err := analytics.NewSQLiteRepository(db, dbBasePath)
+	if err != nil {
+		return result, err
+	}
+
+	result.Analytics, err = importAnalytics(analyticsDir, repo)
+	for _, a := range result.Analytics {
+		slog.Info("Imported analytics", "streamer", a.Streamer, "points", a.Points, "annotations", a.Annotations)
+	}
+	return result, err
+}
@@ -0,0 +1,477 @@
+package pyimport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+	"github.com/PatrickWalther/twitch-miner-go/internal/settings"
+)
+
+// notificationKwargs are LoggerSettings arguments configuring the Python
+// miner's notification providers, which have no counterpart here.
+var notificationKwargs = map[string]bool{
+	"telegram": true, "discord": true, "webhook": true, "matrix": true, "pushover": true, "gotify": true,
+}
+
+// cosmeticKwargs only affect the Python miner's console output and are dropped silently.
+var cosmeticKwargs = map[string]bool{
+	"emoji": true, "color_palette": true, "console_username": true,
+}
+
+type converter struct {
+	warnings []string
+}
+
+func (c *converter) warn(format string, args ...any) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *converter) unsupportedValue(fn, kw string) {
+	c.warn("%s(%s=...): value is not a literal and was skipped", fn, kw)
+}
+
+// arguments returns the keywords of a call's arguments in the order they were
+// given, with positional arguments named after params, the leading parameters
+// of the Python function. Positional arguments beyond params are reported and
+// skipped.
+func (c *converter) arguments(fn string, cl *call, params ...string) ([]string, map[string]value) {
+	order := make([]string, 0, len(cl.args)+len(cl.order))
+	kwargs := make(map[string]value, len(cl.args)+len(cl.kwargs))
+	for i, v := range cl.args {
+		if i >= len(params) {
+			c.warn("%s(...): positional argument %d is not supported and was skipped", fn, i+1)
+			continue
+		}
+		order = append(order, params[i])
+		kwargs[params[i]] = v
+	}
+	for _, kw := range cl.order {
+		if _, ok := kwargs[kw]; !ok {
+			order = append(order, kw)
+		}
+		kwargs[kw] = cl.kwargs[kw]
+	}
+	return order, kwargs
+}
+
+// convertRunPy builds a configuration from the TwitchChannelPointsMiner(...),
+// .analytics(...) and .mine(...) calls of a Python run.py script.
+func convertRunPy(src string) (*config.Config, []string, error) {
+	c := &converter{}
+	cfg := config.DefaultConfig()
+
+	miners := findCalls(src, "TwitchChannelPointsMiner")
+	if len(miners) == 0 {
+		return nil, nil, fmt.Errorf("no TwitchChannelPointsMiner(...) call found")
+	}
+	c.applyMiner(&cfg, miners[0])
+	if cfg.Username == "" {
+		return nil, nil, fmt.Errorf("TwitchChannelPointsMiner(...) has no literal username")
+	}
+
+	if calls := findCalls(src, "analytics"); len(calls) > 0 {
+		c.applyAnalytics(&cfg.Analytics, calls[0])
+	}
+
+	mines := findCalls(src, "mine")
+	if len(mines) == 0 {
+		return nil, nil, fmt.Errorf("no .mine(...) call found")
+	}
+	c.applyMine(&cfg, mines[0])
+
+	config.ValidateConfig(&cfg)
+	return &cfg, c.warnings, nil
+}
+
+func (c *converter) applyMiner(cfg *config.Config, miner *call) {
+	order, kwargs := c.arguments("TwitchChannelPointsMiner", miner, "username", "password")
+	for _, kw := range order {
+		v := kwargs[kw]
+		switch kw {
+		case "username":
+			if username, ok := v.String(); ok {
+				cfg.Username = strings.ToLower(strings.TrimSpace(username))
+			}
+		case "password":
+			c.warn("The password is not imported; log in with the device code shown on first start")
+		case "claim_drops_startup":
+			c.setBool(&cfg.ClaimDropsOnStartup, "TwitchChannelPointsMiner", kw, v)
+		case "enable_analytics":
+			c.setBool(&cfg.EnableAnalytics, "TwitchChannelPointsMiner", kw, v)
+		case "disable_ssl_cert_verification":
+			c.setBool(&cfg.TLS.InsecureSkipVerify, "TwitchChannelPointsMiner", kw, v)
+		case "priority":
+			c.applyPriority(cfg, v)
+		case "logger_settings":
+			if logger, ok := v.Call("LoggerSettings"); ok {
+				c.applyLogger(&cfg.Logger, logger)
+			} else {
+				c.unsupportedValue("TwitchChannelPointsMiner", kw)
+			}
+		case "streamer_settings":
+			if s, ok := v.Call("StreamerSettings"); ok {
+				c.applyStreamerSettings(&cfg.StreamerSettings, s, "default")
+			} else {
+				c.unsupportedValue("TwitchChannelPointsMiner", kw)
+			}
+		default:
+			c.warn("TwitchChannelPointsMiner(%s=...) is not supported and was skipped", kw)
+		}
+	}
+}
+
+func (c *converter) applyPriority(cfg *config.Config, v value) {
+	if v.kind != kindList {
+		v = value{kind: kindList, list: []value{v}}
+	}
+
+	var priorities []config.Priority
+	for _, item := range v.list {
+		name, ok := item.Enum()
+		if !ok {
+			c.unsupportedValue("TwitchChannelPointsMiner", "priority")
+			continue
+		}
+		switch name {
+		case "STREAK":
+			priorities = append(priorities, config.PriorityStreak)
+		case "DROPS":
+			priorities = append(priorities, config.PriorityDrops)
+		case "ORDER":
+			priorities = append(priorities, config.PriorityOrder)
+		case "SUBSCRIBED":
+			priorities = append(priorities, config.PrioritySubscribed)
+		case "POINTS_ASCENDING":
+			priorities = append(priorities, config.PriorityPointsAscending)
+		// The Python enum member is misspelled.
+		case "POINTS_DESCEDING", "POINTS_DESCENDING":
+			priorities = append(priorities, config.PriorityPointsDescending)
+		default:
+			c.warn("Priority.%s is not supported and was skipped", name)
+		}
+	}
+
+	if len(priorities) > 0 {
+		cfg.Priority = priorities
+	}
+}
+
+func (c *converter) applyLogger(dst *config.LoggerSettings, logger *call) {
+	order, kwargs := c.arguments("LoggerSettings", logger)
+	for _, kw := range order {
+		v := kwargs[kw]
+		switch {
+		case kw == "save":
+			c.setBool(&dst.Save, "LoggerSettings", kw, v)
+		case kw == "less":
+			c.setBool(&dst.Less, "LoggerSettings", kw, v)
+		case kw == "colored":
+			c.setBool(&dst.Colored, "LoggerSettings", kw, v)
+		case kw == "auto_clear":
+			c.setBool(&dst.AutoClear, "LoggerSettings", kw, v)
+		case kw == "time_zone":
+			if tz, ok := v.String(); ok {
+				dst.TimeZone = tz
+			} else if v.kind != kindNone {
+				c.unsupportedValue("LoggerSettings", kw)
+			}
+		case kw == "console_level" || kw == "file_level":
+			level, ok := v.Enum()
+			if !ok {
+				c.unsupportedValue("LoggerSettings", kw)
+				continue
+			}
+			if level == "CRITICAL" {
+				level = "ERROR"
+			}
+			if kw == "console_level" {
+				dst.ConsoleLevel = level
+			} else {
+				dst.FileLevel = level
+			}
+		case notificationKwargs[kw]:
+			c.warn("LoggerSettings(%s=...) is not imported; set up Discord or desktop notifications in the dashboard", kw)
+		case cosmeticKwargs[kw]:
+		default:
+			c.warn("LoggerSettings(%s=...) is not supported and was skipped", kw)
+		}
+	}
+}
+
+func (c *converter) applyAnalytics(dst *config.AnalyticsSettings, analytics *call) {
+	order, kwargs := c.arguments("analytics", analytics, "host", "port", "refresh", "days_ago")
+	for _, kw := range order {
+		v := kwargs[kw]
+		switch kw {
+		case "host":
+			if host, ok := v.String(); ok {
+				dst.Host = host
+			} else {
+				c.unsupportedValue("analytics", kw)
+			}
+		case "port":
+			c.setInt(&dst.Port, "analytics", kw, v)
+		case "refresh":
+			c.setInt(&dst.Refresh, "analytics", kw, v)
+		case "days_ago":
+			c.setInt(&dst.DaysAgo, "analytics", kw, v)
+		default:
+			c.warn("analytics(%s=...) is not supported and was skipped", kw)
+		}
+	}
+}
+
+// applyMine imports the streamer list and the follower options. Blacklisted
+// channels are left out of the streamers, as in Python, and excluded from
+// follow discovery.
+func (c *converter) applyMine(cfg *config.Config, mine *call) {
+	order, kwargs := c.arguments("mine", mine, "streamers", "blacklist", "followers", "followers_order")
+
+	excluded := make(map[string]bool)
+	if blacklist, ok := kwargs["blacklist"]; ok {
+		if blacklist.kind != kindList {
+			c.unsupportedValue("mine", "blacklist")
+		}
+		for _, item := range blacklist.list {
+			name, ok := item.String()
+			if !ok {
+				c.warn("mine(blacklist=...): an entry is not a literal and was skipped")
+				continue
+			}
+			login := strings.ToLower(strings.TrimSpace(name))
+			if login != "" && !excluded[login] {
+				excluded[login] = true
+				cfg.Follows.Exclude = append(cfg.Follows.Exclude, login)
+			}
+		}
+	}
+
+	for _, kw := range order {
+		v := kwargs[kw]
+		switch kw {
+		case "streamers":
+			if v.kind != kindList {
+				c.unsupportedValue("mine", kw)
+			}
+			seen := make(map[string]bool)
+			for _, item := range v.list {
+				username, settings := c.streamerEntry(cfg, item)
+				if username == "" || seen[username] {
+					continue
+				}
+				seen[username] = true
+				if excluded[username] {
+					c.warn("mine(...): %s is blacklisted and was not imported", username)
+					continue
+				}
+				cfg.Streamers = append(cfg.Streamers, config.StreamerConfig{Username: username, Settings: settings})
+			}
+		case "blacklist":
+		case "followers":
+			c.setBool(&cfg.Follows.Enabled, "mine", kw, v)
+		case "followers_order":
+			// Follow discovery always prefers the channels followed longest.
+			if followOrder, ok := v.Enum(); !ok {
+				c.unsupportedValue("mine", kw)
+			} else if followOrder != "ASC" {
+				c.warn("mine(followers_order=%s) is not supported; followed channels are picked oldest follow first", followOrder)
+			}
+		default:
+			c.warn("mine(%s=...) is not supported and was skipped", kw)
+		}
+	}
+
+	if len(cfg.Streamers) == 0 && !cfg.Follows.Enabled {
+		c.warn("mine(...) lists no streamers; add some before starting the miner")
+	}
+}
+
+func (c *converter) streamerEntry(cfg *config.Config, item value) (string, *models.StreamerSettings) {
+	if name, ok := item.String(); ok {
+		return strings.ToLower(strings.TrimSpace(name)), nil
+	}
+
+	streamer, ok := item.Call("Streamer")
+	if !ok {
+		c.warn("mine(...): a streamer entry is not a literal and was skipped")
+		return "", nil
+	}
+
+	order, kwargs := c.arguments("Streamer", streamer, "username", "settings")
+	name, ok := kwargs["username"].String()
+	if !ok {
+		c.warn("mine(...): a Streamer(...) has no literal username and was skipped")
+		return "", nil
+	}
+	username := strings.ToLower(strings.TrimSpace(name))
+	for _, kw := range order {
+		if kw != "username" && kw != "settings" {
+			c.warn("Streamer(%q, %s=...) is not supported and was skipped", username, kw)
+		}
+	}
+
+	settingsValue, ok := kwargs["settings"]
+	if !ok || settingsValue.kind == kindNone {
+		return username, nil
+	}
+
+	s, ok := settingsValue.Call("StreamerSettings")
+	if !ok {
+		c.warn("Streamer(%q): settings are not a literal StreamerSettings(...) and were skipped", username)
+		return username, nil
+	}
+
+	// Unset Python streamer settings fall back to the global streamer_settings.
+	merged := cfg.StreamerSettings
+	if merged.Bet.FilterCondition != nil {
+		filter := *merged.Bet.FilterCondition
+		merged.Bet.FilterCondition = &filter
+	}
+	c.applyStreamerSettings(&merged, s, username)
+	return username, &merged
+}
+
+func (c *converter) applyStreamerSettings(dst *models.StreamerSettings, s *call, owner string) {
+	fn := "StreamerSettings"
+	order, kwargs := c.arguments(fn, s)
+	for _, kw := range order {
+		v := kwargs[kw]
+		if v.kind == kindNone {
+			continue
+		}
+		switch kw {
+		case "make_predictions":
+			c.setBool(&dst.MakePredictions, fn, kw, v)
+		case "follow_raid":
+			c.setBool(&dst.FollowRaid, fn, kw, v)
+		case "claim_drops":
+			c.setBool(&dst.ClaimDrops, fn, kw, v)
+		case "claim_moments":
+			c.setBool(&dst.ClaimMoments, fn, kw, v)
+		case "watch_streak":
+			c.setBool(&dst.WatchStreak, fn, kw, v)
+		case "community_goals":
+			c.setBool(&dst.CommunityGoals, fn, kw, v)
+		case "chat":
+			name, ok := v.Enum()
+			if ok && validDTO(settings.StreamerSettingsConfig{Chat: &name}) {
+				dst.Chat = models.ChatPresence(name)
+			} else {
+				c.warn("%s settings: chat presence is not supported and was skipped", owner)
+			}
+		case "bet":
+			if bet, ok := v.Call("BetSettings"); ok {
+				c.applyBetSettings(&dst.Bet, bet, owner)
+			} else {
+				c.unsupportedValue(fn, kw)
+			}
+		default:
+			c.warn("%s settings: StreamerSettings(%s=...) is not supported and was skipped", owner, kw)
+		}
+	}
+}
+
+func (c *converter) applyBetSettings(dst *models.BetSettings, bet *call, owner string) {
+	fn := "BetSettings"
+	order, kwargs := c.arguments(fn, bet)
+	for _, kw := range order {
+		v := kwargs[kw]
+		if v.kind == kindNone {
+			continue
+		}
+		switch kw {
+		case "strategy":
+			name, ok := v.Enum()
+			if ok && validDTO(settings.StreamerSettingsConfig{Bet: &settings.BetSettingsJSON{Strategy: &name}}) {
+				dst.Strategy = models.Strategy(name)
+			} else {
+				c.warn("%s settings: bet strategy is not supported and was skipped", owner)
+			}
+		case "delay_mode":
+			name, ok := v.Enum()
+			if ok && validDTO(settings.StreamerSettingsConfig{Bet: &settings.BetSettingsJSON{DelayMode: &name}}) {
+				dst.DelayMode = models.DelayMode(name)
+			} else {
+				c.warn("%s settings: bet delay mode is not supported and was skipped", owner)
+			}
+		case "percentage":
+			c.setInt(&dst.Percentage, fn, kw, v)
+		case "percentage_gap":
+			c.setInt(&dst.PercentageGap, fn, kw, v)
+		case "max_points":
+			c.setInt(&dst.MaxPoints, fn, kw, v)
+		case "minimum_points":
+			c.setInt(&dst.MinimumPoints, fn, kw, v)
+		case "stealth_mode":
+			c.setBool(&dst.StealthMode, fn, kw, v)
+		case "delay":
+			if delay, ok := v.Float(); ok {
+				dst.Delay = delay
+			} else {
+				c.unsupportedValue(fn, kw)
+			}
+		case "filter_condition":
+			if filter, ok := c.filterCondition(v); ok {
+				dst.FilterCondition = filter
+			} else {
+				c.warn("%s settings: bet filter condition is not supported and was skipped", owner)
+			}
+		default:
+			c.warn("%s settings: BetSettings(%s=...) is not supported and was skipped", owner, kw)
+		}
+	}
+}
+
+func (c *converter) filterCondition(v value) (*models.FilterCondition, bool) {
+	filter, ok := v.Call("FilterCondition")
+	if !ok {
+		return nil, false
+	}
+
+	_, kwargs := c.arguments("FilterCondition", filter, "by", "where", "value")
+	by, okBy := kwargs["by"].Enum()
+	where, okWhere := kwargs["where"].Enum()
+	threshold, okValue := kwargs["value"].Float()
+	if !okBy || !okWhere || !okValue {
+		return nil, false
+	}
+
+	key := models.OutcomeKey(strings.ToLower(by))
+	switch key {
+	case models.OutcomePercentageUsers, models.OutcomeOddsPercentage, models.OutcomeOdds, models.OutcomeTopPoints,
+		models.OutcomeTotalUsers, models.OutcomeTotalPoints, models.OutcomeDecisionUsers, models.OutcomeDecisionPoints:
+	default:
+		return nil, false
+	}
+
+	condition := models.Condition(where)
+	switch condition {
+	case models.ConditionGT, models.ConditionLT, models.ConditionGTE, models.ConditionLTE:
+	default:
+		return nil, false
+	}
+
+	return &models.FilterCondition{By: key, Where: condition, Value: threshold}, true
+}
+
+func (c *converter) setBool(dst *bool, fn, kw string, v value) {
+	if b, ok := v.Bool(); ok {
+		*dst = b
+	} else {
+		c.unsupportedValue(fn, kw)
+	}
+}
+
+func (c *converter) setInt(dst *int, fn, kw string, v value) {
+	if n, ok := v.Int(); ok {
+		*dst = n
+	} else {
+		c.unsupportedValue(fn, kw)
+	}
+}
+
+func validDTO(s settings.StreamerSettingsConfig) bool {
+	return settings.ValidateStreamerSettings(s) == nil
+}
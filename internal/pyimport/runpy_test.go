@@ -0,0 +1,188 @@
+package pyimport
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+// hasWarning reports whether any warning contains substr.
+func hasWarning(warnings []string, substr string) bool {
+	return slices.ContainsFunc(warnings, func(w string) bool {
+		return strings.Contains(w, substr)
+	})
+}
+
+func TestConvertRunPyExample(t *testing.T) {
+	src, err := os.ReadFile("testdata/run.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := convertRunPy(string(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Username != "your-twitch-username" {
+		t.Errorf("username = %q", cfg.Username)
+	}
+	wantPriority := []config.Priority{config.PriorityStreak, config.PriorityDrops, config.PriorityPointsDescending}
+	if !slices.Equal(cfg.Priority, wantPriority) {
+		t.Errorf("priority = %v, want %v", cfg.Priority, wantPriority)
+	}
+	if cfg.TLS.InsecureSkipVerify {
+		t.Error("tls.insecureSkipVerify = true, want false")
+	}
+	if cfg.Logger.FileLevel != "ERROR" || cfg.Logger.TimeZone != "Europe/Berlin" {
+		t.Errorf("logger = %+v", cfg.Logger)
+	}
+	if cfg.Analytics.Host != "0.0.0.0" || cfg.Analytics.Port != 5050 || cfg.Analytics.DaysAgo != 7 {
+		t.Errorf("analytics = %+v", cfg.Analytics)
+	}
+
+	bet := cfg.StreamerSettings.Bet
+	if bet.Strategy != models.StrategySmart || bet.MinimumPoints != 20000 || bet.DelayMode != models.DelayModeFromEnd {
+		t.Errorf("default bet = %+v", bet)
+	}
+	wantFilter := models.FilterCondition{By: models.OutcomeTotalUsers, Where: models.ConditionLTE, Value: 800}
+	if bet.FilterCondition == nil || *bet.FilterCondition != wantFilter {
+		t.Errorf("default filter = %+v, want %+v", bet.FilterCondition, wantFilter)
+	}
+
+	var usernames []string
+	for _, s := range cfg.Streamers {
+		usernames = append(usernames, s.Username)
+	}
+	wantStreamers := []string{"streamer-username01", "streamer-username02", "streamer-username03", "streamer-username09", "streamer-username10"}
+	if !slices.Equal(usernames, wantStreamers) {
+		t.Errorf("streamers = %v, want %v", usernames, wantStreamers)
+	}
+
+	second := cfg.Streamers[1].Settings
+	if second == nil {
+		t.Fatal("streamer-username02 has no settings")
+	}
+	// Unset arguments keep the global streamer_settings.
+	if second.MakePredictions || !second.WatchStreak || second.Bet.MaxPoints != 1234 || second.Bet.MinimumPoints != 20000 {
+		t.Errorf("streamer-username02 settings = %+v", second)
+	}
+	if second.Bet.FilterCondition.By != models.OutcomeTotalPoints {
+		t.Errorf("streamer-username02 filter = %+v", second.Bet.FilterCondition)
+	}
+	if cfg.Streamers[2].Settings != nil {
+		t.Errorf("streamer-username03 settings = %+v, want nil", cfg.Streamers[2].Settings)
+	}
+
+	if !slices.Equal(cfg.Follows.Exclude, []string{"streamer-username11", "spam-channel"}) {
+		t.Errorf("follows.exclude = %v", cfg.Follows.Exclude)
+	}
+	if cfg.Follows.Enabled {
+		t.Error("follows.enabled = true, want false")
+	}
+
+	for _, want := range []string{
+		"password is not imported",
+		"LoggerSettings(telegram=...)",
+		"TwitchChannelPointsMiner(disable_at_in_nickname=...)",
+		"streamer-username11 is blacklisted",
+	} {
+		if !hasWarning(warnings, want) {
+			t.Errorf("no warning about %q in %q", want, warnings)
+		}
+	}
+	if hasWarning(warnings, "color_palette") || hasWarning(warnings, "followers_order") {
+		t.Errorf("unexpected warning in %q", warnings)
+	}
+}
+
+func TestConvertRunPyMine(t *testing.T) {
+	tests := []struct {
+		name      string
+		mine      string
+		streamers []string
+		follows   bool
+		exclude   []string
+		warning   string
+	}{
+		{
+			name:      "positional",
+			mine:      `["a", Streamer("B")], ["b"], True`,
+			streamers: []string{"a"},
+			follows:   true,
+			exclude:   []string{"b"},
+			warning:   "b is blacklisted",
+		},
+		{
+			name:    "followers only",
+			mine:    `followers=True, followers_order=FollowersOrder.DESC`,
+			follows: true,
+			warning: "followers_order=DESC",
+		},
+		{
+			name:    "nothing to mine",
+			mine:    `[], followers=False`,
+			warning: "lists no streamers",
+		},
+		{
+			name:      "unknown option",
+			mine:      `["a"], sort=True`,
+			streamers: []string{"a"},
+			warning:   "mine(sort=...)",
+		},
+		{
+			name:      "non-literal blacklist",
+			mine:      `["a"], blacklist=load_blacklist()`,
+			streamers: []string{"a"},
+			warning:   "mine(blacklist=...)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "miner = TwitchChannelPointsMiner(\"me\")\nminer.mine(" + tt.mine + ")\n"
+			cfg, warnings, err := convertRunPy(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var usernames []string
+			for _, s := range cfg.Streamers {
+				usernames = append(usernames, s.Username)
+			}
+			if !slices.Equal(usernames, tt.streamers) {
+				t.Errorf("streamers = %v, want %v", usernames, tt.streamers)
+			}
+			if cfg.Follows.Enabled != tt.follows {
+				t.Errorf("follows.enabled = %v, want %v", cfg.Follows.Enabled, tt.follows)
+			}
+			if !slices.Equal(cfg.Follows.Exclude, tt.exclude) {
+				t.Errorf("follows.exclude = %v, want %v", cfg.Follows.Exclude, tt.exclude)
+			}
+			if !hasWarning(warnings, tt.warning) {
+				t.Errorf("no warning about %q in %q", tt.warning, warnings)
+			}
+		})
+	}
+}
+
+func TestConvertRunPyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"no miner", `miner.mine(["a"])`},
+		{"no username", `miner = TwitchChannelPointsMiner(username=os.getenv("USER"))` + "\nminer.mine([\"a\"])"},
+		{"no mine", `miner = TwitchChannelPointsMiner("me")`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := convertRunPy(tt.src); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
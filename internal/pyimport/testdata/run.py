@@ -0,0 +1,87 @@
+# -*- coding: utf-8 -*-
+
+import logging
+from colorama import Fore
+from TwitchChannelPointsMiner import TwitchChannelPointsMiner
+from TwitchChannelPointsMiner.logger import LoggerSettings, ColorPalette
+from TwitchChannelPointsMiner.classes.Chat import ChatPresence
+from TwitchChannelPointsMiner.classes.Telegram import Telegram
+from TwitchChannelPointsMiner.classes.Settings import Priority, Events, FollowersOrder
+from TwitchChannelPointsMiner.classes.entities.Bet import Strategy, BetSettings, Condition, OutcomeKeys, FilterCondition, DelayMode
+from TwitchChannelPointsMiner.classes.entities.Streamer import Streamer, StreamerSettings
+
+twitch_miner = TwitchChannelPointsMiner(
+    username="Your-Twitch-Username",
+    password="write-your-secure-psw",           # If no password will be provided, the script will ask interactively
+    claim_drops_startup=False,                  # If you want to auto claim all drops from Twitch inventory on the startup
+    priority=[                                  # Custom priority in this case for example:
+        Priority.STREAK,                        # - We want first of all to catch all watch streak from all streamers
+        Priority.DROPS,                         # - When we don't have anymore watch streak to catch, wait until all drops are collected over the streamers
+        Priority.POINTS_DESCEDING               # - When we have all of the drops claimed and no watch-streak available we use the order priority (POINTS_ASCENDING, POINTS_DESCEDING)
+    ],
+    enable_analytics=True,
+    disable_ssl_cert_verification=False,
+    disable_at_in_nickname=False,
+    logger_settings=LoggerSettings(
+        save=True,
+        console_level=logging.INFO,
+        console_username=False,
+        auto_clear=True,
+        time_zone="Europe/Berlin",
+        file_level=logging.CRITICAL,
+        emoji=True,
+        less=False,
+        colored=True,
+        color_palette=ColorPalette(
+            STREAMER_online="GREEN",
+            streamer_offline="red",
+            BET_wiN=Fore.MAGENTA
+        ),
+        telegram=Telegram(
+            chat_id=123456789,
+            token="123456789:shfuihreuifheuifhiu34578347",
+            events=[Events.STREAMER_ONLINE, Events.STREAMER_OFFLINE, Events.BET_LOSE, Events.CHAT_MENTION],
+            disable_notification=True,
+        ),
+    ),
+    streamer_settings=StreamerSettings(
+        make_predictions=True,
+        follow_raid=True,
+        claim_drops=True,
+        claim_moments=True,
+        watch_streak=True,
+        community_goals=False,
+        chat=ChatPresence.ONLINE,
+        bet=BetSettings(
+            strategy=Strategy.SMART,
+            percentage=5,
+            percentage_gap=20,
+            max_points=50000,
+            stealth_mode=True,
+            delay_mode=DelayMode.FROM_END,
+            delay=6,
+            minimum_points=20000,
+            filter_condition=FilterCondition(
+                by=OutcomeKeys.TOTAL_USERS,
+                where=Condition.LTE,
+                value=800
+            )
+        )
+    )
+)
+
+twitch_miner.analytics(host="0.0.0.0", port=5050, refresh=5, days_ago=7)
+
+twitch_miner.mine(
+    [
+        Streamer("streamer-username01", settings=StreamerSettings(make_predictions=True  , follow_raid=False , claim_drops=True  , watch_streak=True , community_goals=False , bet=BetSettings(strategy=Strategy.SMART      , percentage=5 , stealth_mode=True,  percentage_gap=20 , max_points=234   , filter_condition=FilterCondition(by=OutcomeKeys.TOTAL_USERS,      where=Condition.LTE, value=800 ) ) )),
+        Streamer("streamer-username02", settings=StreamerSettings(make_predictions=False , follow_raid=True  , claim_drops=False ,                     bet=BetSettings(strategy=Strategy.PERCENTAGE , percentage=5 , stealth_mode=False, percentage_gap=20 , max_points=1234  , filter_condition=FilterCondition(by=OutcomeKeys.TOTAL_POINTS,     where=Condition.GTE, value=250 ) ) )),
+        Streamer("streamer-username03"),
+        "streamer-username09",
+        "streamer-username10",
+        "streamer-username11",
+    ],                                  # Array of streamers (order = priority)
+    blacklist=["streamer-username11", "spam-channel"],
+    followers=False,                    # Automatic download the list of your followers
+    followers_order=FollowersOrder.ASC  # Sort the followers list by follow date. ASC or DESC
+)
@@ -0,0 +1,239 @@
+// Package selftest checks that the miner can log in and reach every Twitch
+// service it uses, plus Discord and its database, without starting to mine.
+// It backs the -selftest flag, for container init checks and support triage.
+package selftest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/api"
+	"github.com/PatrickWalther/twitch-miner-go/internal/auth"
+	"github.com/PatrickWalther/twitch-miner-go/internal/chat"
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+	"github.com/PatrickWalther/twitch-miner-go/internal/notifications"
+	"github.com/PatrickWalther/twitch-miner-go/internal/pubsub"
+	"github.com/PatrickWalther/twitch-miner-go/internal/util"
+)
+
+// checkTimeout bounds each network check.
+const checkTimeout = 15 * time.Second
+
+// errSkipped marks a check that did not run because it does not apply or a
+// check it depends on failed.
+var errSkipped = errors.New("skipped")
+
+// Result is the outcome of one check. Detail describes what was checked or
+// why it failed.
+type Result struct {
+	Name   string
+	Err    error
+	Detail string
+}
+
+// Status returns PASS, FAIL or SKIP.
+func (r Result) Status() string {
+	switch {
+	case r.Err == nil:
+		return "PASS"
+	case r.Err == errSkipped:
+		return "SKIP"
+	default:
+		return "FAIL"
+	}
+}
+
+// Failed reports whether any check failed. Skipped checks do not count.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status() == "FAIL" {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the results as a table.
+func Print(w io.Writer, results []Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CHECK\tRESULT\tDETAIL")
+	for _, r := range results {
+		detail := r.Detail
+		if r.Status() == "FAIL" {
+			detail = r.Err.Error()
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Name, r.Status(), detail)
+	}
+	_ = tw.Flush()
+}
+
+// Run checks the token stored for cfg.Username, GQL, PubSub, IRC, the usher
+// and spade endpoints, Discord and the database, in that order. It uses the
+// login saved in cookies/ and never starts the device code login; a token
+// Twitch rejects is refreshed the way the miner would. Usher and spade are
+// checked against the first configured streamer, or the account's own channel.
+func Run(cfg *config.Config) []Result {
+	var results []Result
+	add := func(name string, err error, detail string) error {
+		results = append(results, Result{Name: name, Err: err, Detail: detail})
+		return err
+	}
+
+	deviceID := util.DeviceID()
+	twitchAuth := auth.NewTwitchAuth(cfg.Username, deviceID)
+	tokenErr := add("Token", checkToken(twitchAuth), "stored login for "+cfg.Username+" is valid")
+
+	channel := cfg.Username
+	if len(cfg.Streamers) > 0 {
+		channel = cfg.Streamers[0].Username
+	}
+
+	var client *api.TwitchClient
+	userID := ""
+	if tokenErr != nil {
+		add("GQL", errSkipped, "no valid token")
+	} else {
+		client = api.NewTwitchClient(twitchAuth, deviceID)
+		client.UpdateClientVersion()
+		var err error
+		userID, err = client.GetChannelID(cfg.Username)
+		add("GQL", err, "user ID "+userID)
+	}
+
+	token := twitchAuth.GetAuthToken()
+	if userID == "" {
+		add("PubSub", errSkipped, "needs the user ID from GQL")
+	} else {
+		topic := pubsub.NewTopic(pubsub.TopicCommunityPointsUser, userID)
+		add("PubSub", pubsub.Check(token, topic, checkTimeout), "LISTEN to "+topic.String()+" acknowledged")
+	}
+
+	if tokenErr != nil {
+		add("IRC", errSkipped, "no valid token")
+	} else {
+		add("IRC", chat.CheckLogin(cfg.Username, token, cfg.ChatTLS, checkTimeout), "logged in to "+constants.IRCURL)
+	}
+
+	if userID == "" {
+		add("Usher", errSkipped, "needs a playback token from GQL")
+	} else {
+		status, err := checkUsher(client, channel)
+		add("Usher", err, fmt.Sprintf("playlist for %s answered %d", channel, status))
+	}
+
+	if tokenErr != nil {
+		add("Spade", errSkipped, "no valid token")
+	} else {
+		status, err := checkSpade(client, channel)
+		add("Spade", err, fmt.Sprintf("spade URL of %s answered %d", channel, status))
+	}
+
+	if !cfg.Discord.Enabled {
+		add("Discord", errSkipped, "discord.enabled is false")
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		provider := notifications.NewDiscordProvider(cfg.Discord.BotToken, cfg.Discord.GuildID)
+		add("Discord", provider.ValidateConfig(ctx), "bot can access guild "+cfg.Discord.GuildID)
+		cancel()
+	}
+
+	basePath := filepath.Join("database", cfg.Username)
+	add("Database", checkDatabase(basePath), filepath.Join(basePath, "miner.db")+" is writable")
+
+	return results
+}
+
+// checkToken loads the stored login and validates it, refreshing it if
+// Twitch rejects it.
+func checkToken(twitchAuth *auth.TwitchAuth) error {
+	if err := twitchAuth.LoadStoredAuth(); err != nil || twitchAuth.GetAuthToken() == "" {
+		return fmt.Errorf("no stored login, start the miner once to log in")
+	}
+
+	err := twitchAuth.Validate()
+	if !errors.Is(err, auth.ErrBadCredentials) {
+		return err
+	}
+	if _, err := twitchAuth.Refresh(twitchAuth.GetAuthToken()); err != nil {
+		return fmt.Errorf("token rejected and could not be refreshed: %w", err)
+	}
+	return twitchAuth.Validate()
+}
+
+// checkUsher requests the channel's HLS playlist with a playback token, as the
+// minute watcher does. An offline channel answers 404, which still shows usher
+// is reachable; a rejected token or a server error fails.
+func checkUsher(client *api.TwitchClient, channel string) (int, error) {
+	sig, token, err := client.GetPlaybackAccessToken(channel)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get playback token: %w", err)
+	}
+
+	params := url.Values{"sig": {sig}, "token": {token}}
+	playlistURL := fmt.Sprintf("%s/api/channel/hls/%s.m3u8?%s", constants.UsherURL, strings.ToLower(channel), params.Encode())
+	return reach(http.MethodGet, playlistURL)
+}
+
+// checkSpade looks up the channel's spade URL and sends it an empty request.
+func checkSpade(client *api.TwitchClient, channel string) (int, error) {
+	streamer := models.NewStreamer(channel, models.StreamerSettings{})
+	if err := client.GetSpadeURL(streamer); err != nil {
+		return 0, fmt.Errorf("failed to get spade URL: %w", err)
+	}
+	return reach(http.MethodPost, streamer.GetStream().GetSpadeURL())
+}
+
+// reach sends a request and fails on network errors, 403 and 5xx answers.
+func reach(method, target string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(httpx.WithOperation(ctx, "selftest"), method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", constants.TVUserAgent)
+
+	resp, err := httpx.NewClient(checkTimeout).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode >= 500 {
+		return resp.StatusCode, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// checkDatabase opens the database and creates and drops a table in it.
+func checkDatabase(basePath string) error {
+	db, err := database.Open(basePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	if db.InMemory() {
+		return fmt.Errorf("database failed its integrity check: %s", db.Status().Error)
+	}
+	if _, err := db.Exec("CREATE TABLE selftest (id INTEGER)"); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	if _, err := db.Exec("DROP TABLE selftest"); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
@@ -1,7 +1,10 @@
 package settings
 
 import (
+	"strings"
+
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/features"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 )
 
@@ -17,6 +20,7 @@ func BuildRuntimeSettings(cfg *config.Config) RuntimeSettings {
 		streamers[i] = StreamerConfig{
 			Username: sc.Username,
 			Settings: StreamerSettingsPtrToDTO(sc.Settings),
+			Disabled: sc.Disabled,
 		}
 	}
 
@@ -24,13 +28,39 @@ func BuildRuntimeSettings(cfg *config.Config) RuntimeSettings {
 		Streamers:       streamers,
 		DefaultSettings: StreamerSettingsToDTO(cfg.StreamerSettings),
 		Priority:        priority,
+		WatchMode:       string(cfg.WatchMode),
+		WatchNonEarning: cfg.WatchNonEarning,
+		HLSMode:         string(cfg.HLSMode),
+		Locale:          cfg.Locale,
+		PointsFloor:     cfg.PointsFloor,
+		BetConfirm: BetConfirmSettings{
+			Enabled:   cfg.BetConfirm.Enabled,
+			Threshold: cfg.BetConfirm.Threshold,
+			Fallback:  string(cfg.BetConfirm.Fallback),
+		},
+		Yield: YieldSettings{
+			Enabled: cfg.Yield.Enabled,
+			Minutes: cfg.Yield.Minutes,
+		},
+		RaidDenylist: append([]string{}, cfg.RaidDenylist...),
+		ChatIgnore: ChatIgnoreSettings{
+			Users:    append([]string{}, cfg.ChatIgnore.Users...),
+			Prefixes: append([]string{}, cfg.ChatIgnore.Prefixes...),
+		},
+		ChatMentions: ChatMentionSettings{
+			WholeWord:    cfg.ChatMentions.WholeWord,
+			RequireAt:    cfg.ChatMentions.RequireAt,
+			DedupeWindow: cfg.ChatMentions.DedupeWindow,
+		},
 		RateLimits: RateLimitSettings{
-			WebsocketPingInterval: cfg.RateLimits.WebsocketPingInterval,
-			CampaignSyncInterval:  cfg.RateLimits.CampaignSyncInterval,
-			MinuteWatchedInterval: cfg.RateLimits.MinuteWatchedInterval,
-			RequestDelay:          cfg.RateLimits.RequestDelay,
-			ReconnectDelay:        cfg.RateLimits.ReconnectDelay,
-			StreamCheckInterval:   cfg.RateLimits.StreamCheckInterval,
+			WebsocketPingInterval:  cfg.RateLimits.WebsocketPingInterval,
+			CampaignSyncInterval:   cfg.RateLimits.CampaignSyncInterval,
+			MinuteWatchedInterval:  cfg.RateLimits.MinuteWatchedInterval,
+			RequestDelay:           cfg.RateLimits.RequestDelay,
+			ReconnectDelay:         cfg.RateLimits.ReconnectDelay,
+			StreamCheckInterval:    cfg.RateLimits.StreamCheckInterval,
+			StreamCheckConcurrency: cfg.RateLimits.StreamCheckConcurrency,
+			OnlineDetection:        string(cfg.RateLimits.OnlineDetection),
 		},
 		Logger: LoggerSettings{
 			ConsoleLevel: cfg.Logger.ConsoleLevel,
@@ -39,18 +69,49 @@ func BuildRuntimeSettings(cfg *config.Config) RuntimeSettings {
 			Colored:      cfg.Logger.Colored,
 		},
 		Analytics: AnalyticsUIConfig{
-			Refresh:        cfg.Analytics.Refresh,
-			DaysAgo:        cfg.Analytics.DaysAgo,
-			EnableChatLogs: cfg.Analytics.EnableChatLogs,
+			Refresh:         cfg.Analytics.Refresh,
+			DaysAgo:         cfg.Analytics.DaysAgo,
+			EnableChatLogs:  cfg.Analytics.EnableChatLogs,
+			WarnUnfollowed:  cfg.Analytics.WarnUnfollowed,
+			EfficiencyAlert: cfg.Analytics.EfficiencyAlert,
 		},
 		Discord: DiscordUIConfig{
 			Enabled:  cfg.Discord.Enabled,
 			BotToken: cfg.Discord.BotToken,
 			GuildID:  cfg.Discord.GuildID,
 		},
+		Desktop: DesktopUIConfig{
+			Enabled:  cfg.Desktop.Enabled,
+			Mentions: cfg.Desktop.Mentions,
+			Online:   cfg.Desktop.Online,
+			Drops:    cfg.Desktop.Drops,
+			Errors:   cfg.Desktop.Errors,
+		},
+		Follows: FollowSettings{
+			Enabled:      cfg.Follows.Enabled,
+			Limit:        cfg.Follows.Limit,
+			Exclude:      append([]string{}, cfg.Follows.Exclude...),
+			SyncInterval: cfg.Follows.SyncInterval,
+		},
+		FeatureFlags: buildFeatureFlags(cfg.FeatureFlags),
 	}
 }
 
+// buildFeatureFlags lists every known flag with its configured value.
+func buildFeatureFlags(values map[string]bool) []FeatureFlag {
+	definitions := features.Definitions()
+	flags := make([]FeatureFlag, len(definitions))
+	for i, d := range definitions {
+		flags[i] = FeatureFlag{
+			Name:        string(d.Flag),
+			Label:       d.Label,
+			Description: d.Description,
+			Enabled:     values[string(d.Flag)],
+		}
+	}
+	return flags
+}
+
 // BuildDefaultSettings constructs a RuntimeSettings DTO from defaults, preserving current streamers.
 func BuildDefaultSettings(currentStreamers []config.StreamerConfig) RuntimeSettings {
 	streamers := make([]StreamerConfig, len(currentStreamers))
@@ -58,6 +119,7 @@ func BuildDefaultSettings(currentStreamers []config.StreamerConfig) RuntimeSetti
 		streamers[i] = StreamerConfig{
 			Username: sc.Username,
 			Settings: nil,
+			Disabled: sc.Disabled,
 		}
 	}
 
@@ -71,13 +133,39 @@ func BuildDefaultSettings(currentStreamers []config.StreamerConfig) RuntimeSetti
 		Streamers:       streamers,
 		DefaultSettings: StreamerSettingsToDTO(defaults.StreamerSettings),
 		Priority:        priority,
+		WatchMode:       string(defaults.WatchMode),
+		WatchNonEarning: defaults.WatchNonEarning,
+		HLSMode:         string(defaults.HLSMode),
+		Locale:          defaults.Locale,
+		PointsFloor:     defaults.PointsFloor,
+		BetConfirm: BetConfirmSettings{
+			Enabled:   defaults.BetConfirm.Enabled,
+			Threshold: defaults.BetConfirm.Threshold,
+			Fallback:  string(defaults.BetConfirm.Fallback),
+		},
+		Yield: YieldSettings{
+			Enabled: defaults.Yield.Enabled,
+			Minutes: defaults.Yield.Minutes,
+		},
+		RaidDenylist: []string{},
+		ChatIgnore: ChatIgnoreSettings{
+			Users:    append([]string{}, defaults.ChatIgnore.Users...),
+			Prefixes: append([]string{}, defaults.ChatIgnore.Prefixes...),
+		},
+		ChatMentions: ChatMentionSettings{
+			WholeWord:    defaults.ChatMentions.WholeWord,
+			RequireAt:    defaults.ChatMentions.RequireAt,
+			DedupeWindow: defaults.ChatMentions.DedupeWindow,
+		},
 		RateLimits: RateLimitSettings{
-			WebsocketPingInterval: defaults.RateLimits.WebsocketPingInterval,
-			CampaignSyncInterval:  defaults.RateLimits.CampaignSyncInterval,
-			MinuteWatchedInterval: defaults.RateLimits.MinuteWatchedInterval,
-			RequestDelay:          defaults.RateLimits.RequestDelay,
-			ReconnectDelay:        defaults.RateLimits.ReconnectDelay,
-			StreamCheckInterval:   defaults.RateLimits.StreamCheckInterval,
+			WebsocketPingInterval:  defaults.RateLimits.WebsocketPingInterval,
+			CampaignSyncInterval:   defaults.RateLimits.CampaignSyncInterval,
+			MinuteWatchedInterval:  defaults.RateLimits.MinuteWatchedInterval,
+			RequestDelay:           defaults.RateLimits.RequestDelay,
+			ReconnectDelay:         defaults.RateLimits.ReconnectDelay,
+			StreamCheckInterval:    defaults.RateLimits.StreamCheckInterval,
+			StreamCheckConcurrency: defaults.RateLimits.StreamCheckConcurrency,
+			OnlineDetection:        string(defaults.RateLimits.OnlineDetection),
 		},
 		Logger: LoggerSettings{
 			ConsoleLevel: defaults.Logger.ConsoleLevel,
@@ -86,15 +174,31 @@ func BuildDefaultSettings(currentStreamers []config.StreamerConfig) RuntimeSetti
 			Colored:      defaults.Logger.Colored,
 		},
 		Analytics: AnalyticsUIConfig{
-			Refresh:        defaults.Analytics.Refresh,
-			DaysAgo:        defaults.Analytics.DaysAgo,
-			EnableChatLogs: defaults.Analytics.EnableChatLogs,
+			Refresh:         defaults.Analytics.Refresh,
+			DaysAgo:         defaults.Analytics.DaysAgo,
+			EnableChatLogs:  defaults.Analytics.EnableChatLogs,
+			WarnUnfollowed:  defaults.Analytics.WarnUnfollowed,
+			EfficiencyAlert: defaults.Analytics.EfficiencyAlert,
 		},
 		Discord: DiscordUIConfig{
 			Enabled:  defaults.Discord.Enabled,
 			BotToken: defaults.Discord.BotToken,
 			GuildID:  defaults.Discord.GuildID,
 		},
+		Desktop: DesktopUIConfig{
+			Enabled:  defaults.Desktop.Enabled,
+			Mentions: defaults.Desktop.Mentions,
+			Online:   defaults.Desktop.Online,
+			Drops:    defaults.Desktop.Drops,
+			Errors:   defaults.Desktop.Errors,
+		},
+		Follows: FollowSettings{
+			Enabled:      defaults.Follows.Enabled,
+			Limit:        defaults.Follows.Limit,
+			Exclude:      append([]string{}, defaults.Follows.Exclude...),
+			SyncInterval: defaults.Follows.SyncInterval,
+		},
+		FeatureFlags: buildFeatureFlags(defaults.FeatureFlags),
 	}
 }
 
@@ -106,6 +210,7 @@ func ApplyToConfig(cfg *config.Config, s RuntimeSettings) {
 		cfg.Streamers[i] = config.StreamerConfig{
 			Username: sc.Username,
 			Settings: StreamerSettingsPtrFromDTO(sc.Settings),
+			Disabled: sc.Disabled,
 		}
 	}
 
@@ -116,12 +221,59 @@ func ApplyToConfig(cfg *config.Config, s RuntimeSettings) {
 		cfg.Priority[i] = config.Priority(p)
 	}
 
+	cfg.WatchMode = config.WatchMode(s.WatchMode)
+	cfg.WatchNonEarning = s.WatchNonEarning
+	cfg.HLSMode = config.HLSMode(s.HLSMode)
+	cfg.Locale = s.Locale
+	cfg.PointsFloor = s.PointsFloor
+	cfg.BetConfirm.Enabled = s.BetConfirm.Enabled
+	cfg.BetConfirm.Threshold = s.BetConfirm.Threshold
+	cfg.BetConfirm.Fallback = config.BetConfirmFallback(s.BetConfirm.Fallback)
+	cfg.Yield.Enabled = s.Yield.Enabled
+	cfg.Yield.Minutes = s.Yield.Minutes
+
+	cfg.FeatureFlags = nil
+	for _, flag := range s.FeatureFlags {
+		if flag.Enabled && features.Known(flag.Name) {
+			if cfg.FeatureFlags == nil {
+				cfg.FeatureFlags = make(map[string]bool)
+			}
+			cfg.FeatureFlags[flag.Name] = true
+		}
+	}
+
+	cfg.RaidDenylist = nil
+	for _, game := range s.RaidDenylist {
+		if game = strings.TrimSpace(game); game != "" {
+			cfg.RaidDenylist = append(cfg.RaidDenylist, game)
+		}
+	}
+
+	cfg.ChatIgnore.Users = []string{}
+	for _, user := range s.ChatIgnore.Users {
+		if user = strings.ToLower(strings.TrimSpace(user)); user != "" {
+			cfg.ChatIgnore.Users = append(cfg.ChatIgnore.Users, user)
+		}
+	}
+	cfg.ChatIgnore.Prefixes = []string{}
+	for _, prefix := range s.ChatIgnore.Prefixes {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			cfg.ChatIgnore.Prefixes = append(cfg.ChatIgnore.Prefixes, prefix)
+		}
+	}
+
+	cfg.ChatMentions.WholeWord = s.ChatMentions.WholeWord
+	cfg.ChatMentions.RequireAt = s.ChatMentions.RequireAt
+	cfg.ChatMentions.DedupeWindow = s.ChatMentions.DedupeWindow
+
 	cfg.RateLimits.WebsocketPingInterval = s.RateLimits.WebsocketPingInterval
 	cfg.RateLimits.CampaignSyncInterval = s.RateLimits.CampaignSyncInterval
 	cfg.RateLimits.MinuteWatchedInterval = s.RateLimits.MinuteWatchedInterval
 	cfg.RateLimits.RequestDelay = s.RateLimits.RequestDelay
 	cfg.RateLimits.ReconnectDelay = s.RateLimits.ReconnectDelay
 	cfg.RateLimits.StreamCheckInterval = s.RateLimits.StreamCheckInterval
+	cfg.RateLimits.StreamCheckConcurrency = s.RateLimits.StreamCheckConcurrency
+	cfg.RateLimits.OnlineDetection = config.OnlineDetection(s.RateLimits.OnlineDetection)
 
 	cfg.Logger.ConsoleLevel = s.Logger.ConsoleLevel
 	cfg.Logger.FileLevel = s.Logger.FileLevel
@@ -131,11 +283,29 @@ func ApplyToConfig(cfg *config.Config, s RuntimeSettings) {
 	cfg.Analytics.Refresh = s.Analytics.Refresh
 	cfg.Analytics.DaysAgo = s.Analytics.DaysAgo
 	cfg.Analytics.EnableChatLogs = s.Analytics.EnableChatLogs
+	cfg.Analytics.WarnUnfollowed = s.Analytics.WarnUnfollowed
+	cfg.Analytics.EfficiencyAlert = max(0, s.Analytics.EfficiencyAlert)
 
 	cfg.Discord.Enabled = s.Discord.Enabled
 	cfg.Discord.BotToken = s.Discord.BotToken
 	cfg.Discord.GuildID = s.Discord.GuildID
 
+	cfg.Desktop.Enabled = s.Desktop.Enabled
+	cfg.Desktop.Mentions = s.Desktop.Mentions
+	cfg.Desktop.Online = s.Desktop.Online
+	cfg.Desktop.Drops = s.Desktop.Drops
+	cfg.Desktop.Errors = s.Desktop.Errors
+
+	cfg.Follows.Enabled = s.Follows.Enabled
+	cfg.Follows.Limit = s.Follows.Limit
+	cfg.Follows.SyncInterval = s.Follows.SyncInterval
+	cfg.Follows.Exclude = []string{}
+	for _, login := range s.Follows.Exclude {
+		if login = strings.ToLower(strings.TrimSpace(login)); login != "" {
+			cfg.Follows.Exclude = append(cfg.Follows.Exclude, login)
+		}
+	}
+
 	config.ValidateConfig(cfg)
 }
 
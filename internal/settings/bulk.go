@@ -0,0 +1,208 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+// ApplyBulkStreamerUpdate merges update.Settings into every streamer selected
+// by the update's filter. Streamers without an override start from the
+// default settings, so unchanged fields keep their current effective values.
+// s is only modified if the whole update is valid.
+func ApplyBulkStreamerUpdate(s *RuntimeSettings, update BulkStreamerUpdate) ([]string, error) {
+	targets, err := bulkTargets(s.Streamers, update)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateStreamerSettings(update.Settings); err != nil {
+		return nil, err
+	}
+
+	updated := make([]string, 0, len(targets))
+	for _, i := range targets {
+		base := s.DefaultSettings
+		if s.Streamers[i].Settings != nil {
+			base = *s.Streamers[i].Settings
+		}
+		merged := mergeStreamerSettings(base, update.Settings)
+		s.Streamers[i].Settings = &merged
+		updated = append(updated, s.Streamers[i].Username)
+	}
+	return updated, nil
+}
+
+func bulkTargets(streamers []StreamerConfig, update BulkStreamerUpdate) ([]int, error) {
+	filters := 0
+	if update.All {
+		filters++
+	}
+	if len(update.Streamers) > 0 {
+		filters++
+	}
+	switch {
+	case filters == 0:
+		return nil, fmt.Errorf("no streamers selected: set all or streamers")
+	case filters > 1:
+		return nil, fmt.Errorf("only one of all or streamers may be set")
+	}
+
+	if update.All {
+		targets := make([]int, len(streamers))
+		for i := range streamers {
+			targets[i] = i
+		}
+		return targets, nil
+	}
+
+	index := make(map[string]int, len(streamers))
+	for i, sc := range streamers {
+		index[strings.ToLower(sc.Username)] = i
+	}
+
+	var targets []int
+	var unknown []string
+	seen := make(map[int]bool)
+	for _, name := range update.Streamers {
+		i, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if !seen[i] {
+			seen[i] = true
+			targets = append(targets, i)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown streamers: %s", strings.Join(unknown, ", "))
+	}
+	return targets, nil
+}
+
+// ValidateStreamerSettings checks the set fields of a partial settings override.
+func ValidateStreamerSettings(s StreamerSettingsConfig) error {
+	if s.Chat != nil {
+		switch models.ChatPresence(*s.Chat) {
+		case models.ChatAlways, models.ChatNever, models.ChatOnline, models.ChatOffline:
+		default:
+			return fmt.Errorf("invalid chat presence %q", *s.Chat)
+		}
+	}
+	if s.MaxPoints != nil && *s.MaxPoints < 0 {
+		return fmt.Errorf("max points must not be negative")
+	}
+
+	bet := s.Bet
+	if bet == nil {
+		return nil
+	}
+	if bet.Strategy != nil && !models.Strategy(*bet.Strategy).Valid() {
+		return fmt.Errorf("invalid bet strategy %q", *bet.Strategy)
+	}
+	if bet.DelayMode != nil {
+		switch models.DelayMode(*bet.DelayMode) {
+		case models.DelayModeFromStart, models.DelayModeFromEnd, models.DelayModePercentage:
+		default:
+			return fmt.Errorf("invalid bet delay mode %q", *bet.DelayMode)
+		}
+	}
+	if bet.Percentage != nil && (*bet.Percentage < 0 || *bet.Percentage > 100) {
+		return fmt.Errorf("bet percentage must be between 0 and 100")
+	}
+	if bet.PercentageGap != nil && (*bet.PercentageGap < 0 || *bet.PercentageGap > 100) {
+		return fmt.Errorf("bet percentage gap must be between 0 and 100")
+	}
+	if bet.MaxPoints != nil && *bet.MaxPoints < 0 {
+		return fmt.Errorf("bet max points must not be negative")
+	}
+	if bet.MinimumPoints != nil && *bet.MinimumPoints < 0 {
+		return fmt.Errorf("bet minimum points must not be negative")
+	}
+	if bet.Delay != nil && *bet.Delay < 0 {
+		return fmt.Errorf("bet delay must not be negative")
+	}
+	if bet.AutoDelayStep != nil && *bet.AutoDelayStep < 0 {
+		return fmt.Errorf("bet auto delay step must not be negative")
+	}
+	return nil
+}
+
+// mergeStreamerSettings returns dst with the set fields of src applied. dst's
+// Bet is copied rather than modified, as it may be shared with other streamers.
+func mergeStreamerSettings(dst, src StreamerSettingsConfig) StreamerSettingsConfig {
+	if src.MakePredictions != nil {
+		dst.MakePredictions = src.MakePredictions
+	}
+	if src.FollowRaid != nil {
+		dst.FollowRaid = src.FollowRaid
+	}
+	if src.ClaimDrops != nil {
+		dst.ClaimDrops = src.ClaimDrops
+	}
+	if src.ClaimMoments != nil {
+		dst.ClaimMoments = src.ClaimMoments
+	}
+	if src.WatchStreak != nil {
+		dst.WatchStreak = src.WatchStreak
+	}
+	if src.CommunityGoals != nil {
+		dst.CommunityGoals = src.CommunityGoals
+	}
+	if src.Chat != nil {
+		dst.Chat = src.Chat
+	}
+	if src.WatchWeight != nil {
+		dst.WatchWeight = src.WatchWeight
+	}
+	if src.DropsOnly != nil {
+		dst.DropsOnly = src.DropsOnly
+	}
+	if src.StreamSummary != nil {
+		dst.StreamSummary = src.StreamSummary
+	}
+	if src.MaxPoints != nil {
+		dst.MaxPoints = src.MaxPoints
+	}
+	if src.Bet == nil {
+		return dst
+	}
+
+	var bet BetSettingsJSON
+	if dst.Bet != nil {
+		bet = *dst.Bet
+	}
+	if src.Bet.Strategy != nil {
+		bet.Strategy = src.Bet.Strategy
+	}
+	if src.Bet.Percentage != nil {
+		bet.Percentage = src.Bet.Percentage
+	}
+	if src.Bet.PercentageGap != nil {
+		bet.PercentageGap = src.Bet.PercentageGap
+	}
+	if src.Bet.MaxPoints != nil {
+		bet.MaxPoints = src.Bet.MaxPoints
+	}
+	if src.Bet.MinimumPoints != nil {
+		bet.MinimumPoints = src.Bet.MinimumPoints
+	}
+	if src.Bet.StealthMode != nil {
+		bet.StealthMode = src.Bet.StealthMode
+	}
+	if src.Bet.Delay != nil {
+		bet.Delay = src.Bet.Delay
+	}
+	if src.Bet.DelayMode != nil {
+		bet.DelayMode = src.Bet.DelayMode
+	}
+	if src.Bet.AutoDelayStep != nil {
+		bet.AutoDelayStep = src.Bet.AutoDelayStep
+	}
+	if src.Bet.Seed != nil {
+		bet.Seed = src.Bet.Seed
+	}
+	dst.Bet = &bet
+	return dst
+}
@@ -16,6 +16,10 @@ func StreamerSettingsToDTO(s models.StreamerSettings) StreamerSettingsConfig {
 		WatchStreak:     &s.WatchStreak,
 		CommunityGoals:  &s.CommunityGoals,
 		Chat:            &chat,
+		WatchWeight:     &s.WatchWeight,
+		DropsOnly:       &s.DropsOnly,
+		StreamSummary:   &s.StreamSummary,
+		MaxPoints:       &s.MaxPoints,
 		Bet: &BetSettingsJSON{
 			Strategy:      &strategy,
 			Percentage:    &s.Bet.Percentage,
@@ -25,6 +29,8 @@ func StreamerSettingsToDTO(s models.StreamerSettings) StreamerSettingsConfig {
 			StealthMode:   &s.Bet.StealthMode,
 			Delay:         &s.Bet.Delay,
 			DelayMode:     &delayMode,
+			AutoDelayStep: &s.Bet.AutoDelayStep,
+			Seed:          &s.Bet.Seed,
 		},
 	}
 }
@@ -77,6 +83,18 @@ func ApplyStreamerSettingsFromDTO(dst *models.StreamerSettings, src StreamerSett
 	if src.Chat != nil {
 		dst.Chat = models.ChatPresence(*src.Chat)
 	}
+	if src.WatchWeight != nil {
+		dst.WatchWeight = max(*src.WatchWeight, 1)
+	}
+	if src.DropsOnly != nil {
+		dst.DropsOnly = *src.DropsOnly
+	}
+	if src.StreamSummary != nil {
+		dst.StreamSummary = *src.StreamSummary
+	}
+	if src.MaxPoints != nil {
+		dst.MaxPoints = max(*src.MaxPoints, 0)
+	}
 	if src.Bet != nil {
 		ApplyBetSettingsFromDTO(&dst.Bet, src.Bet)
 	}
@@ -108,4 +126,10 @@ func ApplyBetSettingsFromDTO(dst *models.BetSettings, src *BetSettingsJSON) {
 	if src.DelayMode != nil {
 		dst.DelayMode = models.DelayMode(*src.DelayMode)
 	}
+	if src.AutoDelayStep != nil {
+		dst.AutoDelayStep = *src.AutoDelayStep
+	}
+	if src.Seed != nil {
+		dst.Seed = *src.Seed
+	}
 }
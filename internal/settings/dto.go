@@ -7,10 +7,31 @@ type RuntimeSettings struct {
 	Streamers       []StreamerConfig       `json:"streamers"`
 	DefaultSettings StreamerSettingsConfig `json:"defaultSettings"`
 	Priority        []string               `json:"priority"`
+	WatchMode       string                 `json:"watchMode"`
+	WatchNonEarning bool                   `json:"watchNonEarning"`
+	HLSMode         string                 `json:"hlsMode"`
+	Locale          string                 `json:"locale"`
+	PointsFloor     int                    `json:"pointsFloor"`
+	BetConfirm      BetConfirmSettings     `json:"betConfirm"`
+	Yield           YieldSettings          `json:"yield"`
+	RaidDenylist    []string               `json:"raidDenylist"`
+	ChatIgnore      ChatIgnoreSettings     `json:"chatIgnore"`
+	ChatMentions    ChatMentionSettings    `json:"chatMentions"`
 	RateLimits      RateLimitSettings      `json:"rateLimits"`
 	Logger          LoggerSettings         `json:"logger"`
 	Analytics       AnalyticsUIConfig      `json:"analytics"`
 	Discord         DiscordUIConfig        `json:"discord"`
+	Desktop         DesktopUIConfig        `json:"desktop"`
+	Follows         FollowSettings         `json:"follows"`
+	FeatureFlags    []FeatureFlag          `json:"featureFlags"`
+}
+
+// FeatureFlag is an experimental behavior toggle with its description for the UI.
+type FeatureFlag struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
 }
 
 // DiscordUIConfig contains Discord integration settings for the UI.
@@ -20,14 +41,38 @@ type DiscordUIConfig struct {
 	GuildID  string `json:"guildId"`
 }
 
+// DesktopUIConfig contains desktop notification settings for the UI.
+type DesktopUIConfig struct {
+	Enabled  bool `json:"enabled"`
+	Mentions bool `json:"mentions"`
+	Online   bool `json:"online"`
+	Drops    bool `json:"drops"`
+	Errors   bool `json:"errors"`
+}
+
+// ChatIgnoreSettings contains the usernames and message prefixes ignored in chat.
+type ChatIgnoreSettings struct {
+	Users    []string `json:"users"`
+	Prefixes []string `json:"prefixes"`
+}
+
+// ChatMentionSettings contains how mentions of the account are matched in chat.
+type ChatMentionSettings struct {
+	WholeWord    bool `json:"wholeWord"`
+	RequireAt    bool `json:"requireAt"`
+	DedupeWindow int  `json:"dedupeWindow"`
+}
+
 // RateLimitSettings contains timing intervals for various miner operations.
 type RateLimitSettings struct {
-	WebsocketPingInterval int     `json:"websocketPingInterval"`
-	CampaignSyncInterval  int     `json:"campaignSyncInterval"`
-	MinuteWatchedInterval int     `json:"minuteWatchedInterval"`
-	RequestDelay          float64 `json:"requestDelay"`
-	ReconnectDelay        int     `json:"reconnectDelay"`
-	StreamCheckInterval   int     `json:"streamCheckInterval"`
+	WebsocketPingInterval  int     `json:"websocketPingInterval"`
+	CampaignSyncInterval   int     `json:"campaignSyncInterval"`
+	MinuteWatchedInterval  int     `json:"minuteWatchedInterval"`
+	RequestDelay           float64 `json:"requestDelay"`
+	ReconnectDelay         int     `json:"reconnectDelay"`
+	StreamCheckInterval    int     `json:"streamCheckInterval"`
+	StreamCheckConcurrency int     `json:"streamCheckConcurrency"`
+	OnlineDetection        string  `json:"onlineDetection"`
 }
 
 // LoggerSettings contains logging configuration options.
@@ -40,15 +85,41 @@ type LoggerSettings struct {
 
 // AnalyticsUIConfig contains settings for the analytics dashboard display.
 type AnalyticsUIConfig struct {
-	Refresh        int  `json:"refresh"`
-	DaysAgo        int  `json:"daysAgo"`
-	EnableChatLogs bool `json:"enableChatLogs"`
+	Refresh         int  `json:"refresh"`
+	DaysAgo         int  `json:"daysAgo"`
+	EnableChatLogs  bool `json:"enableChatLogs"`
+	WarnUnfollowed  bool `json:"warnUnfollowed"`
+	EfficiencyAlert int  `json:"efficiencyAlert"`
+}
+
+// FollowSettings contains which followed channels are mined besides the
+// configured streamers.
+type FollowSettings struct {
+	Enabled      bool     `json:"enabled"`
+	Limit        int      `json:"limit"`
+	Exclude      []string `json:"exclude"`
+	SyncInterval int      `json:"syncInterval"`
+}
+
+// BetConfirmSettings contains when large bets wait for approval.
+type BetConfirmSettings struct {
+	Enabled   bool   `json:"enabled"`
+	Threshold int    `json:"threshold"`
+	Fallback  string `json:"fallback"`
+}
+
+// YieldSettings contains whether and how long watching pauses when the
+// account is viewed by hand.
+type YieldSettings struct {
+	Enabled bool `json:"enabled"`
+	Minutes int  `json:"minutes"`
 }
 
 // StreamerConfig represents a streamer in the configuration with optional per-streamer overrides.
 type StreamerConfig struct {
 	Username string                  `json:"username"`
 	Settings *StreamerSettingsConfig `json:"settings,omitempty"`
+	Disabled bool                    `json:"disabled,omitempty"`
 }
 
 // StreamerSettingsConfig is a partial override for a streamer's settings.
@@ -62,6 +133,10 @@ type StreamerSettingsConfig struct {
 	WatchStreak     *bool            `json:"watchStreak,omitempty"`
 	CommunityGoals  *bool            `json:"communityGoals,omitempty"`
 	Chat            *string          `json:"chat,omitempty"`
+	WatchWeight     *int             `json:"watchWeight,omitempty"`
+	DropsOnly       *bool            `json:"dropsOnly,omitempty"`
+	StreamSummary   *bool            `json:"streamSummary,omitempty"`
+	MaxPoints       *int             `json:"maxPoints,omitempty"`
 	Bet             *BetSettingsJSON `json:"bet,omitempty"`
 }
 
@@ -75,6 +150,8 @@ type BetSettingsJSON struct {
 	StealthMode   *bool    `json:"stealthMode,omitempty"`
 	Delay         *float64 `json:"delay,omitempty"`
 	DelayMode     *string  `json:"delayMode,omitempty"`
+	AutoDelayStep *float64 `json:"autoDelayStep,omitempty"`
+	Seed          *int64   `json:"seed,omitempty"`
 }
 
 // StreamersConfig is used for streamer-related API responses.
@@ -83,6 +160,36 @@ type StreamersConfig struct {
 	Streamers       []StreamerConfig       `json:"streamers"`
 }
 
+// BulkStreamerUpdate merges a partial settings override into several streamers.
+// Exactly one of All or Streamers selects the streamers to update.
+type BulkStreamerUpdate struct {
+	All       bool                   `json:"all,omitempty"`
+	Streamers []string               `json:"streamers,omitempty"`
+	Settings  StreamerSettingsConfig `json:"settings"`
+}
+
+// BulkStreamerUpdateCallback applies a bulk update and returns the updated
+// streamers. Nothing is changed if it returns an error.
+type BulkStreamerUpdateCallback func(update BulkStreamerUpdate) ([]string, error)
+
+// StreamerImportResult reports the outcome of importing a streamer list.
+type StreamerImportResult struct {
+	Added    []string `json:"added"`
+	Existing []string `json:"existing"`
+	NotFound []string `json:"notFound"`
+	// Failed lists streamers whose lookup returned an error, so whether
+	// they exist is unknown. They are not added.
+	Failed  []string `json:"failed"`
+	Invalid []string `json:"invalid"`
+	// PresetsIgnored lists streamers whose line named a preset. Presets are
+	// not supported yet, so these streamers use the default settings.
+	PresetsIgnored []string `json:"presetsIgnored"`
+}
+
+// StreamerImportCallback validates the streamers in a pasted or uploaded list
+// and adds the new ones to the configuration.
+type StreamerImportCallback func(text string) (StreamerImportResult, error)
+
 // SettingsUpdateCallback is invoked when the user submits new settings from the UI.
 // The callback should apply the changes atomically and persist them to the config file.
 type SettingsUpdateCallback func(settings RuntimeSettings)
@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"regexp"
+	"strings"
+)
+
+var loginPattern = regexp.MustCompile(`^[a-z0-9_]{1,25}$`)
+
+// ParseStreamerList extracts usernames from a pasted list or CSV file with one
+// streamer per line. Lines may be channel URLs or @names, and a CSV line may
+// carry a preset name after the username. Blank lines, # comments and a
+// username header row are skipped; duplicates are dropped.
+func ParseStreamerList(text string) (usernames, invalid, withPreset []string) {
+	seen := make(map[string]bool)
+	header := true
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ';' || r == '\t'
+		})
+		if len(fields) == 0 {
+			continue
+		}
+
+		username := normalizeLogin(fields[0])
+		if header {
+			header = false
+			if username == "username" || username == "streamer" || username == "login" {
+				continue
+			}
+		}
+		if !loginPattern.MatchString(username) {
+			invalid = append(invalid, strings.TrimSpace(fields[0]))
+			continue
+		}
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+
+		if len(fields) > 1 && strings.Trim(strings.TrimSpace(fields[1]), `"'`) != "" {
+			withPreset = append(withPreset, username)
+		}
+	}
+
+	return usernames, invalid, withPreset
+}
+
+// ParseLogin extracts a username from a login, @name or channel URL and
+// reports whether it is a valid Twitch login.
+func ParseLogin(value string) (string, bool) {
+	login := normalizeLogin(value)
+	return login, loginPattern.MatchString(login)
+}
+
+func normalizeLogin(value string) string {
+	login := strings.ToLower(strings.Trim(strings.TrimSpace(value), `"'`))
+	for _, prefix := range []string{"https://", "http://", "www.", "m.", "twitch.tv/", "@"} {
+		login = strings.TrimPrefix(login, prefix)
+	}
+	if i := strings.IndexAny(login, "/?"); i >= 0 {
+		login = login[:i]
+	}
+	return login
+}
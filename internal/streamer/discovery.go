@@ -0,0 +1,178 @@
+package streamer
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+// SetFollowDiscovery sets which followed channels SyncFollowed mines besides
+// the configured streamers.
+func (m *Manager) SetFollowDiscovery(settings config.FollowSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.discovery = settings
+}
+
+// SyncFollowed adds the followed channels picked by the follow discovery
+// settings and removes discovered streamers that are no longer picked, e.g.
+// after an unfollow. The follow list is fetched again once it is older than
+// the sync interval, or always with force. If the fetch fails, the
+// discovered streamers are left as they are.
+func (m *Manager) SyncFollowed(force bool) (added, removed []*models.Streamer) {
+	m.mu.RLock()
+	discovery := m.discovery
+	defaults := m.defaults
+	m.mu.RUnlock()
+
+	var picked []string
+	if discovery.Enabled {
+		follows, ok := m.loadFollows(force, time.Duration(discovery.SyncInterval)*time.Minute)
+		if !ok {
+			return nil, nil
+		}
+		picked = m.pickFollowed(follows, discovery)
+	}
+
+	added = m.addDiscovered(picked, defaults)
+
+	keep := make(map[string]bool, len(picked))
+	for _, login := range picked {
+		keep[login] = true
+	}
+
+	m.mu.Lock()
+	var remaining []*models.Streamer
+	for _, s := range m.streamers {
+		if s.GetDiscovered() && !keep[s.GetUsername()] {
+			removed = append(removed, s)
+			slog.Info("Removed followed streamer", "username", s.GetUsername())
+		} else {
+			remaining = append(remaining, s)
+		}
+	}
+	m.streamers = remaining
+	m.mu.Unlock()
+
+	if len(added) > 0 || len(removed) > 0 {
+		slog.Info("Synced followed streamers", "added", len(added), "removed", len(removed), "discovered", len(picked))
+	}
+	return added, removed
+}
+
+// loadFollows returns the cached follow list, fetching it first when it is
+// older than maxAge or force is set. ok is false if no list is available.
+func (m *Manager) loadFollows(force bool, maxAge time.Duration) (follows map[string]time.Time, ok bool) {
+	m.followsMu.Lock()
+	defer m.followsMu.Unlock()
+
+	if force || m.follows == nil || time.Since(m.followsAt) >= maxAge {
+		fetched, err := m.client.GetFollows()
+		if err != nil {
+			slog.Warn("Failed to load followed channels", "error", err)
+		} else {
+			m.follows = fetched
+			m.followsAt = time.Now()
+			slog.Debug("Loaded followed channels", "count", len(fetched))
+		}
+	}
+	return m.follows, m.follows != nil
+}
+
+// pickFollowed returns the followed channels to mine: not configured, not
+// excluded and, with a limit, the ones followed longest.
+func (m *Manager) pickFollowed(follows map[string]time.Time, discovery config.FollowSettings) []string {
+	excluded := make(map[string]bool, len(discovery.Exclude))
+	for _, login := range discovery.Exclude {
+		excluded[strings.ToLower(login)] = true
+	}
+
+	m.mu.RLock()
+	for _, s := range m.streamers {
+		if !s.GetDiscovered() {
+			excluded[s.GetUsername()] = true
+		}
+	}
+	m.mu.RUnlock()
+
+	var logins []string
+	for login := range follows {
+		if !excluded[login] {
+			logins = append(logins, login)
+		}
+	}
+	slices.SortFunc(logins, func(a, b string) int {
+		if c := follows[a].Compare(follows[b]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	if discovery.Limit > 0 && len(logins) > discovery.Limit {
+		logins = logins[:discovery.Limit]
+	}
+	return logins
+}
+
+// addDiscovered loads the picked channels that are not tracked yet with the
+// default settings.
+func (m *Manager) addDiscovered(picked []string, defaults models.StreamerSettings) []*models.Streamer {
+	var missing []string
+	for _, login := range picked {
+		if m.Get(login) == nil {
+			missing = append(missing, login)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	ids, failed, err := m.client.GetChannelIDs(missing)
+	if err != nil {
+		slog.Warn("Failed to look up followed channels", "error", err)
+		return nil
+	}
+	if len(failed) > 0 {
+		slog.Warn("Failed to look up some followed channels", "logins", failed)
+	}
+
+	states := m.loadStates()
+	var added []*models.Streamer
+	for _, login := range missing {
+		channelID := ids[login]
+		if channelID == "" {
+			continue
+		}
+
+		streamer := models.NewStreamer(login, defaults)
+		streamer.SetChannelID(channelID)
+		streamer.SetDiscovered(true)
+		if err := m.client.LoadChannelPointsContext(streamer); err != nil {
+			slog.Warn("Failed to load channel points for followed streamer", "streamer", login, "error", err)
+		}
+		restoreState(streamer, states)
+		m.applyFollow(streamer)
+
+		m.mu.Lock()
+		tracked := slices.ContainsFunc(m.streamers, func(s *models.Streamer) bool {
+			return s.GetUsername() == login
+		})
+		if !tracked {
+			m.streamers = append(m.streamers, streamer)
+		}
+		m.mu.Unlock()
+		if tracked {
+			continue
+		}
+
+		m.ensureState(login)
+		m.loadMetadata(streamer)
+		added = append(added, streamer)
+		slog.Info("Added followed streamer", "username", login, "channelID", channelID)
+	}
+	return added
+}
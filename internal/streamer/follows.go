@@ -0,0 +1,34 @@
+package streamer
+
+import (
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+// followsTTL is how long the account's follow list is cached before
+// SyncFollows fetches it again.
+const followsTTL = 6 * time.Hour
+
+// SyncFollows refreshes the cached follow list when it is older than
+// followsTTL and applies it to all streamers. On error the previous state is
+// kept, so streamers are left unknown until a fetch succeeds.
+func (m *Manager) SyncFollows() {
+	m.loadFollows(false, followsTTL)
+
+	for _, s := range m.All() {
+		m.applyFollow(s)
+	}
+}
+
+// applyFollow sets a streamer's follow state from the cached follow list.
+func (m *Manager) applyFollow(s *models.Streamer) {
+	m.followsMu.Lock()
+	defer m.followsMu.Unlock()
+
+	if m.follows == nil {
+		return
+	}
+	followedAt, ok := m.follows[s.GetUsername()]
+	s.SetFollow(models.Follow{Known: true, Following: ok, FollowedAt: followedAt})
+}
@@ -5,9 +5,11 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/api"
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 )
 
@@ -18,17 +20,39 @@ type ProgressCallback func(current, total int, username string)
 type Manager struct {
 	client   *api.TwitchClient
 	defaults models.StreamerSettings
+	repo     *Repository
 
 	streamers []*models.Streamer
 	mu        sync.RWMutex
+
+	// follows caches the account's followed channels; see SyncFollows.
+	follows   map[string]time.Time
+	followsAt time.Time
+	followsMu sync.Mutex
+
+	// discovery picks the followed channels mined besides the configured
+	// streamers; see SyncFollowed.
+	discovery config.FollowSettings
 }
 
 // NewManager creates a new streamer manager.
-func NewManager(client *api.TwitchClient, defaults models.StreamerSettings) *Manager {
-	return &Manager{
+// If db is nil or the state repository cannot be created, streamer state is not persisted.
+func NewManager(client *api.TwitchClient, defaults models.StreamerSettings, db *database.DB) *Manager {
+	m := &Manager{
 		client:   client,
 		defaults: defaults,
 	}
+
+	if db != nil {
+		repo, err := NewRepository(db)
+		if err != nil {
+			slog.Warn("Streamer state will not be persisted", "error", err)
+		} else {
+			m.repo = repo
+		}
+	}
+
+	return m
 }
 
 // LoadFromConfig loads streamers from configuration.
@@ -36,6 +60,8 @@ func NewManager(client *api.TwitchClient, defaults models.StreamerSettings) *Man
 func (m *Manager) LoadFromConfig(configs []config.StreamerConfig, onProgress ProgressCallback) error {
 	slog.Info("Loading streamers", "count", len(configs))
 
+	states := m.loadStates()
+
 	total := len(configs)
 	for i, sc := range configs {
 		if onProgress != nil {
@@ -48,33 +74,43 @@ func (m *Manager) LoadFromConfig(configs []config.StreamerConfig, onProgress Pro
 		}
 
 		streamer := models.NewStreamer(strings.ToLower(sc.Username), settings)
+		streamer.SetEnabled(!sc.Disabled)
 
-		channelID, err := m.client.GetChannelID(streamer.Username)
+		channelID, err := m.client.GetChannelID(streamer.GetUsername())
 		if err != nil {
 			slog.Warn("Streamer not found, skipping", "username", sc.Username, "error", err)
 			continue
 		}
-		streamer.ChannelID = channelID
+		streamer.SetChannelID(channelID)
 
 		if err := m.client.LoadChannelPointsContext(streamer); err != nil {
-			slog.Warn("Failed to load channel points", "streamer", streamer.Username, "error", err)
+			slog.Warn("Failed to load channel points", "streamer", streamer.GetUsername(), "error", err)
 		}
 
+		restoreState(streamer, states)
+
 		m.mu.Lock()
 		m.streamers = append(m.streamers, streamer)
 		m.mu.Unlock()
 
+		m.ensureState(streamer.GetUsername())
+		m.loadMetadata(streamer)
+
 		slog.Info("Loaded streamer",
-			"username", streamer.Username,
-			"channelID", streamer.ChannelID,
+			"username", streamer.GetUsername(),
+			"channelID", streamer.GetChannelID(),
 			"points", streamer.GetChannelPoints(),
 		)
 	}
 
+	m.SyncFollowed(true)
+
 	if len(m.streamers) == 0 {
 		return fmt.Errorf("no valid streamers found")
 	}
 
+	m.SyncFollows()
+
 	return nil
 }
 
@@ -99,7 +135,7 @@ func (m *Manager) Get(username string) *models.Streamer {
 
 	lower := strings.ToLower(username)
 	for _, s := range m.streamers {
-		if s.Username == lower {
+		if s.GetUsername() == lower {
 			return s
 		}
 	}
@@ -113,7 +149,7 @@ func (m *Manager) Names() []string {
 
 	names := make([]string, len(m.streamers))
 	for i, s := range m.streamers {
-		names[i] = s.Username
+		names[i] = s.GetUsername()
 	}
 	return names
 }
@@ -125,7 +161,7 @@ func (m *Manager) PointsMap() map[string]int {
 
 	points := make(map[string]int, len(m.streamers))
 	for _, s := range m.streamers {
-		points[s.Username] = s.GetChannelPoints()
+		points[s.GetUsername()] = s.GetChannelPoints()
 	}
 	return points
 }
@@ -143,18 +179,27 @@ func (m *Manager) ApplySettings(configs []config.StreamerConfig, defaults models
 		configMap[strings.ToLower(sc.Username)] = sc
 	}
 
+	var states map[string]State
+
 	existingMap := make(map[string]*models.Streamer)
 	for _, s := range m.streamers {
-		existingMap[s.Username] = s
+		existingMap[s.GetUsername()] = s
 	}
 
 	for _, streamer := range m.streamers {
-		if sc, ok := configMap[streamer.Username]; ok {
+		if sc, ok := configMap[streamer.GetUsername()]; ok {
 			if sc.Settings != nil {
 				streamer.SetSettings(*sc.Settings)
 			} else {
 				streamer.SetSettings(defaults)
 			}
+			streamer.SetEnabled(!sc.Disabled)
+			// A temporary or followed streamer that is now configured stays
+			// for good.
+			streamer.SetTemporaryUntil(time.Time{})
+			streamer.SetDiscovered(false)
+		} else if !streamer.GetTemporaryUntil().IsZero() || streamer.GetDiscovered() {
+			streamer.SetSettings(defaults)
 		}
 	}
 
@@ -167,30 +212,39 @@ func (m *Manager) ApplySettings(configs []config.StreamerConfig, defaults models
 			}
 
 			streamer := models.NewStreamer(username, settings)
-			channelID, err := m.client.GetChannelID(streamer.Username)
+			streamer.SetEnabled(!sc.Disabled)
+			channelID, err := m.client.GetChannelID(streamer.GetUsername())
 			if err != nil {
 				slog.Warn("Failed to add streamer", "username", username, "error", err)
 				continue
 			}
-			streamer.ChannelID = channelID
+			streamer.SetChannelID(channelID)
 
 			if err := m.client.LoadChannelPointsContext(streamer); err != nil {
 				slog.Warn("Failed to load channel points for new streamer", "streamer", username, "error", err)
 			}
 
+			if states == nil {
+				states = m.loadStates()
+			}
+			restoreState(streamer, states)
+			m.applyFollow(streamer)
+
 			m.streamers = append(m.streamers, streamer)
 			added = append(added, streamer)
+			m.ensureState(streamer.GetUsername())
+			m.loadMetadata(streamer)
 			slog.Info("Added new streamer", "username", username, "channelID", channelID)
 		}
 	}
 
 	var remaining []*models.Streamer
 	for _, streamer := range m.streamers {
-		if _, ok := configMap[streamer.Username]; ok {
+		if _, ok := configMap[streamer.GetUsername()]; ok || !streamer.GetTemporaryUntil().IsZero() || streamer.GetDiscovered() {
 			remaining = append(remaining, streamer)
 		} else {
 			removed = append(removed, streamer)
-			slog.Info("Removed streamer", "username", streamer.Username)
+			slog.Info("Removed streamer", "username", streamer.GetUsername())
 		}
 	}
 	m.streamers = remaining
@@ -198,6 +252,75 @@ func (m *Manager) ApplySettings(configs []config.StreamerConfig, defaults models
 	return added, removed
 }
 
+// AddTemporary adds a streamer with the default settings until the given
+// time without touching the config, or moves the removal of one that was
+// already added temporarily. added is false in that case. Configured
+// streamers cannot be added temporarily.
+func (m *Manager) AddTemporary(username string, until time.Time) (streamer *models.Streamer, added bool, err error) {
+	username = strings.ToLower(username)
+	if existing := m.Get(username); existing != nil {
+		if existing.GetTemporaryUntil().IsZero() {
+			return nil, false, fmt.Errorf("%s is already tracked", username)
+		}
+		existing.SetTemporaryUntil(until)
+		slog.Info("Extended temporary streamer", "username", username, "until", until.Format(time.RFC3339))
+		return existing, false, nil
+	}
+
+	m.mu.RLock()
+	defaults := m.defaults
+	m.mu.RUnlock()
+
+	streamer = models.NewStreamer(username, defaults)
+	channelID, err := m.client.GetChannelID(username)
+	if err != nil {
+		return nil, false, fmt.Errorf("streamer not found: %w", err)
+	}
+	streamer.SetChannelID(channelID)
+	streamer.SetTemporaryUntil(until)
+
+	if err := m.client.LoadChannelPointsContext(streamer); err != nil {
+		slog.Warn("Failed to load channel points for temporary streamer", "streamer", username, "error", err)
+	}
+	restoreState(streamer, m.loadStates())
+	m.applyFollow(streamer)
+
+	m.mu.Lock()
+	for _, s := range m.streamers {
+		if s.GetUsername() == username {
+			m.mu.Unlock()
+			return nil, false, fmt.Errorf("%s is already tracked", username)
+		}
+	}
+	m.streamers = append(m.streamers, streamer)
+	m.mu.Unlock()
+
+	m.ensureState(username)
+	m.loadMetadata(streamer)
+	slog.Info("Added temporary streamer", "username", username, "channelID", channelID, "until", until.Format(time.RFC3339))
+	return streamer, true, nil
+}
+
+// RemoveExpired removes the temporary streamers whose time is up and returns
+// them.
+func (m *Manager) RemoveExpired(now time.Time) []*models.Streamer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var remaining, removed []*models.Streamer
+	for _, streamer := range m.streamers {
+		until := streamer.GetTemporaryUntil()
+		if !until.IsZero() && !now.Before(until) {
+			removed = append(removed, streamer)
+			slog.Info("Removed temporary streamer", "username", streamer.GetUsername())
+		} else {
+			remaining = append(remaining, streamer)
+		}
+	}
+	m.streamers = remaining
+	return removed
+}
+
 // CheckOnlineStatus checks the online status for all streamers.
 func (m *Manager) CheckOnlineStatus() {
 	m.mu.RLock()
@@ -208,27 +331,99 @@ func (m *Manager) CheckOnlineStatus() {
 	}
 }
 
-// PrintReport logs a session report for all streamers.
-func (m *Manager) PrintReport() {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+func (m *Manager) ensureState(username string) {
+	if m.repo == nil {
+		return
+	}
+	if err := m.repo.EnsureStreamer(username, time.Now()); err != nil {
+		slog.Warn("Failed to persist streamer state", "streamer", username, "error", err)
+	}
+}
 
-	slog.Info("=== Session Report ===")
+// SaveState persists the online/offline state and watch-streak progress of every streamer.
+func (m *Manager) SaveState() {
+	if m.repo == nil {
+		return
+	}
 
-	for _, streamer := range m.streamers {
-		slog.Info("Streamer stats",
-			"username", streamer.Username,
-			"points", streamer.GetChannelPoints(),
-		)
+	now := time.Now()
+	for _, s := range m.All() {
+		broadcastID, streakMissing, minuteWatched := s.GetStream().WatchProgress()
+		state := State{
+			Username:           s.GetUsername(),
+			IsOnline:           s.GetIsOnline(),
+			OnlineAt:           s.GetOnlineAt(),
+			OfflineAt:          s.GetOfflineAt(),
+			StreamUpTime:       s.GetStreamUpTime(),
+			UpdatedAt:          now,
+			BroadcastID:        broadcastID,
+			WatchStreakMissing: streakMissing,
+			MinuteWatched:      minuteWatched,
+		}
+		if err := m.repo.SaveState(state); err != nil {
+			slog.Warn("Failed to persist streamer state", "streamer", s.GetUsername(), "error", err)
+		}
+	}
+}
 
-		for reason, entry := range streamer.History {
-			if entry.Counter > 0 || entry.Amount != 0 {
-				slog.Info("  History",
-					"reason", reason,
-					"count", entry.Counter,
-					"amount", entry.Amount,
-				)
-			}
+func (m *Manager) loadStates() map[string]State {
+	if m.repo == nil {
+		return nil
+	}
+
+	states, err := m.repo.GetStates()
+	if err != nil {
+		slog.Warn("Failed to load streamer state", "error", err)
+		return nil
+	}
+	return states
+}
+
+// restoreState applies a persisted state to a freshly created streamer.
+func restoreState(streamer *models.Streamer, states map[string]State) {
+	state, ok := states[streamer.GetUsername()]
+	if !ok {
+		return
+	}
+
+	streamer.RestoreState(state.IsOnline, state.BroadcastID, state.OnlineAt, state.OfflineAt, state.StreamUpTime)
+	streamer.SetMetadata(state.Metadata)
+	if state.IsOnline && state.BroadcastID != "" {
+		streamer.GetStream().RestoreWatchProgress(state.BroadcastID, state.WatchStreakMissing, state.MinuteWatched)
+	}
+
+	slog.Debug("Restored streamer state",
+		"streamer", streamer.GetUsername(),
+		"wasOnline", state.IsOnline,
+		"broadcastID", state.BroadcastID,
+		"onlineAt", state.OnlineAt,
+		"offlineAt", state.OfflineAt,
+	)
+}
+
+// IdleSince returns, for every offline streamer, the time since which it has
+// not been seen live. Streamers never seen live report the time tracking began.
+func (m *Manager) IdleSince() map[string]time.Time {
+	idle := make(map[string]time.Time)
+	if m.repo == nil {
+		return idle
+	}
+
+	states := m.loadStates()
+	for _, s := range m.All() {
+		if s.GetIsOnline() {
+			continue
+		}
+		state, ok := states[s.GetUsername()]
+		if !ok {
+			continue
+		}
+		if !state.LastOnlineAt.IsZero() {
+			idle[s.GetUsername()] = state.LastOnlineAt
+		} else {
+			idle[s.GetUsername()] = state.FirstSeenAt
 		}
 	}
+
+	return idle
 }
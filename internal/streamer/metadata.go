@@ -0,0 +1,37 @@
+package streamer
+
+import (
+	"log/slog"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+// loadMetadata fetches the streamer's channel metadata and stores it. On
+// error the metadata restored from the database is kept. A channel that was
+// affiliate or partner at an earlier fetch and is neither anymore is flagged
+// as having lost its affiliate status until it regains one.
+func (m *Manager) loadMetadata(streamer *models.Streamer) {
+	username := streamer.GetUsername()
+	metadata, err := m.client.GetChannelMetadata(username)
+	if err != nil {
+		slog.Warn("Failed to fetch channel metadata", "streamer", username, "error", err)
+		return
+	}
+
+	previous := streamer.GetMetadata()
+	if !metadata.Partner && !metadata.Affiliate && !previous.FetchedAt.IsZero() {
+		metadata.LostAffiliate = previous.LostAffiliate || previous.Partner || previous.Affiliate
+	}
+	streamer.SetMetadata(metadata)
+
+	if !metadata.Partner && !metadata.Affiliate {
+		slog.Warn("Streamer has no channel points", "streamer", username, "reason", streamer.NonEarningReason())
+	}
+
+	if m.repo == nil {
+		return
+	}
+	if err := m.repo.SaveMetadata(username, metadata); err != nil {
+		slog.Warn("Failed to persist channel metadata", "streamer", username, "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package streamer
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+// Report summarizes what the miner did for one streamer this session.
+// NetPoints is the balance change since the first balance was loaded;
+// DropTime is the part of WatchTime spent on streams with drop campaigns.
+type Report struct {
+	Username          string
+	Points            int
+	NetPoints         int
+	WatchTime         time.Duration
+	DropTime          time.Duration
+	StreaksEarned     int
+	BonusClaims       int
+	PredictionsPlaced int
+	PredictionsWon    int
+	History           map[string]models.HistoryEntry
+}
+
+// Reports returns the session report of every streamer.
+func (m *Manager) Reports() []Report {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	reports := make([]Report, 0, len(m.streamers))
+	for _, streamer := range m.streamers {
+		reports = append(reports, newReport(streamer))
+	}
+	return reports
+}
+
+func newReport(streamer *models.Streamer) Report {
+	stats := streamer.GetSessionStats()
+	history := streamer.GetHistory()
+	points := streamer.GetChannelPoints()
+
+	report := Report{
+		Username:          streamer.GetUsername(),
+		Points:            points,
+		WatchTime:         time.Duration(stats.MinutesWatched) * time.Minute,
+		DropTime:          time.Duration(stats.DropMinutes) * time.Minute,
+		StreaksEarned:     history["WATCH_STREAK"].Counter,
+		BonusClaims:       stats.BonusClaims,
+		PredictionsPlaced: stats.PredictionsPlaced,
+		PredictionsWon:    stats.PredictionsWon,
+		History:           history,
+	}
+	if stats.StartPointsKnown {
+		report.NetPoints = points - stats.StartPoints
+	}
+	return report
+}
+
+// PrintReport logs a session report for all streamers.
+func (m *Manager) PrintReport() {
+	slog.Info("=== Session Report ===")
+
+	for _, report := range m.Reports() {
+		slog.Info("Streamer stats",
+			"username", report.Username,
+			"points", report.Points,
+			"net", report.NetPoints,
+			"watched", report.WatchTime,
+			"dropWatched", report.DropTime,
+			"streaks", report.StreaksEarned,
+			"chests", report.BonusClaims,
+			"predictions", report.PredictionsPlaced,
+			"won", report.PredictionsWon,
+		)
+
+		for reason, entry := range report.History {
+			if entry.Counter > 0 || entry.Amount != 0 {
+				slog.Info("  History",
+					"reason", reason,
+					"count", entry.Counter,
+					"amount", entry.Amount,
+				)
+			}
+		}
+	}
+}
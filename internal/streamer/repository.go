@@ -0,0 +1,215 @@
+package streamer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+// StateModule stores per-streamer state that must survive restarts.
+type StateModule struct{}
+
+func (m *StateModule) Name() string {
+	return "streamer_state"
+}
+
+func (m *StateModule) Migrations() []database.Migration {
+	return []database.Migration{
+		{
+			Version:     1,
+			Description: "Create streamer_state table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS streamer_state (
+					username TEXT PRIMARY KEY,
+					first_seen_at INTEGER NOT NULL,
+					last_online_at INTEGER NOT NULL DEFAULT 0
+				);
+			`,
+		},
+		{
+			Version:     2,
+			Description: "Add online/offline state to streamer_state",
+			SQL: `
+				ALTER TABLE streamer_state ADD COLUMN is_online INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN online_at INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN offline_at INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN stream_up_at INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN updated_at INTEGER NOT NULL DEFAULT 0;
+			`,
+		},
+		{
+			Version:     3,
+			Description: "Add watch-streak progress to streamer_state",
+			SQL: `
+				ALTER TABLE streamer_state ADD COLUMN broadcast_id TEXT NOT NULL DEFAULT '';
+				ALTER TABLE streamer_state ADD COLUMN watch_streak_missing INTEGER NOT NULL DEFAULT 1;
+				ALTER TABLE streamer_state ADD COLUMN minute_watched REAL NOT NULL DEFAULT 0;
+			`,
+		},
+		{
+			Version:     4,
+			Description: "Add channel metadata to streamer_state",
+			SQL: `
+				ALTER TABLE streamer_state ADD COLUMN partner INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN affiliate INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN lost_affiliate INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN followers INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE streamer_state ADD COLUMN metadata_at INTEGER NOT NULL DEFAULT 0;
+			`,
+		},
+	}
+}
+
+// State is the persisted state of a single streamer.
+type State struct {
+	Username     string
+	FirstSeenAt  time.Time
+	LastOnlineAt time.Time
+	IsOnline     bool
+	OnlineAt     time.Time
+	OfflineAt    time.Time
+	StreamUpTime time.Time
+	UpdatedAt    time.Time
+
+	// Watch-streak progress of BroadcastID.
+	BroadcastID        string
+	WatchStreakMissing bool
+	MinuteWatched      float64
+
+	// Metadata is the channel metadata of the last successful fetch.
+	Metadata models.ChannelMetadata
+}
+
+// Repository persists streamer state in the shared database.
+type Repository struct {
+	db *database.DB
+}
+
+// NewRepository registers the streamer state module and returns a repository.
+func NewRepository(db *database.DB) (*Repository, error) {
+	if err := db.RegisterModule(&StateModule{}); err != nil {
+		return nil, fmt.Errorf("failed to register streamer state module: %w", err)
+	}
+	return &Repository{db: db}, nil
+}
+
+// EnsureStreamer records the first time a streamer was tracked.
+func (r *Repository) EnsureStreamer(username string, now time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT OR IGNORE INTO streamer_state (username, first_seen_at, last_online_at)
+		VALUES (?, ?, 0)
+	`, username, now.Unix())
+	return err
+}
+
+// SaveState stores the current online/offline state of a streamer.
+// The last-online time is advanced to UpdatedAt while the streamer is live.
+func (r *Repository) SaveState(state State) error {
+	updatedAt := state.UpdatedAt.Unix()
+	_, err := r.db.Exec(`
+		INSERT INTO streamer_state (
+			username, first_seen_at, last_online_at,
+			is_online, online_at, offline_at, stream_up_at, updated_at,
+			broadcast_id, watch_streak_missing, minute_watched
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(username) DO UPDATE SET
+			last_online_at = CASE WHEN excluded.is_online = 1
+				THEN excluded.updated_at ELSE streamer_state.last_online_at END,
+			is_online = excluded.is_online,
+			online_at = excluded.online_at,
+			offline_at = excluded.offline_at,
+			stream_up_at = excluded.stream_up_at,
+			updated_at = excluded.updated_at,
+			broadcast_id = excluded.broadcast_id,
+			watch_streak_missing = excluded.watch_streak_missing,
+			minute_watched = excluded.minute_watched
+	`,
+		state.Username, updatedAt, boolUnix(state.IsOnline, updatedAt),
+		state.IsOnline, toUnix(state.OnlineAt), toUnix(state.OfflineAt), toUnix(state.StreamUpTime), updatedAt,
+		state.BroadcastID, state.WatchStreakMissing, state.MinuteWatched,
+	)
+	return err
+}
+
+// SaveMetadata stores the channel metadata of a streamer recorded by
+// EnsureStreamer.
+func (r *Repository) SaveMetadata(username string, metadata models.ChannelMetadata) error {
+	_, err := r.db.Exec(`
+		UPDATE streamer_state SET
+			partner = ?, affiliate = ?, lost_affiliate = ?,
+			followers = ?, created_at = ?, metadata_at = ?
+		WHERE username = ?
+	`,
+		metadata.Partner, metadata.Affiliate, metadata.LostAffiliate,
+		metadata.Followers, toUnix(metadata.CreatedAt), toUnix(metadata.FetchedAt),
+		username,
+	)
+	return err
+}
+
+// GetStates returns the persisted state of all known streamers keyed by username.
+func (r *Repository) GetStates() (map[string]State, error) {
+	rows, err := r.db.Query(`
+		SELECT username, first_seen_at, last_online_at,
+			is_online, online_at, offline_at, stream_up_at, updated_at,
+			broadcast_id, watch_streak_missing, minute_watched,
+			partner, affiliate, lost_affiliate, followers, created_at, metadata_at
+		FROM streamer_state
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	states := make(map[string]State)
+	for rows.Next() {
+		var state State
+		var firstSeen, lastOnline, onlineAt, offlineAt, streamUpAt, updatedAt, createdAt, metadataAt int64
+		md := &state.Metadata
+		if err := rows.Scan(
+			&state.Username, &firstSeen, &lastOnline,
+			&state.IsOnline, &onlineAt, &offlineAt, &streamUpAt, &updatedAt,
+			&state.BroadcastID, &state.WatchStreakMissing, &state.MinuteWatched,
+			&md.Partner, &md.Affiliate, &md.LostAffiliate, &md.Followers, &createdAt, &metadataAt,
+		); err != nil {
+			return nil, err
+		}
+
+		state.FirstSeenAt = time.Unix(firstSeen, 0)
+		state.LastOnlineAt = fromUnix(lastOnline)
+		state.OnlineAt = fromUnix(onlineAt)
+		state.OfflineAt = fromUnix(offlineAt)
+		state.StreamUpTime = fromUnix(streamUpAt)
+		state.UpdatedAt = fromUnix(updatedAt)
+		md.CreatedAt = fromUnix(createdAt)
+		md.FetchedAt = fromUnix(metadataAt)
+		states[state.Username] = state
+	}
+
+	return states, rows.Err()
+}
+
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func fromUnix(ts int64) time.Time {
+	if ts <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
+
+func boolUnix(ok bool, ts int64) int64 {
+	if ok {
+		return ts
+	}
+	return 0
+}
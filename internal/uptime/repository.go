@@ -0,0 +1,118 @@
+package uptime
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+)
+
+// UptimeModule stores one row per miner run.
+type UptimeModule struct{}
+
+func (m *UptimeModule) Name() string {
+	return "uptime"
+}
+
+func (m *UptimeModule) Migrations() []database.Migration {
+	return []database.Migration{
+		{
+			Version:     1,
+			Description: "Create runtime_sessions table",
+			SQL: `
+				CREATE TABLE IF NOT EXISTS runtime_sessions (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					started_at INTEGER NOT NULL,
+					last_seen_at INTEGER NOT NULL,
+					stopped_at INTEGER,
+					shutdown_reason TEXT NOT NULL DEFAULT '',
+					version TEXT NOT NULL DEFAULT ''
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_runtime_sessions_started ON runtime_sessions(started_at);
+			`,
+		},
+	}
+}
+
+// Repository persists runtime sessions in the shared database.
+type Repository struct {
+	db *database.DB
+}
+
+// NewRepository registers the uptime module and returns a repository.
+func NewRepository(db *database.DB) (*Repository, error) {
+	if err := db.RegisterModule(&UptimeModule{}); err != nil {
+		return nil, fmt.Errorf("failed to register uptime module: %w", err)
+	}
+	return &Repository{db: db}, nil
+}
+
+// CloseUnfinished marks sessions that never recorded a shutdown as stopped at
+// the last time they were seen alive. It returns the number of sessions closed.
+func (r *Repository) CloseUnfinished(reason string) (int64, error) {
+	res, err := r.db.Exec(`
+		UPDATE runtime_sessions SET stopped_at = last_seen_at, shutdown_reason = ?
+		WHERE stopped_at IS NULL
+	`, reason)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Insert records the start of a session and returns its ID.
+func (r *Repository) Insert(startedAt time.Time, version string) (int64, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO runtime_sessions (started_at, last_seen_at, version) VALUES (?, ?, ?)
+	`, startedAt.Unix(), startedAt.Unix(), version)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// Touch records that the session was alive at t.
+func (r *Repository) Touch(id int64, t time.Time) error {
+	_, err := r.db.Exec(`UPDATE runtime_sessions SET last_seen_at = ? WHERE id = ?`, t.Unix(), id)
+	return err
+}
+
+// Finish records the end of a session.
+func (r *Repository) Finish(id int64, t time.Time, reason string) error {
+	_, err := r.db.Exec(`
+		UPDATE runtime_sessions SET last_seen_at = ?, stopped_at = ?, shutdown_reason = ?
+		WHERE id = ?
+	`, t.Unix(), t.Unix(), reason, id)
+	return err
+}
+
+// CountStartedSince returns how many sessions other than exclude started at or after since.
+func (r *Repository) CountStartedSince(since time.Time, exclude int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM runtime_sessions WHERE started_at >= ? AND id != ?
+	`, since.Unix(), exclude).Scan(&count)
+	return count, err
+}
+
+// LastShutdown returns the most recent finished session's stop time and reason.
+// The time is zero if no session has finished yet.
+func (r *Repository) LastShutdown() (time.Time, string, error) {
+	var stoppedAt int64
+	var reason string
+	err := r.db.QueryRow(`
+		SELECT stopped_at, shutdown_reason FROM runtime_sessions
+		WHERE stopped_at IS NOT NULL
+		ORDER BY stopped_at DESC, id DESC
+		LIMIT 1
+	`).Scan(&stoppedAt, &reason)
+	if err == sql.ErrNoRows {
+		return time.Time{}, "", nil
+	}
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return time.Unix(stoppedAt, 0), reason, nil
+}
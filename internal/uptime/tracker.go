@@ -0,0 +1,158 @@
+// Package uptime records when the miner starts and stops, and why it stopped,
+// so restarts and crashes are visible after the fact.
+package uptime
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+)
+
+// Shutdown reasons recorded by the tracker itself. Other reasons are free text,
+// such as the error that stopped the miner.
+const (
+	ReasonShutdown = "shutdown"
+	ReasonUnclean  = "unclean exit (crash or killed)"
+)
+
+const (
+	// heartbeatInterval bounds how far the recorded stop time of a crashed
+	// session can lag behind the actual crash.
+	heartbeatInterval = time.Minute
+
+	summaryWindow = 7 * 24 * time.Hour
+)
+
+// Summary describes the current run and recent restart history.
+type Summary struct {
+	StartedAt          time.Time  `json:"startedAt"`
+	UptimeSeconds      int64      `json:"uptimeSeconds"`
+	Restarts7d         int        `json:"restarts7d"`
+	LastShutdownAt     *time.Time `json:"lastShutdownAt,omitempty"`
+	LastShutdownReason string     `json:"lastShutdownReason,omitempty"`
+}
+
+// Tracker records the current run as a runtime session.
+type Tracker struct {
+	repo      *Repository
+	sessionID int64
+	startedAt time.Time
+	stopped   bool
+	mu        sync.Mutex
+}
+
+// NewTracker creates an uptime tracker.
+// If db is nil or the uptime repository cannot be created, sessions are not persisted.
+func NewTracker(db *database.DB) *Tracker {
+	t := &Tracker{startedAt: time.Now()}
+
+	if db != nil {
+		repo, err := NewRepository(db)
+		if err != nil {
+			slog.Warn("Uptime history will not be persisted", "error", err)
+		} else {
+			t.repo = repo
+		}
+	}
+
+	return t
+}
+
+// Start closes sessions left open by a previous crash and records this run.
+func (t *Tracker) Start(version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.startedAt = time.Now()
+	if t.repo == nil {
+		return
+	}
+
+	if n, err := t.repo.CloseUnfinished(ReasonUnclean); err != nil {
+		slog.Warn("Failed to close unfinished runtime sessions", "error", err)
+	} else if n > 0 {
+		slog.Warn("Previous run did not shut down cleanly")
+	}
+
+	id, err := t.repo.Insert(t.startedAt, version)
+	if err != nil {
+		slog.Warn("Failed to record runtime session", "error", err)
+		return
+	}
+	t.sessionID = id
+}
+
+// Run periodically records that the miner is alive until ctx is canceled.
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.mu.Lock()
+			if t.repo != nil && t.sessionID != 0 && !t.stopped {
+				if err := t.repo.Touch(t.sessionID, now); err != nil {
+					slog.Debug("Failed to record uptime heartbeat", "error", err)
+				}
+			}
+			t.mu.Unlock()
+		}
+	}
+}
+
+// Stop records the end of this run. Only the first call has an effect.
+func (t *Tracker) Stop(reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stopped {
+		return
+	}
+	t.stopped = true
+
+	if t.repo == nil || t.sessionID == 0 {
+		return
+	}
+	if err := t.repo.Finish(t.sessionID, time.Now(), reason); err != nil {
+		slog.Warn("Failed to record shutdown", "error", err)
+	}
+}
+
+// Summary returns the uptime of this run and the restart history of the last 7 days.
+func (t *Tracker) Summary() (*Summary, error) {
+	t.mu.Lock()
+	startedAt := t.startedAt
+	sessionID := t.sessionID
+	t.mu.Unlock()
+
+	summary := &Summary{
+		StartedAt:     startedAt,
+		UptimeSeconds: int64(time.Since(startedAt).Seconds()),
+	}
+	if t.repo == nil {
+		return summary, nil
+	}
+
+	restarts, err := t.repo.CountStartedSince(time.Now().Add(-summaryWindow), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	summary.Restarts7d = restarts
+
+	stoppedAt, reason, err := t.repo.LastShutdown()
+	if err != nil {
+		return nil, err
+	}
+	if !stoppedAt.IsZero() {
+		summary.LastShutdownAt = &stoppedAt
+		summary.LastShutdownReason = reason
+	}
+
+	return summary, nil
+}
@@ -2,62 +2,74 @@ package util
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// FormatNumber formats an integer with comma separators (e.g., 1234567 -> "1,234,567")
+// FormatNumber formats an integer with the current locale's thousands
+// separator (e.g., 1234567 -> "1,234,567" in English, "1.234.567" in German)
 func FormatNumber(n int) string {
-	if n == 0 {
-		return "0"
-	}
+	loc := getLocale()
 
 	sign := ""
 	if n < 0 {
 		sign = "-"
-		n = -n
+	}
+	s := strconv.Itoa(n)
+	s = strings.TrimPrefix(s, "-")
+	if len(s) < loc.minGroup {
+		return sign + s
 	}
 
-	s := fmt.Sprintf("%d", n)
-	result := ""
+	var b strings.Builder
+	b.WriteString(sign)
 	for i, c := range s {
 		if i > 0 && (len(s)-i)%3 == 0 {
-			result += ","
+			b.WriteString(loc.group)
 		}
-		result += string(c)
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// FormatSigned formats a change in points like FormatNumber, with a leading
+// "+" unless it is negative (e.g., 1500 -> "+1,500")
+func FormatSigned(n int) string {
+	if n < 0 {
+		return FormatNumber(n)
 	}
-	return sign + result
+	return "+" + FormatNumber(n)
 }
 
 // FormatDuration formats a duration into a human-readable short form (e.g., "5m", "2h", "3d")
 func FormatDuration(d time.Duration) string {
-	totalSeconds := int(d.Seconds())
-	if totalSeconds < 60 {
-		return fmt.Sprintf("%ds", totalSeconds)
-	}
-	if totalSeconds < 3600 {
-		return fmt.Sprintf("%dm", totalSeconds/60)
-	}
-	if totalSeconds < 86400 {
-		return fmt.Sprintf("%dh", totalSeconds/3600)
-	}
-	return fmt.Sprintf("%dd", totalSeconds/86400)
+	return formatUnits(int64(d.Seconds()), getLocale())
 }
 
 // FormatTimeAgo formats a Unix millisecond timestamp as a relative time string
 func FormatTimeAgo(timestamp int64) string {
+	loc := getLocale()
 	if timestamp == 0 {
-		return "Never"
+		return loc.never
 	}
 
 	seconds := (time.Now().UnixMilli() - timestamp) / 1000
 	if seconds < 60 {
-		return "Just now"
+		return loc.justNow
 	}
-	if seconds < 3600 {
-		return fmt.Sprintf("%dm ago", seconds/60)
-	}
-	if seconds < 86400 {
-		return fmt.Sprintf("%dh ago", seconds/3600)
+	return fmt.Sprintf(loc.ago, formatUnits(seconds, loc))
+}
+
+func formatUnits(seconds int64, loc locale) string {
+	switch {
+	case seconds < 60:
+		return fmt.Sprintf("%d%s", seconds, loc.units[0])
+	case seconds < 3600:
+		return fmt.Sprintf("%d%s", seconds/60, loc.units[1])
+	case seconds < 86400:
+		return fmt.Sprintf("%d%s", seconds/3600, loc.units[2])
+	default:
+		return fmt.Sprintf("%d%s", seconds/86400, loc.units[3])
 	}
-	return fmt.Sprintf("%dd ago", seconds/86400)
 }
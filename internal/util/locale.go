@@ -0,0 +1,81 @@
+package util
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used for an empty or unsupported locale.
+const DefaultLocale = "en"
+
+// locale is how numbers and durations are written in one language.
+type locale struct {
+	// group separates thousands; minGroup is the fewest digits a number
+	// needs before it is grouped.
+	group    string
+	minGroup int
+	// units are the suffixes of seconds, minutes, hours and days.
+	units   [4]string
+	ago     string
+	justNow string
+	never   string
+}
+
+var locales = map[string]locale{
+	"en": {group: ",", minGroup: 4, units: [4]string{"s", "m", "h", "d"}, ago: "%s ago", justNow: "Just now", never: "Never"},
+	"de": {group: ".", minGroup: 4, units: [4]string{" Sek.", " Min.", " Std.", " T."}, ago: "vor %s", justNow: "Gerade eben", never: "Nie"},
+	"es": {group: ".", minGroup: 5, units: [4]string{" s", " min", " h", " d"}, ago: "hace %s", justNow: "Ahora mismo", never: "Nunca"},
+	"fr": {group: "\u202f", minGroup: 4, units: [4]string{" s", " min", " h", " j"}, ago: "il y a %s", justNow: "À l'instant", never: "Jamais"},
+	"it": {group: ".", minGroup: 4, units: [4]string{" s", " min", " h", " g"}, ago: "%s fa", justNow: "Adesso", never: "Mai"},
+	"nl": {group: ".", minGroup: 4, units: [4]string{" s", " min", " u", " d"}, ago: "%s geleden", justNow: "Zojuist", never: "Nooit"},
+	"pl": {group: "\u00a0", minGroup: 5, units: [4]string{" s", " min", " godz.", " dni"}, ago: "%s temu", justNow: "Przed chwilą", never: "Nigdy"},
+	"pt": {group: ".", minGroup: 4, units: [4]string{" s", " min", " h", " d"}, ago: "há %s", justNow: "Agora mesmo", never: "Nunca"},
+}
+
+var (
+	currentLocale = DefaultLocale
+	localeMu      sync.RWMutex
+)
+
+// NormalizeLocale returns the supported locale for a language tag such as
+// "de" or "de-AT", or DefaultLocale.
+func NormalizeLocale(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if _, ok := locales[tag]; ok {
+		return tag
+	}
+	return DefaultLocale
+}
+
+// Locales returns the supported locales, sorted.
+func Locales() []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// SetLocale sets the locale FormatNumber, FormatDuration and FormatTimeAgo
+// write in.
+func SetLocale(tag string) {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	currentLocale = NormalizeLocale(tag)
+}
+
+// Locale returns the current locale.
+func Locale() string {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	return currentLocale
+}
+
+func getLocale() locale {
+	return locales[Locale()]
+}
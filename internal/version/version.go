@@ -1,7 +1,36 @@
 package version
 
-// Version is set at build time via -ldflags "-X github.com/PatrickWalther/twitch-miner-go/internal/version.Version=..."
-var Version = "dev"
+import "runtime"
+
+// Version, Commit and BuildDate are set at build time via -ldflags, e.g.
+// "-X github.com/PatrickWalther/twitch-miner-go/internal/version.Version=...".
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
 
 // RepoURL is the GitHub repository URL
 const RepoURL = "https://github.com/PatrickWalther/twitch-miner-go"
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// Info returns the build information of the running binary.
+func Info() BuildInfo {
+	return BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+}
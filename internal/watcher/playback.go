@@ -0,0 +1,81 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenRefreshMargin is how long before its expiry a playback token is
+	// replaced, so it never runs out halfway through a watch round.
+	tokenRefreshMargin = 2 * time.Minute
+
+	// tokenFallbackTTL is used for tokens whose value carries no expiry.
+	tokenFallbackTTL = 5 * time.Minute
+)
+
+// errPlaybackForbidden is returned by simulateWatching when the playlist
+// request is rejected, which usually means the playback token went stale.
+var errPlaybackForbidden = errors.New("playback token rejected")
+
+type playbackToken struct {
+	signature string
+	value     string
+	expires   time.Time
+}
+
+// tokenCache keeps one playback access token per streamer so a new one is only
+// requested when the cached one is about to expire or was rejected.
+type tokenCache struct {
+	tokens map[string]playbackToken
+	mu     sync.Mutex
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{tokens: make(map[string]playbackToken)}
+}
+
+// get returns the cached token for username if it is still usable.
+func (c *tokenCache) get(username string) (playbackToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, ok := c.tokens[username]
+	if !ok || time.Until(token.expires) < tokenRefreshMargin {
+		return playbackToken{}, false
+	}
+	return token, true
+}
+
+func (c *tokenCache) put(username, signature, value string) playbackToken {
+	token := playbackToken{
+		signature: signature,
+		value:     value,
+		expires:   tokenExpiry(value),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[username] = token
+	return token
+}
+
+func (c *tokenCache) invalidate(username string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, username)
+}
+
+// tokenExpiry reads the "expires" Unix timestamp from a playback token value,
+// which is itself a JSON document.
+func tokenExpiry(value string) time.Time {
+	var parsed struct {
+		Expires int64 `json:"expires"`
+	}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil || parsed.Expires == 0 {
+		return time.Now().Add(tokenFallbackTTL)
+	}
+	return time.Unix(parsed.Expires, 0)
+}
@@ -2,12 +2,14 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -15,20 +17,80 @@ import (
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/api"
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/connectivity"
 	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
+	"github.com/PatrickWalther/twitch-miner-go/internal/crash"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 )
 
+// shareWindow is the period over which time-share mode balances watch rounds
+// between streamers; the counts start over when it ends.
+const shareWindow = time.Hour
+
+// spadeRefreshFailures is how many minute-watched reports in a row may fail
+// before the streamer's spade URL is fetched again, in case Twitch rotated it.
+const spadeRefreshFailures = 3
+
+// hlsWarnFailures is how many minute-watched rounds in a row the video
+// requests may fail before a warning suggests a lighter HLS mode.
+const hlsWarnFailures = 10
+
+// errSpadeReport wraps failures of the minute-watched report itself, as opposed
+// to the playback token and playlist requests before it.
+var errSpadeReport = errors.New("minute-watched report failed")
+
+// ErrTooManyPinned is returned when pinning a streamer while every watch slot
+// is already pinned.
+var ErrTooManyPinned = errors.New("all watch slots are already pinned")
+
+// WatchHandler is called every interval with the streamers occupying the watch slots.
+type WatchHandler func(streamers []string)
+
+// HeartbeatHandler is called after every minute-watched report with its
+// outcome; err is nil if it succeeded.
+type HeartbeatHandler func(streamer string, err error)
+
 type MinuteWatcher struct {
 	client     *api.TwitchClient
+	network    *connectivity.Monitor
 	streamers  []*models.Streamer
 	priorities []config.Priority
 	settings   config.RateLimitSettings
+	mode       config.WatchMode
+	hlsMode    config.HLSMode
+
+	// shareRounds counts the rounds each streamer was watched in time-share
+	// mode since shareStart.
+	shareRounds map[string]int
+	shareStart  time.Time
+
+	// pinned lists streamers that take a watch slot whenever they are
+	// online, ahead of every priority.
+	pinned []string
+
+	// watchNonEarning keeps non-earning streamers eligible for watch slots.
+	watchNonEarning bool
+
+	// paused stops sending minute-watched reports until resumed.
+	paused bool
 
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	httpClient *http.Client
+	tokens     *tokenCache
+
+	// spadeFailures counts consecutive failed minute-watched reports per
+	// streamer; it is only used by the watch loop.
+	spadeFailures map[string]int
+	// hlsFailures counts consecutive failed video requests across all
+	// streamers; it is only used by the watch loop.
+	hlsFailures int
+
+	onWatch     WatchHandler
+	onHeartbeat HeartbeatHandler
+	watching    []string
 
 	mu sync.RWMutex
 }
@@ -44,7 +106,10 @@ func NewMinuteWatcher(
 		streamers:  streamers,
 		priorities: priorities,
 		settings:   settings,
-		httpClient: &http.Client{Timeout: 20 * time.Second},
+		httpClient: httpx.NewClient(20 * time.Second),
+		tokens:     newTokenCache(),
+
+		spadeFailures: make(map[string]int),
 	}
 }
 
@@ -53,7 +118,7 @@ func (w *MinuteWatcher) Start(ctx context.Context) {
 	w.ctx, w.cancel = context.WithCancel(ctx)
 	w.mu.Unlock()
 
-	go w.loop()
+	go crash.Loop(w.ctx.Done(), "watcher", w.loop)
 }
 
 func (w *MinuteWatcher) Stop() {
@@ -64,6 +129,27 @@ func (w *MinuteWatcher) Stop() {
 	w.mu.Unlock()
 }
 
+func (w *MinuteWatcher) SetWatchHandler(handler WatchHandler) {
+	w.onWatch = handler
+}
+
+func (w *MinuteWatcher) SetHeartbeatHandler(handler HeartbeatHandler) {
+	w.onHeartbeat = handler
+}
+
+// SetConnectivity sets the monitor that pauses the watch loop while the
+// network is down.
+func (w *MinuteWatcher) SetConnectivity(monitor *connectivity.Monitor) {
+	w.network = monitor
+}
+
+// Watching returns the streamers that occupied the watch slots in the last interval.
+func (w *MinuteWatcher) Watching() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return append([]string(nil), w.watching...)
+}
+
 func (w *MinuteWatcher) UpdateSettings(priorities []config.Priority, settings config.RateLimitSettings) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -71,6 +157,100 @@ func (w *MinuteWatcher) UpdateSettings(priorities []config.Priority, settings co
 	w.settings = settings
 }
 
+// SetWatchMode switches between filling the watch slots by priority and
+// sharing them among all eligible streamers.
+func (w *MinuteWatcher) SetWatchMode(mode config.WatchMode) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if mode != w.mode {
+		w.mode = mode
+		w.shareRounds = nil
+	}
+}
+
+// SetHLSMode sets which video requests accompany each minute-watched report
+// and logs the mode when it changes.
+func (w *MinuteWatcher) SetHLSMode(mode config.HLSMode) {
+	w.mu.Lock()
+	changed := mode != w.hlsMode
+	w.hlsMode = mode
+	w.mu.Unlock()
+
+	if changed {
+		slog.Info("Minute-watched requests", "hlsMode", mode, "sends", hlsModeRequests(mode))
+	}
+}
+
+func (w *MinuteWatcher) getHLSMode() config.HLSMode {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.hlsMode
+}
+
+// hlsModeRequests describes the requests sent for a minute watched in the
+// given mode.
+func hlsModeRequests(mode config.HLSMode) string {
+	switch mode {
+	case config.HLSModeOff:
+		return "minute-watched report only, no playback token or video requests"
+	case config.HLSModePlaylist:
+		return "playback token and playlists, no video segment"
+	default:
+		return "playback token, playlists and a HEAD request for a video segment"
+	}
+}
+
+// SetPaused stops or resumes watching. While paused no watch slots are
+// filled, so no watch points are earned.
+func (w *MinuteWatcher) SetPaused(paused bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = paused
+}
+
+// Paused reports whether watching is paused.
+func (w *MinuteWatcher) Paused() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.paused
+}
+
+// SetWatchNonEarning sets whether streamers that earn nothing by being
+// watched still compete for watch slots. Pinned streamers always do.
+func (w *MinuteWatcher) SetWatchNonEarning(watch bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watchNonEarning = watch
+}
+
+// SetPinned pins or unpins a streamer to a watch slot.
+func (w *MinuteWatcher) SetPinned(username string, pinned bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	idx := slices.Index(w.pinned, username)
+	switch {
+	case pinned && idx >= 0, !pinned && idx < 0:
+		return nil
+	case pinned:
+		if len(w.pinned) >= constants.MaxSimultaneousStreams {
+			return ErrTooManyPinned
+		}
+		w.pinned = append(w.pinned, username)
+	default:
+		w.pinned = slices.Delete(w.pinned, idx, idx+1)
+	}
+	return nil
+}
+
+// Pinned returns the streamers pinned to a watch slot.
+func (w *MinuteWatcher) Pinned() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return append([]string(nil), w.pinned...)
+}
+
 func (w *MinuteWatcher) randomizedDelay(base time.Duration) time.Duration {
 	jitter := (rand.Float64() - 0.5) * 0.4
 	return time.Duration(float64(base) * (1.0 + jitter))
@@ -86,48 +266,83 @@ func (w *MinuteWatcher) loop() {
 
 		w.processWatching()
 
-		interval := time.Duration(w.settings.MinuteWatchedInterval) * time.Second
 		select {
 		case <-w.ctx.Done():
 			return
-		case <-time.After(w.randomizedDelay(interval)):
+		case <-time.After(w.randomizedDelay(w.interval())):
 		}
 	}
 }
 
+// interval is the time between minute-watched rounds, stretched while Twitch
+// throttles requests.
+func (w *MinuteWatcher) interval() time.Duration {
+	return time.Duration(w.settings.MinuteWatchedInterval) * time.Second * httpx.Slowdown()
+}
+
 func (w *MinuteWatcher) processWatching() {
+	if !w.network.Online() {
+		return
+	}
+	if w.Paused() {
+		w.setWatching(nil)
+		return
+	}
+
 	onlineStreamers := w.getOnlineStreamers()
 	if len(onlineStreamers) == 0 {
+		w.setWatching(nil)
 		return
 	}
 
 	for _, idx := range onlineStreamers {
-		if w.streamers[idx].Stream.UpdateElapsed() > 10*time.Minute {
+		if w.streamers[idx].GetStream().UpdateElapsed() > 10*time.Minute {
 			w.client.CheckStreamerOnline(w.streamers[idx])
 		}
 	}
 
 	watching := w.selectStreamersToWatch(onlineStreamers)
 	if len(watching) == 0 {
+		w.setWatching(nil)
 		return
 	}
 
 	var watchingNames []string
 	for _, idx := range watching {
-		watchingNames = append(watchingNames, w.streamers[idx].Username)
+		watchingNames = append(watchingNames, w.streamers[idx].GetUsername())
 	}
+	w.setWatching(watchingNames)
 	slog.Debug("Watching streams", "count", len(watching), "max", constants.MaxSimultaneousStreams, "streamers", watchingNames)
 
-	sleepBetween := time.Duration(w.settings.MinuteWatchedInterval) * time.Second / time.Duration(len(watching))
+	if w.onWatch != nil {
+		w.onWatch(watchingNames)
+	}
+
+	sleepBetween := w.interval() / time.Duration(len(watching))
 
 	for _, idx := range watching {
 		streamer := w.streamers[idx]
 
-		if err := w.sendMinuteWatched(streamer); err != nil {
-			slog.Debug("Failed to send minute watched", "streamer", streamer.Username, "error", err)
+		err := w.sendMinuteWatched(streamer)
+		streamer.RecordHeartbeat(err)
+		if w.onHeartbeat != nil {
+			w.onHeartbeat(streamer.GetUsername(), err)
+		}
+		if err != nil {
+			slog.Debug("Failed to send minute watched", "streamer", streamer.GetUsername(), "error", err)
+			if errors.Is(err, errSpadeReport) {
+				w.spadeFailed(streamer)
+			}
 		} else {
-			slog.Debug("Sent minute watched", "streamer", streamer.Username, "minutesWatched", streamer.Stream.MinuteWatched)
-			streamer.Stream.UpdateMinuteWatched()
+			delete(w.spadeFailures, streamer.GetUsername())
+			slog.Debug("Sent minute watched", "streamer", streamer.GetUsername(), "minutesWatched", streamer.GetStream().GetMinuteWatched())
+			streamer.GetStream().UpdateMinuteWatched()
+			if streamer.RecordMinuteWatched() {
+				slog.Warn("Streamer earns no watch points, marking it non-earning",
+					"streamer", streamer.GetUsername(),
+					"minutes", models.NonEarningMinutes,
+				)
+			}
 		}
 
 		select {
@@ -138,10 +353,16 @@ func (w *MinuteWatcher) processWatching() {
 	}
 }
 
+func (w *MinuteWatcher) setWatching(names []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watching = names
+}
+
 func (w *MinuteWatcher) getOnlineStreamers() []int {
 	var online []int
 	for i, s := range w.streamers {
-		if s.GetIsOnline() {
+		if s.GetIsOnline() && s.GetEnabled() {
 			if s.GetOnlineAt().IsZero() || time.Since(s.GetOnlineAt()) > 30*time.Second {
 				online = append(online, i)
 			}
@@ -151,25 +372,136 @@ func (w *MinuteWatcher) getOnlineStreamers() []int {
 }
 
 func (w *MinuteWatcher) selectStreamersToWatch(onlineIndexes []int) []int {
+	w.mu.RLock()
+	priorities, mode, pinned, watchNonEarning := w.priorities, w.mode, w.pinned, w.watchNonEarning
+	w.mu.RUnlock()
+
+	// dropsOnly holds drops-only, non-earning or capped streamers that are
+	// still worth watching for drops; only the drops priority may give them
+	// a slot.
+	var selected, candidates []int
+	dropsOnly := make(map[int]bool)
+	for _, idx := range onlineIndexes {
+		s := w.streamers[idx]
+		switch {
+		case slices.Contains(pinned, s.GetUsername()):
+			selected = append(selected, idx)
+		case s.GetSettings().DropsOnly:
+			// Drops-only streamers never earn points, so they never become
+			// non-earning; they are only worth a slot for an active campaign.
+			if s.DropsCondition() {
+				candidates = append(candidates, idx)
+				dropsOnly[idx] = true
+			}
+		case s.PointsCapReached():
+			// Past the points cap only drop progress is worth a slot.
+			if s.DropsCondition() {
+				candidates = append(candidates, idx)
+				dropsOnly[idx] = true
+			}
+		case watchNonEarning || s.NonEarningReason() == "":
+			candidates = append(candidates, idx)
+		case s.DropsCondition():
+			candidates = append(candidates, idx)
+			dropsOnly[idx] = true
+		}
+	}
+
+	slots := constants.MaxSimultaneousStreams - len(selected)
+	if slots <= 0 || len(candidates) == 0 {
+		return selected
+	}
+
+	ranked, forDrops := w.rankStreamers(candidates, dropsOnly, priorities, len(candidates))
+	if mode != config.WatchModeTimeShare || len(candidates) <= slots {
+		return append(selected, w.spreadCampaigns(ranked, forDrops, selected, slots)...)
+	}
+
+	ranked = appendMissing(ranked, candidates)
+	return append(selected, w.shareSlots(ranked, forDrops, selected, slots)...)
+}
+
+// spreadCampaigns picks up to n streamers from ranked in order, passing over
+// those ranked for drops whose drop campaigns are all already covered by the
+// streamers in watching or an earlier pick, so two slots are not spent on the
+// same campaign. Streamers ranked by another priority are never passed over.
+// Passed-over streamers fill whatever slots are left at the end.
+func (w *MinuteWatcher) spreadCampaigns(ranked []int, forDrops map[int]bool, watching []int, n int) []int {
+	covered := make(map[string]bool)
+	campaigns := func(idx int) []string {
+		if !w.streamers[idx].DropsCondition() {
+			return nil
+		}
+		return w.streamers[idx].GetStream().GetCampaignIDs()
+	}
+	for _, idx := range watching {
+		for _, id := range campaigns(idx) {
+			covered[id] = true
+		}
+	}
+
+	var picked, redundant []int
+	for _, idx := range ranked {
+		if len(picked) == n {
+			break
+		}
+		ids := campaigns(idx)
+		if forDrops[idx] && len(ids) > 0 && !slices.ContainsFunc(ids, func(id string) bool { return !covered[id] }) {
+			redundant = append(redundant, idx)
+			continue
+		}
+		for _, id := range ids {
+			covered[id] = true
+		}
+		picked = append(picked, idx)
+	}
+
+	for _, idx := range redundant {
+		if len(picked) == n {
+			break
+		}
+		picked = append(picked, idx)
+	}
+	return picked
+}
+
+// rankStreamers orders up to limit online streamers by the configured
+// priorities. Streamers in dropsOnly are only ranked by the drops priority.
+// forDrops holds the streamers that were ranked by the drops priority.
+func (w *MinuteWatcher) rankStreamers(candidates []int, dropsOnly map[int]bool, priorities []config.Priority, limit int) (ranked []int, forDrops map[int]bool) {
 	watching := make(map[int]bool)
+	forDrops = make(map[int]bool)
 
+	add := func(idx int) bool {
+		if watching[idx] {
+			return false
+		}
+		watching[idx] = true
+		ranked = append(ranked, idx)
+		return true
+	}
 	remainingSlots := func() int {
-		return constants.MaxSimultaneousStreams - len(watching)
+		return limit - len(ranked)
 	}
 
-	for _, priority := range w.priorities {
+	for _, priority := range priorities {
 		if remainingSlots() <= 0 {
 			break
 		}
 
+		onlineIndexes := candidates
+		if priority != config.PriorityDrops && len(dropsOnly) > 0 {
+			onlineIndexes = slices.DeleteFunc(slices.Clone(candidates), func(idx int) bool {
+				return dropsOnly[idx]
+			})
+		}
+
 		switch priority {
 		case config.PriorityOrder:
 			for _, idx := range onlineIndexes {
-				if !watching[idx] {
-					watching[idx] = true
-					if remainingSlots() <= 0 {
-						break
-					}
+				add(idx)
+				if remainingSlots() <= 0 {
+					break
 				}
 			}
 
@@ -189,26 +521,22 @@ func (w *MinuteWatcher) selectStreamersToWatch(onlineIndexes []int) []int {
 				return items[i].points > items[j].points
 			})
 			for _, item := range items {
-				if !watching[item.index] {
-					watching[item.index] = true
-					if remainingSlots() <= 0 {
-						break
-					}
+				add(item.index)
+				if remainingSlots() <= 0 {
+					break
 				}
 			}
 
 		case config.PriorityStreak:
 			for _, idx := range onlineIndexes {
 				s := w.streamers[idx]
-				if s.Settings.WatchStreak &&
-					s.Stream.WatchStreakMissing &&
+				if s.GetSettings().WatchStreak &&
+					s.GetStream().GetWatchStreakMissing() &&
 					(s.GetOfflineAt().IsZero() || time.Since(s.GetOfflineAt()) > 30*time.Minute) &&
-					s.Stream.MinuteWatched < 7 {
-					if !watching[idx] {
-						watching[idx] = true
-						if remainingSlots() <= 0 {
-							break
-						}
+					s.GetStream().GetMinuteWatched() < 7 {
+					add(idx)
+					if remainingSlots() <= 0 {
+						break
 					}
 				}
 			}
@@ -216,11 +544,11 @@ func (w *MinuteWatcher) selectStreamersToWatch(onlineIndexes []int) []int {
 		case config.PriorityDrops:
 			for _, idx := range onlineIndexes {
 				if w.streamers[idx].DropsCondition() {
-					if !watching[idx] {
-						watching[idx] = true
-						if remainingSlots() <= 0 {
-							break
-						}
+					if add(idx) {
+						forDrops[idx] = true
+					}
+					if remainingSlots() <= 0 {
+						break
 					}
 				}
 			}
@@ -243,43 +571,81 @@ func (w *MinuteWatcher) selectStreamersToWatch(onlineIndexes []int) []int {
 				return items[i].multiplier > items[j].multiplier
 			})
 			for _, item := range items {
-				if !watching[item.index] {
-					watching[item.index] = true
-					if remainingSlots() <= 0 {
-						break
-					}
+				add(item.index)
+				if remainingSlots() <= 0 {
+					break
 				}
 			}
 		}
 	}
 
-	result := make([]int, 0, len(watching))
-	for idx := range watching {
-		result = append(result, idx)
+	return ranked, forDrops
+}
+
+// appendMissing appends the indexes not already ranked, in config order, so
+// streamers no priority applies to still get a share in time-share mode.
+func appendMissing(ranked, indexes []int) []int {
+	seen := make(map[int]bool, len(ranked))
+	for _, idx := range ranked {
+		seen[idx] = true
+	}
+	for _, idx := range indexes {
+		if !seen[idx] {
+			ranked = append(ranked, idx)
+		}
 	}
-	return result
+	return ranked
 }
 
-func (w *MinuteWatcher) sendMinuteWatched(streamer *models.Streamer) error {
-	sig, token, err := w.client.GetPlaybackAccessToken(streamer.Username)
-	if err != nil {
-		return fmt.Errorf("failed to get playback token: %w", err)
+// shareSlots fills the watch slots with the streamers that were watched the
+// fewest rounds in the current share window relative to their watch weight,
+// breaking ties by priority, and counts this round for them. Streamers already
+// in watching are taken into account when spreading drop campaigns.
+func (w *MinuteWatcher) shareSlots(ranked []int, forDrops map[int]bool, watching []int, slots int) []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.shareRounds == nil || time.Since(w.shareStart) >= shareWindow {
+		w.shareRounds = make(map[string]int)
+		w.shareStart = time.Now()
 	}
 
-	if err := w.simulateWatching(streamer.Username, sig, token); err != nil {
-		slog.Debug("Failed to simulate watching", "streamer", streamer.Username, "error", err)
+	usage := make(map[int]float64, len(ranked))
+	for _, idx := range ranked {
+		s := w.streamers[idx]
+		weight := max(s.GetSettings().WatchWeight, 1)
+		usage[idx] = float64(w.shareRounds[s.GetUsername()]) / float64(weight)
 	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return usage[ranked[i]] < usage[ranked[j]]
+	})
 
-	if streamer.Stream.SpadeURL == "" {
-		return fmt.Errorf("no spade URL")
+	selected := w.spreadCampaigns(ranked, forDrops, watching, slots)
+	for _, idx := range selected {
+		w.shareRounds[w.streamers[idx].GetUsername()]++
+	}
+	return selected
+}
+
+func (w *MinuteWatcher) sendMinuteWatched(streamer *models.Streamer) error {
+	if mode := w.getHLSMode(); mode != config.HLSModeOff {
+		if err := w.watchHLS(streamer.GetUsername(), mode); err != nil {
+			return err
+		}
 	}
 
-	payload, err := streamer.Stream.EncodePayload()
+	spadeURL := streamer.GetStream().GetSpadeURL()
+	if spadeURL == "" {
+		return fmt.Errorf("%w: no spade URL", errSpadeReport)
+	}
+
+	payload, err := streamer.GetStream().EncodePayload()
 	if err != nil {
 		return fmt.Errorf("failed to encode payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", streamer.Stream.SpadeURL, strings.NewReader("data="+payload))
+	ctx := httpx.WithOperation(context.Background(), "spade:minute-watched")
+	req, err := http.NewRequestWithContext(ctx, "POST", spadeURL, strings.NewReader("data="+payload))
 	if err != nil {
 		return err
 	}
@@ -289,18 +655,84 @@ func (w *MinuteWatcher) sendMinuteWatched(streamer *models.Streamer) error {
 
 	resp, err := w.httpClient.Do(req)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", errSpadeReport, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		return fmt.Errorf("%w: unexpected status: %d", errSpadeReport, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// watchHLS requests the stream's video the way the HLS mode asks for. Only a
+// missing playback token fails the minute-watched report; failed video
+// requests are logged, with a warning once they keep failing.
+func (w *MinuteWatcher) watchHLS(username string, mode config.HLSMode) error {
+	token, err := w.playbackToken(username)
+	if err != nil {
+		return fmt.Errorf("failed to get playback token: %w", err)
 	}
 
+	err = w.simulateWatching(username, token.signature, token.value, mode)
+	if errors.Is(err, errPlaybackForbidden) {
+		w.tokens.invalidate(username)
+		if token, err = w.playbackToken(username); err == nil {
+			err = w.simulateWatching(username, token.signature, token.value, mode)
+		}
+	}
+	if err == nil {
+		w.hlsFailures = 0
+		return nil
+	}
+
+	slog.Debug("Failed to simulate watching", "streamer", username, "hlsMode", mode, "error", err)
+	w.hlsFailures++
+	if w.hlsFailures == hlsWarnFailures {
+		slog.Warn("Video requests keep failing, the network may block Twitch video; consider a lighter hlsMode",
+			"hlsMode", mode,
+			"failures", w.hlsFailures,
+			"error", err,
+		)
+	}
 	return nil
 }
 
-func (w *MinuteWatcher) simulateWatching(channel, sig, token string) error {
+// spadeFailed counts a failed minute-watched report and fetches the spade URL
+// again once spadeRefreshFailures reports in a row have failed.
+func (w *MinuteWatcher) spadeFailed(streamer *models.Streamer) {
+	username := streamer.GetUsername()
+	w.spadeFailures[username]++
+	if w.spadeFailures[username] < spadeRefreshFailures {
+		return
+	}
+	delete(w.spadeFailures, username)
+
+	if err := w.client.RefreshSpadeURL(streamer); err != nil {
+		slog.Warn("Failed to refresh spade URL", "streamer", username, "error", err)
+		return
+	}
+	slog.Info("Refreshed spade URL after failed minute-watched reports", "streamer", username, "failures", spadeRefreshFailures)
+}
+
+// playbackToken returns the cached playback access token for username, or
+// requests a new one if there is none or it is about to expire.
+func (w *MinuteWatcher) playbackToken(username string) (playbackToken, error) {
+	if token, ok := w.tokens.get(username); ok {
+		return token, nil
+	}
+
+	sig, value, err := w.client.GetPlaybackAccessToken(username)
+	if err != nil {
+		return playbackToken{}, err
+	}
+	return w.tokens.put(username, sig, value), nil
+}
+
+// simulateWatching fetches the channel's playlists and, in HLSModeFull, sends
+// a HEAD request for the newest segment of the lowest quality.
+func (w *MinuteWatcher) simulateWatching(channel, sig, token string, mode config.HLSMode) error {
 	playlistURL := fmt.Sprintf("%s/api/channel/hls/%s.m3u8", constants.UsherURL, channel)
 
 	params := url.Values{
@@ -314,6 +746,9 @@ func (w *MinuteWatcher) simulateWatching(channel, sig, token string) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusForbidden {
+		return errPlaybackForbidden
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("playlist request failed with status %d", resp.StatusCode)
 	}
@@ -352,6 +787,10 @@ func (w *MinuteWatcher) simulateWatching(channel, sig, token string) error {
 		return fmt.Errorf("failed to read stream list: %w", err)
 	}
 
+	if mode == config.HLSModePlaylist {
+		return nil
+	}
+
 	streamLines := strings.Split(string(streamListBody), "\n")
 	var segmentURL string
 	for i := len(streamLines) - 1; i >= 0; i-- {
@@ -0,0 +1,109 @@
+package watcher
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
+
+func onlineStreamer(username string, dropsOnly bool, campaignIDs ...string) *models.Streamer {
+	settings := models.DefaultStreamerSettings()
+	settings.ClaimDrops = true
+	settings.DropsOnly = dropsOnly
+	s := models.NewStreamer(username, settings)
+	s.SetOnline()
+	s.GetStream().SetCampaignIDs(campaignIDs)
+	return s
+}
+
+func TestSelectStreamersToWatchDropsOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		campaigns  []string
+		priorities []config.Priority
+		want       []string
+	}{
+		{
+			name:       "no campaign",
+			priorities: []config.Priority{config.PriorityOrder, config.PriorityDrops},
+			want:       []string{"points"},
+		},
+		{
+			name:       "no campaign with streak",
+			priorities: []config.Priority{config.PriorityStreak, config.PriorityOrder},
+			want:       []string{"points"},
+		},
+		{
+			name:       "active campaign",
+			campaigns:  []string{"campaign"},
+			priorities: []config.Priority{config.PriorityDrops, config.PriorityOrder},
+			want:       []string{"drops", "points"},
+		},
+		{
+			name:       "active campaign without drops priority",
+			campaigns:  []string{"campaign"},
+			priorities: []config.Priority{config.PriorityOrder},
+			want:       []string{"points"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamers := []*models.Streamer{
+				onlineStreamer("drops", true, tt.campaigns...),
+				onlineStreamer("points", false),
+			}
+			w := NewMinuteWatcher(nil, streamers, tt.priorities, config.RateLimitSettings{})
+
+			var got []string
+			for _, idx := range w.selectStreamersToWatch([]int{0, 1}) {
+				got = append(got, streamers[idx].GetUsername())
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selectStreamersToWatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectStreamersToWatchSpreadCampaigns(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []config.Priority
+		want       []string
+	}{
+		{
+			name:       "ranked for drops",
+			priorities: []config.Priority{config.PriorityDrops, config.PriorityOrder},
+			want:       []string{"first", "third"},
+		},
+		{
+			name:       "ranked by order",
+			priorities: []config.Priority{config.PriorityOrder},
+			want:       []string{"first", "second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamers := []*models.Streamer{
+				onlineStreamer("first", false, "campaign"),
+				onlineStreamer("second", false, "campaign"),
+				onlineStreamer("third", false),
+			}
+			w := NewMinuteWatcher(nil, streamers, tt.priorities, config.RateLimitSettings{})
+
+			var got []string
+			for _, idx := range w.selectStreamersToWatch([]int{0, 1, 2}) {
+				got = append(got, streamers[idx].GetUsername())
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selectStreamersToWatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,18 @@
 package web
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+	"github.com/PatrickWalther/twitch-miner-go/internal/chat"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 )
 
 func (s *Server) handleStreamers(w http.ResponseWriter, r *http.Request) {
@@ -44,11 +50,18 @@ func (s *Server) handleJSON(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var types []string
+	for _, t := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+
 	repo := s.analytics.Repository()
 	var data *analytics.StreamerData
 	var err error
-	if !startTime.IsZero() || !endTime.IsZero() {
-		data, err = repo.GetStreamerDataFiltered(streamer, startTime, endTime)
+	if !startTime.IsZero() || !endTime.IsZero() || len(types) > 0 {
+		data, err = repo.GetStreamerDataFiltered(streamer, startTime, endTime, types)
 	} else {
 		data, err = repo.GetStreamerData(streamer)
 	}
@@ -61,6 +74,62 @@ func (s *Server) handleJSON(w http.ResponseWriter, r *http.Request) {
 	writeJSONOK(w, data)
 }
 
+// handleAPICompare returns a streamer's points over startDate-endDate and over
+// a range of the same length starting at compareStartDate, both relative to
+// their range start so the chart can overlay them. Without compareStartDate
+// the range immediately before is used.
+func (s *Server) handleAPICompare(w http.ResponseWriter, r *http.Request) {
+	streamer := strings.TrimPrefix(r.URL.Path, "/api/compare/")
+	if streamer == "" {
+		writeBadRequest(w, "Streamer not specified")
+		return
+	}
+
+	query := r.URL.Query()
+	start, err := time.Parse("2006-01-02", query.Get("startDate"))
+	if err != nil {
+		writeBadRequest(w, "Invalid startDate")
+		return
+	}
+	end, err := time.Parse("2006-01-02", query.Get("endDate"))
+	if err != nil {
+		writeBadRequest(w, "Invalid endDate")
+		return
+	}
+	end = end.Add(24 * time.Hour)
+	if !end.After(start) {
+		writeBadRequest(w, "endDate is before startDate")
+		return
+	}
+	length := end.Sub(start)
+
+	compareStart := start.Add(-length)
+	if v := query.Get("compareStartDate"); v != "" {
+		compareStart, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			writeBadRequest(w, "Invalid compareStartDate")
+			return
+		}
+	}
+
+	repo := s.analytics.Repository()
+	current, err := repo.GetAlignedSeries(streamer, start, end.Add(-time.Second))
+	if err != nil {
+		writeInternalError(w, "Failed to get data")
+		return
+	}
+	previous, err := repo.GetAlignedSeries(streamer, compareStart, compareStart.Add(length-time.Second))
+	if err != nil {
+		writeInternalError(w, "Failed to get data")
+		return
+	}
+
+	writeJSONOK(w, struct {
+		Current  *analytics.AlignedSeries `json:"current"`
+		Previous *analytics.AlignedSeries `json:"previous"`
+	}{current, previous})
+}
+
 func (s *Server) handleJSONAll(w http.ResponseWriter, r *http.Request) {
 	repo := s.analytics.Repository()
 	streamers, err := repo.ListStreamers()
@@ -86,12 +155,95 @@ func (s *Server) handleJSONAll(w http.ResponseWriter, r *http.Request) {
 	writeJSONOK(w, result)
 }
 
+// handleAPIChatConfig returns (GET) or changes (PUT) which chats are logged
+// and their sample rates. Changes apply live and last until restart.
+func (s *Server) handleAPIChatConfig(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.chatConfigProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Chat not available")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var update chat.LogConfigUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeBadRequest(w, "Invalid JSON")
+			return
+		}
+		if err := provider.SetChatLogConfig(update); err != nil {
+			writeBadRequest(w, err.Error())
+			return
+		}
+	default:
+		writeNotAllowed(w)
+		return
+	}
+
+	configs, err := provider.GetChatLogConfig()
+	if err != nil {
+		writeServiceUnavailable(w, err.Error())
+		return
+	}
+	writeJSONOK(w, map[string]any{"channels": configs})
+}
+
+// handleAPIEventStyles returns the color and icon of every chart annotation
+// type with the built-in defaults, or updates them with PUT {"styles": [...]}.
+func (s *Server) handleAPIEventStyles(w http.ResponseWriter, r *http.Request) {
+	repo := s.analytics.Repository()
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req struct {
+			Styles []analytics.EventStyle `json:"styles"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "Invalid JSON")
+			return
+		}
+		if err := repo.SetEventStyles(req.Styles); err != nil {
+			if errors.Is(err, analytics.ErrInvalidEventStyle) {
+				writeBadRequest(w, err.Error())
+				return
+			}
+			writeInternalError(w, "Failed to save event styles")
+			return
+		}
+	default:
+		writeNotAllowed(w)
+		return
+	}
+
+	writeJSONOK(w, map[string]any{
+		"styles":   repo.GetEventStyles(),
+		"defaults": analytics.DefaultEventStyles(),
+	})
+}
+
 func (s *Server) handleAPIChatMessages(w http.ResponseWriter, r *http.Request) {
 	streamer := strings.TrimPrefix(r.URL.Path, "/api/chat/")
 	if streamer == "" {
 		writeBadRequest(w, "Streamer not specified")
 		return
 	}
+	if name, ok := strings.CutSuffix(streamer, "/emotes"); ok {
+		s.handleAPIChatEmotes(w, r, name)
+		return
+	}
+	if name, ok := strings.CutSuffix(streamer, "/stream"); ok {
+		s.handleAPIChatStream(w, r, name)
+		return
+	}
+	if name, ok := strings.CutSuffix(streamer, "/stats"); ok {
+		s.handleAPIChatStats(w, r, name)
+		return
+	}
 
 	limit := 50
 	offset := 0
@@ -127,5 +279,448 @@ func (s *Server) handleAPIChatMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for i := range data.Messages {
+		data.Messages[i].EmoteList = analytics.ParseEmotes(data.Messages[i].Emotes, data.Messages[i].Message)
+	}
+
 	writeJSONOK(w, data)
 }
+
+// ChatEmotesResponse lists the emotes the chat viewer can render for a streamer
+// in addition to the Twitch emotes parsed from each message.
+type ChatEmotesResponse struct {
+	TwitchCDN string                 `json:"twitch_cdn"`
+	Emotes    []chat.ThirdPartyEmote `json:"emotes"`
+}
+
+func (s *Server) handleAPIChatEmotes(w http.ResponseWriter, r *http.Request, streamer string) {
+	providers := chat.Providers
+	if p, ok := r.URL.Query()["providers"]; ok {
+		providers = nil
+		for _, name := range strings.Split(strings.Join(p, ","), ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if !slices.Contains(chat.Providers, name) {
+				writeBadRequest(w, "Unknown emote provider: "+name)
+				return
+			}
+			providers = append(providers, name)
+		}
+	}
+
+	channelID := ""
+	s.mu.RLock()
+	for _, st := range s.streamers {
+		if strings.EqualFold(st.GetUsername(), streamer) {
+			channelID = st.GetChannelID()
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	resp := ChatEmotesResponse{
+		TwitchCDN: chat.EmoteCDN,
+		Emotes:    []chat.ThirdPartyEmote{},
+	}
+	if len(providers) > 0 {
+		resp.Emotes = append(resp.Emotes, s.emotes.ThirdPartyEmotes(channelID, providers)...)
+	}
+
+	writeJSONOK(w, resp)
+}
+
+func (s *Server) handleAPIChatStats(w http.ResponseWriter, r *http.Request, streamer string) {
+	days := 30
+	if d := r.URL.Query().Get("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 {
+			days = parsed
+			if days > 365 {
+				days = 365
+			}
+		}
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	stats, err := s.analytics.Repository().GetChatStats(streamer, s.username, since)
+	if err != nil {
+		writeInternalError(w, "Failed to get chat stats")
+		return
+	}
+
+	writeJSONOK(w, stats)
+}
+
+// chatStreamKeepAlive is how often an idle live chat stream sends a comment so
+// proxies do not close it.
+const chatStreamKeepAlive = 30 * time.Second
+
+func (s *Server) handleAPIChatStream(w http.ResponseWriter, r *http.Request, streamer string) {
+	s.mu.RLock()
+	broker := s.chatBroker
+	s.mu.RUnlock()
+
+	if broker == nil {
+		writeServiceUnavailable(w, "Live chat not available")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeInternalError(w, "SSE not supported")
+		return
+	}
+
+	sub, err := broker.Subscribe(streamer)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	defer broker.Unsubscribe(streamer, sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(chatStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case msg, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if dropped := sub.TakeDropped(); dropped > 0 {
+				_, _ = fmt.Fprintf(w, "event: dropped\ndata: %d\n\n", dropped)
+			}
+			data, _ := json.Marshal(msg)
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// chartPollInterval is how often a chart stream checks for new points and
+// annotations.
+const chartPollInterval = 15 * time.Second
+
+// handleAPIChartStream serves GET /api/chart/{streamer}/stream?since={ms}, an
+// SSE stream of the points and annotations recorded after since. Each event
+// carries only the new entries, so the streamer page does not have to fetch
+// the whole range again to stay current.
+func (s *Server) handleAPIChartStream(w http.ResponseWriter, r *http.Request) {
+	streamer, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/chart/"), "/stream")
+	if !ok || streamer == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var since int64
+	if v := r.URL.Query().Get("since"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeBadRequest(w, "Invalid since")
+			return
+		}
+		since = parsed
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeInternalError(w, "SSE not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	poll := time.NewTicker(chartPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(chatStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	repo := s.analytics.Repository()
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-poll.C:
+			data, err := repo.GetStreamerDataFiltered(streamer, time.UnixMilli(since+1), time.Time{}, nil)
+			if err != nil || (len(data.Series) == 0 && len(data.Annotations) == 0) {
+				continue
+			}
+			for _, p := range data.Series {
+				since = max(since, p.X)
+			}
+			for _, a := range data.Annotations {
+				since = max(since, a.X)
+			}
+			payload, _ := json.Marshal(data)
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", payload)
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleAPIWatchSlots(w http.ResponseWriter, r *http.Request) {
+	hours := 24
+	if h := r.URL.Query().Get("hours"); h != "" {
+		if parsed, err := strconv.Atoi(h); err == nil && parsed > 0 {
+			hours = parsed
+			if hours > 24*7 {
+				hours = 24 * 7
+			}
+		}
+	}
+
+	startTime := time.Now().Add(-time.Duration(hours) * time.Hour)
+
+	repo := s.analytics.Repository()
+	ranges, err := repo.GetWatchSlotHistory(startTime, time.Time{})
+	if err != nil {
+		writeInternalError(w, "Failed to get watch slot history")
+		return
+	}
+
+	writeJSONOK(w, ranges)
+}
+
+func (s *Server) handleAPIRedemptions(w http.ResponseWriter, r *http.Request) {
+	streamer := strings.TrimPrefix(r.URL.Path, "/api/redemptions/")
+	if streamer == "" {
+		writeBadRequest(w, "Streamer not specified")
+		return
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+			if limit > 200 {
+				limit = 200
+			}
+		}
+	}
+
+	repo := s.analytics.Repository()
+	redemptions, err := repo.GetRedemptions(streamer, limit)
+	if err != nil {
+		writeInternalError(w, "Failed to get redemptions")
+		return
+	}
+
+	writeJSONOK(w, redemptions)
+}
+
+// handleAPIBetTiming returns how close to the lock the streamer's bets were
+// placed over the last days (default 30) and a suggestion for its bet delay.
+func (s *Server) handleAPIBetTiming(w http.ResponseWriter, r *http.Request) {
+	streamer := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/api/predictions/timing/"))
+	if streamer == "" {
+		writeBadRequest(w, "Streamer not specified")
+		return
+	}
+
+	days := 30
+	if d := r.URL.Query().Get("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 {
+			days = min(parsed, 365)
+		}
+	}
+
+	var bet models.BetSettings
+	found := false
+	s.mu.RLock()
+	for _, st := range s.streamers {
+		if strings.EqualFold(st.GetUsername(), streamer) {
+			bet = st.GetSettings().Bet
+			found = true
+			break
+		}
+	}
+	s.mu.RUnlock()
+	if !found {
+		writeError(w, http.StatusNotFound, "Streamer not found")
+		return
+	}
+
+	summary, err := s.analytics.Repository().GetBetTimingSummary(streamer, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		writeInternalError(w, "Failed to get bet timings")
+		return
+	}
+
+	writeJSONOK(w, struct {
+		*analytics.BetTimingSummary
+		DelayMode models.DelayMode          `json:"delay_mode"`
+		Delay     float64                   `json:"delay"`
+		Advice    analytics.BetTimingAdvice `json:"advice"`
+	}{summary, bet.DelayMode, bet.Delay, summary.Advise(bet)})
+}
+
+// handleAPIPredictionHistory returns the streamer's predictions of the last
+// days (default 30) with the bet settings each bet was calculated with, the
+// dataset for replaying bets against other settings.
+func (s *Server) handleAPIPredictionHistory(w http.ResponseWriter, r *http.Request) {
+	streamer := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/api/predictions/history/"))
+	if streamer == "" {
+		writeBadRequest(w, "Streamer not specified")
+		return
+	}
+
+	days := 30
+	if d := r.URL.Query().Get("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 {
+			days = min(parsed, 365)
+		}
+	}
+
+	records, err := s.analytics.Repository().GetPredictionHistory(streamer, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		writeInternalError(w, "Failed to get prediction history")
+		return
+	}
+
+	writeJSONOK(w, records)
+}
+
+const (
+	maxNoteLength  = 500
+	maxNoteLabels  = 10
+	maxLabelLength = 32
+)
+
+// handleAPIStreamerNote serves GET and PUT /api/notes/{streamer}, the note and
+// labels shown on a streamer's dashboard card.
+func (s *Server) handleAPIStreamerNote(w http.ResponseWriter, r *http.Request) {
+	streamer := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/api/notes/"))
+	if streamer == "" {
+		writeBadRequest(w, "Streamer not specified")
+		return
+	}
+
+	repo := s.analytics.Repository()
+
+	switch r.Method {
+	case http.MethodGet:
+		notes, err := repo.GetStreamerNotes()
+		if err != nil {
+			writeInternalError(w, "Failed to get note")
+			return
+		}
+		note := notes[streamer]
+		if note.Labels == nil {
+			note.Labels = []string{}
+		}
+		writeJSONOK(w, note)
+
+	case http.MethodPut:
+		var note analytics.StreamerNote
+		if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+			writeBadRequest(w, "Invalid JSON")
+			return
+		}
+
+		note.Note = strings.TrimSpace(note.Note)
+		if len([]rune(note.Note)) > maxNoteLength {
+			writeBadRequest(w, fmt.Sprintf("Note is longer than %d characters", maxNoteLength))
+			return
+		}
+
+		labels, err := normalizeLabels(note.Labels)
+		if err != nil {
+			writeBadRequest(w, err.Error())
+			return
+		}
+		note.Labels = labels
+
+		if err := repo.SetStreamerNote(streamer, note); err != nil {
+			writeInternalError(w, "Failed to save note")
+			return
+		}
+		writeSuccess(w)
+
+	default:
+		writeNotAllowed(w)
+	}
+}
+
+// maxAnnotationLength keeps manual annotations short enough to fit as a chart
+// label.
+const maxAnnotationLength = 100
+
+func (s *Server) handleAPIAnnotations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	streamer := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/api/annotations/"))
+	if streamer == "" {
+		writeBadRequest(w, "Streamer not specified")
+		return
+	}
+
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid JSON")
+		return
+	}
+
+	text := strings.Join(strings.Fields(req.Text), " ")
+	if text == "" {
+		writeBadRequest(w, "Annotation text is required")
+		return
+	}
+	if len([]rune(text)) > maxAnnotationLength {
+		writeBadRequest(w, fmt.Sprintf("Annotation is longer than %d characters", maxAnnotationLength))
+		return
+	}
+
+	if err := s.analytics.RecordNote(streamer, text); err != nil {
+		writeInternalError(w, "Failed to save annotation")
+		return
+	}
+	writeSuccess(w)
+}
+
+// normalizeLabels trims labels and drops empty and duplicate ones, comparing
+// case-insensitively.
+func normalizeLabels(labels []string) ([]string, error) {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, label := range labels {
+		label = strings.Join(strings.Fields(label), " ")
+		if label == "" || seen[strings.ToLower(label)] {
+			continue
+		}
+		if len([]rune(label)) > maxLabelLength {
+			return nil, fmt.Errorf("label %q is longer than %d characters", label, maxLabelLength)
+		}
+		seen[strings.ToLower(label)] = true
+		result = append(result, label)
+	}
+	if len(result) > maxNoteLabels {
+		return nil, fmt.Errorf("at most %d labels are allowed", maxNoteLabels)
+	}
+	return result, nil
+}
@@ -1,13 +1,22 @@
 package web
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+	"github.com/PatrickWalther/twitch-miner-go/internal/api"
+	"github.com/PatrickWalther/twitch-miner-go/internal/constants"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+	"github.com/PatrickWalther/twitch-miner-go/internal/settings"
 	"github.com/PatrickWalther/twitch-miner-go/internal/util"
 	"github.com/PatrickWalther/twitch-miner-go/internal/version"
 )
@@ -26,34 +35,16 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	totalPoints := 0
-	pointsToday := 0
-	todayStart := time.Now().Truncate(24 * time.Hour)
-
-	for _, info := range streamers {
-		totalPoints += info.Points
-
-		data, err := repo.GetStreamerData(info.Name)
-		if err != nil {
-			continue
-		}
-		todayStartMS := todayStart.UnixMilli()
-		for i := len(data.Series) - 1; i >= 0; i-- {
-			if data.Series[i].X < todayStartMS {
-				if i+1 < len(data.Series) {
-					pointsToday += info.Points - data.Series[i+1].Y
-				}
-				break
-			}
-			if i == 0 && len(data.Series) > 0 {
-				pointsToday += info.Points - data.Series[0].Y
-			}
-		}
+	totalPoints, pointsToday, err := repo.GetPointsTotals(time.Now().Truncate(24 * time.Hour))
+	if err != nil {
+		slog.Error("Failed to get points totals", "error", err)
 	}
 
 	s.mu.RLock()
 	refresh := s.refresh
 	discordEnabled := s.discordEnabled
+	uptimeProvider := s.uptimeProvider
+	databaseProvider := s.databaseProvider
 	s.mu.RUnlock()
 
 	data := DashboardData{
@@ -66,9 +57,57 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		DiscordEnabled: discordEnabled,
 	}
 
+	if uptimeProvider != nil {
+		summary, err := uptimeProvider.GetUptime()
+		if err != nil {
+			slog.Error("Failed to get uptime", "error", err)
+		} else {
+			data.Uptime = &UptimeData{
+				Uptime:       util.FormatDuration(time.Duration(summary.UptimeSeconds) * time.Second),
+				Restarts7d:   summary.Restarts7d,
+				LastShutdown: "Never",
+			}
+			if summary.LastShutdownAt != nil {
+				data.Uptime.LastShutdown = util.FormatTimeAgo(summary.LastShutdownAt.UnixMilli())
+				data.Uptime.LastShutdownReason = summary.LastShutdownReason
+			}
+		}
+	}
+
+	if databaseProvider != nil {
+		if status := databaseProvider.GetDatabaseStatus(); status.InMemory {
+			data.Database = &status
+		}
+	}
+
 	s.renderPage(w, "dashboard.html", data)
 }
 
+// handleAvatar serves GET /static/avatars/{name}, a streamer's profile image
+// from the local cache.
+func (s *Server) handleAvatar(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	cache := s.avatars
+	s.mu.RUnlock()
+
+	if cache == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	name := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/static/avatars/"))
+	avatar, err := cache.Get(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", avatar.ContentType)
+	w.Header().Set("ETag", avatar.ETag)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	http.ServeContent(w, r, name, avatar.ModTime, bytes.NewReader(avatar.Data))
+}
+
 func (s *Server) handleStreamerPage(w http.ResponseWriter, r *http.Request) {
 	name := strings.TrimPrefix(r.URL.Path, "/streamer/")
 	if name == "" {
@@ -120,12 +159,48 @@ func (s *Server) handleStreamerPage(w http.ResponseWriter, r *http.Request) {
 		DataPoints:     len(data.Series),
 		DaysAgo:        daysAgo,
 		DiscordEnabled: discordEnabled,
+		Channel:        s.channelInfo(name),
 	}
 
 	s.renderPage(w, "streamer.html", pageData)
 }
 
+// channelInfo returns the channel metadata of a tracked streamer, or nil if
+// it is not tracked or its metadata was never fetched.
+func (s *Server) channelInfo(name string) *ChannelInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, st := range s.streamers {
+		if !strings.EqualFold(st.GetUsername(), name) {
+			continue
+		}
+		md := st.GetMetadata()
+		if md.FetchedAt.IsZero() {
+			return nil
+		}
+		info := &ChannelInfo{
+			Partner:   md.Partner,
+			Affiliate: md.Affiliate,
+			Followers: util.FormatNumber(md.Followers),
+		}
+		if !md.CreatedAt.IsZero() {
+			info.Created = md.CreatedAt.Format("2006-01-02")
+		}
+		if !md.Partner && !md.Affiliate {
+			info.Warning = st.NonEarningReason()
+		}
+		return info
+	}
+	return nil
+}
+
 func (s *Server) handleAPIStreamers(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		s.handleAPIAddStreamer(w, r)
+		return
+	}
+
 	repo := s.analytics.Repository()
 	repoStreamers, err := repo.ListStreamers()
 	if err != nil {
@@ -135,20 +210,117 @@ func (s *Server) handleAPIStreamers(w http.ResponseWriter, r *http.Request) {
 
 	streamers := convertStreamerInfoList(repoStreamers)
 
+	notes, err := repo.GetStreamerNotes()
+	if err != nil {
+		slog.Warn("Failed to load streamer notes", "error", err)
+	}
+	efficiency, err := repo.GetEfficiency(time.Now().Add(-efficiencyWindow))
+	if err != nil {
+		slog.Warn("Failed to load streamer efficiency", "error", err)
+	}
+	ranks := rankEfficiency(efficiency)
+	gainedToday, err := repo.GetPointsGained(time.Now().Truncate(24 * time.Hour))
+	if err != nil {
+		slog.Warn("Failed to load points gained today", "error", err)
+	}
+	labelFilter := strings.TrimSpace(r.URL.Query().Get("label"))
+	labelSet := make(map[string]string)
+	filtered := streamers[:0]
+	for _, info := range streamers {
+		if note, ok := notes[info.Name]; ok {
+			info.Note = note.Note
+			info.Labels = note.Labels
+		}
+		if gained := gainedToday[info.Name]; gained != 0 {
+			info.PointsToday = gained
+			info.PointsTodayFormatted = util.FormatNumber(gained)
+			if gained > 0 {
+				info.PointsTodayFormatted = "+" + info.PointsTodayFormatted
+			}
+		}
+		if rank, ok := ranks[info.Name]; ok {
+			eff := efficiency[info.Name]
+			info.EfficiencyRank = rank
+			info.Efficiency = eff.PointsPerHour
+			info.EfficiencyFormatted = util.FormatNumber(int(eff.PointsPerHour + 0.5))
+			if eff.AvgViewers > 0 {
+				info.AvgViewers = util.FormatNumber(eff.AvgViewers)
+			}
+		}
+		matches := labelFilter == ""
+		for _, label := range info.Labels {
+			labelSet[strings.ToLower(label)] = label
+			if strings.EqualFold(label, labelFilter) {
+				matches = true
+			}
+		}
+		if matches {
+			filtered = append(filtered, info)
+		}
+	}
+	streamers = filtered
+
+	labels := make([]string, 0, len(labelSet))
+	for _, label := range labelSet {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return strings.ToLower(labels[i]) < strings.ToLower(labels[j])
+	})
+
 	streamerMap := make(map[string]*models.Streamer)
 	configOrder := make(map[string]int)
 	for i, st := range s.streamers {
-		streamerMap[st.Username] = st
-		configOrder[st.Username] = i
+		streamerMap[st.GetUsername()] = st
+		configOrder[st.GetUsername()] = i
+	}
+
+	s.mu.RLock()
+	actions := s.streamerActionProvider
+	warnUnfollowed := s.warnUnfollowed
+	efficiencyAlert := s.efficiencyAlert
+	s.mu.RUnlock()
+
+	pinned := make(map[string]bool)
+	if actions != nil {
+		for _, name := range actions.GetPinnedStreamers() {
+			pinned[name] = true
+		}
 	}
 
 	var trackedLive, trackedOffline, untracked []StreamerInfo
 
 	for i := range streamers {
 		if st, ok := streamerMap[streamers[i].Name]; ok {
+			streamers[i].Tracked = actions != nil
+			streamers[i].Enabled = st.GetEnabled()
+			streamers[i].Pinned = pinned[streamers[i].Name]
+			if follow := st.GetFollow(); follow.Known {
+				streamers[i].FollowKnown = true
+				streamers[i].Following = follow.Following
+				if follow.Following && !follow.FollowedAt.IsZero() {
+					streamers[i].FollowAge = util.FormatDuration(time.Since(follow.FollowedAt))
+				}
+				streamers[i].WarnUnfollowed = warnUnfollowed && !follow.Following
+			}
+			streamers[i].NonEarning = st.NonEarningReason()
+			streamers[i].DropsOnly = st.GetSettings().DropsOnly
+			streamers[i].AtPointsCap = st.PointsCapReached()
+			if until := st.GetTemporaryUntil(); !until.IsZero() {
+				streamers[i].TemporaryUntil = until.UnixMilli()
+			}
+			streamers[i].Discovered = st.GetDiscovered()
+			hb := st.GetHeartbeats()
+			streamers[i].Heartbeat = heartbeatHealth(hb)
+			streamers[i].HeartbeatOK = hb.OK
+			streamers[i].HeartbeatTotal = hb.OK + hb.Failed
+			streamers[i].HeartbeatError = hb.LastError
+			streamers[i].LowEfficiency = efficiencyAlert > 0 && streamers[i].EfficiencyRank > 0 &&
+				streamers[i].Efficiency < float64(efficiencyAlert)
 			streamers[i].IsLive = st.GetIsOnline()
 			if streamers[i].IsLive {
 				streamers[i].LiveDuration = util.FormatDuration(time.Since(st.GetOnlineAt()))
+				streamers[i].LiveSince = st.GetOnlineAt().UnixMilli()
 				trackedLive = append(trackedLive, streamers[i])
 			} else {
 				offlineAt := st.GetOfflineAt()
@@ -162,20 +334,48 @@ func (s *Server) handleAPIStreamers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	sort.Slice(trackedLive, func(i, j int) bool {
-		return configOrder[trackedLive[i].Name] < configOrder[trackedLive[j].Name]
-	})
-	sort.Slice(trackedOffline, func(i, j int) bool {
-		return configOrder[trackedOffline[i].Name] < configOrder[trackedOffline[j].Name]
-	})
-	sort.Slice(untracked, func(i, j int) bool {
-		return untracked[i].Name < untracked[j].Name
-	})
+	state := r.URL.Query().Get("state")
+	switch state {
+	case "live":
+		trackedOffline, untracked = nil, nil
+	case "offline":
+		trackedLive = nil
+	default:
+		state = ""
+	}
+
+	sortBy := r.URL.Query().Get("sort")
+	order := gridOrder(sortBy)
+	if order == nil {
+		sortBy = ""
+	}
+	sorted := func(list []StreamerInfo, fallback func(a, b StreamerInfo) bool) func(i, j int) bool {
+		return func(i, j int) bool {
+			a, b := list[i], list[j]
+			if order != nil {
+				if c := order(a, b); c != 0 {
+					return c < 0
+				}
+			}
+			return fallback(a, b)
+		}
+	}
+	byConfig := func(a, b StreamerInfo) bool { return configOrder[a.Name] < configOrder[b.Name] }
+
+	sort.Slice(trackedLive, sorted(trackedLive, byConfig))
+	sort.Slice(trackedOffline, sorted(trackedOffline, byConfig))
+	sort.Slice(untracked, sorted(untracked, func(a, b StreamerInfo) bool {
+		return a.Name < b.Name
+	}))
 
 	gridData := StreamerGridData{
 		TrackedLive:    trackedLive,
 		TrackedOffline: trackedOffline,
 		Untracked:      untracked,
+		Labels:         labels,
+		Label:          labelFilter,
+		Sort:           sortBy,
+		State:          state,
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -189,3 +389,644 @@ func (s *Server) handleAPIStreamers(w http.ResponseWriter, r *http.Request) {
 		writeInternalError(w, "Failed to render")
 	}
 }
+
+// gridOrder returns the comparison for the streamer grid's sort parameter, or
+// nil for config order. Streamers without a value, such as unranked or
+// offline ones, go last.
+func gridOrder(sortBy string) func(a, b StreamerInfo) int {
+	switch sortBy {
+	case "points":
+		return func(a, b StreamerInfo) int { return cmp.Compare(b.Points, a.Points) }
+	case "today":
+		return func(a, b StreamerInfo) int { return cmp.Compare(b.PointsToday, a.PointsToday) }
+	case "activity":
+		return func(a, b StreamerInfo) int { return cmp.Compare(b.LastActivity, a.LastActivity) }
+	case "live":
+		return func(a, b StreamerInfo) int { return compareMissingLast(a.LiveSince, b.LiveSince) }
+	case "efficiency":
+		return func(a, b StreamerInfo) int {
+			return compareMissingLast(int64(a.EfficiencyRank), int64(b.EfficiencyRank))
+		}
+	}
+	return nil
+}
+
+// compareMissingLast orders ascending with zero values after all others.
+func compareMissingLast(a, b int64) int {
+	if a == 0 || b == 0 {
+		return cmp.Compare(b, a)
+	}
+	return cmp.Compare(a, b)
+}
+
+const (
+	// efficiencyWindow is the period the dashboard's efficiency score covers.
+	efficiencyWindow = 30 * 24 * time.Hour
+
+	// minEfficiencyMinutes is how long a streamer must have been watched in the
+	// window before it gets a score; a few minutes would be mostly noise.
+	minEfficiencyMinutes = 60
+)
+
+// rankEfficiency ranks the streamers watched for at least minEfficiencyMinutes
+// by points per watched hour, starting at 1 for the best.
+func rankEfficiency(efficiency map[string]analytics.Efficiency) map[string]int {
+	var names []string
+	for name, eff := range efficiency {
+		if eff.WatchedMinutes >= minEfficiencyMinutes {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := efficiency[names[i]].PointsPerHour, efficiency[names[j]].PointsPerHour
+		if a != b {
+			return a > b
+		}
+		return names[i] < names[j]
+	})
+
+	ranks := make(map[string]int, len(names))
+	for i, name := range names {
+		ranks[name] = i + 1
+	}
+	return ranks
+}
+
+// handleAPIStreamerAction serves POST /api/streamers/{name}/{action} for the
+// quick actions on dashboard streamer cards.
+func (s *Server) handleAPIStreamerAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		s.handleAPIRemoveStreamer(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	name, action, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/streamers/"), "/")
+	if !ok || name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.streamerActionProvider
+	tracked := false
+	for _, st := range s.streamers {
+		if strings.EqualFold(st.GetUsername(), name) {
+			tracked = true
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Streamer actions not available")
+		return
+	}
+	if !tracked {
+		writeError(w, http.StatusNotFound, "Streamer not tracked")
+		return
+	}
+
+	switch action {
+	case "recheck":
+		if err := provider.RecheckStreamer(name); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+	case "claim-bonus":
+		if err := provider.ClaimStreamerBonus(name); err != nil {
+			writeError(w, http.StatusBadGateway, "Failed to claim bonus: "+err.Error())
+			return
+		}
+	case "pin":
+		var req struct {
+			Pinned bool `json:"pinned"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "Invalid JSON: "+err.Error())
+			return
+		}
+		if err := provider.SetStreamerPinned(name, req.Pinned); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+	case "enabled":
+		var req struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "Invalid JSON: "+err.Error())
+			return
+		}
+		if err := provider.SetStreamerEnabled(name, req.Enabled); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+	case "reset-earning":
+		if err := provider.ResetStreamerEarning(name); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	writeSuccess(w)
+}
+
+// maxTemporaryHours caps how long a streamer can be added temporarily.
+const maxTemporaryHours = 168
+
+// handleAPIAddTemporaryStreamer serves POST /api/streamers/temporary, which
+// mines a streamer for a limited number of hours without changing the config.
+// handleAPIAddStreamer adds a streamer to the config with the default
+// settings. The name may also be an @name or channel URL.
+func (s *Server) handleAPIAddStreamer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid JSON: "+err.Error())
+		return
+	}
+	name, ok := settings.ParseLogin(req.Name)
+	if !ok {
+		writeBadRequest(w, "Invalid streamer name")
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.streamerActionProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Streamer actions not available")
+		return
+	}
+
+	if err := provider.AddStreamer(name); err != nil {
+		if errors.Is(err, api.ErrStreamerDoesNotExist) {
+			writeError(w, http.StatusNotFound, "Streamer not found on Twitch")
+			return
+		}
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":   "ok",
+		"streamer": name,
+	})
+}
+
+// handleAPIRemoveStreamer removes a streamer from the config. Its analytics
+// stay, so it is listed as untracked afterwards.
+func (s *Server) handleAPIRemoveStreamer(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/streamers/")
+	if name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.streamerActionProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Streamer actions not available")
+		return
+	}
+
+	if err := provider.RemoveStreamer(name); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeSuccess(w)
+}
+
+func (s *Server) handleAPIAddTemporaryStreamer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	var req struct {
+		Name  string  `json:"name"`
+		Hours float64 `json:"hours"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid JSON: "+err.Error())
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeBadRequest(w, "Streamer name is required")
+		return
+	}
+	if req.Hours <= 0 || req.Hours > maxTemporaryHours {
+		writeBadRequest(w, fmt.Sprintf("Hours must be between 0 and %d", maxTemporaryHours))
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.streamerActionProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Streamer actions not available")
+		return
+	}
+
+	until, err := provider.AddTemporaryStreamer(req.Name, time.Duration(req.Hours*float64(time.Hour)))
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	writeJSONOK(w, map[string]interface{}{
+		"status":          "ok",
+		"streamer":        strings.ToLower(req.Name),
+		"temporary_until": until.UnixMilli(),
+	})
+}
+
+// heartbeatDays is how many days of daily heartbeat totals /api/heartbeats returns.
+const heartbeatDays = 7
+
+// HeartbeatStatus is a streamer's minute-watched report and bonus claim
+// counts: this session's and the persisted daily totals.
+type HeartbeatStatus struct {
+	Streamer          string                   `json:"streamer"`
+	OK                int                      `json:"ok"`
+	Failed            int                      `json:"failed"`
+	LastOK            int64                    `json:"last_ok,omitempty"`
+	LastFailure       int64                    `json:"last_failure,omitempty"`
+	LastError         string                   `json:"last_error,omitempty"`
+	Claims            int                      `json:"claims"`
+	MinutesSinceClaim int                      `json:"minutes_since_claim"`
+	Days              []analytics.HeartbeatDay `json:"days"`
+}
+
+func (s *Server) handleAPIHeartbeats(w http.ResponseWriter, r *http.Request) {
+	totals, err := s.analytics.Repository().GetHeartbeatTotals(time.Now().AddDate(0, 0, -(heartbeatDays - 1)))
+	if err != nil {
+		writeInternalError(w, "Failed to get heartbeat totals")
+		return
+	}
+
+	s.mu.RLock()
+	streamers := s.streamers
+	s.mu.RUnlock()
+
+	result := make([]HeartbeatStatus, 0, len(streamers))
+	for _, st := range streamers {
+		hb := st.GetHeartbeats()
+		stats := st.GetSessionStats()
+		status := HeartbeatStatus{
+			Streamer:          st.GetUsername(),
+			OK:                hb.OK,
+			Failed:            hb.Failed,
+			LastError:         hb.LastError,
+			Claims:            stats.BonusClaims,
+			MinutesSinceClaim: stats.MinutesSinceClaim,
+			Days:              totals[st.GetUsername()],
+		}
+		if !hb.LastOK.IsZero() {
+			status.LastOK = hb.LastOK.UnixMilli()
+		}
+		if !hb.LastFailure.IsZero() {
+			status.LastFailure = hb.LastFailure.UnixMilli()
+		}
+		if status.Days == nil {
+			status.Days = []analytics.HeartbeatDay{}
+		}
+		result = append(result, status)
+	}
+
+	writeJSONOK(w, result)
+}
+
+// heartbeatHealth summarizes a session's minute-watched reports for a card:
+// "fail" if the latest one failed, "warn" if more than a tenth failed, "ok"
+// otherwise and "" if none were sent yet.
+func heartbeatHealth(hb models.Heartbeats) string {
+	switch {
+	case hb.OK+hb.Failed == 0:
+		return ""
+	case hb.LastFailure.After(hb.LastOK):
+		return "fail"
+	case hb.Failed*10 > hb.OK+hb.Failed:
+		return "warn"
+	default:
+		return "ok"
+	}
+}
+
+func (s *Server) handleAPIOverview(w http.ResponseWriter, r *http.Request) {
+	repo := s.analytics.Repository()
+	totalPoints, pointsToday, err := repo.GetPointsTotals(time.Now().Truncate(24 * time.Hour))
+	if err != nil {
+		writeInternalError(w, "Failed to get points totals")
+		return
+	}
+
+	overview := OverviewData{
+		TotalPoints:   util.FormatNumber(totalPoints),
+		PointsToday:   util.FormatNumber(pointsToday),
+		PointsGained:  pointsToday >= 0,
+		MaxWatchSlots: constants.MaxSimultaneousStreams,
+	}
+
+	s.mu.RLock()
+	provider := s.overviewProvider
+	s.mu.RUnlock()
+
+	if provider != nil {
+		overview.Watching = provider.GetWatchedStreamers()
+		overview.WatchSlots = len(overview.Watching)
+		if drop, ok := provider.GetNextDrop(); ok {
+			overview.NextDrop = drop.Benefit
+			if overview.NextDrop == "" {
+				overview.NextDrop = drop.Name
+			}
+			overview.NextDropETA = util.FormatDuration(time.Duration(drop.RemainingMinutes()) * time.Minute)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := s.templates["partials"]
+	if tmpl == nil {
+		writeInternalError(w, "Partials not loaded")
+		return
+	}
+	if err := tmpl.ExecuteTemplate(w, "overview", overview); err != nil {
+		slog.Error("Failed to render overview", "error", err)
+		writeInternalError(w, "Failed to render")
+	}
+}
+
+func (s *Server) handleAPIActivePredictions(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.predictionProvider
+	s.mu.RUnlock()
+
+	predictions := []ActivePrediction{}
+	if provider != nil {
+		for _, p := range provider.GetActivePredictions() {
+			predictions = append(predictions, convertPrediction(p))
+		}
+	}
+
+	writeJSONOK(w, predictions)
+}
+
+// handleAPIBetApproval serves POST /api/predictions/approval, which approves
+// or rejects a large bet waiting for approval.
+func (s *Server) handleAPIBetApproval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	var req struct {
+		EventID string `json:"eventId"`
+		Approve bool   `json:"approve"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid JSON: "+err.Error())
+		return
+	}
+	if req.EventID == "" {
+		writeBadRequest(w, "eventId is required")
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.predictionProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Predictions not available")
+		return
+	}
+
+	if err := provider.ResolveBetApproval(req.EventID, req.Approve); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeSuccess(w)
+}
+
+// PublishPrediction pushes a newly opened prediction to the dashboards
+// following /api/predictions/stream.
+func (s *Server) PublishPrediction(p models.PredictionSnapshot) {
+	s.predictionFeed.publish(convertPrediction(p))
+}
+
+// handleAPIPredictionStream serves GET /api/predictions/stream, an SSE stream
+// with one event per prediction that opens, carrying its bet time so the
+// dashboard can count down and offer to skip or force the bet.
+func (s *Server) handleAPIPredictionStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeInternalError(w, "SSE not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ch := s.predictionFeed.subscribe()
+	defer s.predictionFeed.unsubscribe(ch)
+
+	keepAlive := time.NewTicker(chatStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case prediction, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(prediction)
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// handleAPIPredictionSkip serves POST /api/predictions/skip, which cancels
+// the scheduled bet on a prediction.
+func (s *Server) handleAPIPredictionSkip(w http.ResponseWriter, r *http.Request) {
+	s.overridePrediction(w, r, models.OverrideSkip)
+}
+
+// handleAPIPredictionForce serves POST /api/predictions/force, which places
+// the scheduled bet on a prediction right away.
+func (s *Server) handleAPIPredictionForce(w http.ResponseWriter, r *http.Request) {
+	s.overridePrediction(w, r, models.OverrideForce)
+}
+
+func (s *Server) overridePrediction(w http.ResponseWriter, r *http.Request, override models.BetOverride) {
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	var req struct {
+		EventID string `json:"eventId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid JSON: "+err.Error())
+		return
+	}
+	if req.EventID == "" {
+		writeBadRequest(w, "eventId is required")
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.predictionProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Predictions not available")
+		return
+	}
+
+	if err := provider.OverridePrediction(req.EventID, override); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeSuccess(w)
+}
+
+// handleAPIManualBet serves POST /api/predictions/{eventID}/bet, which
+// replaces the scheduled bet on a prediction with the given outcome and
+// amount and places it right away.
+func (s *Server) handleAPIManualBet(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/predictions/"), "/bet")
+	if !ok || eventID == "" || strings.Contains(eventID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	var req struct {
+		OutcomeID string `json:"outcomeID"`
+		Amount    int    `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid JSON: "+err.Error())
+		return
+	}
+	if req.OutcomeID == "" {
+		writeBadRequest(w, "outcomeID is required")
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.predictionProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Predictions not available")
+		return
+	}
+
+	if err := provider.PlaceManualBet(eventID, req.OutcomeID, req.Amount); err != nil {
+		if errors.Is(err, models.ErrInvalidBet) {
+			writeBadRequest(w, err.Error())
+			return
+		}
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeSuccess(w)
+}
+
+func (s *Server) handleInventoryPage(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	refresh := s.refresh
+	discordEnabled := s.discordEnabled
+	provider := s.inventoryProvider
+	s.mu.RUnlock()
+
+	data := InventoryPageData{
+		Username:       s.username,
+		RefreshMinutes: refresh,
+		Version:        version.Version,
+		DiscordEnabled: discordEnabled,
+	}
+
+	if provider != nil {
+		if inv := provider.GetInventory(); inv != nil {
+			data.Loaded = true
+			data.UpdatedAgo = util.FormatTimeAgo(inv.UpdatedAt.UnixMilli())
+			data.InProgress = inv.InProgress
+			data.Drops = inv.Drops
+			data.Rewards = inv.Rewards
+		}
+	}
+
+	s.renderPage(w, "inventory.html", data)
+}
+
+func (s *Server) handleDropsPage(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	refresh := s.refresh
+	discordEnabled := s.discordEnabled
+	provider := s.dropsProvider
+	s.mu.RUnlock()
+
+	data := DropsPageData{
+		Username:       s.username,
+		RefreshMinutes: refresh,
+		Version:        version.Version,
+		DiscordEnabled: discordEnabled,
+	}
+
+	if provider != nil {
+		if progress, syncedAt := provider.GetDropCampaigns(); !syncedAt.IsZero() {
+			data.Loaded = true
+			data.UpdatedAgo = util.FormatTimeAgo(syncedAt.UnixMilli())
+			data.Campaigns = convertDropCampaigns(progress, syncedAt, time.Now())
+		}
+	}
+
+	s.renderPage(w, "drops.html", data)
+}
+
+// handleAPIDrops serves GET /api/drops, the progress on active drop campaigns
+// as of the last campaign sync.
+func (s *Server) handleAPIDrops(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.dropsProvider
+	s.mu.RUnlock()
+
+	resp := DropsResponse{Campaigns: []DropCampaign{}}
+	if provider != nil {
+		if progress, syncedAt := provider.GetDropCampaigns(); !syncedAt.IsZero() {
+			resp.UpdatedAt = syncedAt.UnixMilli()
+			resp.Campaigns = convertDropCampaigns(progress, syncedAt, time.Now())
+		}
+	}
+
+	writeJSONOK(w, resp)
+}
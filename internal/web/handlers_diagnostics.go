@@ -0,0 +1,196 @@
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/api"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
+	"github.com/PatrickWalther/twitch-miner-go/internal/logger"
+	"github.com/PatrickWalther/twitch-miner-go/internal/version"
+)
+
+// handleAPIDiagnosticsBundle returns a zip to attach to bug reports: build
+// info, the redacted config, recent logs, database schema versions, component
+// status and request stats. Parts that are unavailable are replaced by a note
+// instead of failing the whole bundle.
+func (s *Server) handleAPIDiagnosticsBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeNotAllowed(w)
+		return
+	}
+
+	s.mu.RLock()
+	configProvider := s.configProvider
+	diagnostics := s.diagnosticsProvider
+	s.mu.RUnlock()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	now := time.Now()
+
+	addJSON := func(name string, v any) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			data = []byte(fmt.Sprintf("unavailable: %v", err))
+		}
+		addBundleFile(zw, name, now, data)
+	}
+
+	addJSON("build.json", version.Info())
+
+	if configProvider != nil {
+		addJSON("config.redacted.json", configProvider.GetConfig().Redacted())
+	} else {
+		addBundleFile(zw, "config.redacted.json", now, []byte("unavailable: config not loaded"))
+	}
+
+	addBundleFile(zw, "logs.txt", now, []byte(strings.Join(logger.Recent(), "\n")+"\n"))
+
+	if diagnostics != nil {
+		if versions, err := diagnostics.GetSchemaVersions(); err != nil {
+			addBundleFile(zw, "schema.json", now, []byte(fmt.Sprintf("unavailable: %v", err)))
+		} else {
+			addJSON("schema.json", versions)
+		}
+		addJSON("status.json", diagnostics.GetDiagnosticsStatus())
+	} else {
+		addBundleFile(zw, "schema.json", now, []byte("unavailable: miner not started"))
+		addBundleFile(zw, "status.json", now, []byte("unavailable: miner not started"))
+	}
+
+	addJSON("requests.json", httpx.Stats())
+	addJSON("budget.json", httpx.Budget())
+
+	if err := zw.Close(); err != nil {
+		slog.Error("Failed to write diagnostics bundle", "error", err)
+		writeInternalError(w, "Failed to write diagnostics bundle")
+		return
+	}
+
+	filename := fmt.Sprintf("twitch-miner-diagnostics-%s.zip", now.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// addBundleFile adds a file to the bundle. The archive is written to memory,
+// so writes cannot fail.
+func addBundleFile(zw *zip.Writer, name string, modified time.Time, data []byte) {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return
+	}
+	_, _ = f.Write(data)
+}
+
+// isAdminRequest reports whether r may use the debug tools. With dashboard
+// credentials set, the auth middleware has already checked them; otherwise
+// only requests from the local machine are allowed.
+func (s *Server) isAdminRequest(r *http.Request) bool {
+	if authEnabled() {
+		return true
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		// Connections over the Unix socket have no address.
+		return s.socket != ""
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) handleDebugGQLPage(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	refresh := s.refresh
+	discordEnabled := s.discordEnabled
+	provider := s.debugProvider
+	streamers := make([]string, 0, len(s.streamers))
+	for _, st := range s.streamers {
+		streamers = append(streamers, st.GetUsername())
+	}
+	s.mu.RUnlock()
+	sort.Strings(streamers)
+
+	data := DebugPageData{
+		Username:       s.username,
+		RefreshMinutes: refresh,
+		Version:        version.Version,
+		DiscordEnabled: discordEnabled,
+		Allowed:        s.isAdminRequest(r),
+		Streamers:      streamers,
+	}
+	if provider != nil {
+		data.Operations = provider.GetDebugOperations()
+	}
+
+	s.renderPage(w, "debug.html", data)
+}
+
+// handleAPIDebugGQL lists the debug GQL operations on GET and runs one for a
+// tracked streamer on POST, returning Twitch's raw response.
+func (s *Server) handleAPIDebugGQL(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdminRequest(r) {
+		writeError(w, http.StatusForbidden, "Debug tools require dashboard credentials or a local connection")
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.debugProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Debug tools not available")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSONOK(w, map[string]any{"operations": provider.GetDebugOperations()})
+	case http.MethodPost:
+		var req struct {
+			Operation string `json:"operation"`
+			Streamer  string `json:"streamer"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "Invalid JSON: "+err.Error())
+			return
+		}
+
+		s.mu.RLock()
+		tracked := false
+		for _, st := range s.streamers {
+			if strings.EqualFold(st.GetUsername(), req.Streamer) {
+				tracked = true
+				break
+			}
+		}
+		s.mu.RUnlock()
+
+		if !tracked {
+			writeError(w, http.StatusNotFound, "Streamer not tracked")
+			return
+		}
+
+		result, err := provider.RunDebugOperation(req.Operation, req.Streamer)
+		switch {
+		case errors.Is(err, api.ErrUnknownDebugOperation):
+			writeBadRequest(w, err.Error())
+		case err != nil:
+			writeError(w, http.StatusBadGateway, "GQL request failed: "+err.Error())
+		default:
+			writeJSONOK(w, result)
+		}
+	default:
+		writeNotAllowed(w)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/notifications"
 	"github.com/PatrickWalther/twitch-miner-go/internal/version"
@@ -25,7 +26,7 @@ func (s *Server) handleNotificationsPage(w http.ResponseWriter, r *http.Request)
 
 	var streamers []string
 	for _, st := range s.streamers {
-		streamers = append(streamers, st.Username)
+		streamers = append(streamers, st.GetUsername())
 	}
 
 	configValid := true
@@ -74,6 +75,11 @@ func (s *Server) handleAPINotificationsConfig(w http.ResponseWriter, r *http.Req
 			return
 		}
 
+		if cfg.SummaryWeekday < 0 || cfg.SummaryWeekday > 6 || cfg.SummaryHour < 0 || cfg.SummaryHour > 23 {
+			writeBadRequest(w, "Summary weekday must be 0-6 and hour 0-23")
+			return
+		}
+
 		if err := notifMgr.SaveConfig(&cfg); err != nil {
 			writeInternalError(w, "Failed to save config")
 			return
@@ -175,6 +181,80 @@ func (s *Server) handleAPINotificationsPointsDelete(w http.ResponseWriter, r *ht
 	writeSuccess(w)
 }
 
+func (s *Server) handleAPINotificationsIdle(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	notifMgr := s.notificationManager
+	s.mu.RUnlock()
+
+	if notifMgr == nil {
+		writeServiceUnavailable(w, "Notifications not available")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		rules, err := notifMgr.GetIdleRules()
+		if err != nil {
+			writeInternalError(w, "Failed to get rules")
+			return
+		}
+		writeJSONOK(w, rules)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var rule notifications.IdleRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			writeBadRequest(w, "Invalid JSON: "+err.Error())
+			return
+		}
+
+		if rule.Streamer == "" || rule.Days < 1 {
+			writeBadRequest(w, "Streamer and a positive number of days are required")
+			return
+		}
+
+		if err := notifMgr.AddIdleRule(&rule); err != nil {
+			writeInternalError(w, "Failed to add rule")
+			return
+		}
+
+		writeJSONOK(w, rule)
+		return
+	}
+
+	writeNotAllowed(w)
+}
+
+func (s *Server) handleAPINotificationsIdleDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeNotAllowed(w)
+		return
+	}
+
+	s.mu.RLock()
+	notifMgr := s.notificationManager
+	s.mu.RUnlock()
+
+	if notifMgr == nil {
+		writeServiceUnavailable(w, "Notifications not available")
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/notifications/idle/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeBadRequest(w, "Invalid ID")
+		return
+	}
+
+	if err := notifMgr.DeleteIdleRule(id); err != nil {
+		writeInternalError(w, "Failed to delete rule")
+		return
+	}
+
+	writeSuccess(w)
+}
+
 func (s *Server) handleAPINotificationsTest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeNotAllowed(w)
@@ -198,3 +278,59 @@ func (s *Server) handleAPINotificationsTest(w http.ResponseWriter, r *http.Reque
 
 	writeJSONOK(w, map[string]int{"sent": sent})
 }
+
+func (s *Server) handleAPINotificationsLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeNotAllowed(w)
+		return
+	}
+
+	s.mu.RLock()
+	notifMgr := s.notificationManager
+	s.mu.RUnlock()
+
+	if notifMgr == nil {
+		writeServiceUnavailable(w, "Notifications not available")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := notifications.LogFilter{
+		Type:  notifications.NotificationType(query.Get("type")),
+		Limit: 100,
+	}
+
+	if l := query.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			filter.Limit = min(parsed, 500)
+		}
+	}
+
+	if startDate := query.Get("startDate"); startDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			writeBadRequest(w, "Invalid startDate")
+			return
+		}
+		filter.Since = t
+	}
+	if endDate := query.Get("endDate"); endDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			writeBadRequest(w, "Invalid endDate")
+			return
+		}
+		filter.Until = t.Add(24*time.Hour - time.Millisecond)
+	}
+
+	entries, err := notifMgr.GetLog(filter)
+	if err != nil {
+		writeInternalError(w, "Failed to get notification log")
+		return
+	}
+	if entries == nil {
+		entries = []notifications.LogEntry{}
+	}
+
+	writeJSONOK(w, entries)
+}
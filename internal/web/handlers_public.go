@@ -0,0 +1,149 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/version"
+)
+
+// publicStatusPath serves the status for external status pages. It is
+// protected by STATUS_TOKEN instead of the dashboard credentials.
+const publicStatusPath = "/api/public/status"
+
+const (
+	// publicStatusLimit is how many requests with a valid token
+	// publicStatusPath answers per publicStatusWindow, across all clients.
+	publicStatusLimit  = 30
+	publicStatusWindow = time.Minute
+
+	// heartbeatStale is how long the miner may go without a successful
+	// minute-watched report while watching before it counts as degraded.
+	heartbeatStale = 5 * time.Minute
+)
+
+// PublicStatus is the summary returned to external status pages.
+type PublicStatus struct {
+	Status        string `json:"status"`
+	Online        bool   `json:"online"`
+	DatabaseOK    bool   `json:"database_ok"`
+	LastHeartbeat int64  `json:"last_heartbeat,omitempty"`
+	Streamers     int    `json:"streamers"`
+	Live          int    `json:"live"`
+	Watching      int    `json:"watching"`
+	Version       string `json:"version"`
+}
+
+// windowLimiter allows a fixed number of requests per time window.
+type windowLimiter struct {
+	mu    sync.Mutex
+	start time.Time
+	count int
+}
+
+// allow counts a request and reports whether it is within the limit, and if
+// not, how long until the window resets.
+func (l *windowLimiter) allow(limit int, window time.Duration) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.start) >= window {
+		l.start = now
+		l.count = 0
+	}
+	if l.count >= limit {
+		return false, window - now.Sub(l.start)
+	}
+	l.count++
+	return true, 0
+}
+
+func getStatusToken() string {
+	return os.Getenv("STATUS_TOKEN")
+}
+
+// validStatusToken checks the token from the Authorization bearer header or
+// the token query parameter.
+func validStatusToken(r *http.Request, expected string) bool {
+	token := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// handleAPIPublicStatus returns overall health, the last successful heartbeat
+// and streamer counts. It answers 503 while the miner is offline so status
+// pages can alert on the status code alone.
+func (s *Server) handleAPIPublicStatus(w http.ResponseWriter, r *http.Request) {
+	expected := getStatusToken()
+	if expected == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeNotAllowed(w)
+		return
+	}
+	// Only requests with the token count toward the limit, so clients
+	// without it cannot use up the monitor's budget.
+	if !validStatusToken(r, expected) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if ok, retry := s.publicLimiter.allow(publicStatusLimit, publicStatusWindow); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds())+1))
+		writeError(w, http.StatusTooManyRequests, "Too many requests")
+		return
+	}
+
+	s.mu.RLock()
+	streamers := s.streamers
+	overview := s.overviewProvider
+	database := s.databaseProvider
+	network := s.network
+	s.mu.RUnlock()
+
+	status := PublicStatus{
+		Online:     network.Online(),
+		DatabaseOK: database == nil || !database.GetDatabaseStatus().InMemory,
+		Streamers:  len(streamers),
+		Version:    version.Version,
+	}
+
+	var lastHeartbeat time.Time
+	for _, st := range streamers {
+		if st.GetIsOnline() {
+			status.Live++
+		}
+		if last := st.GetHeartbeats().LastOK; last.After(lastHeartbeat) {
+			lastHeartbeat = last
+		}
+	}
+	if !lastHeartbeat.IsZero() {
+		status.LastHeartbeat = lastHeartbeat.UnixMilli()
+	}
+	if overview != nil {
+		status.Watching = len(overview.GetWatchedStreamers())
+	}
+
+	code := http.StatusOK
+	switch {
+	case !status.Online:
+		status.Status = "down"
+		code = http.StatusServiceUnavailable
+	case !status.DatabaseOK, status.Watching > 0 && !lastHeartbeat.IsZero() && time.Since(lastHeartbeat) > heartbeatStale:
+		status.Status = "degraded"
+	default:
+		status.Status = "ok"
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, code, status)
+}
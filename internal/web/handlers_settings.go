@@ -2,7 +2,9 @@ package web
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/settings"
 	"github.com/PatrickWalther/twitch-miner-go/internal/version"
@@ -48,6 +50,8 @@ func (s *Server) handleAPISettings(w http.ResponseWriter, r *http.Request) {
 		s.mu.Lock()
 		s.refresh = newSettings.Analytics.Refresh
 		s.daysAgo = newSettings.Analytics.DaysAgo
+		s.warnUnfollowed = newSettings.Analytics.WarnUnfollowed
+		s.efficiencyAlert = newSettings.Analytics.EfficiencyAlert
 		s.mu.Unlock()
 
 		writeSuccess(w)
@@ -77,7 +81,126 @@ func (s *Server) handleAPISettingsReset(w http.ResponseWriter, r *http.Request)
 	s.mu.Lock()
 	s.refresh = defaults.Analytics.Refresh
 	s.daysAgo = defaults.Analytics.DaysAgo
+	s.warnUnfollowed = defaults.Analytics.WarnUnfollowed
+	s.efficiencyAlert = defaults.Analytics.EfficiencyAlert
 	s.mu.Unlock()
 
 	writeJSONOK(w, defaults)
 }
+
+func (s *Server) handleAPISettingsStreamersBulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	if s.onBulkStreamerUpdate == nil {
+		writeServiceUnavailable(w, "Settings not available")
+		return
+	}
+
+	var update settings.BulkStreamerUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeBadRequest(w, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	updated, err := s.onBulkStreamerUpdate(update)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	writeJSONOK(w, map[string]any{"updated": updated})
+}
+
+// maxImportSize limits the size of an imported streamer list.
+const maxImportSize = 1 << 20
+
+func (s *Server) handleAPISettingsStreamersImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	if s.onStreamerImport == nil {
+		writeServiceUnavailable(w, "Settings not available")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var text string
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxImportSize); err != nil {
+			writeBadRequest(w, "Invalid upload: "+err.Error())
+			return
+		}
+		text = r.FormValue("text")
+
+		if file, _, err := r.FormFile("file"); err == nil {
+			data, err := io.ReadAll(file)
+			_ = file.Close()
+			if err != nil {
+				writeBadRequest(w, "Invalid upload: "+err.Error())
+				return
+			}
+			text += "\n" + string(data)
+		}
+	} else {
+		var req struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "Invalid JSON: "+err.Error())
+			return
+		}
+		text = req.Text
+	}
+
+	result, err := s.onStreamerImport(text)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSONOK(w, result)
+}
+
+// handleAPIConfigExport returns the configuration as config.json. Credentials
+// are redacted unless ?full=true is passed, so the default output can be
+// pasted into bug reports.
+func (s *Server) handleAPIConfigExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeNotAllowed(w)
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.configProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Config not available")
+		return
+	}
+
+	cfg := provider.GetConfig()
+	filename := "config.json"
+	if r.URL.Query().Get("full") != "true" {
+		cfg = cfg.Redacted()
+		filename = "config.redacted.json"
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		writeInternalError(w, "Failed to encode config")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("download") == "true" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+	_, _ = w.Write(data)
+}
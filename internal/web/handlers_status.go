@@ -3,11 +3,23 @@ package web
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
 )
 
 func (s *Server) handleAPIStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	network := s.network
+	s.mu.RUnlock()
+
 	w.Header().Set("Content-Type", "text/html")
+	if !network.Online() {
+		since := network.OfflineSince().Format("15:04")
+		_, _ = w.Write([]byte("Offline since " + since + ", mining paused"))
+		return
+	}
 	_, _ = w.Write([]byte("Connected"))
 }
 
@@ -62,3 +74,86 @@ func (s *Server) handleAPINextCheck(w http.ResponseWriter, r *http.Request) {
 		"nextCheck": nextCheck.Unix(),
 	})
 }
+
+func (s *Server) handleAPIDiagnosticsRequests(w http.ResponseWriter, r *http.Request) {
+	writeJSONOK(w, httpx.Stats())
+}
+
+// handleAPIDiagnosticsBudget reports the rate limit and integrity errors of
+// recent Twitch requests and whether the miner slowed down because of them.
+func (s *Server) handleAPIDiagnosticsBudget(w http.ResponseWriter, r *http.Request) {
+	writeJSONOK(w, httpx.Budget())
+}
+
+func (s *Server) handleAPIDiagnosticsPubSub(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.diagnosticsProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "PubSub diagnostics not available")
+		return
+	}
+
+	writeJSONOK(w, provider.GetPubSubDiagnostics())
+}
+
+func (s *Server) handleAPIUptime(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.uptimeProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Uptime not available")
+		return
+	}
+
+	summary, err := provider.GetUptime()
+	if err != nil {
+		slog.Error("Failed to get uptime", "error", err)
+		writeInternalError(w, "Failed to get uptime")
+		return
+	}
+
+	writeJSONOK(w, summary)
+}
+
+func (s *Server) handleAPIDatabase(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.databaseProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Database status not available")
+		return
+	}
+
+	writeJSONOK(w, provider.GetDatabaseStatus())
+}
+
+// handleAPIDatabaseRecover backs up a damaged database file and recreates it
+// from the in-memory fallback. The dashboard asks for confirmation first.
+func (s *Server) handleAPIDatabaseRecover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNotAllowed(w)
+		return
+	}
+
+	s.mu.RLock()
+	provider := s.databaseProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeServiceUnavailable(w, "Database recovery not available")
+		return
+	}
+
+	status, err := provider.RecoverDatabase()
+	if err != nil {
+		slog.Error("Failed to recover database", "error", err)
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	writeJSONOK(w, status)
+}
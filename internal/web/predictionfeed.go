@@ -0,0 +1,51 @@
+package web
+
+import (
+	"sync"
+)
+
+// predictionFeed fans out newly opened predictions to the dashboards
+// following /api/predictions/stream. Predictions published while nobody
+// listens are not kept.
+type predictionFeed struct {
+	listeners []chan ActivePrediction
+	mu        sync.RWMutex
+}
+
+func newPredictionFeed() *predictionFeed {
+	return &predictionFeed{}
+}
+
+func (f *predictionFeed) subscribe() chan ActivePrediction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan ActivePrediction, 10)
+	f.listeners = append(f.listeners, ch)
+	return ch
+}
+
+func (f *predictionFeed) unsubscribe(ch chan ActivePrediction) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, listener := range f.listeners {
+		if listener == ch {
+			f.listeners = append(f.listeners[:i], f.listeners[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+func (f *predictionFeed) publish(prediction ActivePrediction) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, ch := range f.listeners {
+		select {
+		case ch <- prediction:
+		default:
+		}
+	}
+}
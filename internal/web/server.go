@@ -6,16 +6,24 @@ import (
 	"html/template"
 	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+	"github.com/PatrickWalther/twitch-miner-go/internal/api"
+	"github.com/PatrickWalther/twitch-miner-go/internal/avatars"
+	"github.com/PatrickWalther/twitch-miner-go/internal/chat"
 	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/connectivity"
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
 	"github.com/PatrickWalther/twitch-miner-go/internal/models"
 	"github.com/PatrickWalther/twitch-miner-go/internal/notifications"
 	"github.com/PatrickWalther/twitch-miner-go/internal/settings"
+	"github.com/PatrickWalther/twitch-miner-go/internal/uptime"
+	"github.com/PatrickWalther/twitch-miner-go/internal/util"
 )
 
 //go:embed templates/*.html templates/partials/*.html
@@ -28,24 +36,122 @@ type NextStreamCheckProvider interface {
 	GetNextStreamCheck() time.Time
 }
 
+// ConfigProvider exposes the miner's current configuration for export.
+type ConfigProvider interface {
+	GetConfig() config.Config
+}
+
+// UptimeProvider exposes the miner's uptime and restart history.
+type UptimeProvider interface {
+	GetUptime() (*uptime.Summary, error)
+}
+
+// InventoryProvider exposes the cached drops inventory.
+type InventoryProvider interface {
+	GetInventory() *models.Inventory
+}
+
+// DropsProvider exposes the progress on active drop campaigns.
+type DropsProvider interface {
+	GetDropCampaigns() ([]models.CampaignProgress, time.Time)
+}
+
+// StreamerActionProvider performs the quick actions on dashboard streamer cards.
+type StreamerActionProvider interface {
+	RecheckStreamer(username string) error
+	ClaimStreamerBonus(username string) error
+	SetStreamerPinned(username string, pinned bool) error
+	SetStreamerEnabled(username string, enabled bool) error
+	ResetStreamerEarning(username string) error
+	GetPinnedStreamers() []string
+	AddTemporaryStreamer(username string, d time.Duration) (time.Time, error)
+	AddStreamer(username string) error
+	RemoveStreamer(username string) error
+}
+
+// PredictionProvider exposes the predictions the miner is tracking, answers
+// the approvals of large bets and overrides scheduled bets.
+type PredictionProvider interface {
+	GetActivePredictions() []models.PredictionSnapshot
+	ResolveBetApproval(eventID string, approve bool) error
+	OverridePrediction(eventID string, override models.BetOverride) error
+	PlaceManualBet(eventID, outcomeID string, amount int) error
+}
+
+// DiagnosticsProvider exposes the miner state included in diagnostics bundles.
+type DiagnosticsProvider interface {
+	GetSchemaVersions() (map[string]int, error)
+	GetDiagnosticsStatus() any
+	GetPubSubDiagnostics() any
+}
+
+// DatabaseProvider reports the health of the database file and recreates it
+// when it is damaged.
+type DatabaseProvider interface {
+	GetDatabaseStatus() database.Status
+	RecoverDatabase() (database.Status, error)
+}
+
+// ChatConfigProvider controls which chats are logged while the miner runs.
+type ChatConfigProvider interface {
+	GetChatLogConfig() ([]chat.ChannelLogConfig, error)
+	SetChatLogConfig(update chat.LogConfigUpdate) error
+}
+
+// DebugProvider runs the raw GQL operations of the admin debug page.
+type DebugProvider interface {
+	GetDebugOperations() []string
+	RunDebugOperation(operation, streamer string) (*api.DebugResult, error)
+}
+
+// OverviewProvider exposes live miner state shown in the account overview header.
+type OverviewProvider interface {
+	GetWatchedStreamers() []string
+	GetNextDrop() (*models.Drop, bool)
+}
+
 type Server struct {
-	host           string
-	port           int
-	refresh        int
-	daysAgo        int
-	username       string
-	basePath       string
-	streamers      []*models.Streamer
-	discordEnabled bool
+	host            string
+	port            int
+	apiHost         string
+	apiPort         int
+	socket          string
+	refresh         int
+	daysAgo         int
+	warnUnfollowed  bool
+	efficiencyAlert int
+	username        string
+	basePath        string
+	streamers       []*models.Streamer
+	discordEnabled  bool
 
 	analytics               *analytics.Service
-	server                  *http.Server
+	emotes                  *chat.EmoteResolver
+	avatars                 *avatars.Cache
+	chatBroker              *chat.Broker
+	network                 *connectivity.Monitor
+	servers                 []*http.Server
 	templates               map[string]*template.Template
 	settingsProvider        settings.SettingsProvider
 	onSettingsUpdate        settings.SettingsUpdateCallback
+	onBulkStreamerUpdate    settings.BulkStreamerUpdateCallback
+	onStreamerImport        settings.StreamerImportCallback
 	notificationManager     *notifications.Manager
 	nextStreamCheckProvider NextStreamCheckProvider
+	overviewProvider        OverviewProvider
+	inventoryProvider       InventoryProvider
+	dropsProvider           DropsProvider
+	uptimeProvider          UptimeProvider
+	configProvider          ConfigProvider
+	streamerActionProvider  StreamerActionProvider
+	predictionProvider      PredictionProvider
+	diagnosticsProvider     DiagnosticsProvider
+	debugProvider           DebugProvider
+	databaseProvider        DatabaseProvider
+	chatConfigProvider      ChatConfigProvider
 	status                  *StatusBroadcaster
+	predictionFeed          *predictionFeed
+	publicLimiter           windowLimiter
 	ready                   bool
 	mu                      sync.RWMutex
 }
@@ -54,17 +160,24 @@ func NewServer(analyticsSettings config.AnalyticsSettings, username string, base
 	templates := loadTemplates()
 
 	return &Server{
-		host:         analyticsSettings.Host,
-		port:         analyticsSettings.Port,
-		refresh:      analyticsSettings.Refresh,
-		daysAgo:      analyticsSettings.DaysAgo,
-		username:     username,
-		basePath:     basePath,
-		streamers:    streamers,
-		analytics:    analyticsSvc,
-		templates:    templates,
-		status: NewStatusBroadcaster(),
-		ready:  len(streamers) > 0,
+		host:            analyticsSettings.Host,
+		port:            analyticsSettings.Port,
+		apiHost:         analyticsSettings.APIHost,
+		apiPort:         analyticsSettings.APIPort,
+		socket:          analyticsSettings.Socket,
+		refresh:         analyticsSettings.Refresh,
+		daysAgo:         analyticsSettings.DaysAgo,
+		warnUnfollowed:  analyticsSettings.WarnUnfollowed,
+		efficiencyAlert: analyticsSettings.EfficiencyAlert,
+		username:        username,
+		basePath:        basePath,
+		streamers:       streamers,
+		analytics:       analyticsSvc,
+		emotes:          chat.NewEmoteResolver(),
+		templates:       templates,
+		status:          NewStatusBroadcaster(),
+		predictionFeed:  newPredictionFeed(),
+		ready:           len(streamers) > 0,
 	}
 }
 
@@ -72,26 +185,39 @@ func NewServerEarly(analyticsSettings config.AnalyticsSettings, username string,
 	templates := loadTemplates()
 
 	return &Server{
-		host:         analyticsSettings.Host,
-		port:         analyticsSettings.Port,
-		refresh:      analyticsSettings.Refresh,
-		daysAgo:      analyticsSettings.DaysAgo,
-		username:     username,
-		basePath:     basePath,
-		streamers:    nil,
-		analytics:    analyticsSvc,
-		templates:    templates,
-		status: NewStatusBroadcaster(),
-		ready:  false,
+		host:            analyticsSettings.Host,
+		port:            analyticsSettings.Port,
+		apiHost:         analyticsSettings.APIHost,
+		apiPort:         analyticsSettings.APIPort,
+		socket:          analyticsSettings.Socket,
+		refresh:         analyticsSettings.Refresh,
+		daysAgo:         analyticsSettings.DaysAgo,
+		warnUnfollowed:  analyticsSettings.WarnUnfollowed,
+		efficiencyAlert: analyticsSettings.EfficiencyAlert,
+		username:        username,
+		basePath:        basePath,
+		streamers:       nil,
+		analytics:       analyticsSvc,
+		emotes:          chat.NewEmoteResolver(),
+		templates:       templates,
+		status:          NewStatusBroadcaster(),
+		predictionFeed:  newPredictionFeed(),
+		ready:           false,
 	}
 }
 
+// templateFuncs are available to every page. locale is the language numbers
+// are formatted in, so the page scripts match the server-side formatting.
+var templateFuncs = template.FuncMap{
+	"locale": util.Locale,
+}
+
 func loadTemplates() map[string]*template.Template {
 	templates := make(map[string]*template.Template)
 
-	pages := []string{"dashboard.html", "streamer.html", "settings.html", "notifications.html"}
+	pages := []string{"dashboard.html", "streamer.html", "settings.html", "notifications.html", "inventory.html", "drops.html", "debug.html"}
 	for _, page := range pages {
-		tmpl, err := template.ParseFS(templatesFS,
+		tmpl, err := template.New("base.html").Funcs(templateFuncs).ParseFS(templatesFS,
 			"templates/base.html",
 			"templates/"+page,
 			"templates/partials/*.html",
@@ -124,6 +250,12 @@ func (s *Server) GetStatusBroadcaster() *StatusBroadcaster {
 	return s.status
 }
 
+// SetStatusBroadcaster replaces the broadcaster the status API reports from,
+// so code outside the server can follow the status from before it starts.
+func (s *Server) SetStatusBroadcaster(status *StatusBroadcaster) {
+	s.status = status
+}
+
 func (s *Server) GetAnalyticsService() *analytics.Service {
 	return s.analytics
 }
@@ -140,6 +272,14 @@ func (s *Server) SetSettingsUpdateCallback(callback settings.SettingsUpdateCallb
 	s.onSettingsUpdate = callback
 }
 
+func (s *Server) SetBulkStreamerUpdateCallback(callback settings.BulkStreamerUpdateCallback) {
+	s.onBulkStreamerUpdate = callback
+}
+
+func (s *Server) SetStreamerImportCallback(callback settings.StreamerImportCallback) {
+	s.onStreamerImport = callback
+}
+
 func (s *Server) SetNotificationManager(mgr *notifications.Manager) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -152,6 +292,93 @@ func (s *Server) SetNextStreamCheckProvider(provider NextStreamCheckProvider) {
 	s.nextStreamCheckProvider = provider
 }
 
+func (s *Server) SetOverviewProvider(provider OverviewProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overviewProvider = provider
+}
+
+func (s *Server) SetInventoryProvider(provider InventoryProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inventoryProvider = provider
+}
+
+func (s *Server) SetDropsProvider(provider DropsProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropsProvider = provider
+}
+
+func (s *Server) SetPredictionProvider(provider PredictionProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.predictionProvider = provider
+}
+
+func (s *Server) SetDiagnosticsProvider(provider DiagnosticsProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.diagnosticsProvider = provider
+}
+
+func (s *Server) SetDatabaseProvider(provider DatabaseProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.databaseProvider = provider
+}
+
+func (s *Server) SetChatConfigProvider(provider ChatConfigProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatConfigProvider = provider
+}
+
+func (s *Server) SetDebugProvider(provider DebugProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.debugProvider = provider
+}
+
+func (s *Server) SetUptimeProvider(provider UptimeProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uptimeProvider = provider
+}
+
+func (s *Server) SetConfigProvider(provider ConfigProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configProvider = provider
+}
+
+func (s *Server) SetStreamerActionProvider(provider StreamerActionProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streamerActionProvider = provider
+}
+
+// SetAvatarCache sets where /static/avatars/{name} serves profile images from.
+func (s *Server) SetAvatarCache(cache *avatars.Cache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.avatars = cache
+}
+
+// SetConnectivity sets the monitor the status endpoints report the network
+// state from.
+func (s *Server) SetConnectivity(monitor *connectivity.Monitor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.network = monitor
+}
+
+func (s *Server) SetChatBroker(broker *chat.Broker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatBroker = broker
+}
+
 func (s *Server) SetDiscordEnabled(enabled bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -170,7 +397,8 @@ func authEnabled() bool {
 func basicAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		expectedUser, expectedPass := getAuthCredentials()
-		if expectedUser == "" || expectedPass == "" {
+		// The public status checks its own token.
+		if expectedUser == "" || expectedPass == "" || r.URL.Path == publicStatusPath {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -186,72 +414,186 @@ func basicAuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func (s *Server) Start() {
-	mux := http.NewServeMux()
+// readOnlyHandler rejects requests that could make changes, for the
+// dashboard listener when changes go through the separate API listener.
+func readOnlyHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, http.StatusMethodNotAllowed, "Changes are only accepted on the API port")
+			return
+		}
+		next(w, r)
+	}
+}
 
-	// Static files
+// registerPageRoutes adds the HTML pages and static files.
+func (s *Server) registerPageRoutes(mux *http.ServeMux) {
 	staticSub, err := fs.Sub(staticFS, "static")
 	if err != nil {
 		slog.Error("Failed to create static filesystem", "error", err)
 	} else {
 		mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticSub))))
 	}
+	mux.HandleFunc("/static/avatars/", s.handleAvatar)
 
-	// Dashboard routes
 	mux.HandleFunc("/", s.handleDashboard)
 	mux.HandleFunc("/streamer/", s.handleStreamerPage)
-	mux.HandleFunc("/api/streamers", s.handleAPIStreamers)
+	mux.HandleFunc("/inventory", s.handleInventoryPage)
+	mux.HandleFunc("/drops", s.handleDropsPage)
+	mux.HandleFunc("/settings", s.handleSettingsPage)
+	mux.HandleFunc("/notifications", s.handleNotificationsPage)
+	mux.HandleFunc("/debug/gql", s.handleDebugGQLPage)
+}
+
+// registerAPIRoutes adds the JSON, SSE and HTMX partial endpoints. With
+// readOnly, the routes only answer GET and HEAD requests, and the ones that
+// only make changes or export secrets are left out.
+func (s *Server) registerAPIRoutes(mux *http.ServeMux, readOnly bool) {
+	handle := func(pattern string, handler http.HandlerFunc) {
+		if readOnly {
+			handler = readOnlyHandler(handler)
+		}
+		mux.HandleFunc(pattern, handler)
+	}
+	apiOnly := func(pattern string, handler http.HandlerFunc) {
+		if !readOnly {
+			mux.HandleFunc(pattern, handler)
+		}
+	}
+
+	// Dashboard routes
+	handle("/api/streamers", s.handleAPIStreamers)
+	apiOnly("/api/streamers/temporary", s.handleAPIAddTemporaryStreamer)
+	handle("/api/streamers/", s.handleAPIStreamerAction)
+	handle("/api/predictions/active", s.handleAPIActivePredictions)
+	apiOnly("/api/predictions/approval", s.handleAPIBetApproval)
+	handle("/api/predictions/stream", s.handleAPIPredictionStream)
+	apiOnly("/api/predictions/skip", s.handleAPIPredictionSkip)
+	apiOnly("/api/predictions/force", s.handleAPIPredictionForce)
+	apiOnly("/api/predictions/", s.handleAPIManualBet)
+	handle("/api/heartbeats", s.handleAPIHeartbeats)
+	handle("/api/drops", s.handleAPIDrops)
 
 	// Status routes
-	mux.HandleFunc("/api/status", s.handleAPIStatus)
-	mux.HandleFunc("/api/miner-status", s.handleAPIMinerStatus)
-	mux.HandleFunc("/api/miner-status/stream", s.handleAPIMinerStatusStream)
-	mux.HandleFunc("/api/next-check", s.handleAPINextCheck)
+	handle("/api/status", s.handleAPIStatus)
+	handle("/api/miner-status", s.handleAPIMinerStatus)
+	handle("/api/miner-status/stream", s.handleAPIMinerStatusStream)
+	handle("/api/next-check", s.handleAPINextCheck)
+	handle("/api/overview", s.handleAPIOverview)
+	handle("/api/diagnostics/requests", s.handleAPIDiagnosticsRequests)
+	handle("/api/diagnostics/pubsub", s.handleAPIDiagnosticsPubSub)
+	handle("/api/diagnostics/budget", s.handleAPIDiagnosticsBudget)
+	apiOnly("/api/diagnostics/bundle", s.handleAPIDiagnosticsBundle)
+	handle("/api/database", s.handleAPIDatabase)
+	apiOnly("/api/database/recover", s.handleAPIDatabaseRecover)
+	apiOnly("/api/debug/gql", s.handleAPIDebugGQL)
+	handle("/api/uptime", s.handleAPIUptime)
+	handle(publicStatusPath, s.handleAPIPublicStatus)
 
 	// Settings routes
-	mux.HandleFunc("/settings", s.handleSettingsPage)
-	mux.HandleFunc("/api/settings", s.handleAPISettings)
-	mux.HandleFunc("/api/settings/reset", s.handleAPISettingsReset)
+	handle("/api/settings", s.handleAPISettings)
+	apiOnly("/api/settings/reset", s.handleAPISettingsReset)
+	apiOnly("/api/settings/streamers/bulk", s.handleAPISettingsStreamersBulk)
+	apiOnly("/api/settings/streamers/import", s.handleAPISettingsStreamersImport)
+	apiOnly("/api/config/export", s.handleAPIConfigExport)
 
 	// Analytics/data routes
-	mux.HandleFunc("/streamers", s.handleStreamers)
-	mux.HandleFunc("/json/", s.handleJSON)
-	mux.HandleFunc("/json_all", s.handleJSONAll)
-	mux.HandleFunc("/api/chat/", s.handleAPIChatMessages)
+	handle("/streamers", s.handleStreamers)
+	handle("/json/", s.handleJSON)
+	handle("/json_all", s.handleJSONAll)
+	handle("/api/compare/", s.handleAPICompare)
+	handle("/api/chart/", s.handleAPIChartStream)
+	handle("/api/chat/", s.handleAPIChatMessages)
+	handle("/api/chat/config", s.handleAPIChatConfig)
+	handle("/api/analytics/styles", s.handleAPIEventStyles)
+	handle("/api/watch-slots", s.handleAPIWatchSlots)
+	handle("/api/redemptions/", s.handleAPIRedemptions)
+	handle("/api/predictions/timing/", s.handleAPIBetTiming)
+	handle("/api/predictions/history/", s.handleAPIPredictionHistory)
+	handle("/api/notes/", s.handleAPIStreamerNote)
+	handle("/api/annotations/", s.handleAPIAnnotations)
 
 	// Notifications routes
-	mux.HandleFunc("/notifications", s.handleNotificationsPage)
-	mux.HandleFunc("/api/notifications/config", s.handleAPINotificationsConfig)
-	mux.HandleFunc("/api/notifications/channels", s.handleAPINotificationsChannels)
-	mux.HandleFunc("/api/notifications/points", s.handleAPINotificationsPoints)
-	mux.HandleFunc("/api/notifications/points/", s.handleAPINotificationsPointsDelete)
-	mux.HandleFunc("/api/notifications/test", s.handleAPINotificationsTest)
+	handle("/api/notifications/config", s.handleAPINotificationsConfig)
+	handle("/api/notifications/channels", s.handleAPINotificationsChannels)
+	handle("/api/notifications/points", s.handleAPINotificationsPoints)
+	apiOnly("/api/notifications/points/", s.handleAPINotificationsPointsDelete)
+	handle("/api/notifications/idle", s.handleAPINotificationsIdle)
+	apiOnly("/api/notifications/idle/", s.handleAPINotificationsIdleDelete)
+	apiOnly("/api/notifications/test", s.handleAPINotificationsTest)
+	handle("/api/notifications/log", s.handleAPINotificationsLog)
+}
 
-	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+// Start serves the dashboard on host:port, or on the Unix socket path if one
+// is configured. With an API port configured, the API routes are served on
+// their own listener at apiHost:apiPort, which has no HTML pages, and the
+// dashboard listener only keeps the read-only routes its pages display.
+func (s *Server) Start() {
+	if authEnabled() {
+		slog.Info("Web server authentication enabled")
+	}
 
+	dashboard := http.NewServeMux()
+	s.registerPageRoutes(dashboard)
+	s.registerAPIRoutes(dashboard, s.apiPort > 0)
+	if s.socket != "" {
+		s.listen("Web server", "unix", s.socket, dashboard)
+	} else {
+		s.listen("Web server", "tcp", fmt.Sprintf("%s:%d", s.host, s.port), dashboard)
+	}
+
+	if s.apiPort > 0 {
+		host := s.apiHost
+		if host == "" {
+			host = s.host
+		}
+		api := http.NewServeMux()
+		s.registerAPIRoutes(api, false)
+		s.listen("API server", "tcp", fmt.Sprintf("%s:%d", host, s.apiPort), api)
+	}
+}
+
+func (s *Server) listen(name, network, addr string, mux *http.ServeMux) {
 	var handler http.Handler = mux
 	if authEnabled() {
 		handler = basicAuthMiddleware(mux)
-		slog.Info("Web server authentication enabled")
 	}
 
-	s.server = &http.Server{
+	if network == "unix" {
+		// A socket left behind by an unclean shutdown would make Listen fail.
+		if info, err := os.Stat(addr); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(addr)
+		}
+	}
+
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		slog.Error(name+" error", "error", err)
+		return
+	}
+
+	server := &http.Server{
 		Addr:    addr,
 		Handler: handler,
 	}
+	s.servers = append(s.servers, server)
 
-	slog.Info("Web server starting", "url", "http://"+addr+"/")
+	if network == "unix" {
+		slog.Info(name+" starting", "socket", addr)
+	} else {
+		slog.Info(name+" starting", "url", "http://"+addr+"/")
+	}
 
 	go func() {
-		if err := s.server.ListenAndServe(); err != http.ErrServerClosed {
-			slog.Error("Web server error", "error", err)
+		if err := server.Serve(ln); err != http.ErrServerClosed {
+			slog.Error(name+" error", "error", err)
 		}
 	}()
 }
 
 func (s *Server) Stop() {
-	if s.server != nil {
-		_ = s.server.Close()
+	for _, server := range s.servers {
+		_ = server.Close()
 	}
 }
 
@@ -26,10 +26,15 @@ type StatusInfo struct {
 	Message      string      `json:"message,omitempty"`
 	Auth         *AuthInfo   `json:"auth,omitempty"`
 	StreamerInfo string      `json:"streamerInfo,omitempty"`
+	// Warning is a problem shown as a banner on every page while mining
+	// continues. It survives status changes until cleared.
+	Warning string `json:"warning,omitempty"`
 }
 
 type StatusBroadcaster struct {
 	status    StatusInfo
+	warning   string
+	throttle  string
 	listeners []chan StatusInfo
 	mu        sync.RWMutex
 }
@@ -54,6 +59,7 @@ func (b *StatusBroadcaster) SetStatus(status MinerStatus, message string) {
 	b.status = StatusInfo{
 		Status:  status,
 		Message: message,
+		Warning: b.warningText(),
 	}
 	current := b.status
 	b.mu.Unlock()
@@ -71,6 +77,7 @@ func (b *StatusBroadcaster) SetAuthRequired(verificationURI, userCode string, ex
 			UserCode:        userCode,
 			ExpiresIn:       expiresIn,
 		},
+		Warning: b.warningText(),
 	}
 	current := b.status
 	b.mu.Unlock()
@@ -84,6 +91,7 @@ func (b *StatusBroadcaster) SetStreamerProgress(current, total int, name string)
 		Status:       StatusLoadingStreamers,
 		Message:      "Loading streamers...",
 		StreamerInfo: name,
+		Warning:      b.warningText(),
 	}
 	current2 := b.status
 	b.mu.Unlock()
@@ -91,6 +99,43 @@ func (b *StatusBroadcaster) SetStreamerProgress(current, total int, name string)
 	b.broadcast(current2)
 }
 
+// SetWarning shows message as a banner on every page until ClearWarning is
+// called.
+func (b *StatusBroadcaster) SetWarning(message string) {
+	b.mu.Lock()
+	b.warning = message
+	b.status.Warning = b.warningText()
+	current := b.status
+	b.mu.Unlock()
+
+	b.broadcast(current)
+}
+
+// SetThrottleWarning shows message in the banner while Twitch throttles the
+// miner, next to any warning set by SetWarning. An empty message removes it.
+func (b *StatusBroadcaster) SetThrottleWarning(message string) {
+	b.mu.Lock()
+	b.throttle = message
+	b.status.Warning = b.warningText()
+	current := b.status
+	b.mu.Unlock()
+
+	b.broadcast(current)
+}
+
+// warningText joins the warnings shown in the banner. b.mu must be held.
+func (b *StatusBroadcaster) warningText() string {
+	if b.warning != "" && b.throttle != "" {
+		return b.warning + " " + b.throttle
+	}
+	return b.warning + b.throttle
+}
+
+// ClearWarning removes the banner set by SetWarning.
+func (b *StatusBroadcaster) ClearWarning() {
+	b.SetWarning("")
+}
+
 func (b *StatusBroadcaster) Subscribe() chan StatusInfo {
 	b.mu.Lock()
 	defer b.mu.Unlock()
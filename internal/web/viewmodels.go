@@ -1,16 +1,49 @@
 package web
 
-import "github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+import (
+	"strings"
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+)
 
 type StreamerInfo struct {
-	Name                  string `json:"name"`
-	Points                int    `json:"points"`
-	PointsFormatted       string `json:"points_formatted"`
-	LastActivity          int64  `json:"last_activity"`
-	LastActivityFormatted string `json:"last_activity_formatted"`
-	IsLive                bool   `json:"is_live"`
-	LiveDuration          string `json:"live_duration,omitempty"`
-	OfflineDuration       string `json:"offline_duration,omitempty"`
+	Name                  string   `json:"name"`
+	Points                int      `json:"points"`
+	PointsFormatted       string   `json:"points_formatted"`
+	LastActivity          int64    `json:"last_activity"`
+	LastActivityFormatted string   `json:"last_activity_formatted"`
+	IsLive                bool     `json:"is_live"`
+	LiveDuration          string   `json:"live_duration,omitempty"`
+	LiveSince             int64    `json:"live_since,omitempty"`
+	OfflineDuration       string   `json:"offline_duration,omitempty"`
+	PointsToday           int      `json:"points_today"`
+	PointsTodayFormatted  string   `json:"points_today_formatted,omitempty"`
+	Tracked               bool     `json:"tracked"`
+	Enabled               bool     `json:"enabled"`
+	Pinned                bool     `json:"pinned"`
+	FollowKnown           bool     `json:"follow_known"`
+	Following             bool     `json:"following"`
+	FollowAge             string   `json:"follow_age,omitempty"`
+	WarnUnfollowed        bool     `json:"warn_unfollowed"`
+	Note                  string   `json:"note,omitempty"`
+	Labels                []string `json:"labels,omitempty"`
+	EfficiencyRank        int      `json:"efficiency_rank,omitempty"`
+	Efficiency            float64  `json:"efficiency,omitempty"`
+	EfficiencyFormatted   string   `json:"efficiency_formatted,omitempty"`
+	AvgViewers            string   `json:"avg_viewers,omitempty"`
+	LowEfficiency         bool     `json:"low_efficiency"`
+	NonEarning            string   `json:"non_earning,omitempty"`
+	Heartbeat             string   `json:"heartbeat,omitempty"`
+	HeartbeatOK           int      `json:"heartbeat_ok"`
+	HeartbeatTotal        int      `json:"heartbeat_total"`
+	HeartbeatError        string   `json:"heartbeat_error,omitempty"`
+	TemporaryUntil        int64    `json:"temporary_until,omitempty"`
+	Discovered            bool     `json:"discovered,omitempty"`
+	DropsOnly             bool     `json:"drops_only"`
+	AtPointsCap           bool     `json:"at_points_cap"`
 }
 
 type DashboardData struct {
@@ -21,6 +54,17 @@ type DashboardData struct {
 	StreamerCount  int
 	PointsToday    string
 	DiscordEnabled bool
+	Uptime         *UptimeData
+	// Database is set while the miner runs on the in-memory fallback.
+	Database *database.Status
+}
+
+// UptimeData is the formatted uptime summary shown on the dashboard.
+type UptimeData struct {
+	Uptime             string
+	Restarts7d         int
+	LastShutdown       string
+	LastShutdownReason string
 }
 
 type StreamerPageData struct {
@@ -32,12 +76,138 @@ type StreamerPageData struct {
 	DataPoints     int
 	DaysAgo        int
 	DiscordEnabled bool
+	// Channel is nil unless the streamer is tracked and its channel
+	// metadata was fetched.
+	Channel *ChannelInfo
+}
+
+// ChannelInfo is a tracked streamer's channel metadata on its page.
+type ChannelInfo struct {
+	Partner   bool
+	Affiliate bool
+	Followers string
+	Created   string
+	// Warning explains why the channel has no channel points, if so.
+	Warning string
 }
 
 type StreamerGridData struct {
 	TrackedLive    []StreamerInfo
 	TrackedOffline []StreamerInfo
 	Untracked      []StreamerInfo
+	Labels         []string
+	Label          string
+	Sort           string
+	State          string
+}
+
+// OverviewData is the account-wide summary rendered in the navbar header.
+type OverviewData struct {
+	TotalPoints   string
+	PointsToday   string
+	PointsGained  bool
+	WatchSlots    int
+	MaxWatchSlots int
+	Watching      []string
+	NextDrop      string
+	NextDropETA   string
+}
+
+// ActivePrediction is an open prediction in the dashboard's live view.
+// Times are Unix milliseconds; BetAt is when the miner places its bet.
+type ActivePrediction struct {
+	EventID   string              `json:"eventId"`
+	Streamer  string              `json:"streamer"`
+	Title     string              `json:"title"`
+	Status    string              `json:"status"`
+	CreatedAt int64               `json:"createdAt"`
+	BetAt     int64               `json:"betAt"`
+	Outcomes  []PredictionOutcome `json:"outcomes"`
+	Bet       *PredictionBet      `json:"bet,omitempty"`
+	// Approval is "pending" while a large bet waits for approval, then
+	// "approved", "rejected" or "expired". Empty if none was asked for.
+	Approval string `json:"approval,omitempty"`
+	// Override is "skip" or "force" once the bet was overridden from the
+	// dashboard.
+	Override string `json:"override,omitempty"`
+}
+
+type PredictionOutcome struct {
+	ID              string  `json:"id"`
+	Title           string  `json:"title"`
+	Color           string  `json:"color"`
+	TotalUsers      int     `json:"totalUsers"`
+	TotalPoints     int     `json:"totalPoints"`
+	PercentageUsers float64 `json:"percentageUsers"`
+	Odds            float64 `json:"odds"`
+}
+
+// PredictionBet is the miner's bet on a prediction. State is "planned" until
+// the bet time, then "placing", "placed", "confirmed", "not_placed" (the
+// miner chose not to bet) or "skipped" (Twitch does not allow betting).
+type PredictionBet struct {
+	Outcome int    `json:"outcome"`
+	Amount  int    `json:"amount"`
+	State   string `json:"state"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type InventoryPageData struct {
+	Username       string
+	RefreshMinutes int
+	Version        string
+	DiscordEnabled bool
+	Loaded         bool
+	UpdatedAgo     string
+	InProgress     []*models.InventoryCampaign
+	Drops          []*models.InventoryDrop
+	Rewards        []*models.InventoryReward
+}
+
+type DropsPageData struct {
+	Username       string
+	RefreshMinutes int
+	Version        string
+	DiscordEnabled bool
+	Loaded         bool
+	UpdatedAgo     string
+	Campaigns      []DropCampaign
+}
+
+// DropsResponse is the body of GET /api/drops. UpdatedAt is the Unix
+// millisecond time of the last campaign sync, or 0 before the first one.
+type DropsResponse struct {
+	UpdatedAt int64          `json:"updatedAt"`
+	Campaigns []DropCampaign `json:"campaigns"`
+}
+
+// DropCampaign is an active drop campaign. Streamers are the online streamers
+// currently progressing it.
+type DropCampaign struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Game        string       `json:"game"`
+	EndAt       time.Time    `json:"endAt"`
+	InInventory bool         `json:"inInventory"`
+	Streamers   []string     `json:"streamers"`
+	Drops       []DropStatus `json:"drops"`
+}
+
+// DropStatus is the progress on one drop of a campaign. ETA is when the drop
+// is earned if the campaign keeps being watched, and is nil while no online
+// streamer progresses it or once the minutes are watched. Late means the ETA
+// is after the drop ends.
+type DropStatus struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Benefit         string     `json:"benefit"`
+	MinutesRequired int        `json:"minutesRequired"`
+	MinutesWatched  int        `json:"minutesWatched"`
+	Percent         int        `json:"percent"`
+	Claimed         bool       `json:"claimed"`
+	EndAt           time.Time  `json:"endAt"`
+	ETA             *time.Time `json:"eta,omitempty"`
+	Late            bool       `json:"late,omitempty"`
 }
 
 type SettingsPageData struct {
@@ -47,6 +217,18 @@ type SettingsPageData struct {
 	DiscordEnabled bool
 }
 
+// DebugPageData is the admin GQL debug page. Allowed is false for requests
+// that may not use the debug tools.
+type DebugPageData struct {
+	Username       string
+	RefreshMinutes int
+	Version        string
+	DiscordEnabled bool
+	Allowed        bool
+	Operations     []string
+	Streamers      []string
+}
+
 type NotificationsPageData struct {
 	Username       string
 	RefreshMinutes int
@@ -77,3 +259,95 @@ func convertStreamerInfoList(infos []analytics.StreamerInfo) []StreamerInfo {
 	}
 	return result
 }
+
+// convertDropCampaigns builds the drops view of the campaign progress synced
+// at syncedAt. Watched minutes accrue on all drops of a campaign at once, so
+// each drop's ETA counts its remaining minutes from the sync.
+func convertDropCampaigns(progress []models.CampaignProgress, syncedAt, now time.Time) []DropCampaign {
+	campaigns := make([]DropCampaign, 0, len(progress))
+	for _, p := range progress {
+		campaign := DropCampaign{
+			ID:          p.ID,
+			Name:        p.Name,
+			Game:        p.Game,
+			EndAt:       p.EndAt,
+			InInventory: p.InInventory,
+			Streamers:   p.Streamers,
+			Drops:       make([]DropStatus, 0, len(p.Drops)),
+		}
+		if campaign.Streamers == nil {
+			campaign.Streamers = []string{}
+		}
+		for _, d := range p.Drops {
+			drop := DropStatus{
+				ID:              d.ID,
+				Name:            d.Name,
+				Benefit:         d.Benefit,
+				MinutesRequired: d.MinutesRequired,
+				MinutesWatched:  d.CurrentMinutesWatched,
+				Claimed:         d.IsClaimed,
+				EndAt:           d.EndAt,
+			}
+			if d.MinutesRequired > 0 {
+				drop.Percent = min(d.CurrentMinutesWatched*100/d.MinutesRequired, 100)
+			}
+			if remaining := d.RemainingMinutes(); !d.IsClaimed && remaining > 0 && len(p.Streamers) > 0 {
+				eta := syncedAt.Add(time.Duration(remaining) * time.Minute)
+				if eta.Before(now) {
+					eta = now
+				}
+				drop.ETA = &eta
+				drop.Late = !d.EndAt.IsZero() && eta.After(d.EndAt)
+			}
+			campaign.Drops = append(campaign.Drops, drop)
+		}
+		campaigns = append(campaigns, campaign)
+	}
+	return campaigns
+}
+
+func convertPrediction(p models.PredictionSnapshot) ActivePrediction {
+	prediction := ActivePrediction{
+		EventID:   p.EventID,
+		Streamer:  p.Streamer,
+		Title:     p.Title,
+		Status:    string(p.Status),
+		CreatedAt: p.CreatedAt.UnixMilli(),
+		BetAt:     p.BetAt.UnixMilli(),
+		Outcomes:  make([]PredictionOutcome, len(p.Outcomes)),
+		Approval:  strings.ToLower(string(p.Approval)),
+		Override:  strings.ToLower(string(p.Override)),
+	}
+	for i, o := range p.Outcomes {
+		prediction.Outcomes[i] = PredictionOutcome{
+			ID:              o.ID,
+			Title:           o.Title,
+			Color:           o.Color,
+			TotalUsers:      o.TotalUsers,
+			TotalPoints:     o.TotalPoints,
+			PercentageUsers: o.PercentageUsers,
+			Odds:            o.Odds,
+		}
+	}
+
+	bet := &PredictionBet{Outcome: p.Decision.Choice, Amount: p.Decision.Amount}
+	switch {
+	case p.NotBiddable:
+		bet.State, bet.Reason = "skipped", p.SkipReason
+	case p.BetConfirmed:
+		bet.State = "confirmed"
+	case p.BetPlaced:
+		bet.State = "placed"
+	case p.SkipReason != "":
+		bet.State, bet.Reason = "not_placed", p.SkipReason
+	case p.Decision.ID != "":
+		bet.State = "placing"
+	case p.Decision.Choice >= 0:
+		bet.State = "planned"
+	default:
+		bet = nil
+	}
+	prediction.Bet = bet
+
+	return prediction
+}
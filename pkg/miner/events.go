@@ -0,0 +1,136 @@
+package miner
+
+import (
+	"time"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/miner"
+	"github.com/PatrickWalther/twitch-miner-go/internal/web"
+)
+
+// subscribeBuffer is how many events or statuses a subscriber may fall
+// behind before newer ones are dropped.
+const subscribeBuffer = 64
+
+// EventType identifies the kind of an Event.
+type EventType string
+
+const (
+	EventStreamerOnline   EventType = "streamer_online"
+	EventStreamerOffline  EventType = "streamer_offline"
+	EventPointsEarned     EventType = "points_earned"
+	EventPointsSpent      EventType = "points_spent"
+	EventPredictionResult EventType = "prediction_result"
+	EventWatching         EventType = "watching"
+	EventSettingsApplied  EventType = "settings_applied"
+	EventBetPlaced        EventType = "bet_placed"
+	EventDropClaimed      EventType = "drop_claimed"
+	EventMentionReceived  EventType = "mention_received"
+	// EventViewingConflict is watch points credited for a channel the miner
+	// is not watching, i.e. the account is viewed somewhere else.
+	EventViewingConflict EventType = "viewing_conflict"
+)
+
+// Event is something the miner did or observed. Fields that do not apply to
+// the event's type are left empty.
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Streamer string
+	// Balance is the streamer's channel points after the event.
+	Balance int
+	// Gained is the points earned, or the net result of a prediction.
+	Gained int
+	// Reason is the Twitch reason code of earned points or the prediction
+	// result type (WIN, LOSE, REFUND).
+	Reason string
+	// Watching lists the streamers occupying the watch slots.
+	Watching []string
+	// Placed is the points bet on a prediction.
+	Placed int
+	// Drop is the name of a claimed drop.
+	Drop string
+	// From and Message are the chat user and message that mentioned the
+	// account.
+	From    string
+	Message string
+}
+
+func eventFrom(e miner.Event) Event {
+	return Event{
+		Type:     EventType(e.Type),
+		Time:     e.Time,
+		Streamer: e.Streamer,
+		Balance:  e.Balance,
+		Gained:   e.Gained,
+		Reason:   e.Reason,
+		Watching: e.Watching,
+		Placed:   e.Placed,
+		Drop:     e.Drop,
+		From:     e.From,
+		Message:  e.Message,
+	}
+}
+
+// State is the miner's startup or running state.
+type State string
+
+const (
+	StateInitializing     State = "initializing"
+	StateAuthRequired     State = "auth_required"
+	StateAuthWaiting      State = "auth_waiting"
+	StateLoadingStreamers State = "loading_streamers"
+	StateRunning          State = "running"
+	StateError            State = "error"
+)
+
+// Login is a pending device code login.
+type Login struct {
+	VerificationURI string
+	UserCode        string
+	// ExpiresIn is how long the code was valid for when it was issued.
+	ExpiresIn time.Duration
+}
+
+// Status is the miner's startup and running state, as the dashboard shows it.
+type Status struct {
+	State   State
+	Message string
+	// Login is set while the miner waits for the device code login.
+	Login        *Login
+	StreamerInfo string
+	// Warning is a problem shown while mining continues, until cleared.
+	Warning string
+}
+
+func statusFrom(s web.StatusInfo) Status {
+	status := Status{
+		State:        State(s.Status),
+		Message:      s.Message,
+		StreamerInfo: s.StreamerInfo,
+		Warning:      s.Warning,
+	}
+	if s.Auth != nil {
+		status.Login = &Login{
+			VerificationURI: s.Auth.VerificationURI,
+			UserCode:        s.Auth.UserCode,
+			ExpiresIn:       time.Duration(s.Auth.ExpiresIn) * time.Second,
+		}
+	}
+	return status
+}
+
+// relay converts what arrives on in and passes it on to the returned channel
+// until in is closed, dropping values the receiver is too slow for.
+func relay[T, U any](in <-chan T, convert func(T) U) <-chan U {
+	out := make(chan U, subscribeBuffer)
+	go func() {
+		defer close(out)
+		for v := range in {
+			select {
+			case out <- convert(v):
+			default:
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,201 @@
+// Package miner is the public API for embedding the Twitch channel points
+// miner in another Go program. It wraps the same miner the command runs:
+// build a Config (or load config.json), create a Miner with New, call Run and
+// follow what it does through Subscribe.
+//
+// The miner keeps its state relative to the working directory, as the
+// command does: cookies/ for the login, logs/ and database/<username>/.
+// Logging goes through log/slog's default logger.
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/PatrickWalther/twitch-miner-go/internal/analytics"
+	"github.com/PatrickWalther/twitch-miner-go/internal/config"
+	"github.com/PatrickWalther/twitch-miner-go/internal/database"
+	"github.com/PatrickWalther/twitch-miner-go/internal/httpx"
+	"github.com/PatrickWalther/twitch-miner-go/internal/miner"
+	"github.com/PatrickWalther/twitch-miner-go/internal/models"
+	"github.com/PatrickWalther/twitch-miner-go/internal/settings"
+	"github.com/PatrickWalther/twitch-miner-go/internal/web"
+)
+
+// Config and Settings mirror config.json and the dashboard's settings API,
+// so they follow those formats rather than promising a fixed Go API.
+type (
+	// Config is the miner configuration, in the format of config.json.
+	Config = config.Config
+	// StreamerConfig is a streamer to mine, with optional settings overrides.
+	StreamerConfig = config.StreamerConfig
+	// StreamerSettings are the per-streamer mining and betting settings.
+	StreamerSettings = models.StreamerSettings
+	// Settings are the settings that can be changed while the miner runs,
+	// in the shape used by the dashboard's settings API.
+	Settings = settings.RuntimeSettings
+)
+
+// ErrNotRunning is returned by ApplySettings before mining has started or
+// after it stopped.
+var ErrNotRunning = errors.New("miner is not running")
+
+// DefaultConfig returns a configuration with default settings and no
+// username or streamers.
+func DefaultConfig() *Config {
+	cfg := config.DefaultConfig()
+	return &cfg
+}
+
+// LoadConfig reads a config.json file, applying defaults for missing values.
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadConfig(path)
+}
+
+// Options controls how an embedded miner runs.
+type Options struct {
+	// ConfigPath is where settings applied at runtime are saved. When empty,
+	// changes only last until the miner stops.
+	ConfigPath string
+	// Dashboard serves the web dashboard on cfg.Analytics.Host and Port
+	// while the miner runs. It requires cfg.EnableAnalytics.
+	Dashboard bool
+}
+
+// Miner is an embedded miner.
+type Miner struct {
+	cfg    *Config
+	opts   Options
+	inner  *miner.Miner
+	status *web.StatusBroadcaster
+}
+
+// New validates cfg and creates a miner. The miner owns cfg from then on;
+// read the current configuration with Config.
+func New(cfg *Config, opts Options) (*Miner, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	if cfg.Username == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+	if len(cfg.Streamers) == 0 && !cfg.Follows.Enabled {
+		return nil, fmt.Errorf("at least one streamer is required unless follows are enabled")
+	}
+	config.ValidateConfig(cfg)
+
+	if err := httpx.SetTLS(cfg.TLS.CAFile, cfg.TLS.InsecureSkipVerify); err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+
+	return &Miner{
+		cfg:    cfg,
+		opts:   opts,
+		inner:  miner.New(cfg, opts.ConfigPath),
+		status: web.NewStatusBroadcaster(),
+	}, nil
+}
+
+// Run logs in, starts mining and blocks until ctx is cancelled or the miner
+// fails to start. On first use it asks for the Twitch device code login in
+// the log output. A Miner can only be run once.
+func (m *Miner) Run(ctx context.Context) error {
+	if m.cfg.EnableAnalytics {
+		dbBasePath := filepath.Join("database", m.cfg.Username)
+		if err := os.MkdirAll(dbBasePath, 0755); err != nil {
+			return fmt.Errorf("failed to create database directory: %w", err)
+		}
+		db, err := database.Open(dbBasePath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		analyticsSvc, err := analytics.NewService(db, dbBasePath)
+		if err != nil {
+			return fmt.Errorf("failed to create analytics service: %w", err)
+		}
+		m.inner.SetAnalyticsService(analyticsSvc)
+
+		if m.opts.Dashboard {
+			if webServer := web.NewServerEarly(m.cfg.Analytics, m.cfg.Username, dbBasePath, analyticsSvc); webServer != nil {
+				webServer.SetStatusBroadcaster(m.status)
+				webServer.Start()
+				defer webServer.Stop()
+				m.inner.SetWebServer(webServer)
+			}
+		}
+	}
+
+	return m.inner.Run(ctx)
+}
+
+// Subscribe returns a channel receiving the miner's events and a function
+// that unsubscribes and closes it. Slow subscribers miss events rather than
+// holding up the miner.
+func (m *Miner) Subscribe() (<-chan Event, func()) {
+	events, unsubscribe := m.inner.Subscribe()
+	return relay(events, eventFrom), unsubscribe
+}
+
+// SubscribeStatus returns a channel receiving every status change and a
+// function that unsubscribes. Status is only reported with Options.Dashboard.
+func (m *Miner) SubscribeStatus() (<-chan Status, func()) {
+	ch := m.status.Subscribe()
+	return relay(ch, statusFrom), func() { m.status.Unsubscribe(ch) }
+}
+
+// Status returns the current status.
+func (m *Miner) Status() Status {
+	return statusFrom(m.status.GetStatus())
+}
+
+// TotalPoints returns the channel points of all streamers combined.
+func (m *Miner) TotalPoints() int {
+	return m.inner.GetTotalPoints()
+}
+
+// Pause stops watching streams, so no watch points are earned, until
+// Resume. Predictions and bonus claims continue.
+func (m *Miner) Pause() {
+	m.inner.SetPaused(true)
+}
+
+// Resume continues watching after Pause.
+func (m *Miner) Resume() {
+	m.inner.SetPaused(false)
+}
+
+// Paused reports whether watching is paused.
+func (m *Miner) Paused() bool {
+	return m.inner.Paused()
+}
+
+// Config returns a copy of the current configuration.
+func (m *Miner) Config() Config {
+	return m.inner.GetConfig()
+}
+
+// Settings returns the current runtime settings.
+func (m *Miner) Settings() Settings {
+	return m.inner.GetRuntimeSettings()
+}
+
+// Running reports whether mining has started and not yet stopped.
+func (m *Miner) Running() bool {
+	return m.inner.Running()
+}
+
+// ApplySettings applies runtime settings, as saving the dashboard's settings
+// page does: streamers are added, removed and reconfigured in place and the
+// configuration is saved to Options.ConfigPath.
+func (m *Miner) ApplySettings(s Settings) error {
+	if !m.inner.Running() {
+		return ErrNotRunning
+	}
+	m.inner.ApplySettings(s)
+	return nil
+}